@@ -45,15 +45,17 @@ type AIUserSettingResponse struct {
 
 // AIDefaultSettingRequest 表示默认AI设置请求
 type AIDefaultSettingRequest struct {
-	DefaultModel      string `json:"defaultModel,omitempty"`
-	DefaultImageModel string `json:"defaultImageModel,omitempty"`
+	DefaultModel      string   `json:"defaultModel,omitempty"`
+	DefaultImageModel string   `json:"defaultImageModel,omitempty"`
+	FallbackModels    []string `json:"fallbackModels"` // 为 null 时保持不变，空数组表示清空
 }
 
 // AIDefaultSettingResponse 表示默认AI设置响应
 type AIDefaultSettingResponse struct {
-	ID                uint   `json:"id"`
-	DefaultModel      string `json:"defaultModel"`
-	DefaultImageModel string `json:"defaultImageModel"`
+	ID                uint     `json:"id"`
+	DefaultModel      string   `json:"defaultModel"`
+	DefaultImageModel string   `json:"defaultImageModel"`
+	FallbackModels    []string `json:"fallbackModels"`
 }
 
 // AIChatRequest 表示AI聊天请求
@@ -83,11 +85,72 @@ type AIImageGenerationRequest struct {
 	APIKey   string                 `json:"apiKey,omitempty"`
 	Endpoint string                 `json:"endpoint,omitempty"`
 	Params   map[string]interface{} `json:"params,omitempty"`
+	DraftID  uint                   `json:"draftId,omitempty"` // 将第一张图像设为该草稿的封面
+	PostID   uint                   `json:"postId,omitempty"`  // 将第一张图像设为该文章的封面
+}
+
+// AIAskRequest 表示知识库问答请求
+type AIAskRequest struct {
+	Question string `json:"question" binding:"required"`
+	Limit    int    `json:"limit,omitempty"` // 检索的分块数量
+	Stream   bool   `json:"stream"`
+}
+
+// AICitation 表示回答中引用的文章
+type AICitation struct {
+	Index  int     `json:"index"` // 回答中使用的引用编号 [n]
+	PostID uint    `json:"postId"`
+	Slug   string  `json:"slug"`
+	Title  string  `json:"title"`
+	Score  float64 `json:"score"`
+}
+
+// AIAskResponse 表示知识库问答响应
+type AIAskResponse struct {
+	Answer    string       `json:"answer"`
+	Citations []AICitation `json:"citations"`
+	Model     string       `json:"model"`
+}
+
+// AIImageResponse 表示已保存的AI生成图像
+type AIImageResponse struct {
+	ID           uint   `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	Provider     string `json:"provider"`
+	ModelID      string `json:"modelId"`
+	Prompt       string `json:"prompt"`
+	Size         string `json:"size"`
+	ContentType  string `json:"contentType"`
+	FileSize     int64  `json:"fileSize"`
 }
 
 // AIImageGenerationResponse 表示图像生成响应
 type AIImageGenerationResponse struct {
-	Images []string `json:"images"`
+	Images []string          `json:"images"`
+	Items  []AIImageResponse `json:"items"`
+}
+
+// AIAssistRequest 表示写作助手请求
+type AIAssistRequest struct {
+	Action          string `json:"action" binding:"required,oneof=summarize suggest_title suggest_tags translate fix_grammar continue"`
+	DraftID         uint   `json:"draftId,omitempty"`
+	PostID          uint   `json:"postId,omitempty"`
+	Content         string `json:"content,omitempty"`  // 选中的文本，为空时使用草稿或文章的全文
+	Language        string `json:"language,omitempty"` // 翻译的目标语言
+	TemplateVersion int    `json:"templateVersion,omitempty"`
+	Stream          bool   `json:"stream"`
+	Apply           bool   `json:"apply"` // 是否将摘要或标题写回草稿或文章
+}
+
+// AIAssistResponse 表示写作助手响应
+type AIAssistResponse struct {
+	Action          string    `json:"action"`
+	Result          string    `json:"result"`
+	Tags            []TagInfo `json:"tags,omitempty"`
+	Model           string    `json:"model"`
+	TemplateVersion int       `json:"templateVersion"`
+	Applied         bool      `json:"applied"`
 }
 
 // AIAvailableModelsResponse 表示可用AI模型响应
@@ -158,5 +221,21 @@ func ConvertToAIDefaultSettingResponse(setting *model.AIDefaultSetting) AIDefaul
 		ID:                setting.ID,
 		DefaultModel:      setting.DefaultModel,
 		DefaultImageModel: setting.DefaultImageModel,
+		FallbackModels:    setting.FallbackModels,
+	}
+}
+
+// ConvertToAIImageResponse 将模型转换为响应
+func ConvertToAIImageResponse(image *model.AIImage) AIImageResponse {
+	return AIImageResponse{
+		ID:           image.ID,
+		URL:          image.URL,
+		ThumbnailURL: image.ThumbnailURL,
+		Provider:     image.Provider,
+		ModelID:      image.ModelID,
+		Prompt:       image.Prompt,
+		Size:         image.Size,
+		ContentType:  image.ContentType,
+		FileSize:     image.FileSize,
 	}
 }
@@ -0,0 +1,63 @@
+package dto
+
+// AnalyticsQuery 作者统计查询参数
+type AnalyticsQuery struct {
+	From        string `form:"from"`                                                   // 开始日期 YYYY-MM-DD，默认为结束日期前 29 天
+	To          string `form:"to"`                                                     // 结束日期 YYYY-MM-DD，默认为今天（UTC）
+	Granularity string `form:"granularity,default=day" binding:"oneof=day week month"` // 时间序列的粒度，周从周一开始
+	UserID      uint   `form:"user_id"`                                                // 要查看的作者，默认为当前用户，只有管理员可以查看其他作者
+}
+
+// AnalyticsTotals 统计区间内的合计
+type AnalyticsTotals struct {
+	Views          int64 `json:"views"`
+	UniqueVisitors int64 `json:"unique_visitors"` // 各文章每日独立访客数之和
+	Comments       int64 `json:"comments"`
+	NewFollowers   int64 `json:"new_followers"` // 只在作者统计中计算，单篇文章统计中为 0
+}
+
+// AnalyticsPoint 时间序列中的一个周期
+type AnalyticsPoint struct {
+	Period string `json:"period"` // 周期的开始日期 YYYY-MM-DD
+	AnalyticsTotals
+}
+
+// PostTrafficStat 文章在统计区间内的浏览量
+type PostTrafficStat struct {
+	PostID         uint   `json:"post_id"`
+	Title          string `json:"title"`
+	Views          int64  `json:"views"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// GroupTrafficStat 分类或标签下文章在统计区间内的浏览量
+type GroupTrafficStat struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Views int64  `json:"views"`
+}
+
+// AuthorAnalyticsResponse 作者统计响应，汇总数据由后台任务定期更新
+type AuthorAnalyticsResponse struct {
+	UserID       uint               `json:"user_id"`
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	Granularity  string             `json:"granularity"`
+	Totals       AnalyticsTotals    `json:"totals"`
+	Series       []AnalyticsPoint   `json:"series"`
+	TopPosts     []PostTrafficStat  `json:"top_posts"`
+	TopReferrers []ReferrerStat     `json:"top_referrers"`
+	Categories   []GroupTrafficStat `json:"categories"`
+	Tags         []GroupTrafficStat `json:"tags"`
+}
+
+// PostAnalyticsResponse 单篇文章的统计响应
+type PostAnalyticsResponse struct {
+	PostID      uint             `json:"post_id"`
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Granularity string           `json:"granularity"`
+	Totals      AnalyticsTotals  `json:"totals"`
+	Series      []AnalyticsPoint `json:"series"`
+	Referrers   []ReferrerStat   `json:"referrers"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"fmt"
+	"notex/model"
+	"time"
+)
+
+// AttachmentResponse 附件响应
+type AttachmentResponse struct {
+	ID          uint      `json:"id"`
+	Filename    string    `json:"filename"`
+	MimeType    string    `json:"mime_type"`
+	Size        int64     `json:"size"`
+	Position    int       `json:"position"`
+	Downloads   int64     `json:"downloads"`
+	DownloadURL string    `json:"download_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentDownloadURL 返回附件的下载地址
+func AttachmentDownloadURL(id uint) string {
+	return fmt.Sprintf("/api/public/attachments/%d", id)
+}
+
+// ConvertToAttachmentResponse 将模型转换为响应
+func ConvertToAttachmentResponse(attachment *model.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          attachment.ID,
+		Filename:    attachment.Filename,
+		MimeType:    attachment.MimeType,
+		Size:        attachment.Size,
+		Position:    attachment.Position,
+		Downloads:   attachment.Downloads,
+		DownloadURL: AttachmentDownloadURL(attachment.ID),
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+// ConvertToAttachmentResponses 将附件列表转换为响应
+func ConvertToAttachmentResponses(attachments []model.Attachment) []AttachmentResponse {
+	responses := make([]AttachmentResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = ConvertToAttachmentResponse(&attachments[i])
+	}
+	return responses
+}
@@ -45,16 +45,18 @@ type ChangePasswordRequest struct {
 
 // UserProfile 用户信息
 type UserProfile struct {
-	ID           uint      `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	Role         string    `json:"role"`
-	Status       string    `json:"status"`
-	Bio          string    `json:"bio"`
-	Avatar       string    `json:"avatar"`
-	PostCount    int64     `json:"post_count"`
-	CommentCount int64     `json:"comment_count"`
-	ViewCount    int64     `json:"view_count"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             uint      `json:"id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	Status         string    `json:"status"`
+	Bio            string    `json:"bio"`
+	Avatar         string    `json:"avatar"`
+	PostCount      int64     `json:"post_count"`
+	CommentCount   int64     `json:"comment_count"`
+	ViewCount      int64     `json:"view_count"`
+	FollowerCount  int64     `json:"follower_count"`  // 关注该用户的人数
+	FollowingCount int64     `json:"following_count"` // 该用户关注的作者数
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
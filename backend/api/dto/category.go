@@ -4,29 +4,71 @@ import "time"
 
 // CreateCategoryRequest 创建分类请求
 type CreateCategoryRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	Name              string `json:"name" binding:"required"`
+	Slug              string `json:"slug"` // 为空时根据名称生成
+	Description       string `json:"description"`
+	Cover             string `json:"cover"`
+	ParentID          *uint  `json:"parent_id"` // 上级分类，需要与新分类属于同一工作区
+	SortOrder         int    `json:"sort_order"`
+	CommentMode       string `json:"comment_mode"`       // open, approval, closed，为空时继承上级分类
+	DefaultVisibility string `json:"default_visibility"` // public, unlisted, private，为空时继承上级分类
+	WorkspaceID       *uint  `json:"workspace_id"`       // 所属工作区，为空时创建全站分类
 }
 
-// UpdateCategoryRequest 更新分类请求
+// UpdateCategoryRequest 更新分类请求，未提供的字段保持不变
 type UpdateCategoryRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name              string  `json:"name"`
+	Slug              string  `json:"slug"`
+	Description       string  `json:"description"`
+	Cover             *string `json:"cover"`     // 为空字符串时移除封面
+	ParentID          *uint   `json:"parent_id"` // 为 0 时移为顶级分类
+	SortOrder         *int    `json:"sort_order"`
+	CommentMode       *string `json:"comment_mode"`       // 为空字符串时改为继承上级分类
+	DefaultVisibility *string `json:"default_visibility"` // 为空字符串时改为继承上级分类
 }
 
 // CategoryResponse 分类响应
 type CategoryResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	PostCount   int64     `json:"post_count"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uint      `json:"id"`
+	Name              string    `json:"name"`
+	Slug              string    `json:"slug"`
+	Description       string    `json:"description"`
+	Cover             string    `json:"cover,omitempty"`
+	ParentID          *uint     `json:"parent_id"`
+	SortOrder         int       `json:"sort_order"`
+	CommentMode       string    `json:"comment_mode"`       // 分类自身的设置，为空时继承上级分类
+	DefaultVisibility string    `json:"default_visibility"` // 分类自身的设置，为空时继承上级分类
+	WorkspaceID       *uint     `json:"workspace_id,omitempty"`
+	PostCount         int64     `json:"post_count"`       // 直接属于该分类的文章数量
+	TotalPostCount    int64     `json:"total_post_count"` // 包括全部下级分类的文章数量
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// 沿上级分类继承后实际生效的设置
+	EffectiveCommentMode       string `json:"effective_comment_mode"`
+	EffectiveDefaultVisibility string `json:"effective_default_visibility"`
+}
+
+// CategoryNode 分类树中的节点
+type CategoryNode struct {
+	CategoryResponse
+	Children []*CategoryNode `json:"children"`
+}
+
+// CategoryTreeQuery 分类树查询参数
+type CategoryTreeQuery struct {
+	WorkspaceID uint `form:"workspace_id"` // 为空时返回全站分类
 }
 
 // CategoryListQuery 分类列表查询参数
 type CategoryListQuery struct {
-	Page     int    `form:"page,default=1"`
-	PageSize int    `form:"page_size,default=10"`
-	Search   string `form:"search"`
+	Page        int    `form:"page,default=1"`
+	PageSize    int    `form:"page_size,default=10"`
+	Search      string `form:"search"`
+	WorkspaceID uint   `form:"workspace_id"` // 为空时列出全站分类
+}
+
+// CategoryEditorsRequest 替换分类责任编辑请求
+type CategoryEditorsRequest struct {
+	UserIDs []uint `json:"user_ids"` // 为空数组时移除全部责任编辑
 }
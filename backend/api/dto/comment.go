@@ -16,18 +16,63 @@ type CommentRequest struct {
 	ReplyToID *uint  `json:"reply_to_id"` // ID of the comment being replied to
 }
 
+// GuestCommentRequest 访客评论请求
+type GuestCommentRequest struct {
+	Content       string `json:"content" binding:"required"`
+	ParentID      *uint  `json:"parent_id"`
+	ReplyToID     *uint  `json:"reply_to_id"`
+	Name          string `json:"name" binding:"required,max=50"`
+	Email         string `json:"email" binding:"required,email,max=100"`
+	CaptchaToken  string `json:"captcha_token"`  // 内置验证码的挑战令牌
+	CaptchaAnswer string `json:"captcha_answer"` // 验证码的答案，或 hCaptcha 组件返回的响应
+}
+
+// GuestCommentResponse 访客评论的提交结果，评论在邮箱确认前不公开
+type GuestCommentResponse struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"`
+}
+
+// VerifyGuestCommentQuery 确认访客评论的参数，令牌来自确认邮件中的链接
+type VerifyGuestCommentQuery struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// ClaimGuestCommentsResponse 认领访客评论的结果
+type ClaimGuestCommentsResponse struct {
+	Claimed int64 `json:"claimed"`
+}
+
+// CaptchaResponse 人机验证挑战
+type CaptchaResponse struct {
+	Provider string `json:"provider"`           // none 表示不需要验证
+	Token    string `json:"token,omitempty"`    // 内置验证码的挑战令牌，提交评论时原样带回
+	Image    string `json:"image,omitempty"`    // 内置验证码的题目图片（data URL）
+	SiteKey  string `json:"site_key,omitempty"` // hCaptcha 站点密钥
+}
+
+// UpdateCommentRequest 修改评论请求
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
 // CommentResponse represents a comment with user information
 type CommentResponse struct {
-	ID         uint      `json:"id"`
-	Content    string    `json:"content"`
-	PostID     uint      `json:"post_id"`
-	PostTitle  string    `json:"post_title,omitempty"`
-	UserID     uint      `json:"user_id"`
-	ParentID   *uint     `json:"parent_id,omitempty"`
-	User       *UserInfo `json:"user"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	ReplyCount int       `json:"reply_count"` // 子评论数量
+	ID          uint              `json:"id"`
+	Content     string            `json:"content"`
+	ContentHTML string            `json:"content_html"` // 转义后的内容，@ 到的用户链接到其主页
+	PostID      uint              `json:"post_id"`
+	PostTitle   string            `json:"post_title,omitempty"`
+	Status      string            `json:"status"` // active, hidden（已隐藏或等待审核）
+	UserID      uint              `json:"user_id"`
+	ParentID    *uint             `json:"parent_id,omitempty"`
+	User        *UserInfo         `json:"user"`
+	Mentions    []*UserInfo       `json:"mentions,omitempty"` // 内容中 @ 到的用户
+	Reactions   []ReactionSummary `json:"reactions"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	EditedAt    *time.Time        `json:"edited_at,omitempty"` // 作者最后一次修改的时间
+	ReplyCount  int               `json:"reply_count"`         // 子评论数量
 
 	// 回复相关
 	Parent   *CommentBrief   `json:"parent,omitempty"`   // Parent comment if this is a reply
@@ -37,22 +82,40 @@ type CommentResponse struct {
 
 // CommentBrief represents a brief version of a comment
 type CommentBrief struct {
-	ID        uint      `json:"id"`
-	Content   string    `json:"content"`
-	UserID    uint      `json:"user_id"`
-	User      *UserInfo `json:"user"`
-	CreatedAt time.Time `json:"created_at"`
-	ReplyTo   *struct { // 添加被回复评论的信息
+	ID          uint              `json:"id"`
+	Content     string            `json:"content"`
+	ContentHTML string            `json:"content_html,omitempty"`
+	UserID      uint              `json:"user_id"`
+	User        *UserInfo         `json:"user"`
+	Reactions   []ReactionSummary `json:"reactions,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	EditedAt    *time.Time        `json:"edited_at,omitempty"`
+	ReplyTo     *struct {         // 添加被回复评论的信息
 		ID   uint      `json:"id"`
 		User *UserInfo `json:"user"`
 	} `json:"reply_to,omitempty"`
 }
 
+// ReactionSummary 评论的一种表情回应
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"` // 当前用户是否使用了该表情
+}
+
+// CommentRevisionResponse 评论修改前的一个版本
+type CommentRevisionResponse struct {
+	ID         uint      `json:"id"`
+	Content    string    `json:"content"`
+	ReplacedAt time.Time `json:"replaced_at"` // 该版本被修改的时间
+}
+
 // UserInfo 评论用户信息
 type UserInfo struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
 	Avatar   string `json:"avatar"`
+	Guest    bool   `json:"guest,omitempty"` // 访客评论的作者，ID 为 0，Username 为访客填写的名称
 }
 
 // CommentListQuery 评论列表查询参数
@@ -61,3 +124,26 @@ type CommentListQuery struct {
 	PageSize     int  `form:"page_size,default=10"`
 	WithChildren bool `form:"with_children"` // 是否包含子评论
 }
+
+// CommentTreeQuery 评论树查询参数
+type CommentTreeQuery struct {
+	ParentID   uint   `form:"parent_id"`                                                   // 从该评论的回复开始，用于加载某条评论下的更多回复
+	Cursor     string `form:"cursor"`                                                      // 上一页返回的游标，第一层使用 next_cursor，回复使用所在评论的 replies_cursor
+	Sort       string `form:"sort,default=newest" binding:"oneof=newest oldest reactions"` // 每一层的排序方式
+	Limit      int    `form:"limit,default=20" binding:"min=1,max=100"`                    // 第一层的评论数量
+	ReplyLimit int    `form:"reply_limit,default=3" binding:"min=0,max=20"`                // 每条评论下加载的回复数量
+	Depth      int    `form:"depth,default=3" binding:"min=1,max=5"`                       // 加载的层数，包括第一层
+}
+
+// CommentNode 评论树中的一条评论
+type CommentNode struct {
+	CommentResponse
+	Replies       []*CommentNode `json:"replies"`                  // 已加载的回复，超过查询层数时为空
+	RepliesCursor string         `json:"replies_cursor,omitempty"` // 还有更多回复时用于加载下一页的游标
+}
+
+// CommentTreeResponse 评论树响应
+type CommentTreeResponse struct {
+	Items      []*CommentNode `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"` // 第一层没有更多评论时为空
+}
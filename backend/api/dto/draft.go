@@ -4,41 +4,47 @@ import "time"
 
 // CreateDraftRequest 创建草稿请求
 type CreateDraftRequest struct {
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content"`
-	Summary    string `json:"summary"`
-	Cover      string `json:"cover"`
-	CategoryID uint   `json:"category_id"`
-	TagIDs     []uint `json:"tag_ids"`
+	Title         string `json:"title" binding:"required"`
+	Content       string `json:"content"`
+	Summary       string `json:"summary"`
+	Cover         string `json:"cover"`
+	CategoryID    uint   `json:"category_id"`
+	TagIDs        []uint `json:"tag_ids"`
+	AttachmentIDs []uint `json:"attachment_ids"` // 按顺序关联的附件
+	WorkspaceID   *uint  `json:"workspace_id"`   // 所属工作区，为空时属于作者个人
 }
 
 // UpdateDraftRequest 更新草稿请求
 type UpdateDraftRequest struct {
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	Summary    string `json:"summary"`
-	Cover      string `json:"cover"`
-	CategoryID uint   `json:"category_id"`
-	TagIDs     []uint `json:"tag_ids"`
+	Title         string  `json:"title"`
+	Content       string  `json:"content"`
+	Summary       string  `json:"summary"`
+	Cover         string  `json:"cover"`
+	CategoryID    uint    `json:"category_id"`
+	TagIDs        []uint  `json:"tag_ids"`
+	AttachmentIDs *[]uint `json:"attachment_ids"` // 按顺序替换附件，为空数组时移除全部附件
 }
 
 // DraftResponse 草稿响应
 type DraftResponse struct {
-	ID         uint      `json:"id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	Summary    string    `json:"summary"`
-	Cover      string    `json:"cover"`
-	CategoryID uint      `json:"category_id"`
-	Category   string    `json:"category"`
-	Tags       []TagInfo `json:"tags"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID          uint                 `json:"id"`
+	Title       string               `json:"title"`
+	Content     string               `json:"content"`
+	Summary     string               `json:"summary"`
+	Cover       string               `json:"cover"`
+	CategoryID  uint                 `json:"category_id"`
+	Category    string               `json:"category"`
+	WorkspaceID *uint                `json:"workspace_id,omitempty"`
+	Tags        []TagInfo            `json:"tags"`
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
 }
 
 // DraftListQuery 草稿列表查询参数
 type DraftListQuery struct {
-	Page     int    `form:"page,default=1"`
-	PageSize int    `form:"page_size,default=10"`
-	Search   string `form:"search"`
+	Page        int    `form:"page,default=1"`
+	PageSize    int    `form:"page_size,default=10"`
+	Search      string `form:"search"`
+	WorkspaceID uint   `form:"workspace_id"` // 工作区成员可以列出工作区的全部草稿
 }
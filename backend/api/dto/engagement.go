@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// LikeResponse 点赞状态
+type LikeResponse struct {
+	Liked     bool  `json:"liked"`
+	LikeCount int64 `json:"like_count"`
+}
+
+// BookmarkRequest 收藏文章请求
+type BookmarkRequest struct {
+	ReadingListID *uint `json:"reading_list_id"` // 归入的阅读列表，为空时不归入阅读列表
+}
+
+// BookmarkListQuery 收藏列表查询参数
+type BookmarkListQuery struct {
+	Page          int   `form:"page,default=1" binding:"min=1"`
+	PageSize      int   `form:"page_size,default=10" binding:"min=1,max=100"`
+	ReadingListID *uint `form:"reading_list_id"` // 只列出该阅读列表中的收藏，为 0 时列出未归入阅读列表的收藏
+}
+
+// BookmarkResponse 收藏响应
+type BookmarkResponse struct {
+	ID            uint          `json:"id"`
+	PostID        uint          `json:"post_id"`
+	ReadingListID *uint         `json:"reading_list_id"`
+	Post          *PostResponse `json:"post,omitempty"`
+	Unavailable   bool          `json:"unavailable,omitempty"` // 文章已不再对当前用户可见
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// ReadingListRequest 创建或修改阅读列表请求
+type ReadingListRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// ReadingListResponse 阅读列表响应
+type ReadingListResponse struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	BookmarkCount int64     `json:"bookmark_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// FollowStatus 关注状态
+type FollowStatus struct {
+	Following     bool  `json:"following"`
+	FollowerCount int64 `json:"follower_count"`
+}
+
+// FollowListQuery 关注列表查询参数
+type FollowListQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// FollowResponse 关注列表中的一项，Avatar 只有用户才有
+type FollowResponse struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	Avatar     string    `json:"avatar,omitempty"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FeedQuery 关注动态查询参数
+type FeedQuery struct {
+	Cursor string `form:"cursor"` // 上一页返回的 next_cursor，为空时从最新的文章开始
+	Limit  int    `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
+// FeedResponse 关注动态，按发布时间倒序
+type FeedResponse struct {
+	Items      []PostResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"` // 没有更多文章时为空
+}
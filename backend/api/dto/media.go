@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"notex/model"
+	"notex/pkg/storage"
+	"time"
+)
+
+// MediaListRequest 媒体文件列表请求
+type MediaListRequest struct {
+	Page       int    `form:"page" binding:"required,min=1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100"`
+	Search     string `form:"search"`
+	MimeType   string `form:"mime_type"`
+	Orphaned   *bool  `form:"orphaned"`
+	ScanStatus string `form:"scan_status"`
+	UserID     uint   `form:"user_id"` // 仅管理员接口使用
+}
+
+// MediaReferenceResponse 媒体文件引用响应
+type MediaReferenceResponse struct {
+	RefType string `json:"ref_type"`
+	RefID   uint   `json:"ref_id"`
+	Field   string `json:"field"`
+}
+
+// MediaAssetResponse 媒体文件响应
+type MediaAssetResponse struct {
+	ID            uint                     `json:"id"`
+	UserID        uint                     `json:"user_id"`
+	Username      string                   `json:"username,omitempty"`
+	StorageType   string                   `json:"storage_type"`
+	Key           string                   `json:"key"`
+	URL           string                   `json:"url"`
+	ThumbnailURL  string                   `json:"thumbnail_url"`
+	Filename      string                   `json:"filename"`
+	Size          int64                    `json:"size"`
+	MimeType      string                   `json:"mime_type"`
+	Width         int                      `json:"width"`
+	Height        int                      `json:"height"`
+	Checksum      string                   `json:"checksum"`
+	RefCount      int                      `json:"ref_count"`
+	OrphanedAt    *time.Time               `json:"orphaned_at"`
+	Variants      model.MediaVariants      `json:"variants,omitempty"`
+	ScanStatus    string                   `json:"scan_status"`
+	ScanSignature string                   `json:"scan_signature,omitempty"`
+	QuarantinedAt *time.Time               `json:"quarantined_at,omitempty"`
+	References    []MediaReferenceResponse `json:"references,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"`
+}
+
+// MediaListResponse 媒体文件列表响应
+type MediaListResponse struct {
+	Total int64                `json:"total"`
+	Items []MediaAssetResponse `json:"items"`
+}
+
+// MediaUploadResponse 文件上传响应
+type MediaUploadResponse struct {
+	*storage.UploadResult
+	AssetID      uint                `json:"asset_id"`
+	Width        int                 `json:"width"`
+	Height       int                 `json:"height"`
+	Variants     model.MediaVariants `json:"variants,omitempty"`
+	Deduplicated bool                `json:"deduplicated"` // 是否复用了已存储的相同文件
+}
+
+// MediaImageURLRequest 图片动态缩放URL请求
+type MediaImageURLRequest struct {
+	Width  int    `form:"w" binding:"required,min=1"`
+	Format string `form:"fmt"`
+}
+
+// MediaImageURLResponse 图片动态缩放URL响应
+type MediaImageURLResponse struct {
+	URL string `json:"url"`
+}
+
+// MediaScanResponse 引用扫描结果
+type MediaScanResponse struct {
+	Assets     int `json:"assets"`     // 文件总数
+	References int `json:"references"` // 找到的引用数
+	Orphaned   int `json:"orphaned"`   // 无引用的文件数
+}
+
+// MediaGCResponse 垃圾回收结果
+type MediaGCResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// ConvertToMediaAssetResponse 将模型转换为响应
+func ConvertToMediaAssetResponse(asset *model.MediaAsset) MediaAssetResponse {
+	resp := MediaAssetResponse{
+		ID:            asset.ID,
+		UserID:        asset.UserID,
+		StorageType:   asset.StorageType,
+		Key:           asset.Key,
+		URL:           asset.URL,
+		ThumbnailURL:  asset.ThumbnailURL,
+		Filename:      asset.Filename,
+		Size:          asset.Size,
+		MimeType:      asset.MimeType,
+		Width:         asset.Width,
+		Height:        asset.Height,
+		Checksum:      asset.Checksum,
+		RefCount:      asset.RefCount,
+		OrphanedAt:    asset.OrphanedAt,
+		Variants:      asset.Variants,
+		ScanStatus:    asset.ScanStatus,
+		ScanSignature: asset.ScanSignature,
+		QuarantinedAt: asset.QuarantinedAt,
+		CreatedAt:     asset.CreatedAt,
+	}
+	if asset.User != nil {
+		resp.Username = asset.User.Username
+	}
+	for _, ref := range asset.References {
+		resp.References = append(resp.References, MediaReferenceResponse{
+			RefType: ref.RefType,
+			RefID:   ref.RefID,
+			Field:   ref.Field,
+		})
+	}
+	return resp
+}
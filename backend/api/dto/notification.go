@@ -4,13 +4,14 @@ import "time"
 
 // NotificationResponse 通知响应
 type NotificationResponse struct {
-	ID        uint      `json:"id"`
-	Type      string    `json:"type"`       // 通知类型：comment, reply
-	Content   string    `json:"content"`    // 通知内容
-	PostID    uint      `json:"post_id"`    // 相关文章ID
-	CommentID uint      `json:"comment_id"` // 相关评论ID
-	IsRead    bool      `json:"is_read"`    // 是否已读
-	CreatedAt time.Time `json:"created_at"` // 创建时间
+	ID         uint      `json:"id"`
+	Type       string    `json:"type"`        // 通知类型：post_comment, comment_reply, post_like, followed_post, mention
+	Content    string    `json:"content"`     // 通知内容
+	PostID     uint      `json:"post_id"`     // 相关文章ID
+	CommentID  uint      `json:"comment_id"`  // 相关评论ID
+	IsRead     bool      `json:"is_read"`     // 是否已读
+	ActorCount int       `json:"actor_count"` // 聚合通知中触发通知的人数，发送者为最近一位
+	CreatedAt  time.Time `json:"created_at"`  // 创建时间
 
 	// 发送者信息
 	SenderID       uint   `json:"sender_id"`
@@ -39,5 +40,5 @@ type NotificationListQuery struct {
 	Page     int    `form:"page,default=1"`
 	PageSize int    `form:"page_size,default=10"`
 	Unread   *bool  `form:"unread,omitempty"` // 是否只查询未读通知
-	Type     string `form:"type,omitempty"`   // 通知类型过滤：post_comment, comment_reply
+	Type     string `form:"type,omitempty"`   // 通知类型过滤：post_comment, comment_reply, post_like, followed_post, mention
 }
@@ -10,60 +10,99 @@ type TagInfo struct {
 
 // CreatePostRequest 创建文章请求
 type CreatePostRequest struct {
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	Summary    string `json:"summary"`
-	Cover      string `json:"cover"`
-	Slug       string `json:"slug"`
-	CategoryID uint   `json:"category_id"`
-	TagIDs     []uint `json:"tag_ids"`
-	Status     string `json:"status" binding:"required,oneof=draft published"`
-	UserID     uint   `json:"-"` // 内部使用，不从请求参数中绑定
+	Title         string `json:"title" binding:"required"`
+	Content       string `json:"content" binding:"required"`
+	Summary       string `json:"summary"`
+	Cover         string `json:"cover"`
+	Slug          string `json:"slug"`
+	CategoryID    uint   `json:"category_id"`
+	TagIDs        []uint `json:"tag_ids"`
+	AttachmentIDs []uint `json:"attachment_ids"` // 按顺序关联的附件
+	Status        string `json:"status" binding:"required,oneof=draft published"`
+	Visibility    string `json:"visibility" binding:"omitempty,oneof=public unlisted private password"` // 默认为 public
+	Password      string `json:"password" binding:"omitempty,max=72"`                                   // 可见性为 password 时的访问密码
+	WorkspaceID   *uint  `json:"workspace_id"`                                                          // 所属工作区，为空时属于作者个人
+	UserID        uint   `json:"-"`                                                                     // 内部使用，不从请求参数中绑定
+
+	GuestCommentsDisabled bool `json:"guest_comments_disabled"` // 关闭本文的访客评论
 }
 
 // UpdatePostRequest 更新文章请求
 type UpdatePostRequest struct {
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	Summary    string `json:"summary"`
-	Cover      string `json:"cover"`
-	Slug       string `json:"slug"`
-	CategoryID uint   `json:"category_id"`
-	TagIDs     []uint `json:"tag_ids"`
-	Status     string `json:"status" binding:"omitempty,oneof=draft published"`
+	Title         string  `json:"title"`
+	Content       string  `json:"content"`
+	Summary       string  `json:"summary"`
+	Cover         string  `json:"cover"`
+	Slug          string  `json:"slug"`
+	CategoryID    uint    `json:"category_id"`
+	TagIDs        []uint  `json:"tag_ids"`
+	AttachmentIDs *[]uint `json:"attachment_ids"` // 按顺序替换附件，为空数组时移除全部附件
+	Status        string  `json:"status" binding:"omitempty,oneof=draft published"`
+	Visibility    string  `json:"visibility" binding:"omitempty,oneof=public unlisted private password"`
+	Password      string  `json:"password" binding:"omitempty,max=72"` // 为空时保留原有的访问密码
+
+	GuestCommentsDisabled *bool `json:"guest_comments_disabled"` // 为空时不修改
 }
 
 // PostResponse 文章响应
 type PostResponse struct {
-	ID           uint      `json:"id"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	Summary      string    `json:"summary"`
-	Cover        string    `json:"cover"`
-	Slug         string    `json:"slug"`
-	CategoryID   uint      `json:"category_id"`
-	Category     string    `json:"category"`
-	Tags         []TagInfo `json:"tags"`
-	Status       string    `json:"status"`
-	Views        int64     `json:"views"`
-	CommentCount int64     `json:"comment_count"`
-	Author       *UserInfo `json:"author"`
-	PublishedAt  time.Time `json:"published_at"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uint                 `json:"id"`
+	Title         string               `json:"title"`
+	Content       string               `json:"content"`
+	Summary       string               `json:"summary"`
+	Cover         string               `json:"cover"`
+	Slug          string               `json:"slug"`
+	CategoryID    uint                 `json:"category_id"`
+	Category      string               `json:"category"`
+	WorkspaceID   *uint                `json:"workspace_id,omitempty"`
+	Tags          []TagInfo            `json:"tags"`
+	Status        string               `json:"status"`
+	Visibility    string               `json:"visibility"`
+	Protected     bool                 `json:"protected,omitempty"` // 密码保护且未解锁，内容已隐藏
+	Views         int64                `json:"views"`
+	CommentCount  int64                `json:"comment_count"`
+	LikeCount     int64                `json:"like_count"`
+	BookmarkCount int64                `json:"bookmark_count"`
+	Liked         bool                 `json:"liked,omitempty"`      // 当前用户已点赞，只在文章详情中返回
+	Bookmarked    bool                 `json:"bookmarked,omitempty"` // 当前用户已收藏，只在文章详情中返回
+	Author        *UserInfo            `json:"author"`
+	Attachments   []AttachmentResponse `json:"attachments,omitempty"`
+	PublishedAt   time.Time            `json:"published_at"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+
+	GuestCommentsDisabled bool `json:"guest_comments_disabled"` // 作者关闭了本文的访客评论
 }
 
 // PostListQuery 文章列表查询参数
 type PostListQuery struct {
-	Page       int    `form:"page,default=1"`
-	PageSize   int    `form:"page_size,default=10"`
-	Status     string `form:"status"`
-	CategoryID uint   `form:"category_id"`
-	TagID      uint   `form:"tag_id"`
-	Search     string `form:"search"`
-	Sort       string `form:"sort"`
-	User       string `form:"user"` // 用于过滤特定用户的文章，值为 "current" 时表示当前用户
-	UserID     uint   `form:"-"`    // 内部使用，不从请求参数中绑定
+	Page        int    `form:"page,default=1"`
+	PageSize    int    `form:"page_size,default=10"`
+	Status      string `form:"status"`
+	CategoryID  uint   `form:"category_id"`
+	TagID       uint   `form:"tag_id"`
+	Search      string `form:"search"`
+	Mode        string `form:"mode" binding:"omitempty,oneof=keyword semantic"` // 搜索模式：keyword（默认）, semantic
+	Sort        string `form:"sort"`
+	User        string `form:"user"`         // 用于过滤特定用户的文章，值为 "current" 时表示当前用户
+	WorkspaceID uint   `form:"workspace_id"` // 工作区成员可以列出工作区的全部文章
+	UserID      uint   `form:"-"`            // 内部使用，不从请求参数中绑定
+	Listed      bool   `form:"-"`            // 内部使用，仅列出公开列表中可见的已发布文章
+	AllPersonal bool   `form:"-"`            // 内部使用，Listed 为真时仍列出不属于工作区的全部文章
+
+	// 按分类过滤时是否包括全部下级分类中的文章
+	IncludeSubcategories bool `form:"include_subcategories"`
+}
+
+// UnlockPostRequest 解锁密码保护文章请求
+type UnlockPostRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// UnlockPostResponse 解锁密码保护文章响应，访问文章时通过 X-Post-Token 头携带令牌
+type UnlockPostResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // ArchiveResponse 文章归档响应
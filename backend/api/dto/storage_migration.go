@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"notex/model"
+	"time"
+)
+
+// StorageMigrationRequest 存储迁移请求
+type StorageMigrationRequest struct {
+	Source string `json:"source" binding:"required,oneof=local oss cos minio s3"`
+	Target string `json:"target" binding:"required,oneof=local oss cos minio s3"`
+	DryRun bool   `json:"dry_run"` // 只检查并统计，不复制文件也不修改数据
+}
+
+// StorageMigrationResumeRequest 继续执行存储迁移请求
+type StorageMigrationResumeRequest struct {
+	SkipFailed bool `json:"skip_failed"` // 跳过复制失败的文件，直接改写其余URL
+}
+
+// StorageMigrationListRequest 存储迁移任务列表请求
+type StorageMigrationListRequest struct {
+	Page     int `form:"page" binding:"required,min=1"`
+	PageSize int `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// StorageMigrationResponse 存储迁移任务响应
+type StorageMigrationResponse struct {
+	ID         uint       `json:"id"`
+	Source     string     `json:"source"`
+	Target     string     `json:"target"`
+	DryRun     bool       `json:"dry_run"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Copied     int        `json:"copied"`
+	Failed     int        `json:"failed"`
+	Missing    int        `json:"missing"`
+	Rewritten  int        `json:"rewritten"`
+	Progress   float64    `json:"progress"` // 已处理文件的百分比
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedBy  uint       `json:"created_by"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// StorageMigrationListResponse 存储迁移任务列表响应
+type StorageMigrationListResponse struct {
+	Total int64                      `json:"total"`
+	Items []StorageMigrationResponse `json:"items"`
+}
+
+// StorageMigrationItemListRequest 迁移文件列表请求
+type StorageMigrationItemListRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Status   string `form:"status"`
+}
+
+// StorageMigrationItemResponse 迁移文件响应
+type StorageMigrationItemResponse struct {
+	ID        uint      `json:"id"`
+	Key       string    `json:"key"`
+	SourceURL string    `json:"source_url"`
+	TargetURL string    `json:"target_url"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StorageMigrationItemListResponse 迁移文件列表响应
+type StorageMigrationItemListResponse struct {
+	Total int64                          `json:"total"`
+	Items []StorageMigrationItemResponse `json:"items"`
+}
+
+// ConvertToStorageMigrationResponse 将模型转换为响应
+func ConvertToStorageMigrationResponse(migration *model.StorageMigration) StorageMigrationResponse {
+	resp := StorageMigrationResponse{
+		ID:         migration.ID,
+		Source:     migration.Source,
+		Target:     migration.Target,
+		DryRun:     migration.DryRun,
+		Status:     migration.Status,
+		Total:      migration.Total,
+		Copied:     migration.Copied,
+		Failed:     migration.Failed,
+		Missing:    migration.Missing,
+		Rewritten:  migration.Rewritten,
+		LastError:  migration.LastError,
+		CreatedBy:  migration.CreatedBy,
+		StartedAt:  migration.StartedAt,
+		FinishedAt: migration.FinishedAt,
+		CreatedAt:  migration.CreatedAt,
+	}
+
+	switch {
+	case migration.Status == model.StorageMigrationCompleted:
+		resp.Progress = 100
+	case migration.Total > 0:
+		resp.Progress = float64(migration.Copied+migration.Failed+migration.Missing) * 100 / float64(migration.Total)
+	}
+	return resp
+}
+
+// ConvertToStorageMigrationItemResponse 将模型转换为响应
+func ConvertToStorageMigrationItemResponse(item *model.StorageMigrationItem) StorageMigrationItemResponse {
+	return StorageMigrationItemResponse{
+		ID:        item.ID,
+		Key:       item.Key,
+		SourceURL: item.SourceURL,
+		TargetURL: item.TargetURL,
+		Status:    item.Status,
+		Error:     item.Error,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
@@ -2,28 +2,59 @@ package dto
 
 import "time"
 
-// CreateTagRequest 创建标签请求
+// CreateTagRequest 创建标签请求，名称或别名与已有标签相同（不区分大小写）时返回已有标签
 type CreateTagRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Slug        string `json:"slug"` // 为空时根据名称生成
+	Description string `json:"description"`
 }
 
-// UpdateTagRequest 更新标签请求
+// UpdateTagRequest 更新标签请求，未提供的字段保持不变
 type UpdateTagRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description *string `json:"description"`
+	KeepAlias   bool    `json:"keep_alias"` // 重命名时将原名称保留为别名
 }
 
 // TagResponse 标签响应
 type TagResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	PostCount int64     `json:"post_count"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint           `json:"id"`
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	Description string         `json:"description"`
+	Aliases     []TagAliasInfo `json:"aliases"`
+	PostCount   int64          `json:"post_count"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TagAliasInfo 标签别名信息
+type TagAliasInfo struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// TagAliasRequest 添加标签别名请求
+type TagAliasRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// MergeTagsRequest 合并标签请求，被合并的标签合并到路径中的目标标签
+type MergeTagsRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+}
+
+// MergeTagsResponse 合并标签响应
+type MergeTagsResponse struct {
+	Tag    *TagResponse `json:"tag"`    // 合并后的目标标签
+	Posts  int64        `json:"posts"`  // 改用目标标签的文章数量
+	Drafts int64        `json:"drafts"` // 改用目标标签的草稿数量
 }
 
 // TagListQuery 标签列表查询参数
 type TagListQuery struct {
 	Page     int    `form:"page,default=1"`
 	PageSize int    `form:"page_size,default=10"`
-	Search   string `form:"search"`
+	Search   string `form:"search"` // 匹配名称和别名
 }
@@ -0,0 +1,31 @@
+package dto
+
+// PostViewStatsQuery 文章浏览统计查询参数
+type PostViewStatsQuery struct {
+	Days int `form:"days,default=30" binding:"min=1,max=365"` // 统计截至今天（UTC）的最近天数
+}
+
+// DailyViewStat 一天的浏览统计
+type DailyViewStat struct {
+	Date           string `json:"date"` // YYYY-MM-DD，UTC
+	Views          int64  `json:"views"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// ReferrerStat 来源域名的浏览量
+type ReferrerStat struct {
+	Domain string `json:"domain"`
+	Views  int64  `json:"views"`
+}
+
+// PostViewStatsResponse 文章浏览统计响应。统计数据定期批量写入，最近几秒的浏览可能尚未计入
+type PostViewStatsResponse struct {
+	PostID         uint            `json:"post_id"`
+	From           string          `json:"from"`
+	To             string          `json:"to"`
+	TotalViews     int64           `json:"total_views"`     // 文章的累计浏览量
+	Views          int64           `json:"views"`           // 统计区间内的浏览量
+	UniqueVisitors int64           `json:"unique_visitors"` // 统计区间内各天独立访客数之和
+	Daily          []DailyViewStat `json:"daily"`
+	Referrers      []ReferrerStat  `json:"referrers"` // 浏览量最多的站外来源域名
+}
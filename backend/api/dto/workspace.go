@@ -0,0 +1,64 @@
+package dto
+
+import "time"
+
+// CreateWorkspaceRequest 创建工作区请求
+type CreateWorkspaceRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Slug        string `json:"slug" binding:"required,max=100"` // 小写字母、数字和连字符
+	Description string `json:"description" binding:"max=500"`
+}
+
+// UpdateWorkspaceRequest 更新工作区请求
+type UpdateWorkspaceRequest struct {
+	Name        string  `json:"name" binding:"max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"` // 为空字符串时清空描述
+}
+
+// WorkspaceResponse 工作区响应
+type WorkspaceResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	OwnerID     uint      `json:"owner_id"`
+	Role        string    `json:"role,omitempty"` // 当前用户在工作区中的角色
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WorkspaceMemberResponse 工作区成员响应
+type WorkspaceMemberResponse struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	Avatar    string    `json:"avatar"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UpdateWorkspaceMemberRequest 修改成员角色请求
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner maintainer writer reader"`
+}
+
+// InviteWorkspaceMemberRequest 邀请成员请求
+type InviteWorkspaceMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner maintainer writer reader"`
+}
+
+// WorkspaceInvitationResponse 工作区邀请响应
+type WorkspaceInvitationResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy *UserInfo `json:"invited_by,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AcceptWorkspaceInvitationRequest 接受邀请请求
+type AcceptWorkspaceInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
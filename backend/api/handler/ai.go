@@ -2,13 +2,15 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
 	"notex/api/dto"
 	"notex/api/service"
 	"notex/middleware"
+	"notex/pkg/ai"
 	"strconv"
 	"strings"
 
@@ -17,13 +19,21 @@ import (
 
 // AIHandler 处理AI相关的请求
 type AIHandler struct {
-	aiService service.AIService
+	aiService     service.AIService
+	gateway       *service.AIGateway
+	assistService service.AIAssistService
+	askService    service.AIAskService
+	imageService  service.AIImageService
 }
 
 // NewAIHandler 创建一个新的AIHandler实例
-func NewAIHandler(aiService service.AIService) *AIHandler {
+func NewAIHandler(aiService service.AIService, gateway *service.AIGateway, assistService service.AIAssistService, askService service.AIAskService, imageService service.AIImageService) *AIHandler {
 	return &AIHandler{
-		aiService: aiService,
+		aiService:     aiService,
+		gateway:       gateway,
+		assistService: assistService,
+		askService:    askService,
+		imageService:  imageService,
 	}
 }
 
@@ -70,6 +80,12 @@ func (h *AIHandler) RegisterRoutes(router *gin.RouterGroup) {
 			authenticated.POST("/chat", h.HandleAIChat)
 			authenticated.POST("/test-connection", h.HandleAITest)
 
+			// 写作助手相关
+			authenticated.POST("/assist", h.HandleAIAssist)
+
+			// 知识库问答相关
+			authenticated.POST("/ask", h.HandleAIAsk)
+
 			// 图像生成相关
 			authenticated.POST("/generate-image", h.HandleImageGeneration)
 		}
@@ -212,70 +228,6 @@ type AIProvider struct {
 	Params   map[string]interface{} `json:"params,omitempty"`
 }
 
-// 获取AI提供商的API端点
-func getProviderEndpoint(provider string) string {
-	endpoints := map[string]string{
-		"openai":    "https://api.openai.com/v1/chat/completions",
-		"anthropic": "https://api.anthropic.com/v1/messages",
-		"google":    "https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent",
-		"deepseek":  "https://api.deepseek.com/v1/chat/completions",
-	}
-	return endpoints[provider]
-}
-
-// 获取AI提供商的请求头
-func getProviderHeaders(provider, apiKey string) map[string]string {
-	headers := map[string]string{
-		"Content-Type": "application/json",
-	}
-
-	switch provider {
-	case "openai":
-		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
-	case "stabilityai":
-		headers["Accept"] = "application/json"
-		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
-	case "anthropic":
-		headers["x-api-key"] = apiKey
-	case "google":
-		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
-	case "deepseek":
-		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
-	case "custom":
-		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
-	}
-
-	return headers
-}
-
-// 格式化消息以适应不同提供商的格式
-func formatMessages(messages []map[string]string, provider string) interface{} {
-	switch provider {
-	case "anthropic":
-		formatted := make([]map[string]interface{}, len(messages))
-		for i, msg := range messages {
-			formatted[i] = map[string]interface{}{
-				"role":    msg["role"],
-				"content": msg["content"],
-			}
-		}
-		return formatted
-	case "google":
-		formatted := make([]map[string]interface{}, len(messages))
-		for i, msg := range messages {
-			formatted[i] = map[string]interface{}{
-				"role": msg["role"],
-				"parts": []map[string]string{
-					{"text": msg["content"]},
-				},
-			}
-		}
-		return formatted
-	default:
-		return messages
-	}
-}
-
 // HandleAIChat 处理AI聊天请求
 func (h *AIHandler) HandleAIChat(c *gin.Context) {
 	userID := getUserIDFromContext(c)
@@ -296,51 +248,143 @@ func (h *AIHandler) HandleAIChat(c *gin.Context) {
 	// 获取API端点
 	endpoint := setting.Endpoint
 	if endpoint == "" {
-		endpoint = getProviderEndpoint(req.Provider)
+		endpoint = ai.GetProviderEndpoint(req.Provider)
 		if endpoint == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提供商或缺少端点"})
 			return
 		}
 	}
 
-	// 准备请求体
-	requestBody := map[string]interface{}{
-		"messages": formatMessages(req.Messages, req.Provider),
-		"model":    req.Model,
-		"stream":   req.Stream,
+	// 发送请求，主模型不可用时切换到备用模型
+	resp, used, err := h.gateway.Chat(c.Request.Context(), userID, &ai.ChatRequest{
+		Provider: req.Provider,
+		Endpoint: endpoint,
+		APIKey:   setting.APIKey,
+		Model:    req.Model,
+		Messages: req.Messages,
+		Stream:   req.Stream,
+		Params:   req.Params,
+	})
+	if err != nil {
+		handleAIRequestError(c, err)
+		return
 	}
+	defer resp.Body.Close()
 
-	// 添加其他参数
-	if req.Params != nil {
-		for k, v := range req.Params {
-			requestBody[k] = v
-		}
+	if resp.StatusCode != http.StatusOK {
+		writeUpstreamError(c, resp)
+		return
 	}
 
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "请求体序列化失败"})
+	// 告知客户端实际使用的模型
+	c.Header("X-AI-Provider", used.Provider)
+	c.Header("X-AI-Model", used.Model)
+
+	// 设置响应头
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 禁用 Nginx 缓冲
+
+	// 如果是流式响应，直接转发
+	if req.Stream {
+		forwardStream(c, resp.Body)
 		return
 	}
 
-	// 创建请求
-	proxyReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonBody))
+	// 非流式响应，读取并转发
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建请求失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取响应失败"})
 		return
 	}
 
-	// 设置请求头
-	headers := getProviderHeaders(req.Provider, setting.APIKey)
-	for k, v := range headers {
-		proxyReq.Header.Set(k, v)
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// forwardStream 将提供商的流式响应转发给客户端
+func forwardStream(c *gin.Context, body io.Reader) {
+	// 创建一个缓冲区
+	buf := make([]byte, 4096)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			// 写入数据
+			if _, err := c.Writer.Write(buf[:n]); err != nil {
+				return
+			}
+			// 刷新响应
+			c.Writer.Flush()
+		}
+		if err != nil {
+			return
+		}
 	}
+}
 
-	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(proxyReq)
+// handleAIRequestError 将向AI提供商发送请求的错误转换为响应
+func handleAIRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ai.ErrCircuitOpen):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "AI提供商响应超时"})
+	case errors.Is(err, context.Canceled):
+		// 客户端已断开，无需响应
+		c.Abort()
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": "发送请求失败: " + err.Error()})
+	}
+}
+
+// writeUpstreamError 将提供商的错误响应转换为响应，提供商的 5xx 错误返回 502
+func writeUpstreamError(c *gin.Context, resp *http.Response) {
+	body, _ := io.ReadAll(resp.Body)
+
+	var errorMsg string
+	// 尝试解析错误消息
+	var errorResp map[string]interface{}
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		if errObj, ok := errorResp["error"].(map[string]interface{}); ok {
+			if msg, ok := errObj["message"].(string); ok {
+				errorMsg = msg
+			}
+		}
+	}
+	if errorMsg == "" {
+		errorMsg = string(body)
+	}
+
+	status := resp.StatusCode
+	if status >= http.StatusInternalServerError {
+		status = http.StatusBadGateway
+	}
+	c.JSON(status, gin.H{"error": "API请求失败: " + errorMsg})
+}
+
+// HandleAIAssist 处理写作助手请求
+func (h *AIHandler) HandleAIAssist(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	var req dto.AIAssistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if !req.Stream {
+		result, err := h.assistService.Run(c.Request.Context(), userID, &req)
+		if err != nil {
+			handleAIAssistError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	resp, err := h.assistService.Stream(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "发送请求失败"})
+		handleAIAssistError(c, err)
 		return
 	}
 	defer resp.Body.Close()
@@ -351,38 +395,82 @@ func (h *AIHandler) HandleAIChat(c *gin.Context) {
 	c.Header("Connection", "keep-alive")
 	c.Header("X-Accel-Buffering", "no") // 禁用 Nginx 缓冲
 
-	// 如果是流式响应，直接转发
-	if req.Stream {
-		// 创建一个缓冲区
-		buf := make([]byte, 4096)
-		for {
-			n, err := resp.Body.Read(buf)
-			if n > 0 {
-				// 写入数据
-				if _, err := c.Writer.Write(buf[:n]); err != nil {
-					return
-				}
-				// 刷新响应
-				c.Writer.Flush()
-			}
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				return
-			}
+	forwardStream(c, resp.Body)
+}
+
+// handleAIAssistError 将写作助手的错误转换为响应
+func handleAIAssistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrDraftNotFound), errors.Is(err, service.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAIDefaultModelNotSet),
+		errors.Is(err, service.ErrAIProviderNotConfigured),
+		errors.Is(err, service.ErrAIPromptTemplateNotFound),
+		errors.Is(err, service.ErrAIAssistContentEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ai.ErrCircuitOpen),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, context.Canceled):
+		handleAIRequestError(c, err)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// HandleAIAsk 处理基于已发布文章的问答请求
+func (h *AIHandler) HandleAIAsk(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	var req dto.AIAskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if !req.Stream {
+		result, err := h.askService.Ask(c.Request.Context(), userID, &req)
+		if err != nil {
+			handleAIAskError(c, err)
+			return
 		}
+		c.JSON(http.StatusOK, result)
 		return
 	}
 
-	// 非流式响应，读取并转发
-	body, err := io.ReadAll(resp.Body)
+	resp, citations, err := h.askService.Stream(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取响应失败"})
+		handleAIAskError(c, err)
 		return
 	}
+	defer resp.Body.Close()
 
-	c.Data(http.StatusOK, "application/json", body)
+	// 设置响应头
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 禁用 Nginx 缓冲
+
+	// 先发送引用列表，再转发模型输出
+	data, err := json.Marshal(citations)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "序列化引用失败"})
+		return
+	}
+	c.Writer.WriteString("event: citations\ndata: " + string(data) + "\n\n")
+	c.Writer.Flush()
+
+	forwardStream(c, resp.Body)
+}
+
+// handleAIAskError 将问答的错误转换为响应
+func handleAIAskError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrAIAskNoRelevantPosts) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	handleAIAssistError(c, err)
 }
 
 // HandleAITest 处理AI连接测试请求
@@ -396,7 +484,7 @@ func (h *AIHandler) HandleAITest(c *gin.Context) {
 	// 获取API端点
 	endpoint := req.Endpoint
 	if endpoint == "" {
-		endpoint = getProviderEndpoint(req.Provider)
+		endpoint = ai.GetProviderEndpoint(req.Provider)
 		if endpoint == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提供商或缺少端点"})
 			return
@@ -409,40 +497,24 @@ func (h *AIHandler) HandleAITest(c *gin.Context) {
 		"content": "Hello",
 	}
 
-	// 准备请求体
-	requestBody := map[string]interface{}{
-		"messages": []map[string]string{testMessage},
-		"model":    req.Model,
-		"stream":   false,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
+	// 发送请求，测试连接时不切换备用模型，也不经过熔断器，避免用户填写的地址影响其他请求
+	resp, err := h.gateway.Client().Chat(ai.WithoutBreaker(c.Request.Context()), &ai.ChatRequest{
+		Provider: req.Provider,
+		Endpoint: endpoint,
+		APIKey:   req.APIKey,
+		Model:    req.Model,
+		Messages: []map[string]string{testMessage},
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "请求体序列化失败"})
+		handleAIRequestError(c, err)
 		return
 	}
+	defer resp.Body.Close()
 
-	// 创建请求
-	proxyReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建请求失败"})
-		return
-	}
-
-	// 设置请求头
-	headers := getProviderHeaders(req.Provider, req.APIKey)
-	for k, v := range headers {
-		proxyReq.Header.Set(k, v)
-	}
-
-	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(proxyReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "发送请求失败"})
+	if resp.StatusCode != http.StatusOK {
+		writeUpstreamError(c, resp)
 		return
 	}
-	defer resp.Body.Close()
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
@@ -597,28 +669,33 @@ func (h *AIHandler) HandleImageGeneration(c *gin.Context) {
 		return
 	}
 
-	// 创建请求
-	proxyReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonBody))
+	// 创建请求，客户端断开时取消上游调用
+	proxyReq, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建请求失败"})
 		return
 	}
 
 	// 设置请求头
-	headers := getProviderHeaders(req.Provider, req.APIKey)
+	headers := ai.GetProviderHeaders(req.Provider, req.APIKey)
 	for k, v := range headers {
 		proxyReq.Header.Set(k, v)
 	}
 
 	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(proxyReq)
+	resp, err := h.gateway.Client().Do(proxyReq, req.Provider, false)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "发送请求失败: " + err.Error()})
+		handleAIRequestError(c, err)
 		return
 	}
 	defer resp.Body.Close()
 
+	// 检查响应状态
+	if resp.StatusCode != http.StatusOK {
+		writeUpstreamError(c, resp)
+		return
+	}
+
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -626,25 +703,6 @@ func (h *AIHandler) HandleImageGeneration(c *gin.Context) {
 		return
 	}
 
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		var errorMsg string
-		// 尝试解析错误消息
-		var errorResp map[string]interface{}
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			if errObj, ok := errorResp["error"].(map[string]interface{}); ok {
-				if msg, ok := errObj["message"].(string); ok {
-					errorMsg = msg
-				}
-			}
-		}
-		if errorMsg == "" {
-			errorMsg = string(body)
-		}
-		c.JSON(resp.StatusCode, gin.H{"error": "API请求失败: " + errorMsg})
-		return
-	}
-
 	// 处理不同提供商的响应格式
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -677,8 +735,27 @@ func (h *AIHandler) HandleImageGeneration(c *gin.Context) {
 		}
 	}
 
+	// 保存到存储，避免提供商返回的临时URL过期
+	items, err := h.imageService.SaveGeneratedImages(c.Request.Context(), userID, &req, images)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDraftNotFound), errors.Is(err, service.ErrPostNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存生成的图像失败: " + err.Error()})
+		}
+		return
+	}
+
 	// 返回统一格式的响应
-	c.JSON(http.StatusOK, gin.H{
-		"images": images,
+	saved := make([]string, len(items))
+	for i, item := range items {
+		saved[i] = item.URL
+	}
+	c.JSON(http.StatusOK, dto.AIImageGenerationResponse{
+		Images: saved,
+		Items:  items,
 	})
 }
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler 处理作者统计请求
+type AnalyticsHandler struct {
+	service *service.AnalyticsService
+}
+
+// NewAnalyticsHandler 创建作者统计处理器
+func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: analyticsService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *AnalyticsHandler) RegisterRoutes(r *gin.RouterGroup) {
+	analytics := r.Group("/analytics")
+	{
+		analytics.GET("/overview", h.GetOverview)
+		analytics.GET("/posts/:id", h.GetPostAnalytics)
+	}
+}
+
+// GetOverview 获取作者全部文章的统计
+func (h *AnalyticsHandler) GetOverview(c *gin.Context) {
+	var query dto.AnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analytics, err := h.service.GetAuthorAnalytics(getUserIDFromContext(c), getRoleFromContext(c), &query)
+	if err != nil {
+		handleAnalyticsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// GetPostAnalytics 获取单篇文章的统计
+func (h *AnalyticsHandler) GetPostAnalytics(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query dto.AnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analytics, err := h.service.GetPostAnalytics(postViewer(c), id, &query)
+	if err != nil {
+		handleAnalyticsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// handleAnalyticsError 将统计相关的错误转换为响应
+func handleAnalyticsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAnalyticsInvalidRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAnalyticsForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		handlePostError(c, err)
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"notex/api/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttachmentHandler 处理附件相关的请求
+type AttachmentHandler struct {
+	service *service.AttachmentService
+}
+
+// NewAttachmentHandler 创建附件处理器
+func NewAttachmentHandler(attachmentService *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		service: attachmentService,
+	}
+}
+
+// Upload 上传附件，上传后通过文章或草稿的 attachment_ids 关联
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.Upload(c.Request.Context(), getUserIDFromContext(c), file, header)
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// Delete 删除附件
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return
+	}
+
+	if err := h.service.Delete(getUserIDFromContext(c), getRoleFromContext(c), uint(id)); err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted successfully"})
+}
+
+// Download 下载附件，支持范围请求，从头开始的下载计入下载次数
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return
+	}
+
+	download, err := h.service.Open(postViewer(c), uint(id))
+	if err != nil {
+		handleAttachmentError(c, err)
+		return
+	}
+	defer download.Content.Close()
+	attachment := download.Attachment
+
+	// 续传和分段请求不重复计数
+	if rangeHeader := c.GetHeader("Range"); rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-") {
+		go h.service.RecordDownload(attachment.ID)
+	}
+
+	c.Header("Content-Type", attachment.MimeType)
+	c.Header("Content-Disposition", contentDisposition(attachment.Filename))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=0, must-revalidate")
+	http.ServeContent(c.Writer, c.Request, "", attachment.Asset.CreatedAt, download.Content)
+}
+
+// contentDisposition 生成附件下载的 Content-Disposition，非 ASCII 文件名按 RFC 5987 编码并提供 ASCII 回退
+func contentDisposition(filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fallback})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	if fallback == filename {
+		return disposition
+	}
+
+	var encoded strings.Builder
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return disposition + "; filename*=UTF-8''" + encoded.String()
+}
+
+// isAttrChar 判断字节是否为 RFC 5987 中无需编码的字符
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+// handleAttachmentError 将附件相关的错误转换为响应
+func handleAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAttachmentNotFound), errors.Is(err, service.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrAttachmentNotFound.Error()})
+	case errors.Is(err, service.ErrAttachmentUnavailable), errors.Is(err, service.ErrAttachmentTooMany):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		handleMediaError(c, err)
+	}
+}
+
+// isAttachmentBindError 判断是否为关联附件时的请求错误
+func isAttachmentBindError(err error) bool {
+	return errors.Is(err, service.ErrAttachmentUnavailable) || errors.Is(err, service.ErrAttachmentTooMany)
+}
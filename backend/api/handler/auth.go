@@ -222,7 +222,7 @@ func (h *AuthHandler) GetUserHome(c *gin.Context) {
 		UserID:   uint(userID),
 		Page:     page,
 		PageSize: pageSize,
-		Status:   "published", // 强制设置状态为已发布
+		Listed:   true, // 只列出已发布的公开文章
 	}
 
 	// 获取用户文章列表
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"notex/api/dto"
 	"notex/api/service"
@@ -27,9 +28,9 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 		return
 	}
 
-	categories, total, err := h.service.ListCategories(&query)
+	categories, total, err := h.service.ListCategories(&query, getUserIDFromContext(c), getRoleFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleCategoryError(c, err)
 		return
 	}
 
@@ -39,6 +40,23 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 	})
 }
 
+// ListCategoryTree 获取分类树
+func (h *CategoryHandler) ListCategoryTree(c *gin.Context) {
+	var query dto.CategoryTreeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tree, err := h.service.ListCategoryTree(&query, getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": tree})
+}
+
 // GetCategory 获取分类详情
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -47,9 +65,9 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.service.GetCategory(uint(id))
+	category, err := h.service.GetCategory(uint(id), getUserIDFromContext(c), getRoleFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleCategoryError(c, err)
 		return
 	}
 
@@ -64,9 +82,9 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.service.CreateCategory(&req)
+	category, err := h.service.CreateCategory(getUserIDFromContext(c), getRoleFromContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleCategoryError(c, err)
 		return
 	}
 
@@ -87,9 +105,9 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.service.UpdateCategory(uint(id), &req)
+	category, err := h.service.UpdateCategory(uint(id), getUserIDFromContext(c), getRoleFromContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleCategoryError(c, err)
 		return
 	}
 
@@ -104,14 +122,54 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteCategory(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.DeleteCategory(uint(id), getUserIDFromContext(c), getRoleFromContext(c)); err != nil {
+		handleCategoryError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// ListEditors 获取分类的责任编辑
+func (h *CategoryHandler) ListEditors(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	editors, err := h.service.ListEditors(uint(id), getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": editors})
+}
+
+// ReplaceEditors 替换分类的责任编辑
+func (h *CategoryHandler) ReplaceEditors(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.CategoryEditorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	editors, err := h.service.ReplaceEditors(uint(id), getUserIDFromContext(c), getRoleFromContext(c), req.UserIDs)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": editors})
+}
+
 // GetTopCategories 获取热门分类
 func (h *CategoryHandler) GetTopCategories(c *gin.Context) {
 	limit := 5 // 默认获取5个
@@ -132,3 +190,21 @@ func (h *CategoryHandler) GetTopCategories(c *gin.Context) {
 		"total": len(categories),
 	})
 }
+
+// handleCategoryError 将分类相关的错误转换为响应
+func handleCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryUnavailable), errors.Is(err, service.ErrCategoryEditorsScope),
+		errors.Is(err, service.ErrCategoryInvalidSlug), errors.Is(err, service.ErrCategoryInvalidParent),
+		errors.Is(err, service.ErrCategoryInvalidSettings):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategorySlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		handleWorkspaceError(c, err)
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -34,9 +35,9 @@ func (h *CommentHandler) ListComments(c *gin.Context) {
 		return
 	}
 
-	comments, total, err := h.service.ListComments(uint(postID), &query)
+	comments, total, err := h.service.ListComments(uint(postID), &query, postViewer(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handlePostError(c, err)
 		return
 	}
 
@@ -46,6 +47,28 @@ func (h *CommentHandler) ListComments(c *gin.Context) {
 	})
 }
 
+// GetCommentTree 获取文章的评论树
+func (h *CommentHandler) GetCommentTree(c *gin.Context) {
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query dto.CommentTreeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tree, err := h.service.GetCommentTree(postViewer(c), postID, &query)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
 // CreateComment 创建评论
 func (h *CommentHandler) CreateComment(c *gin.Context) {
 	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -60,9 +83,9 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	// 从上下文中获取当前用户ID
-	userID, exists := c.Get("user_id")
-	if !exists {
+	// 从上下文中获取当前用户
+	viewer := postViewer(c)
+	if viewer.UserID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
@@ -74,31 +97,208 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		ReplyToID: createReq.ReplyToID,
 	}
 
-	comment, err := h.service.CreateComment(userID.(uint), uint(postID), req)
+	comment, err := h.service.CreateComment(viewer, uint(postID), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleCommentError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": comment})
 }
 
+// GetCaptcha 获取访客评论使用的人机验证挑战
+func (h *CommentHandler) GetCaptcha(c *gin.Context) {
+	challenge, err := h.service.GetCaptcha(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// CreateGuestComment 访客发表评论，评论在邮箱确认后公开
+func (h *CommentHandler) CreateGuestComment(c *gin.Context) {
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.GuestCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.CreateGuestComment(c.Request.Context(), postViewer(c), postID, &req, c.ClientIP())
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, comment)
+}
+
+// VerifyGuestComment 通过确认邮件中的链接公开访客评论
+func (h *CommentHandler) VerifyGuestComment(c *gin.Context) {
+	var query dto.VerifyGuestCommentQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.VerifyGuestComment(query.Token)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// ClaimGuestComments 将与当前用户邮箱相同的访客评论归入当前用户
+func (h *CommentHandler) ClaimGuestComments(c *gin.Context) {
+	result, err := h.service.ClaimGuestComments(getUserIDFromContext(c))
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateComment 修改评论
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.UpdateComment(postViewer(c), postID, commentID, &req)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// ListRevisions 获取评论修改前的各个版本
+func (h *CommentHandler) ListRevisions(c *gin.Context) {
+	commentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(postViewer(c), commentID)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": revisions,
+	})
+}
+
+// React 对评论使用表情回应
+func (h *CommentHandler) React(c *gin.Context) {
+	h.setReaction(c, true)
+}
+
+// Unreact 取消表情回应
+func (h *CommentHandler) Unreact(c *gin.Context) {
+	h.setReaction(c, false)
+}
+
+// setReaction 添加或取消表情回应，表情在路径中
+func (h *CommentHandler) setReaction(c *gin.Context, add bool) {
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId")
+	if !ok {
+		return
+	}
+
+	react := h.service.Unreact
+	if add {
+		react = h.service.React
+	}
+	reactions, err := react(postViewer(c), postID, commentID, c.Param("emoji"))
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": reactions,
+	})
+}
+
 // DeleteComment 删除评论
 func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post id"})
+		return
+	}
 	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
 		return
 	}
 
-	if err := h.service.DeleteComment(uint(commentID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.DeleteComment(postViewer(c), uint(postID), uint(commentID)); err != nil {
+		handleCommentError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// HideComment 隐藏评论
+func (h *CommentHandler) HideComment(c *gin.Context) {
+	h.setHidden(c, true)
+}
+
+// UnhideComment 恢复被隐藏的评论
+func (h *CommentHandler) UnhideComment(c *gin.Context) {
+	h.setHidden(c, false)
+}
+
+// setHidden 隐藏或恢复评论
+func (h *CommentHandler) setHidden(c *gin.Context, hidden bool) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post id"})
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+
+	if err := h.service.SetCommentHidden(postViewer(c), uint(postID), uint(commentID), hidden); err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // ListUserComments 获取用户的评论列表
 func (h *CommentHandler) ListUserComments(c *gin.Context) {
 	// 从上下文获取当前用户ID
@@ -136,8 +336,13 @@ func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
 	}
 
 	// 获取回复列表
-	replies, err := h.service.GetCommentReplies(uint(commentID))
+	replies, err := h.service.GetCommentReplies(uint(commentID), postViewer(c))
 	if err != nil {
+		if errors.Is(err, service.ErrCommentNotFound) || errors.Is(err, service.ErrPostNotFound) ||
+			errors.Is(err, service.ErrPostLocked) {
+			handleCommentError(c, err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get replies"})
 		return
 	}
@@ -146,3 +351,26 @@ func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
 		"items": replies,
 	})
 }
+
+// handleCommentError 将评论相关的错误转换为响应
+func handleCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCommentForbidden), errors.Is(err, service.ErrCommentEditExpired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrGuestCommentsDisabled), errors.Is(err, service.ErrEmailNotVerified),
+		errors.Is(err, service.ErrCommentsClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrGuestEmailRegistered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReactionNotAllowed),
+		errors.Is(err, service.ErrCommentInvalidCursor),
+		errors.Is(err, service.ErrGuestNameInvalid),
+		errors.Is(err, service.ErrGuestTokenInvalid),
+		errors.Is(err, service.ErrCaptchaFailed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		handlePostError(c, err)
+	}
+}
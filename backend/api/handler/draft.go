@@ -36,8 +36,12 @@ func (h *DraftHandler) ListDrafts(c *gin.Context) {
 		return
 	}
 
-	drafts, total, err := h.draftService.ListDrafts(userID.(uint), query.Page, query.PageSize, query.Search)
+	drafts, total, err := h.draftService.ListDrafts(userID.(uint), getRoleFromContext(c), &query)
 	if err != nil {
+		if status := workspaceErrorStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "获取草稿列表失败", err)
 		return
 	}
@@ -68,13 +72,9 @@ func (h *DraftHandler) GetDraft(c *gin.Context) {
 		return
 	}
 
-	draft, err := h.draftService.GetDraft(uint(id), userID.(uint))
+	draft, err := h.draftService.GetDraft(uint(id), userID.(uint), getRoleFromContext(c))
 	if err != nil {
-		if errors.Is(err, service.ErrDraftNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get draft"})
+		handleDraftError(c, err, "Failed to get draft")
 		return
 	}
 
@@ -96,9 +96,9 @@ func (h *DraftHandler) CreateDraft(c *gin.Context) {
 		return
 	}
 
-	draft, err := h.draftService.CreateDraft(userID.(uint), req)
+	draft, err := h.draftService.CreateDraft(userID.(uint), getRoleFromContext(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建草稿失败"})
+		handleDraftError(c, err, "创建草稿失败")
 		return
 	}
 
@@ -126,17 +126,9 @@ func (h *DraftHandler) UpdateDraft(c *gin.Context) {
 		return
 	}
 
-	draft, err := h.draftService.UpdateDraft(uint(id), userID.(uint), req)
+	draft, err := h.draftService.UpdateDraft(uint(id), userID.(uint), getRoleFromContext(c), req)
 	if err != nil {
-		if errors.Is(err, service.ErrUnauthorized) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-		if errors.Is(err, service.ErrDraftNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update draft"})
+		handleDraftError(c, err, "Failed to update draft")
 		return
 	}
 
@@ -158,8 +150,12 @@ func (h *DraftHandler) DeleteDraft(c *gin.Context) {
 		return
 	}
 
-	err = h.draftService.DeleteDraft(uint(id), userID.(uint))
+	err = h.draftService.DeleteDraft(uint(id), userID.(uint), getRoleFromContext(c))
 	if err != nil {
+		if status := draftErrorStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "删除草稿失败", err)
 		return
 	}
@@ -181,20 +177,37 @@ func (h *DraftHandler) PublishDraft(c *gin.Context) {
 		return
 	}
 
-	post, err := h.draftService.PublishDraft(uint(id), userID.(uint))
+	post, err := h.draftService.PublishDraft(uint(id), userID.(uint), getRoleFromContext(c))
 	if err != nil {
-		if errors.Is(err, service.ErrUnauthorized) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-		if errors.Is(err, service.ErrDraftNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish draft"})
+		handleDraftError(c, err, "Failed to publish draft")
 		return
 	}
 
 	// 直接返回发布后的文章数据
 	c.JSON(http.StatusOK, post)
 }
+
+// draftErrorStatus 返回草稿相关错误对应的状态码，未知错误返回 0
+func draftErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrDraftNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrDraftForbidden):
+		return http.StatusForbidden
+	case isAttachmentBindError(err), errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrCategoryUnavailable):
+		return http.StatusBadRequest
+	default:
+		return workspaceErrorStatus(err)
+	}
+}
+
+// handleDraftError 将草稿相关的错误转换为响应，未知错误返回 fallback 信息
+func handleDraftError(c *gin.Context, err error, fallback string) {
+	if status := draftErrorStatus(err); status != 0 {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
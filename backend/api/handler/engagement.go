@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EngagementHandler 处理点赞、收藏和阅读列表请求
+type EngagementHandler struct {
+	likes     *service.LikeService
+	bookmarks *service.BookmarkService
+}
+
+// NewEngagementHandler 创建点赞和收藏处理器
+func NewEngagementHandler(likeService *service.LikeService, bookmarkService *service.BookmarkService) *EngagementHandler {
+	return &EngagementHandler{
+		likes:     likeService,
+		bookmarks: bookmarkService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *EngagementHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/posts/:id/like", h.Like)
+	r.DELETE("/posts/:id/like", h.Unlike)
+	r.PUT("/posts/:id/bookmark", h.Bookmark)
+	r.DELETE("/posts/:id/bookmark", h.Unbookmark)
+
+	r.GET("/bookmarks", h.ListBookmarks)
+
+	lists := r.Group("/reading-lists")
+	{
+		lists.GET("", h.ListReadingLists)
+		lists.POST("", h.CreateReadingList)
+		lists.PUT("/:id", h.UpdateReadingList)
+		lists.DELETE("/:id", h.DeleteReadingList)
+	}
+}
+
+// Like 点赞文章
+func (h *EngagementHandler) Like(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.likes.Like(postViewer(c), id)
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Unlike 取消点赞
+func (h *EngagementHandler) Unlike(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.likes.Unlike(postViewer(c), id)
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Bookmark 收藏文章或将收藏移动到其他阅读列表
+func (h *EngagementHandler) Bookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.BookmarkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	bookmark, err := h.bookmarks.Bookmark(postViewer(c), id, &req)
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// Unbookmark 取消收藏
+func (h *EngagementHandler) Unbookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.bookmarks.Unbookmark(getUserIDFromContext(c), id); err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListBookmarks 获取当前用户的收藏
+func (h *EngagementHandler) ListBookmarks(c *gin.Context) {
+	var query dto.BookmarkListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmarks, total, err := h.bookmarks.ListBookmarks(postViewer(c), &query)
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": bookmarks,
+		"total": total,
+	})
+}
+
+// ListReadingLists 获取当前用户的阅读列表
+func (h *EngagementHandler) ListReadingLists(c *gin.Context) {
+	lists, err := h.bookmarks.ListReadingLists(getUserIDFromContext(c))
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": lists,
+		"total": len(lists),
+	})
+}
+
+// CreateReadingList 创建阅读列表
+func (h *EngagementHandler) CreateReadingList(c *gin.Context) {
+	var req dto.ReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.bookmarks.CreateReadingList(getUserIDFromContext(c), &req)
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+// UpdateReadingList 修改阅读列表
+func (h *EngagementHandler) UpdateReadingList(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.bookmarks.UpdateReadingList(getUserIDFromContext(c), id, &req)
+	if err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// DeleteReadingList 删除阅读列表
+func (h *EngagementHandler) DeleteReadingList(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.bookmarks.DeleteReadingList(getUserIDFromContext(c), id); err != nil {
+		handleEngagementError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleEngagementError 将点赞和收藏相关的错误转换为响应
+func handleEngagementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrBookmarkNotFound), errors.Is(err, service.ErrReadingListNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReadingListNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReadingListNameRequired), errors.Is(err, service.ErrPostUnpublished):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReactForbidden):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
+		handlePostError(c, err)
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FollowHandler 处理关注和关注动态请求
+type FollowHandler struct {
+	service *service.FollowService
+}
+
+// NewFollowHandler 创建关注处理器
+func NewFollowHandler(followService *service.FollowService) *FollowHandler {
+	return &FollowHandler{
+		service: followService,
+	}
+}
+
+// RegisterRoutes 注册路由，用户的关注者和关注的作者是公开的。
+// :type 为 users、tags 或 categories
+func (h *FollowHandler) RegisterRoutes(public, authenticated *gin.RouterGroup) {
+	public.GET("/users/:id/followers", h.ListFollowers)
+	public.GET("/users/:id/following", h.ListUserFollowing)
+
+	follows := authenticated.Group("/follows")
+	{
+		follows.GET("/:type", h.ListFollowing)
+		follows.GET("/:type/:id", h.GetFollowStatus)
+		follows.PUT("/:type/:id", h.Follow)
+		follows.DELETE("/:type/:id", h.Unfollow)
+	}
+	authenticated.GET("/feed", h.GetFeed)
+}
+
+// Follow 关注作者、标签或分类
+func (h *FollowHandler) Follow(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Follow(getUserIDFromContext(c), c.Param("type"), id)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Unfollow 取消关注
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Unfollow(getUserIDFromContext(c), c.Param("type"), id)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetFollowStatus 获取当前用户对关注对象的关注状态
+func (h *FollowHandler) GetFollowStatus(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status, err := h.service.GetFollowStatus(getUserIDFromContext(c), c.Param("type"), id)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ListFollowing 获取当前用户关注的作者、标签或分类
+func (h *FollowHandler) ListFollowing(c *gin.Context) {
+	var query dto.FollowListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	follows, total, err := h.service.ListFollowing(getUserIDFromContext(c), c.Param("type"), &query)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": follows,
+		"total": total,
+	})
+}
+
+// ListUserFollowing 获取用户关注的作者
+func (h *FollowHandler) ListUserFollowing(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var query dto.FollowListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	follows, total, err := h.service.ListFollowing(id, "users", &query)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": follows,
+		"total": total,
+	})
+}
+
+// ListFollowers 获取用户的关注者
+func (h *FollowHandler) ListFollowers(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var query dto.FollowListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	followers, total, err := h.service.ListFollowers(id, &query)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": followers,
+		"total": total,
+	})
+}
+
+// GetFeed 获取当前用户的关注动态
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	var query dto.FeedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.service.GetFeed(getUserIDFromContext(c), &query)
+	if err != nil {
+		handleFollowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// handleFollowError 将关注服务的错误转换为响应
+func handleFollowError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFollowTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFollowInvalidType),
+		errors.Is(err, service.ErrFollowSelf),
+		errors.Is(err, service.ErrFeedInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/service"
+	"notex/pkg/imaging"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImageHandler 处理图片动态缩放请求
+type ImageHandler struct {
+	service *service.ImageService
+}
+
+// NewImageHandler 创建图片处理器
+func NewImageHandler(imageService *service.ImageService) *ImageHandler {
+	return &ImageHandler{
+		service: imageService,
+	}
+}
+
+// Serve 按 w 和 fmt 参数返回缩放后的图片，参数需带有效签名 sig
+func (h *ImageHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	width, err := strconv.Atoi(c.Query("w"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid width"})
+		return
+	}
+
+	filePath, contentType, err := h.service.Render(key, width, c.Query("fmt"), c.Query("sig"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImageInvalidSignature):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrImageInvalidParams), errors.Is(err, imaging.ErrTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrImageNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// 签名URL的内容不会变化，允许长期缓存
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("Content-Type", contentType)
+	c.File(filePath)
+}
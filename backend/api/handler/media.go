@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/service"
+	"notex/middleware"
+	"notex/pkg/imaging"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaHandler 处理媒体库相关的请求
+type MediaHandler struct {
+	service *service.MediaService
+}
+
+// NewMediaHandler 创建媒体库处理器
+func NewMediaHandler(mediaService *service.MediaService) *MediaHandler {
+	return &MediaHandler{
+		service: mediaService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *MediaHandler) RegisterRoutes(r *gin.RouterGroup) {
+	media := r.Group("/media")
+	{
+		media.GET("", h.ListAssets)
+		media.GET("/:id", h.GetAsset)
+		media.GET("/:id/image", h.GetImageURL)
+		media.DELETE("/:id", h.DeleteAsset)
+	}
+
+	admin := r.Group("/admin/media")
+	admin.Use(middleware.RequireAdmin())
+	{
+		admin.GET("", h.AdminListAssets)
+		admin.GET("/:id", h.GetAsset)
+		admin.DELETE("/:id", middleware.AuditLog("delete", "media"), h.DeleteAsset)
+		admin.POST("/scan", middleware.AuditLog("scan", "media"), h.ScanReferences)
+		admin.POST("/gc", middleware.AuditLog("gc", "media"), h.CollectGarbage)
+	}
+}
+
+// ListAssets 获取当前用户的媒体文件
+func (h *MediaHandler) ListAssets(c *gin.Context) {
+	var req dto.MediaListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ListAssets(getUserIDFromContext(c), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AdminListAssets 获取全部用户的媒体文件
+func (h *MediaHandler) AdminListAssets(c *gin.Context) {
+	var req dto.MediaListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.AdminListAssets(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAsset 获取媒体文件详情及引用
+func (h *MediaHandler) GetAsset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return
+	}
+
+	asset, err := h.service.GetAsset(getUserIDFromContext(c), getRoleFromContext(c), uint(id))
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// GetImageURL 获取图片指定宽度和格式的签名动态缩放URL
+func (h *MediaHandler) GetImageURL(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return
+	}
+
+	var req dto.MediaImageURLRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	url, err := h.service.ImageURL(getUserIDFromContext(c), getRoleFromContext(c), uint(id), req.Width, req.Format)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MediaImageURLResponse{URL: url})
+}
+
+// DeleteAsset 删除无引用的媒体文件
+func (h *MediaHandler) DeleteAsset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return
+	}
+
+	if err := h.service.DeleteAsset(getUserIDFromContext(c), getRoleFromContext(c), uint(id)); err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "media asset deleted successfully"})
+}
+
+// ScanReferences 立即扫描文件引用
+func (h *MediaHandler) ScanReferences(c *gin.Context) {
+	resp, err := h.service.ScanReferences()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CollectGarbage 立即清理超过保留期的无引用文件
+func (h *MediaHandler) CollectGarbage(c *gin.Context) {
+	resp, err := h.service.CollectGarbage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleMediaError 将媒体库的错误转换为响应
+func handleMediaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMediaInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrImageInvalidParams), errors.Is(err, imaging.ErrTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMediaTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMediaTypeNotAllowed), errors.Is(err, service.ErrMediaTypeMismatch):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMediaInfected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMediaScanUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"notex/api/dto"
 	"notex/api/service"
@@ -11,11 +12,13 @@ import (
 
 type PostHandler struct {
 	service *service.PostService
+	views   *service.ViewService
 }
 
-func NewPostHandler(postService *service.PostService) *PostHandler {
+func NewPostHandler(postService *service.PostService, viewService *service.ViewService) *PostHandler {
 	return &PostHandler{
 		service: postService,
+		views:   viewService,
 	}
 }
 
@@ -28,6 +31,7 @@ func (h *PostHandler) ListPosts(c *gin.Context) {
 	}
 
 	// 处理当前用户的文章过滤
+	viewer := postViewer(c)
 	if query.User == "current" {
 		userID, exists := c.Get("user_id")
 		if !exists {
@@ -36,6 +40,18 @@ func (h *PostHandler) ListPosts(c *gin.Context) {
 		}
 		query.UserID = userID.(uint)
 	}
+	if query.WorkspaceID > 0 {
+		// 工作区成员可以列出工作区的全部文章
+		if err := h.service.RequireWorkspaceReader(query.WorkspaceID, viewer); err != nil {
+			handlePostError(c, err)
+			return
+		}
+	} else if query.User != "current" {
+		// 其他用户的文章只列出公开可见的部分，编辑和管理员可以列出不属于工作区的全部文章，
+		// 工作区文章仍需成员身份
+		query.Listed = true
+		query.AllPersonal = viewer.IsEditor()
+	}
 
 	posts, total, err := h.service.ListPosts(&query)
 	if err != nil {
@@ -57,18 +73,99 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.service.GetPost(uint(id))
+	post, err := h.service.GetPost(uint(id), postViewer(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handlePostError(c, err)
 		return
 	}
 
-	// 增加浏览量
-	go h.service.IncrementViews(uint(id))
+	// 记录浏览，未解锁的密码保护文章和作者本人的访问不计入
+	if !post.Protected && (post.Author == nil || post.Author.ID != getUserIDFromContext(c)) {
+		h.views.Record(service.ViewEvent{
+			PostID:    post.ID,
+			UserID:    getUserIDFromContext(c),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Referrer:  c.Request.Referer(),
+			Host:      c.Request.Host,
+		})
+	}
 
 	c.JSON(http.StatusOK, post)
 }
 
+// GetPostStats 获取文章最近一段时间的浏览统计
+func (h *PostHandler) GetPostStats(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var query dto.PostViewStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.views.GetPostStats(postViewer(c), uint(id), query.Days)
+	if err != nil {
+		handlePostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// UnlockPost 验证密码保护文章的密码，返回解锁令牌
+func (h *PostHandler) UnlockPost(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.UnlockPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unlock, err := h.service.UnlockPost(uint(id), req.Password)
+	if err != nil {
+		handlePostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, unlock)
+}
+
+// GetRelatedPosts 获取语义相关的文章
+func (h *PostHandler) GetRelatedPosts(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit := 5 // 默认获取5篇
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 20 {
+			limit = l
+		}
+	}
+
+	posts, err := h.service.GetRelatedPosts(uint(id), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": posts,
+	})
+}
+
 // CreatePost 创建文章
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -85,9 +182,9 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 
 	req.UserID = userID.(uint)
 
-	post, err := h.service.CreatePost(&req)
+	post, err := h.service.CreatePost(postViewer(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handlePostError(c, err)
 		return
 	}
 
@@ -108,9 +205,9 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.service.UpdatePost(uint(id), &req)
+	post, err := h.service.UpdatePost(uint(id), postViewer(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handlePostError(c, err)
 		return
 	}
 
@@ -125,8 +222,8 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeletePost(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.DeletePost(uint(id), postViewer(c)); err != nil {
+		handlePostError(c, err)
 		return
 	}
 
@@ -161,8 +258,8 @@ func (h *PostHandler) ListPublicPosts(c *gin.Context) {
 		return
 	}
 
-	// 强制设置状态为已发布
-	query.Status = "published"
+	// 只列出已发布的公开文章
+	query.Listed = true
 
 	posts, total, err := h.service.ListPosts(&query)
 	if err != nil {
@@ -207,3 +304,39 @@ func (h *PostHandler) GetPostsByArchive(c *gin.Context) {
 		"items": posts,
 	})
 }
+
+// postViewer 从上下文中获取访问文章的用户，密码保护文章的解锁令牌通过 X-Post-Token 头或 post_token 参数携带
+func postViewer(c *gin.Context) *service.PostViewer {
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+
+	token := c.GetHeader("X-Post-Token")
+	if token == "" {
+		token = c.Query("post_token")
+	}
+
+	return &service.PostViewer{
+		UserID: getUserIDFromContext(c),
+		Role:   roleName,
+		Token:  token,
+	}
+}
+
+// handlePostError 将文章相关的错误转换为响应
+func handlePostError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPostLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "protected": true})
+	case errors.Is(err, service.ErrPostWrongPassword), errors.Is(err, service.ErrPostForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPostPasswordRequired), isAttachmentBindError(err),
+		errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrCategoryUnavailable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case workspaceErrorStatus(err) != 0:
+		c.JSON(workspaceErrorStatus(err), gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/service"
+	"notex/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StorageMigrationHandler 处理存储迁移相关的请求
+type StorageMigrationHandler struct {
+	service *service.StorageMigrationService
+}
+
+// NewStorageMigrationHandler 创建存储迁移处理器
+func NewStorageMigrationHandler(migrationService *service.StorageMigrationService) *StorageMigrationHandler {
+	return &StorageMigrationHandler{
+		service: migrationService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *StorageMigrationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	admin := r.Group("/admin/storage/migrations")
+	admin.Use(middleware.RequireAdmin())
+	{
+		admin.GET("", h.ListMigrations)
+		admin.POST("", middleware.AuditLog("migrate", "storage"), h.StartMigration)
+		admin.GET("/:id", h.GetMigration)
+		admin.GET("/:id/items", h.ListItems)
+		admin.POST("/:id/resume", middleware.AuditLog("resume", "storage"), h.ResumeMigration)
+	}
+}
+
+// StartMigration 创建存储迁移任务
+func (h *StorageMigrationHandler) StartMigration(c *gin.Context) {
+	var req dto.StorageMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.Start(getUserIDFromContext(c), &req)
+	if err != nil {
+		handleStorageMigrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// ResumeMigration 继续执行失败或中断的存储迁移任务
+func (h *StorageMigrationHandler) ResumeMigration(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid migration id"})
+		return
+	}
+
+	var req dto.StorageMigrationResumeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resp, err := h.service.Resume(uint(id), req.SkipFailed)
+	if err != nil {
+		handleStorageMigrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// GetMigration 获取存储迁移任务进度
+func (h *StorageMigrationHandler) GetMigration(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid migration id"})
+		return
+	}
+
+	resp, err := h.service.GetMigration(uint(id))
+	if err != nil {
+		handleStorageMigrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListMigrations 获取存储迁移任务列表
+func (h *StorageMigrationHandler) ListMigrations(c *gin.Context) {
+	var req dto.StorageMigrationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ListMigrations(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListItems 获取存储迁移任务中的文件
+func (h *StorageMigrationHandler) ListItems(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid migration id"})
+		return
+	}
+
+	var req dto.StorageMigrationItemListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ListItems(uint(id), &req)
+	if err != nil {
+		handleStorageMigrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleStorageMigrationError 将存储迁移的错误转换为响应
+func handleStorageMigrationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrStorageMigrationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrStorageMigrationRunning), errors.Is(err, service.ErrStorageMigrationFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrStorageMigrationInvalid), errors.Is(err, service.ErrStorageMigrationConfig):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"notex/api/dto"
 	"notex/api/service"
@@ -49,7 +50,7 @@ func (h *TagHandler) GetTag(c *gin.Context) {
 
 	tag, err := h.service.GetTag(uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleTagError(c, err)
 		return
 	}
 
@@ -64,13 +65,18 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.service.CreateTag(&req)
+	tag, created, err := h.service.CreateTag(getUserIDFromContext(c), getRoleFromContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleTagError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"items": []*dto.TagResponse{tag}})
+	// 名称或别名与已有标签相同时返回已有标签
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"items": []*dto.TagResponse{tag}})
 }
 
 // UpdateTag 更新标签
@@ -87,9 +93,9 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.service.UpdateTag(uint(id), &req)
+	tag, err := h.service.UpdateTag(uint(id), getUserIDFromContext(c), getRoleFromContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleTagError(c, err)
 		return
 	}
 
@@ -104,14 +110,106 @@ func (h *TagHandler) DeleteTag(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteTag(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.DeleteTag(uint(id), getUserIDFromContext(c), getRoleFromContext(c)); err != nil {
+		handleTagError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// MergeTags 将其他标签合并到路径中的标签
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.MergeTags(uint(id), getUserIDFromContext(c), getRoleFromContext(c), &req)
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// AddAlias 为标签添加别名
+func (h *TagHandler) AddAlias(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.TagAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.AddAlias(uint(id), getUserIDFromContext(c), getRoleFromContext(c), &req)
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"items": []*dto.TagResponse{tag}})
+}
+
+// DeleteAlias 删除标签别名
+func (h *TagHandler) DeleteAlias(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	aliasID, err := strconv.ParseUint(c.Param("alias_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alias id"})
+		return
+	}
+
+	if err := h.service.DeleteAlias(uint(id), uint(aliasID), getUserIDFromContext(c), getRoleFromContext(c)); err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListUnusedTags 列出未使用的标签
+func (h *TagHandler) ListUnusedTags(c *gin.Context) {
+	tags, err := h.service.ListUnusedTags(getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": tags,
+		"total": len(tags),
+	})
+}
+
+// DeleteUnusedTags 删除未使用的标签
+func (h *TagHandler) DeleteUnusedTags(c *gin.Context) {
+	deleted, err := h.service.DeleteUnusedTags(getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
 // GetTopTags 获取热门标签
 func (h *TagHandler) GetTopTags(c *gin.Context) {
 	limit := 10 // 默认获取10个
@@ -132,3 +230,20 @@ func (h *TagHandler) GetTopTags(c *gin.Context) {
 		"total": len(tags),
 	})
 }
+
+// handleTagError 将标签相关的错误转换为响应
+func handleTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTagForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTagNameInvalid), errors.Is(err, service.ErrTagInvalidSlug),
+		errors.Is(err, service.ErrTagMergeInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTagNameTaken), errors.Is(err, service.ErrTagSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
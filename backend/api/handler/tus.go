@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/service"
+	"notex/model"
+	"notex/pkg/tus"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	tusExtensions        = "creation,termination,expiration"
+	tusOffsetContentType = "application/offset+octet-stream"
+)
+
+// TusHandler 处理 tus 1.0 可续传上传请求
+type TusHandler struct {
+	service *service.ResumableUploadService
+}
+
+// NewTusHandler 创建可续传上传处理器
+func NewTusHandler(uploadService *service.ResumableUploadService) *TusHandler {
+	return &TusHandler{
+		service: uploadService,
+	}
+}
+
+// RegisterRoutes 注册路由，OPTIONS 请求用于发现服务端能力，无需认证
+func (h *TusHandler) RegisterRoutes(public, authenticated *gin.RouterGroup) {
+	public.OPTIONS("/upload/tus", h.Options)
+	public.OPTIONS("/upload/tus/:id", h.Options)
+
+	uploads := authenticated.Group("/upload/tus")
+	uploads.Use(h.requireVersion)
+	{
+		uploads.POST("", h.Create)
+		uploads.HEAD("/:id", h.Head)
+		uploads.PATCH("/:id", h.Patch)
+		uploads.DELETE("/:id", h.Delete)
+	}
+	// 查询上传结果，不属于 tus 协议，无需 Tus-Resumable 头
+	authenticated.GET("/upload/tus/:id", h.Get)
+}
+
+// Options 返回服务端支持的协议版本、扩展和最大上传长度
+func (h *TusHandler) Options(c *gin.Context) {
+	c.Header("Tus-Resumable", tus.Version)
+	c.Header("Tus-Version", tus.Version)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.service.MaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// requireVersion 检查客户端使用的协议版本
+func (h *TusHandler) requireVersion(c *gin.Context) {
+	c.Header("Tus-Resumable", tus.Version)
+	if c.GetHeader("Tus-Resumable") != tus.Version {
+		c.Header("Tus-Version", tus.Version)
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "unsupported tus version"})
+		return
+	}
+	c.Next()
+}
+
+// Create 创建上传，元数据 filename 为必填，target 指定上传完成后保存到媒体库（media）还是作为附件（attachment）
+func (h *TusHandler) Create(c *gin.Context) {
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deferred upload length is not supported"})
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Length header"})
+		return
+	}
+	if length > h.service.MaxSize() {
+		c.Header("Tus-Max-Size", strconv.FormatInt(h.service.MaxSize(), 10))
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrMediaTooLarge.Error()})
+		return
+	}
+	metadata, err := tus.ParseMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, _ := c.Get("role")
+	canAttach := role == model.RoleAdmin || role == model.RoleEditor
+	upload, err := h.service.Create(getUserIDFromContext(c), canAttach, length, metadata)
+	if err != nil {
+		handleTusError(c, err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+upload.ID)
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// Head 返回上传的当前偏移量，客户端据此续传
+func (h *TusHandler) Head(c *gin.Context) {
+	upload, err := h.service.Get(getUserIDFromContext(c), c.Param("id"))
+	if err != nil {
+		// HEAD 响应不能包含响应体
+		c.Status(tusErrorStatus(err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if len(upload.Metadata) > 0 {
+		c.Header("Upload-Metadata", tus.FormatMetadata(upload.Metadata))
+	}
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusOK)
+}
+
+// Patch 从 Upload-Offset 处追加数据，数据全部接收后移交给上传目标
+func (h *TusHandler) Patch(c *gin.Context) {
+	if c.ContentType() != tusOffsetContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + tusOffsetContentType})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Offset header"})
+		return
+	}
+
+	upload, err := h.service.Append(c.Request.Context(), getUserIDFromContext(c), c.Param("id"), offset, c.Request.Body)
+	if err != nil {
+		handleTusError(c, err)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusNoContent)
+}
+
+// Delete 终止上传并删除已接收的数据
+func (h *TusHandler) Delete(c *gin.Context) {
+	if err := h.service.Terminate(getUserIDFromContext(c), c.Param("id")); err != nil {
+		handleTusError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Get 返回上传进度，完成后包含媒体文件或附件信息
+func (h *TusHandler) Get(c *gin.Context) {
+	upload, err := h.service.Get(getUserIDFromContext(c), c.Param("id"))
+	if err != nil {
+		handleTusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         upload.ID,
+		"offset":     upload.Offset,
+		"length":     upload.Length,
+		"metadata":   upload.Metadata,
+		"completed":  upload.Completed,
+		"result":     upload.Result,
+		"expires_at": upload.ExpiresAt,
+	})
+}
+
+// tusErrorStatus 返回上传错误对应的状态码
+func tusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, tus.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, tus.ErrExpired):
+		return http.StatusGone
+	case errors.Is(err, tus.ErrOffsetMismatch), errors.Is(err, tus.ErrCompleted):
+		return http.StatusConflict
+	case errors.Is(err, tus.ErrLocked):
+		return http.StatusLocked
+	case errors.Is(err, tus.ErrExceedsLength):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, tus.ErrInvalidMeta), errors.Is(err, service.ErrUploadInvalidTarget), errors.Is(err, service.ErrUploadNoFilename):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// handleTusError 将可续传上传的错误转换为响应，移交上传目标时的错误按附件和媒体库的规则处理
+func handleTusError(c *gin.Context, err error) {
+	if status := tusErrorStatus(err); status != http.StatusInternalServerError {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	handleAttachmentError(c, err)
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"notex/api/dto"
+	"notex/api/service"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/storage"
+	"notex/pkg/tus"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tusMedia 记录导入媒体库的内容
+type tusMedia struct {
+	data string
+}
+
+func (m *tusMedia) MaxSize() int64 {
+	return 64
+}
+
+func (m *tusMedia) Import(ctx context.Context, userID uint, reader io.Reader, filename, declared string) (*dto.MediaUploadResponse, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	m.data = string(data)
+	return &dto.MediaUploadResponse{UploadResult: &storage.UploadResult{URL: "/uploads/" + filename}, AssetID: 1}, nil
+}
+
+// tusAttachments 只允许不超过 16 字节的 pdf 附件
+type tusAttachments struct{}
+
+func (tusAttachments) MaxSize(filename string) (int64, error) {
+	if !strings.HasSuffix(filename, ".pdf") {
+		return 0, service.ErrMediaTypeNotAllowed
+	}
+	return 16, nil
+}
+
+func (tusAttachments) Import(ctx context.Context, userID uint, reader io.Reader, filename string, size int64) (*dto.AttachmentResponse, error) {
+	return &dto.AttachmentResponse{ID: 2, Filename: filename, Size: size}, nil
+}
+
+type tusTest struct {
+	router *gin.Engine
+	media  *tusMedia
+}
+
+func newTusTest(t *testing.T) *tusTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, err := tus.NewStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	media := &tusMedia{}
+	uploads := service.NewResumableUploadService(store, media, tusAttachments{}, &config.TusConfig{MaxSize: 128})
+
+	router := gin.New()
+	public := router.Group("/api")
+	// X-Test-User 模拟认证中间件设置的用户
+	authenticated := router.Group("/api", func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		if c.GetHeader("X-Test-User") == "other" {
+			c.Set("user_id", uint(8))
+		}
+		c.Set("role", model.RoleUser)
+	})
+	NewTusHandler(uploads).RegisterRoutes(public, authenticated)
+	return &tusTest{router: router, media: media}
+}
+
+func (tt *tusTest) do(method, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	tt.router.ServeHTTP(w, req)
+	return w
+}
+
+// create 创建上传并返回 Location
+func (tt *tusTest) create(t *testing.T, length string, metadata string) string {
+	t.Helper()
+	w := tt.do(http.MethodPost, "/api/upload/tus", "", map[string]string{
+		"Tus-Resumable":   tus.Version,
+		"Upload-Length":   length,
+		"Upload-Metadata": metadata,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", w.Code, w.Body)
+	}
+	return w.Header().Get("Location")
+}
+
+func meta(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+" "+base64.StdEncoding.EncodeToString([]byte(pairs[i+1])))
+	}
+	return strings.Join(parts, ",")
+}
+
+func patchHeaders(offset string) map[string]string {
+	return map[string]string{
+		"Tus-Resumable": tus.Version,
+		"Content-Type":  tusOffsetContentType,
+		"Upload-Offset": offset,
+	}
+}
+
+func TestTusOptions(t *testing.T) {
+	tt := newTusTest(t)
+	w := tt.do(http.MethodOptions, "/api/upload/tus", "", nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d", w.Code)
+	}
+	for header, want := range map[string]string{
+		"Tus-Resumable": tus.Version,
+		"Tus-Version":   tus.Version,
+		"Tus-Extension": tusExtensions,
+		"Tus-Max-Size":  "128",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestTusCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "created", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Length": "11", "Upload-Metadata": meta("filename", "a.txt")}, status: http.StatusCreated},
+		{name: "missing version", headers: map[string]string{"Upload-Length": "11", "Upload-Metadata": meta("filename", "a.txt")}, status: http.StatusPreconditionFailed},
+		{name: "missing length", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Metadata": meta("filename", "a.txt")}, status: http.StatusBadRequest},
+		{name: "deferred length", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Defer-Length": "1", "Upload-Metadata": meta("filename", "a.txt")}, status: http.StatusBadRequest},
+		{name: "over max size", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Length": "129", "Upload-Metadata": meta("filename", "a.txt")}, status: http.StatusRequestEntityTooLarge},
+		{name: "over media limit", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Length": "65", "Upload-Metadata": meta("filename", "a.txt")}, status: http.StatusRequestEntityTooLarge},
+		{name: "invalid metadata", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Length": "11", "Upload-Metadata": "filename !!!"}, status: http.StatusBadRequest},
+		{name: "missing filename", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Length": "11"}, status: http.StatusBadRequest},
+		{name: "attachment needs editor", headers: map[string]string{"Tus-Resumable": tus.Version, "Upload-Length": "11", "Upload-Metadata": meta("filename", "a.pdf", "target", "attachment")}, status: http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newTusTest(t)
+			w := tt.do(http.MethodPost, "/api/upload/tus", "", tc.headers)
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tc.status, w.Body)
+			}
+			if w.Header().Get("Tus-Resumable") != tus.Version {
+				t.Errorf("Tus-Resumable = %q", w.Header().Get("Tus-Resumable"))
+			}
+			if tc.status != http.StatusCreated {
+				return
+			}
+			if !strings.HasPrefix(w.Header().Get("Location"), "/api/upload/tus/") || w.Header().Get("Upload-Expires") == "" {
+				t.Fatalf("headers = %v", w.Header())
+			}
+		})
+	}
+}
+
+func TestTusUpload(t *testing.T) {
+	tt := newTusTest(t)
+	location := tt.create(t, "11", meta("filename", "a.txt", "filetype", "text/plain"))
+	version := map[string]string{"Tus-Resumable": tus.Version}
+
+	w := tt.do(http.MethodHead, location, "", version)
+	if w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "0" || w.Header().Get("Upload-Length") != "11" {
+		t.Fatalf("HEAD = %d %v", w.Code, w.Header())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" || w.Body.Len() != 0 {
+		t.Fatalf("HEAD headers = %v, body %q", w.Header(), w.Body)
+	}
+	if got, _ := tus.ParseMetadata(w.Header().Get("Upload-Metadata")); got["filename"] != "a.txt" {
+		t.Fatalf("Upload-Metadata = %q", w.Header().Get("Upload-Metadata"))
+	}
+
+	w = tt.do(http.MethodPatch, location, "hello ", patchHeaders("0"))
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("PATCH = %d %v %s", w.Code, w.Header(), w.Body)
+	}
+
+	w = tt.do(http.MethodHead, location, "", version)
+	if w.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("HEAD offset = %q, want 6", w.Header().Get("Upload-Offset"))
+	}
+
+	// 偏移量不一致
+	w = tt.do(http.MethodPatch, location, "world", patchHeaders("0"))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("PATCH at wrong offset = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	// 错误的内容类型
+	headers := patchHeaders("6")
+	headers["Content-Type"] = "application/octet-stream"
+	w = tt.do(http.MethodPatch, location, "world", headers)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("PATCH with wrong Content-Type = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+
+	// 超过声明的长度
+	w = tt.do(http.MethodPatch, location, "world!", patchHeaders("6"))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("PATCH beyond length = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// 其他用户的上传按不存在处理
+	w = tt.do(http.MethodHead, location, "", map[string]string{"Tus-Resumable": tus.Version, "X-Test-User": "other"})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("HEAD by other user = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = tt.do(http.MethodPatch, location, "world", patchHeaders("6"))
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "11" {
+		t.Fatalf("final PATCH = %d %v %s", w.Code, w.Header(), w.Body)
+	}
+	if tt.media.data != "hello world" {
+		t.Fatalf("media imported %q", tt.media.data)
+	}
+
+	w = tt.do(http.MethodGet, location, "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"completed":true`) || !strings.Contains(w.Body.String(), `"asset_id":1`) {
+		t.Fatalf("GET = %d %s", w.Code, w.Body)
+	}
+
+	w = tt.do(http.MethodPatch, location, "", patchHeaders("11"))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("PATCH after completion = %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestTusTerminate(t *testing.T) {
+	tt := newTusTest(t)
+	location := tt.create(t, "11", meta("filename", "a.txt"))
+	version := map[string]string{"Tus-Resumable": tus.Version}
+
+	if w := tt.do(http.MethodPatch, location, "hello", patchHeaders("0")); w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH = %d", w.Code)
+	}
+	if w := tt.do(http.MethodDelete, location, "", map[string]string{"Tus-Resumable": tus.Version, "X-Test-User": "other"}); w.Code != http.StatusNotFound {
+		t.Fatalf("DELETE by other user = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := tt.do(http.MethodDelete, location, "", version); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if w := tt.do(http.MethodHead, location, "", version); w.Code != http.StatusNotFound {
+		t.Fatalf("HEAD after DELETE = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := tt.do(http.MethodPatch, location, " world", patchHeaders("5")); w.Code != http.StatusNotFound {
+		t.Fatalf("PATCH after DELETE = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
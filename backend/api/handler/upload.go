@@ -1,22 +1,11 @@
 package handler
 
 import (
-	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"image"
-	_ "image/gif"
-	"image/jpeg"
-	"image/png"
-	_ "image/png"
 
 	"github.com/gin-gonic/gin"
-	"github.com/nfnt/resize"
 
+	"notex/api/service"
 	"notex/pkg/storage"
 	"notex/pkg/types"
 )
@@ -25,13 +14,15 @@ import (
 type UploadHandler struct {
 	storage storage.Storage
 	config  *types.StorageConfig
+	media   *service.MediaService
 }
 
 // NewUploadHandler 创建上传处理器
-func NewUploadHandler(storage storage.Storage, config *types.StorageConfig) *UploadHandler {
+func NewUploadHandler(storage storage.Storage, config *types.StorageConfig, media *service.MediaService) *UploadHandler {
 	return &UploadHandler{
 		storage: storage,
 		config:  config,
+		media:   media,
 	}
 }
 
@@ -53,9 +44,10 @@ func (h *UploadHandler) Upload(c *gin.Context) {
 	}
 	defer file.Close()
 
-	result, err := h.storage.Upload(file, header)
+	// 校验类型并扫描后上传到媒体库，图片会去除元数据并生成尺寸变体
+	result, err := h.media.Upload(c.Request.Context(), getUserIDFromContext(c), file, header)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleMediaError(c, err)
 		return
 	}
 
@@ -78,75 +70,6 @@ func (h *UploadHandler) isAllowedType(contentType string) bool {
 	return false
 }
 
-// isImage 检查是否为图片
-func (h *UploadHandler) isImage(contentType string) bool {
-	return strings.HasPrefix(contentType, "image/")
-}
-
-// saveFile 保存文件
-func (h *UploadHandler) saveFile(file multipart.File, filepath string) error {
-	dst, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer dst.Close()
-
-	if _, err = io.Copy(dst, file); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// createThumbnail 创建缩略图
-func (h *UploadHandler) createThumbnail(filePath string) (string, error) {
-	// 打开原图
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	// 解码图片
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return "", err
-	}
-
-	// 生成缩略图
-	thumbnail := resize.Thumbnail(uint(h.config.ThumbnailSize), uint(h.config.ThumbnailSize), img, resize.Lanczos3)
-
-	// 生成缩略图文件名
-	dir := filepath.Dir(filePath)
-	ext := filepath.Ext(filePath)
-	base := filepath.Base(filePath)
-	baseWithoutExt := strings.TrimSuffix(base, ext)
-	thumbnailPath := filepath.Join(dir, baseWithoutExt+"_thumb"+ext)
-
-	// 保存缩略图
-	out, err := os.Create(thumbnailPath)
-	if err != nil {
-		return "", err
-	}
-	defer out.Close()
-
-	// 根据文件扩展名选择编码器
-	switch strings.ToLower(ext) {
-	case ".jpg", ".jpeg":
-		err = jpeg.Encode(out, thumbnail, nil)
-	case ".png":
-		err = png.Encode(out, thumbnail)
-	default:
-		err = jpeg.Encode(out, thumbnail, nil)
-	}
-
-	if err != nil {
-		return "", err
-	}
-
-	return thumbnailPath, nil
-}
-
 // GetCredentials 获取上传凭证
 func (h *UploadHandler) GetCredentials(c *gin.Context) {
 	filename := c.Query("filename")
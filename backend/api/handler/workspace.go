@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler 处理团队工作区、成员和邀请请求
+type WorkspaceHandler struct {
+	service *service.WorkspaceService
+}
+
+// NewWorkspaceHandler 创建工作区处理器
+func NewWorkspaceHandler(workspaceService *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		service: workspaceService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *WorkspaceHandler) RegisterRoutes(r *gin.RouterGroup) {
+	workspaces := r.Group("/workspaces")
+	{
+		workspaces.GET("", h.ListWorkspaces)
+		workspaces.POST("", h.CreateWorkspace)
+		workspaces.POST("/invitations/accept", h.AcceptInvitation)
+		workspaces.GET("/:id", h.GetWorkspace)
+		workspaces.PUT("/:id", h.UpdateWorkspace)
+		workspaces.DELETE("/:id", h.DeleteWorkspace)
+
+		workspaces.GET("/:id/members", h.ListMembers)
+		workspaces.PUT("/:id/members/:userId", h.UpdateMember)
+		workspaces.DELETE("/:id/members/:userId", h.RemoveMember)
+
+		workspaces.GET("/:id/invitations", h.ListInvitations)
+		workspaces.POST("/:id/invitations", h.InviteMember)
+		workspaces.DELETE("/:id/invitations/:invitationId", h.RevokeInvitation)
+	}
+}
+
+// ListWorkspaces 获取当前用户加入的工作区
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	workspaces, err := h.service.ListWorkspaces(getUserIDFromContext(c))
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": workspaces,
+		"total": len(workspaces),
+	})
+}
+
+// CreateWorkspace 创建工作区
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req dto.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.service.CreateWorkspace(getUserIDFromContext(c), &req)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// GetWorkspace 获取工作区详情
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.service.GetWorkspace(id, getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspace 更新工作区
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.service.UpdateWorkspace(id, getUserIDFromContext(c), getRoleFromContext(c), &req)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// DeleteWorkspace 删除工作区
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWorkspace(id, getUserIDFromContext(c), getRoleFromContext(c)); err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers 获取工作区成员列表
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListMembers(id, getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": members,
+		"total": len(members),
+	})
+}
+
+// UpdateMember 修改成员角色
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	memberUserID, ok := parseIDParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.UpdateMember(id, memberUserID, getUserIDFromContext(c), getRoleFromContext(c), req.Role); err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveMember 移除成员或退出工作区
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	memberUserID, ok := parseIDParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(id, memberUserID, getUserIDFromContext(c), getRoleFromContext(c)); err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListInvitations 获取工作区尚未接受的邀请
+func (h *WorkspaceHandler) ListInvitations(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(id, getUserIDFromContext(c), getRoleFromContext(c))
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": invitations,
+		"total": len(invitations),
+	})
+}
+
+// InviteMember 通过邮件邀请成员
+func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.InviteWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.service.InviteMember(id, getUserIDFromContext(c), getRoleFromContext(c), &req)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// RevokeInvitation 撤销邀请
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	invitationID, ok := parseIDParam(c, "invitationId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeInvitation(id, invitationID, getUserIDFromContext(c), getRoleFromContext(c)); err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation 使用邮件中的邀请码加入工作区
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	var req dto.AcceptWorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.service.AcceptInvitation(getUserIDFromContext(c), req.Token)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// parseIDParam 解析路径中的ID参数，解析失败时直接返回错误响应
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// getRoleFromContext 从上下文中获取当前用户的全局角色，未登录时为空
+func getRoleFromContext(c *gin.Context) string {
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+	return roleName
+}
+
+// workspaceErrorStatus 返回工作区相关错误对应的状态码，非工作区错误返回 0
+func workspaceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound), errors.Is(err, service.ErrWorkspaceMemberNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrWorkspaceForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrWorkspaceSlugTaken), errors.Is(err, service.ErrWorkspaceMemberExists),
+		errors.Is(err, service.ErrWorkspaceLastOwner):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrWorkspaceInvalidSlug), errors.Is(err, service.ErrWorkspaceInvitationInvalid):
+		return http.StatusBadRequest
+	default:
+		return 0
+	}
+}
+
+// handleWorkspaceError 将工作区相关的错误转换为响应
+func handleWorkspaceError(c *gin.Context, err error) {
+	if status := workspaceErrorStatus(err); status != 0 {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -26,6 +26,12 @@ type AIRepository interface {
 	// 默认设置相关
 	GetDefaultSetting(userID uint) (*model.AIDefaultSetting, error)
 	SaveDefaultSetting(setting *model.AIDefaultSetting) error
+
+	// 提示词模板相关
+	GetPromptTemplate(action string, version int) (*model.AIPromptTemplate, error)
+
+	// 生成图像相关
+	CreateImage(image *model.AIImage) error
 }
 
 // AIRepositoryImpl 实现AIRepository接口
@@ -155,3 +161,23 @@ func (r *AIRepositoryImpl) GetModelsByType(modelType string) ([]model.AIModel, e
 	err := r.db.Where("type = ? AND is_enabled = ?", modelType, true).Find(&models).Error
 	return models, err
 }
+
+// GetPromptTemplate 获取指定动作的提示词模板，version 为 0 时返回最新的启用版本
+func (r *AIRepositoryImpl) GetPromptTemplate(action string, version int) (*model.AIPromptTemplate, error) {
+	var template model.AIPromptTemplate
+	query := r.db.Where("action = ?", action)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	} else {
+		query = query.Where("is_active = ?", true).Order("version DESC")
+	}
+	if err := query.First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateImage 保存AI生成图像的记录
+func (r *AIRepositoryImpl) CreateImage(image *model.AIImage) error {
+	return r.db.Create(image).Error
+}
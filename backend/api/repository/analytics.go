@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostDailyStat 文章一天的浏览和评论
+type PostDailyStat struct {
+	Date           time.Time
+	Views          int64
+	UniqueVisitors int64
+	Comments       int64
+}
+
+// PostTraffic 文章在一段时间内的浏览量
+type PostTraffic struct {
+	PostID         uint
+	Title          string
+	Views          int64
+	UniqueVisitors int64
+}
+
+// GroupTraffic 分类或标签下文章在一段时间内的浏览量
+type GroupTraffic struct {
+	ID    uint
+	Name  string
+	Views int64
+}
+
+type AnalyticsRepository struct {
+	DB *gorm.DB
+}
+
+func NewAnalyticsRepository() *AnalyticsRepository {
+	return &AnalyticsRepository{
+		DB: database.GetDB(),
+	}
+}
+
+// Refresh 在事务中重新计算 from 及之后日期的文章评论数和作者每日汇总，
+// 新增关注者数按仍然存在的关注计算，取消的关注不计入
+func (r *AnalyticsRepository) Refresh(from time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date >= ?", from).Delete(&model.PostCommentStat{}).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(`
+			INSERT INTO post_comment_stats (post_id, date, comments)
+			SELECT post_id, DATE(created_at), COUNT(*)
+			FROM comments
+			WHERE created_at >= ? AND deleted_at IS NULL AND status NOT IN ('deleted', 'pending')
+			GROUP BY post_id, DATE(created_at)`, from).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("date >= ?", from).Delete(&model.AuthorDailyStat{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO author_daily_stats (user_id, date, views, unique_visitors, comments, new_followers)
+			SELECT user_id, date, SUM(views), SUM(unique_visitors), SUM(comments), SUM(new_followers)
+			FROM (
+				SELECT p.user_id, s.date, s.views, s.unique_visitors, 0 AS comments, 0 AS new_followers
+				FROM post_view_stats s JOIN posts p ON p.id = s.post_id
+				WHERE s.date >= ?
+				UNION ALL
+				SELECT p.user_id, c.date, 0, 0, c.comments, 0
+				FROM post_comment_stats c JOIN posts p ON p.id = c.post_id
+				WHERE c.date >= ?
+				UNION ALL
+				SELECT followee_id, DATE(created_at), 0, 0, 0, COUNT(*)
+				FROM user_follows
+				WHERE created_at >= ?
+				GROUP BY followee_id, DATE(created_at)
+			) daily
+			GROUP BY user_id, date`, from, from, from).Error
+	})
+}
+
+// AuthorDaily 按日期顺序获取作者在 [from, to] 内的每日汇总
+func (r *AnalyticsRepository) AuthorDaily(userID uint, from, to time.Time) ([]model.AuthorDailyStat, error) {
+	var stats []model.AuthorDailyStat
+	err := r.DB.Where("user_id = ? AND date BETWEEN ? AND ?", userID, from, to).
+		Order("date").
+		Find(&stats).Error
+	return stats, err
+}
+
+// PostDaily 按日期顺序获取文章在 [from, to] 内的每日浏览和评论
+func (r *AnalyticsRepository) PostDaily(postID uint, from, to time.Time) ([]PostDailyStat, error) {
+	var stats []PostDailyStat
+	err := r.DB.Raw(`
+		SELECT COALESCE(v.date, c.date) AS date,
+			COALESCE(v.views, 0) AS views,
+			COALESCE(v.unique_visitors, 0) AS unique_visitors,
+			COALESCE(c.comments, 0) AS comments
+		FROM (SELECT * FROM post_view_stats WHERE post_id = ? AND date BETWEEN ? AND ?) v
+		FULL OUTER JOIN (SELECT * FROM post_comment_stats WHERE post_id = ? AND date BETWEEN ? AND ?) c
+			ON c.date = v.date
+		ORDER BY 1`, postID, from, to, postID, from, to).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// TopPosts 获取作者在 [from, to] 内浏览量最多的文章
+func (r *AnalyticsRepository) TopPosts(userID uint, from, to time.Time, limit int) ([]PostTraffic, error) {
+	var posts []PostTraffic
+	err := r.DB.Table("post_view_stats s").
+		Select("p.id AS post_id, p.title, SUM(s.views) AS views, SUM(s.unique_visitors) AS unique_visitors").
+		Joins("JOIN posts p ON p.id = s.post_id").
+		Where("p.user_id = ? AND s.date BETWEEN ? AND ?", userID, from, to).
+		Group("p.id, p.title").
+		Order("views DESC, p.id").
+		Limit(limit).
+		Scan(&posts).Error
+	return posts, err
+}
+
+// TopReferrers 获取作者的文章在 [from, to] 内浏览量最多的来源域名
+func (r *AnalyticsRepository) TopReferrers(userID uint, from, to time.Time, limit int) ([]ReferrerCount, error) {
+	var referrers []ReferrerCount
+	err := r.DB.Table("post_view_referrers s").
+		Select("s.domain, SUM(s.views) AS views").
+		Joins("JOIN posts p ON p.id = s.post_id").
+		Where("p.user_id = ? AND s.date BETWEEN ? AND ?", userID, from, to).
+		Group("s.domain").
+		Order("views DESC, s.domain").
+		Limit(limit).
+		Scan(&referrers).Error
+	return referrers, err
+}
+
+// TrafficByCategory 按分类汇总作者的文章在 [from, to] 内的浏览量
+func (r *AnalyticsRepository) TrafficByCategory(userID uint, from, to time.Time) ([]GroupTraffic, error) {
+	var groups []GroupTraffic
+	err := r.DB.Table("post_view_stats s").
+		Select("c.id, c.name, SUM(s.views) AS views").
+		Joins("JOIN posts p ON p.id = s.post_id").
+		Joins("JOIN categories c ON c.id = p.category_id").
+		Where("p.user_id = ? AND s.date BETWEEN ? AND ?", userID, from, to).
+		Group("c.id, c.name").
+		Order("views DESC, c.id").
+		Scan(&groups).Error
+	return groups, err
+}
+
+// TrafficByTag 按标签汇总作者的文章在 [from, to] 内的浏览量，带多个标签的文章计入每个标签
+func (r *AnalyticsRepository) TrafficByTag(userID uint, from, to time.Time, limit int) ([]GroupTraffic, error) {
+	var groups []GroupTraffic
+	err := r.DB.Table("post_view_stats s").
+		Select("t.id, t.name, SUM(s.views) AS views").
+		Joins("JOIN posts p ON p.id = s.post_id").
+		Joins("JOIN post_tags pt ON pt.post_id = s.post_id").
+		Joins("JOIN tags t ON t.id = pt.tag_id").
+		Where("p.user_id = ? AND s.date BETWEEN ? AND ?", userID, from, to).
+		Group("t.id, t.name").
+		Order("views DESC, t.id").
+		Limit(limit).
+		Scan(&groups).Error
+	return groups, err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"notex/model"
+	"notex/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// ErrAttachmentUnavailable 附件不存在、不属于该用户或已关联其他文章或草稿
+var ErrAttachmentUnavailable = errors.New("attachment is unavailable")
+
+type AttachmentRepository struct {
+	DB *gorm.DB
+}
+
+func NewAttachmentRepository() *AttachmentRepository {
+	return &AttachmentRepository{
+		DB: database.GetDB(),
+	}
+}
+
+// Create 创建附件
+func (r *AttachmentRepository) Create(attachment *model.Attachment) error {
+	return r.DB.Create(attachment).Error
+}
+
+// Delete 删除附件
+func (r *AttachmentRepository) Delete(id uint) error {
+	return r.DB.Delete(&model.Attachment{}, id).Error
+}
+
+// FindByID 根据ID查找附件，包含媒体文件
+func (r *AttachmentRepository) FindByID(id uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	if err := r.DB.Preload("Asset").First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListByOwner 按顺序获取文章或草稿的附件，column 为 post_id 或 draft_id
+func (r *AttachmentRepository) ListByOwner(column string, ownerID uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := orderAttachments(r.DB.Where(column+" = ?", ownerID)).Find(&attachments).Error
+	return attachments, err
+}
+
+// Bind 在事务中将文章或草稿的附件替换为 ids 指定的附件并按顺序排列，
+// 不再使用的附件解除关联。附件必须属于 userID 且未关联其他文章或草稿。
+func (r *AttachmentRepository) Bind(tx *gorm.DB, column string, ownerID, userID uint, ids []uint) error {
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if len(ids) > 0 {
+			var count int64
+			err := tx.Model(&model.Attachment{}).
+				Where("id IN ? AND user_id = ?", ids, userID).
+				Where("(post_id IS NULL AND draft_id IS NULL) OR "+column+" = ?", ownerID).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if int(count) != len(ids) {
+				return ErrAttachmentUnavailable
+			}
+		}
+
+		detach := tx.Model(&model.Attachment{}).Where(column+" = ?", ownerID)
+		if len(ids) > 0 {
+			detach = detach.Where("id NOT IN ?", ids)
+		}
+		if err := detach.Update(column, nil).Error; err != nil {
+			return err
+		}
+
+		for i, id := range ids {
+			err := tx.Model(&model.Attachment{}).Where("id = ?", id).
+				Updates(map[string]interface{}{column: ownerID, "position": i}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IncrementDownloads 增加附件下载次数
+func (r *AttachmentRepository) IncrementDownloads(id uint) error {
+	return r.DB.Model(&model.Attachment{}).Where("id = ?", id).
+		UpdateColumn("downloads", gorm.Expr("downloads + ?", 1)).Error
+}
+
+// FindPostVisibility 获取附件所属文章的作者、工作区、状态和可见性
+func (r *AttachmentRepository) FindPostVisibility(postID uint) (*model.Post, error) {
+	var post model.Post
+	if err := r.DB.Select("id", "user_id", "workspace_id", "status", "visibility", "password_hash").First(&post, postID).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// orderAttachments 按附件在文章或草稿中的顺序预加载
+func orderAttachments(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
@@ -22,6 +22,11 @@ func (r *AuditLogRepository) Create(log *model.AuditLog) error {
 	return r.db.Create(log).Error
 }
 
+// CreateSystem 创建后台任务的审计日志，日志没有操作用户
+func (r *AuditLogRepository) CreateSystem(log *model.AuditLog) error {
+	return r.db.Omit("UserID").Create(log).Error
+}
+
 // FindAll 查找审计日志
 func (r *AuditLogRepository) FindAll(page, pageSize int, userID uint, action, resource string) ([]*model.AuditLog, int64, error) {
 	var logs []*model.AuditLog
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReadingListCount 阅读列表及其中的收藏数
+type ReadingListCount struct {
+	model.ReadingList
+	BookmarkCount int64
+}
+
+type BookmarkRepository struct {
+	DB *gorm.DB
+}
+
+func NewBookmarkRepository() *BookmarkRepository {
+	return &BookmarkRepository{
+		DB: database.GetDB(),
+	}
+}
+
+// Save 收藏文章，已收藏时更新所属的阅读列表
+func (r *BookmarkRepository) Save(bookmark *model.Bookmark) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reading_list_id", "updated_at"}),
+	}).Create(bookmark).Error
+}
+
+// Find 查找用户对文章的收藏
+func (r *BookmarkRepository) Find(userID, postID uint) (*model.Bookmark, error) {
+	var bookmark model.Bookmark
+	if err := r.DB.Where("user_id = ? AND post_id = ?", userID, postID).First(&bookmark).Error; err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+// Delete 取消收藏，返回是否删除了收藏
+func (r *BookmarkRepository) Delete(userID, postID uint) (bool, error) {
+	result := r.DB.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&model.Bookmark{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Exists 检查用户是否收藏了文章
+func (r *BookmarkRepository) Exists(userID, postID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&model.Bookmark{}).Where("user_id = ? AND post_id = ?", userID, postID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListByUser 按收藏时间倒序获取用户的收藏。readingListID 为空时获取全部收藏，
+// 为 0 时获取未归入阅读列表的收藏
+func (r *BookmarkRepository) ListByUser(userID uint, readingListID *uint, page, pageSize int) ([]model.Bookmark, int64, error) {
+	var bookmarks []model.Bookmark
+	var total int64
+
+	query := r.DB.Model(&model.Bookmark{}).Where("user_id = ?", userID)
+	if readingListID != nil {
+		if *readingListID == 0 {
+			query = query.Where("reading_list_id IS NULL")
+		} else {
+			query = query.Where("reading_list_id = ?", *readingListID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Post").Preload("Post.User").Preload("Post.Category").Preload("Post.Tags").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&bookmarks).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return bookmarks, total, nil
+}
+
+// CreateList 创建阅读列表
+func (r *BookmarkRepository) CreateList(list *model.ReadingList) error {
+	return r.DB.Create(list).Error
+}
+
+// UpdateList 更新阅读列表
+func (r *BookmarkRepository) UpdateList(list *model.ReadingList) error {
+	return r.DB.Save(list).Error
+}
+
+// DeleteList 删除阅读列表，其中的收藏变为未归入阅读列表
+func (r *BookmarkRepository) DeleteList(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Bookmark{}).Where("reading_list_id = ?", id).Update("reading_list_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.ReadingList{}, id).Error
+	})
+}
+
+// FindList 根据ID查找阅读列表
+func (r *BookmarkRepository) FindList(id uint) (*model.ReadingList, error) {
+	var list model.ReadingList
+	if err := r.DB.First(&list, id).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListNameExists 检查用户是否已有同名的阅读列表，excludeID 用于修改时排除自身
+func (r *BookmarkRepository) ListNameExists(userID uint, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&model.ReadingList{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountInList 获取阅读列表中的收藏数
+func (r *BookmarkRepository) CountInList(id uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.Bookmark{}).Where("reading_list_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// ListsByUser 按名称获取用户的阅读列表及其中的收藏数
+func (r *BookmarkRepository) ListsByUser(userID uint) ([]ReadingListCount, error) {
+	var lists []ReadingListCount
+	err := r.DB.Model(&model.ReadingList{}).
+		Select("reading_lists.*, (SELECT COUNT(*) FROM bookmarks WHERE bookmarks.reading_list_id = reading_lists.id) AS bookmark_count").
+		Where("user_id = ?", userID).
+		Order("name").
+		Scan(&lists).Error
+	return lists, err
+}
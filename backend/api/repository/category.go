@@ -27,9 +27,15 @@ func (r *CategoryRepository) Update(category *model.Category) error {
 	return r.db.Save(category).Error
 }
 
-// Delete 删除分类
-func (r *CategoryRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Category{}, id).Error
+// Delete 删除分类，下级分类移到被删除分类的上级分类下
+func (r *CategoryRepository) Delete(category *model.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Category{}).Where("parent_id = ?", category.ID).
+			Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Category{}, category.ID).Error
+	})
 }
 
 // FindByID 根据ID查找分类
@@ -42,12 +48,17 @@ func (r *CategoryRepository) FindByID(id uint) (*model.Category, error) {
 	return &category, nil
 }
 
-// List 获取分类列表
-func (r *CategoryRepository) List(page, pageSize int, search string) ([]model.Category, int64, error) {
+// List 获取分类列表，workspaceID 为 0 时只列出全站分类
+func (r *CategoryRepository) List(page, pageSize int, search string, workspaceID uint) ([]model.Category, int64, error) {
 	var categories []model.Category
 	var total int64
 
 	query := r.db.Model(&model.Category{})
+	if workspaceID > 0 {
+		query = query.Where("workspace_id = ?", workspaceID)
+	} else {
+		query = query.Where("workspace_id IS NULL")
+	}
 
 	if search != "" {
 		query = query.Where("name LIKE ? OR description LIKE ?", "%"+search+"%", "%"+search+"%")
@@ -70,6 +81,124 @@ func (r *CategoryRepository) List(page, pageSize int, search string) ([]model.Ca
 	return categories, total, nil
 }
 
+// ListScope 获取全站或工作区的全部分类，同级分类按排序值排列，workspaceID 为 0 时列出全站分类
+func (r *CategoryRepository) ListScope(workspaceID uint) ([]model.Category, error) {
+	var categories []model.Category
+	err := scopeCategories(r.db.Model(&model.Category{}), workspaceID).
+		Order("sort_order ASC, id ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// CountPostsInScope 统计全站或工作区每个分类直接包含的文章数量
+func (r *CategoryRepository) CountPostsInScope(workspaceID uint) (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	query := r.db.Model(&model.Post{}).
+		Select("posts.category_id, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = posts.category_id")
+	if workspaceID > 0 {
+		query = query.Where("categories.workspace_id = ?", workspaceID)
+	} else {
+		query = query.Where("categories.workspace_id IS NULL")
+	}
+	if err := query.Group("posts.category_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// Ancestors 获取分类及其全部上级分类，从分类本身开始逐级向上排列
+func (r *CategoryRepository) Ancestors(id uint) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT categories.*, 0 AS depth FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.*, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			WHERE a.depth < 100
+		)
+		SELECT * FROM ancestors ORDER BY depth`, id).
+		Scan(&categories).Error
+	return categories, err
+}
+
+// ExistsBySlug 检查全站或工作区中是否已有使用该 slug 的其他分类
+func (r *CategoryRepository) ExistsBySlug(workspaceID *uint, slug string, excludeID uint) (bool, error) {
+	var scope uint
+	if workspaceID != nil {
+		scope = *workspaceID
+	}
+	var count int64
+	err := scopeCategories(r.db.Model(&model.Category{}), scope).
+		Where("slug = ? AND id <> ?", slug, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetTotalPostCount 获取分类及其全部下级分类中的文章数量
+func (r *CategoryRepository) GetTotalPostCount(categoryID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Post{}).
+		Where("category_id IN ("+categorySubtreeSQL+")", categoryID).
+		Count(&count).Error
+	return count, err
+}
+
+// categorySubtreeSQL 查询分类及其全部下级分类ID的子查询，参数为分类ID
+const categorySubtreeSQL = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM categories WHERE id = ?
+		UNION
+		SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+	)
+	SELECT id FROM subtree`
+
+// scopeCategories 限定全站或工作区的分类，workspaceID 为 0 时为全站分类
+func scopeCategories(query *gorm.DB, workspaceID uint) *gorm.DB {
+	if workspaceID > 0 {
+		return query.Where("workspace_id = ?", workspaceID)
+	}
+	return query.Where("workspace_id IS NULL")
+}
+
+// IsEditor 检查用户是否是分类的责任编辑
+func (r *CategoryRepository) IsEditor(categoryID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("category_editors").
+		Where("category_id = ? AND user_id = ?", categoryID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListEditors 获取分类的责任编辑
+func (r *CategoryRepository) ListEditors(categoryID uint) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Model(&model.Category{ID: categoryID}).Association("Editors").Find(&users)
+	return users, err
+}
+
+// ReplaceEditors 替换分类的责任编辑
+func (r *CategoryRepository) ReplaceEditors(category *model.Category, userIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(userIDs) == 0 {
+			return tx.Model(category).Association("Editors").Clear()
+		}
+		var users []model.User
+		if err := tx.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return err
+		}
+		return tx.Model(category).Association("Editors").Replace(users)
+	})
+}
+
 // GetPostCount 获取分类下的文章数量
 func (r *CategoryRepository) GetPostCount(categoryID uint) (int64, error) {
 	var count int64
@@ -77,12 +206,13 @@ func (r *CategoryRepository) GetPostCount(categoryID uint) (int64, error) {
 	return count, err
 }
 
-// ListByPostCount 获取热门分类（按文章数量排序）
+// ListByPostCount 获取热门的全站分类（按文章数量排序）
 func (r *CategoryRepository) ListByPostCount(limit int) ([]model.Category, error) {
 	var categories []model.Category
 	err := r.db.Model(&model.Category{}).
 		Select("categories.*, COUNT(posts.id) as post_count").
 		Joins("LEFT JOIN posts ON posts.category_id = categories.id").
+		Where("categories.workspace_id IS NULL").
 		Group("categories.id").
 		Order("post_count DESC").
 		Limit(limit).
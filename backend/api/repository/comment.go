@@ -3,10 +3,41 @@ package repository
 import (
 	"notex/model"
 	"notex/pkg/database"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// 评论树的排序方式
+const (
+	CommentSortNewest    = "newest"
+	CommentSortOldest    = "oldest"
+	CommentSortReactions = "reactions" // 按表情回应总数，相同时较新的在前
+)
+
+// commentOrders 各排序方式的排序语句
+var commentOrders = map[string]string{
+	CommentSortNewest:    "created_at DESC, id DESC",
+	CommentSortOldest:    "created_at ASC, id ASC",
+	CommentSortReactions: "reaction_count DESC, id DESC",
+}
+
+// CommentKey 评论在树中的位置和排序键，也用作分页游标
+type CommentKey struct {
+	ID            uint
+	ParentID      *uint
+	CreatedAt     time.Time
+	ReactionCount int64
+}
+
+// ReactionCount 评论的一种表情回应及其数量
+type ReactionCount struct {
+	CommentID uint
+	Emoji     string
+	Count     int64
+}
+
 type CommentRepository struct {
 	db *gorm.DB
 }
@@ -17,9 +48,179 @@ func NewCommentRepository() *CommentRepository {
 	}
 }
 
-// Create 创建评论
+// Create 在事务中创建评论及其提醒的用户
 func (r *CommentRepository) Create(comment *model.Comment) error {
-	return r.db.Create(comment).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Mentions").Create(comment).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, comment.ID, comment.Mentions)
+	})
+}
+
+// CreateGuest 在事务中创建等待确认的访客评论及其确认记录
+func (r *CommentRepository) CreateGuest(comment *model.Comment, verification *model.GuestCommentVerification) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Mentions").Create(comment).Error; err != nil {
+			return err
+		}
+		if err := saveMentions(tx, comment.ID, comment.Mentions); err != nil {
+			return err
+		}
+		verification.CommentID = comment.ID
+		return tx.Create(verification).Error
+	})
+}
+
+// FindGuestVerification 根据令牌哈希查找未过期的访客评论确认记录
+func (r *CommentRepository) FindGuestVerification(tokenHash string) (*model.GuestCommentVerification, error) {
+	var verification model.GuestCommentVerification
+	err := r.db.Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// ConfirmGuest 在事务中将等待确认的访客评论改为指定状态并删除确认记录
+func (r *CommentRepository) ConfirmGuest(commentID uint, status string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Comment{}).
+			Where("id = ? AND status = ?", commentID, "pending").
+			Update("status", status).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("comment_id = ?", commentID).Delete(&model.GuestCommentVerification{}).Error
+	})
+}
+
+// DeleteExpiredGuests 彻底删除确认链接已过期的访客评论，确认记录随评论级联删除，返回删除的数量
+func (r *CommentRepository) DeleteExpiredGuests(now time.Time) (int64, error) {
+	expired := r.db.Model(&model.GuestCommentVerification{}).Select("comment_id").Where("expires_at <= ?", now)
+	result := r.db.Unscoped().
+		Where("status = ? AND id IN (?)", "pending", expired).
+		Delete(&model.Comment{})
+	return result.RowsAffected, result.Error
+}
+
+// PendingGuestPostIDs 获取邮箱相同的等待确认的访客评论所属的文章ID
+func (r *CommentRepository) PendingGuestPostIDs(email string) ([]uint, error) {
+	var postIDs []uint
+	err := r.db.Model(&model.Comment{}).
+		Where("user_id IS NULL AND guest_email = ? AND status = ?", email, "pending").
+		Distinct().
+		Pluck("post_id", &postIDs).Error
+	return postIDs, err
+}
+
+// ClaimGuests 将邮箱相同的访客评论归入用户，返回认领的数量。等待确认的评论按 statuses 中所属文章对应的状态确认，
+// 不在 statuses 中的文章下的评论保持等待确认且不被认领，在确认链接过期后被清理
+func (r *CommentRepository) ClaimGuests(userID uint, email string, statuses map[uint]string) (int64, error) {
+	var claimed int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for postID, status := range statuses {
+			err := tx.Model(&model.Comment{}).
+				Where("user_id IS NULL AND guest_email = ? AND status = ? AND post_id = ?", email, "pending", postID).
+				Update("status", status).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		claimable := tx.Model(&model.Comment{}).Select("id").
+			Where("user_id IS NULL AND guest_email = ? AND status <> ?", email, "pending")
+		if err := tx.Where("comment_id IN (?)", claimable).Delete(&model.GuestCommentVerification{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Comment{}).
+			Where("user_id IS NULL AND guest_email = ? AND status <> ?", email, "pending").
+			Updates(map[string]interface{}{
+				"user_id":     userID,
+				"guest_name":  nil,
+				"guest_email": nil,
+			})
+		claimed = result.RowsAffected
+		return result.Error
+	})
+	return claimed, err
+}
+
+// UpdateContent 在事务中修改评论内容：保存修改前的内容为修订版本，并替换提醒的用户
+func (r *CommentRepository) UpdateContent(comment *model.Comment, previous string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		revision := &model.CommentRevision{CommentID: comment.ID, Content: previous}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&model.Comment{}).Where("id = ?", comment.ID).
+			Updates(map[string]interface{}{"content": comment.Content, "edited_at": comment.EditedAt}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&model.CommentMention{}).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, comment.ID, comment.Mentions)
+	})
+}
+
+// saveMentions 记录评论提醒的用户
+func saveMentions(tx *gorm.DB, commentID uint, users []model.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	mentions := make([]model.CommentMention, 0, len(users))
+	for _, user := range users {
+		mentions = append(mentions, model.CommentMention{CommentID: commentID, UserID: user.ID})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error
+}
+
+// ListRevisions 按时间倒序获取评论修改前的各个版本
+func (r *CommentRepository) ListRevisions(commentID uint) ([]model.CommentRevision, error) {
+	var revisions []model.CommentRevision
+	err := r.db.Where("comment_id = ?", commentID).Order("created_at DESC, id DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// AddReaction 添加表情回应，已有相同回应时不做修改
+func (r *CommentRepository) AddReaction(reaction *model.CommentReaction) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+// RemoveReaction 取消表情回应
+func (r *CommentRepository) RemoveReaction(commentID, userID uint, emoji string) error {
+	return r.db.Where("comment_id = ? AND user_id = ? AND emoji = ?", commentID, userID, emoji).
+		Delete(&model.CommentReaction{}).Error
+}
+
+// CountReactions 获取多条评论每种表情回应的数量，同一评论内按最早使用的顺序排列
+func (r *CommentRepository) CountReactions(commentIDs []uint) ([]ReactionCount, error) {
+	var counts []ReactionCount
+	if len(commentIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.Model(&model.CommentReaction{}).
+		Select("comment_id, emoji, COUNT(*) AS count").
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id, emoji").
+		Order("comment_id, MIN(created_at), emoji").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// ListUserReactions 获取用户对多条评论使用的表情回应
+func (r *CommentRepository) ListUserReactions(commentIDs []uint, userID uint) ([]model.CommentReaction, error) {
+	var reactions []model.CommentReaction
+	if len(commentIDs) == 0 || userID == 0 {
+		return reactions, nil
+	}
+	err := r.db.Where("comment_id IN ? AND user_id = ?", commentIDs, userID).Find(&reactions).Error
+	return reactions, err
 }
 
 // Delete 删除评论
@@ -27,11 +228,17 @@ func (r *CommentRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Comment{}, id).Error
 }
 
+// UpdateStatus 更新评论状态
+func (r *CommentRepository) UpdateStatus(id uint, status string) error {
+	return r.db.Model(&model.Comment{}).Where("id = ?", id).Update("status", status).Error
+}
+
 // FindByID 根据ID查找评论
 func (r *CommentRepository) FindByID(id uint) (*model.Comment, error) {
 	var comment model.Comment
 	result := r.db.
 		Preload("User").
+		Preload("Mentions").
 		Preload("Parent").
 		Preload("Parent.User").
 		Preload("ReplyTo").
@@ -61,6 +268,7 @@ func (r *CommentRepository) ListByPostID(postID uint, page, pageSize int) ([]mod
 	// Get parent comments with preloaded user information
 	err := r.db.
 		Preload("User").
+		Preload("Mentions").
 		Where("post_id = ? AND parent_id IS NULL AND status = ?", postID, "active").
 		Order("created_at DESC").
 		Offset(offset).
@@ -81,21 +289,28 @@ func (r *CommentRepository) CountByUserID(userID uint) (int64, error) {
 	return count, err
 }
 
-// ListByUser 获取用户的评论列表
+// ListByUser 获取用户的评论列表，不包含已转为草稿或私密的他人文章下的评论
 func (r *CommentRepository) ListByUser(userID uint, page, pageSize int) ([]model.Comment, int64, error) {
 	var comments []model.Comment
 	var total int64
 
+	// 只包含用户仍可访问的文章下的评论
+	visiblePosts := r.db.Model(&model.Post{}).Select("id").
+		Where("(status = ? AND visibility <> ?) OR user_id = ?", "published", model.PostVisibilityPrivate, userID)
+
 	// 获取总数
-	if err := r.db.Model(&model.Comment{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.db.Model(&model.Comment{}).
+		Where("user_id = ? AND post_id IN (?)", userID, visiblePosts).
+		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取评论列表，包括关联的文章和用户信息
 	err := r.db.Model(&model.Comment{}).
-		Where("user_id = ?", userID).
+		Where("user_id = ? AND post_id IN (?)", userID, visiblePosts).
 		Preload("Post").
 		Preload("User").
+		Preload("Mentions").
 		Preload("Parent").
 		Preload("Parent.User").
 		Preload("ReplyTo").
@@ -118,6 +333,7 @@ func (r *CommentRepository) ListReplies(commentID uint) ([]model.Comment, error)
 
 	err := r.db.
 		Preload("User").
+		Preload("Mentions").
 		Preload("ReplyTo").
 		Preload("ReplyTo.User").
 		Where("parent_id = ? AND status = ?", commentID, "active").
@@ -172,12 +388,94 @@ func (r *CommentRepository) ListByPost(postID uint, page, pageSize int) ([]model
 	return comments, total, nil
 }
 
-// GetReplyCount 获取评论的回复数量
-func (r *CommentRepository) GetReplyCount(commentID uint) (int, error) {
-	var count int64
-	err := r.db.Model(&model.Comment{}).Where("parent_id = ? AND status = ?", commentID, "active").Count(&count).Error
+// CountReplies 获取多条评论的直接回复数量，没有回复的评论不在结果中
+func (r *CommentRepository) CountReplies(commentIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ParentID uint
+		Count    int
+	}
+	err := r.db.Model(&model.Comment{}).
+		Select("parent_id, COUNT(*) AS count").
+		Where("parent_id IN ? AND status = ?", commentIDs, "active").
+		Group("parent_id").
+		Scan(&rows).Error
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ParentID] = row.Count
 	}
-	return int(count), nil
+	return counts, nil
+}
+
+// FindByIDs 获取多条评论及其作者、提醒的用户和被回复的评论
+func (r *CommentRepository) FindByIDs(ids []uint) ([]model.Comment, error) {
+	var comments []model.Comment
+	if len(ids) == 0 {
+		return comments, nil
+	}
+	err := r.db.
+		Preload("User").
+		Preload("Mentions").
+		Preload("ReplyTo").
+		Preload("ReplyTo.User").
+		Where("id IN ?", ids).
+		Find(&comments).Error
+	return comments, err
+}
+
+// ListThread 按 sort 获取文章中一层的可见评论：parentID 为空时为第一层评论，否则为该评论的直接回复。
+// cursor 不为空时从该评论之后开始，最多返回 limit 条
+func (r *CommentRepository) ListThread(postID uint, parentID *uint, sort string, cursor *CommentKey, limit int) ([]CommentKey, error) {
+	inner := r.threadQuery().Where("c.post_id = ?", postID)
+	if parentID == nil {
+		inner = inner.Where("c.parent_id IS NULL")
+	} else {
+		inner = inner.Where("c.parent_id = ?", *parentID)
+	}
+
+	query := r.db.Table("(?) AS t", inner)
+	if cursor != nil {
+		switch sort {
+		case CommentSortOldest:
+			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+		case CommentSortReactions:
+			query = query.Where("(reaction_count, id) < (?, ?)", cursor.ReactionCount, cursor.ID)
+		default:
+			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+		}
+	}
+
+	var keys []CommentKey
+	err := query.Order(commentOrders[sort]).Limit(limit).Scan(&keys).Error
+	return keys, err
+}
+
+// ListFirstReplies 按 sort 获取多条评论各自的前 limit 条可见回复，结果按父评论分组排列
+func (r *CommentRepository) ListFirstReplies(parentIDs []uint, sort string, limit int) ([]CommentKey, error) {
+	var keys []CommentKey
+	if len(parentIDs) == 0 {
+		return keys, nil
+	}
+
+	ranked := r.db.Table("(?) AS t", r.threadQuery().Where("c.parent_id IN ?", parentIDs)).
+		Select("t.*, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY " + commentOrders[sort] + ") AS rn")
+	err := r.db.Table("(?) AS ranked", ranked).
+		Select("id, parent_id, created_at, reaction_count").
+		Where("rn <= ?", limit).
+		Order("parent_id, rn").
+		Scan(&keys).Error
+	return keys, err
+}
+
+// threadQuery 可见评论的排序键
+func (r *CommentRepository) threadQuery() *gorm.DB {
+	return r.db.Table("comments c").
+		Select("c.id, c.parent_id, c.created_at, (SELECT COUNT(*) FROM comment_reactions cr WHERE cr.comment_id = c.id) AS reaction_count").
+		Where("c.status = ? AND c.deleted_at IS NULL", "active")
 }
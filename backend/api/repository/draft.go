@@ -31,9 +31,9 @@ func (r *DraftRepository) Create(draft *model.Draft) error {
 	return r.DB.Create(draft).Error
 }
 
-// Update 更新草稿
+// Update 更新草稿，附件通过 AttachmentRepository 单独维护
 func (r *DraftRepository) Update(draft *model.Draft) error {
-	return r.DB.Save(draft).Error
+	return r.DB.Omit("Attachments").Save(draft).Error
 }
 
 // Delete 删除草稿
@@ -44,7 +44,7 @@ func (r *DraftRepository) Delete(draft *model.Draft) error {
 // FindByID 根据ID查找草稿
 func (r *DraftRepository) FindByID(id uint) (*model.Draft, error) {
 	var draft model.Draft
-	err := r.DB.Preload("Category").Preload("Tags").First(&draft, id).Error
+	err := r.DB.Preload("Category").Preload("Tags").Preload("Attachments", orderAttachments).First(&draft, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +64,8 @@ func (r *DraftRepository) List(page, pageSize int, conditions map[string]interfa
 			switch key {
 			case "user_id":
 				query = query.Where("user_id = ?", value)
+			case "workspace_id":
+				query = query.Where("workspace_id = ?", value)
 			case "search":
 				searchTerm := "%" + value.(string) + "%"
 				query = query.Where("LOWER(title) LIKE LOWER(?) OR LOWER(content) LIKE LOWER(?) OR LOWER(summary) LIKE LOWER(?)",
@@ -104,8 +106,8 @@ func (r *DraftRepository) CountByUserID(userID uint) (int64, error) {
 	return count, err
 }
 
-// PublishDraft 发布草稿为文章
-func (r *DraftRepository) PublishDraft(draft *model.Draft) (*model.Post, error) {
+// PublishDraft 发布草稿为文章，visibility 为文章的可见性
+func (r *DraftRepository) PublishDraft(draft *model.Draft, visibility string) (*model.Post, error) {
 	// 开启事务
 	tx := r.DB.Begin()
 
@@ -121,7 +123,9 @@ func (r *DraftRepository) PublishDraft(draft *model.Draft) (*model.Post, error)
 		Cover:       draft.Cover,
 		CategoryID:  draft.CategoryID,
 		UserID:      draft.UserID,
+		WorkspaceID: draft.WorkspaceID,
 		Status:      "published",
+		Visibility:  visibility,
 		Slug:        slug,
 		PublishedAt: time.Now(),
 	}
@@ -140,6 +144,13 @@ func (r *DraftRepository) PublishDraft(draft *model.Draft) (*model.Post, error)
 		}
 	}
 
+	// 将草稿的附件转移到文章
+	if err := tx.Model(&model.Attachment{}).Where("draft_id = ?", draft.ID).
+		Updates(map[string]interface{}{"post_id": post.ID, "draft_id": nil}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// 删除草稿
 	if err := tx.Delete(draft).Error; err != nil {
 		tx.Rollback()
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type EmbeddingRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingRepository() *EmbeddingRepository {
+	return &EmbeddingRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ReplacePostEmbeddings 替换文章的全部分块向量
+func (r *EmbeddingRepository) ReplacePostEmbeddings(postID uint, embeddings []model.PostEmbedding) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&model.PostEmbedding{}).Error; err != nil {
+			return err
+		}
+		if len(embeddings) == 0 {
+			return nil
+		}
+		return tx.Create(&embeddings).Error
+	})
+}
+
+// DeleteByPostID 删除文章的分块向量
+func (r *EmbeddingRepository) DeleteByPostID(postID uint) error {
+	return r.db.Where("post_id = ?", postID).Delete(&model.PostEmbedding{}).Error
+}
+
+// ListByModel 获取指定模型生成的全部分块向量
+func (r *EmbeddingRepository) ListByModel(modelName string) ([]model.PostEmbedding, error) {
+	var embeddings []model.PostEmbedding
+	err := r.db.Where("model = ?", modelName).Order("post_id, chunk_index").Find(&embeddings).Error
+	return embeddings, err
+}
+
+// ListUnindexedPostIDs 获取尚未使用指定模型生成向量的已发布公开文章ID
+func (r *EmbeddingRepository) ListUnindexedPostIDs(modelName string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.Post{}).
+		Where("status = ? AND visibility = ?", "published", model.PostVisibilityPublic).
+		Where("id NOT IN (?)", r.db.Model(&model.PostEmbedding{}).Select("post_id").Where("model = ?", modelName)).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ListByPostIDs 获取指定文章由指定模型生成的分块
+func (r *EmbeddingRepository) ListByPostIDs(modelName string, postIDs []uint) ([]model.PostEmbedding, error) {
+	var embeddings []model.PostEmbedding
+	if len(postIDs) == 0 {
+		return embeddings, nil
+	}
+	err := r.db.Where("model = ? AND post_id IN ?", modelName, postIDs).
+		Order("post_id, chunk_index").
+		Find(&embeddings).Error
+	return embeddings, err
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowTarget 关注列表中的一项：关注对象或关注者，以及关注的时间
+type FollowTarget struct {
+	ID         uint
+	Name       string
+	Avatar     string
+	FollowedAt time.Time
+}
+
+// FeedCursor 关注动态的分页位置，下一页从该文章之后开始
+type FeedCursor struct {
+	PublishedAt time.Time
+	ID          uint
+}
+
+// followTable 一种关注对象对应的表
+type followTable struct {
+	name     string // 关注表
+	follower string // 关注者列
+	target   string // 关注对象列
+	source   string // 关注对象所在的表
+	label    string // 关注对象的名称列
+	avatar   string // 关注对象的头像，没有头像时为空字符串
+}
+
+var followTables = map[string]followTable{
+	model.FollowTargetUser:     {"user_follows", "follower_id", "followee_id", "users", "username", "o.avatar"},
+	model.FollowTargetTag:      {"tag_follows", "user_id", "tag_id", "tags", "name", "''"},
+	model.FollowTargetCategory: {"category_follows", "user_id", "category_id", "categories", "name", "''"},
+}
+
+type FollowRepository struct {
+	DB *gorm.DB
+}
+
+func NewFollowRepository() *FollowRepository {
+	return &FollowRepository{
+		DB: database.GetDB(),
+	}
+}
+
+// Create 关注，已关注时不做修改，返回是否新增了关注
+func (r *FollowRepository) Create(targetType string, userID, targetID uint) (bool, error) {
+	table := followTables[targetType]
+	result := r.DB.Table(table.name).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(map[string]interface{}{table.follower: userID, table.target: targetID})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 取消关注，返回是否删除了关注
+func (r *FollowRepository) Delete(targetType string, userID, targetID uint) (bool, error) {
+	table := followTables[targetType]
+	result := r.DB.Exec("DELETE FROM "+table.name+" WHERE "+table.follower+" = ? AND "+table.target+" = ?", userID, targetID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Exists 检查用户是否关注了对象
+func (r *FollowRepository) Exists(targetType string, userID, targetID uint) (bool, error) {
+	table := followTables[targetType]
+	var count int64
+	err := r.DB.Table(table.name).
+		Where(table.follower+" = ? AND "+table.target+" = ?", userID, targetID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountFollowers 获取对象的关注者数量
+func (r *FollowRepository) CountFollowers(targetType string, targetID uint) (int64, error) {
+	table := followTables[targetType]
+	var count int64
+	err := r.DB.Table(table.name).Where(table.target+" = ?", targetID).Count(&count).Error
+	return count, err
+}
+
+// CountFollowing 获取用户关注的作者数量
+func (r *FollowRepository) CountFollowing(userID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.UserFollow{}).Where("follower_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// FollowerIDs 获取关注作者的全部用户ID
+func (r *FollowRepository) FollowerIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.DB.Model(&model.UserFollow{}).Where("followee_id = ?", userID).Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+// ListFollowing 按关注时间倒序获取用户关注的某类对象
+func (r *FollowRepository) ListFollowing(targetType string, userID uint, page, pageSize int) ([]FollowTarget, int64, error) {
+	table := followTables[targetType]
+	query := r.DB.Table(table.name+" f").
+		Joins("JOIN "+table.source+" o ON o.id = f."+table.target).
+		Where("f."+table.follower+" = ?", userID)
+	return r.listTargets(query, table, page, pageSize)
+}
+
+// ListFollowers 按关注时间倒序获取关注对象的用户
+func (r *FollowRepository) ListFollowers(targetType string, targetID uint, page, pageSize int) ([]FollowTarget, int64, error) {
+	table := followTables[targetType]
+	query := r.DB.Table(table.name+" f").
+		Joins("JOIN users o ON o.id = f."+table.follower).
+		Where("f."+table.target+" = ?", targetID)
+	return r.listTargets(query, followTables[model.FollowTargetUser], page, pageSize)
+}
+
+// listTargets 分页获取关注列表，o 为列表项所在的表
+func (r *FollowRepository) listTargets(query *gorm.DB, source followTable, page, pageSize int) ([]FollowTarget, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var targets []FollowTarget
+	err := query.Select("o.id, o." + source.label + " AS name, " + source.avatar + " AS avatar, f.created_at AS followed_at").
+		Order("f.created_at DESC, o.id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&targets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return targets, total, nil
+}
+
+// Feed 按发布时间倒序获取用户关注的作者、标签和分类下的已发布文章，不包括用户自己的文章。
+// cursor 不为空时从该位置之后开始，最多返回 limit 篇
+func (r *FollowRepository) Feed(userID uint, visibilities []string, cursor *FeedCursor, limit int) ([]model.Post, error) {
+	query := r.DB.Model(&model.Post{}).
+		Where("status = ? AND visibility IN ? AND user_id <> ?", "published", visibilities, userID).
+		Where(`(user_id IN (SELECT followee_id FROM user_follows WHERE follower_id = ?)
+			OR category_id IN (SELECT category_id FROM category_follows WHERE user_id = ?)
+			OR id IN (SELECT pt.post_id FROM post_tags pt JOIN tag_follows tf ON tf.tag_id = pt.tag_id WHERE tf.user_id = ?))`,
+			userID, userID, userID)
+	if cursor != nil {
+		query = query.Where("(published_at, id) < (?, ?)", cursor.PublishedAt, cursor.ID)
+	}
+
+	var posts []model.Post
+	err := query.Preload("Category").
+		Preload("Tags").
+		Preload("User").
+		Order("published_at DESC, id DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LikeRepository struct {
+	DB *gorm.DB
+}
+
+func NewLikeRepository() *LikeRepository {
+	return &LikeRepository{
+		DB: database.GetDB(),
+	}
+}
+
+// Create 点赞文章，已点赞时不做修改，返回是否新增了点赞
+func (r *LikeRepository) Create(like *model.PostLike) (bool, error) {
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(like)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 取消点赞，返回是否删除了点赞
+func (r *LikeRepository) Delete(postID, userID uint) (bool, error) {
+	result := r.DB.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&model.PostLike{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Exists 检查用户是否点赞了文章
+func (r *LikeRepository) Exists(postID, userID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&model.PostLike{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// CountSince 获取 since 之后点赞文章的人数，不包括 excludeUserID
+func (r *LikeRepository) CountSince(postID uint, since time.Time, excludeUserID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.PostLike{}).
+		Where("post_id = ? AND created_at >= ? AND user_id <> ?", postID, since, excludeUserID).
+		Count(&count).Error
+	return count, err
+}
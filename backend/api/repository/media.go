@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MediaDocument 扫描引用时使用的文章或草稿内容
+type MediaDocument struct {
+	ID      uint
+	Content string
+	Cover   string
+}
+
+// MediaURLColumn 扫描引用时使用的记录中保存文件URL的字段
+type MediaURLColumn struct {
+	ID  uint
+	URL string
+}
+
+// MediaListFilter 媒体文件列表的过滤条件
+type MediaListFilter struct {
+	UserID     uint   // 为 0 时不限用户
+	Search     string // 按文件名或URL模糊匹配
+	MimeType   string // 按MIME类型前缀匹配，如 image/
+	Orphaned   *bool  // 是否仅列出无引用的文件
+	ScanStatus string // 按扫描状态过滤
+}
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository() *MediaRepository {
+	return &MediaRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建媒体文件记录
+func (r *MediaRepository) Create(asset *model.MediaAsset) error {
+	return r.db.Create(asset).Error
+}
+
+// Update 更新媒体文件记录
+func (r *MediaRepository) Update(asset *model.MediaAsset) error {
+	return r.db.Save(asset).Error
+}
+
+// Delete 删除媒体文件记录及其引用
+func (r *MediaRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("asset_id = ?", id).Delete(&model.MediaReference{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.MediaAsset{}, id).Error
+	})
+}
+
+// FindByID 根据ID查找媒体文件，包含引用列表
+func (r *MediaRepository) FindByID(id uint) (*model.MediaAsset, error) {
+	var asset model.MediaAsset
+	err := r.db.Preload("References").First(&asset, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// List 分页获取媒体文件列表
+func (r *MediaRepository) List(page, pageSize int, filter MediaListFilter) ([]model.MediaAsset, int64, error) {
+	var assets []model.MediaAsset
+	var total int64
+
+	query := r.db.Model(&model.MediaAsset{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Search != "" {
+		like := "%" + filter.Search + "%"
+		query = query.Where("filename ILIKE ? OR url ILIKE ?", like, like)
+	}
+	if filter.MimeType != "" {
+		query = query.Where("mime_type LIKE ?", filter.MimeType+"%")
+	}
+	if filter.ScanStatus != "" {
+		query = query.Where("scan_status = ?", filter.ScanStatus)
+	}
+	if filter.Orphaned != nil {
+		if *filter.Orphaned {
+			query = query.Where("ref_count = 0")
+		} else {
+			query = query.Where("ref_count > 0")
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&assets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return assets, total, nil
+}
+
+// FindByChecksum 查找同一存储中内容相同且未被隔离的文件
+func (r *MediaRepository) FindByChecksum(storageType, checksum string) (*model.MediaAsset, error) {
+	var asset model.MediaAsset
+	err := r.db.Where("storage_type = ? AND checksum = ? AND quarantined_at IS NULL", storageType, checksum).
+		Order("id").
+		First(&asset).Error
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// CountSharedKey 统计除指定文件外共用同一存储对象的文件数
+func (r *MediaRepository) CountSharedKey(storageType, key string, excludeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.MediaAsset{}).
+		Where("storage_type = ? AND key = ? AND id <> ?", storageType, key, excludeID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListAll 获取全部媒体文件，用于引用扫描
+func (r *MediaRepository) ListAll() ([]model.MediaAsset, error) {
+	var assets []model.MediaAsset
+	err := r.db.Select("id", "storage_type", "key", "url", "thumbnail_url", "variants", "ref_count", "orphaned_at").
+		Where("quarantined_at IS NULL").Find(&assets).Error
+	return assets, err
+}
+
+// EachPost 分批遍历全部文章的内容和封面
+func (r *MediaRepository) EachPost(fn func(doc MediaDocument)) error {
+	return r.eachDocument(&model.Post{}, fn)
+}
+
+// EachDraft 分批遍历全部草稿的内容和封面
+func (r *MediaRepository) EachDraft(fn func(doc MediaDocument)) error {
+	return r.eachDocument(&model.Draft{}, fn)
+}
+
+// ListBoundAttachments 获取已关联文章或未删除草稿的附件
+func (r *MediaRepository) ListBoundAttachments() ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Select("id", "asset_id", "post_id", "draft_id").
+		Where("post_id IS NOT NULL OR draft_id IN (?)", r.db.Model(&model.Draft{}).Select("id")).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// ListURLColumn 获取指定表中字段不为空的记录ID和文件URL
+func (r *MediaRepository) ListURLColumn(table interface{}, column string) ([]MediaURLColumn, error) {
+	var rows []MediaURLColumn
+	err := r.db.Model(table).Select("id", column+" AS url").
+		Where(column + " <> ''").
+		Find(&rows).Error
+	return rows, err
+}
+
+// eachDocument 按ID游标分批遍历指定表的内容和封面
+func (r *MediaRepository) eachDocument(table interface{}, fn func(doc MediaDocument)) error {
+	var lastID uint
+	for {
+		var docs []MediaDocument
+		err := r.db.Model(table).Select("id", "content", "cover").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(200).
+			Find(&docs).Error
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		for _, doc := range docs {
+			fn(doc)
+		}
+		lastID = docs[len(docs)-1].ID
+	}
+}
+
+// ReplaceReferences 替换全部引用关系，并更新各文件的引用计数和无引用时间
+func (r *MediaRepository) ReplaceReferences(refs []model.MediaReference, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&model.MediaReference{}).Error; err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			if err := tx.CreateInBatches(&refs, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		// 根据引用表重新计算引用计数
+		if err := tx.Exec(`UPDATE media_assets SET ref_count = (
+			SELECT COUNT(*) FROM media_references WHERE media_references.asset_id = media_assets.id
+		)`).Error; err != nil {
+			return err
+		}
+
+		// 新失去引用的文件记录时间，重新被引用的文件清除时间
+		if err := tx.Model(&model.MediaAsset{}).
+			Where("ref_count = 0 AND orphaned_at IS NULL").
+			Update("orphaned_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.MediaAsset{}).
+			Where("ref_count > 0 AND orphaned_at IS NOT NULL").
+			Update("orphaned_at", nil).Error
+	})
+}
+
+// ListExpiredOrphans 获取在指定时间之前就已无引用的文件
+func (r *MediaRepository) ListExpiredOrphans(before time.Time) ([]model.MediaAsset, error) {
+	var assets []model.MediaAsset
+	err := r.db.Where("ref_count = 0 AND orphaned_at IS NOT NULL AND orphaned_at < ?", before).
+		Find(&assets).Error
+	return assets, err
+}
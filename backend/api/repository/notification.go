@@ -3,6 +3,7 @@ package repository
 import (
 	"notex/model"
 	"notex/pkg/database"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -22,6 +23,32 @@ func (r *NotificationRepository) Create(notification *model.Notification) error
 	return r.db.Create(notification).Error
 }
 
+// CreateBatch 批量创建通知
+func (r *NotificationRepository) CreateBatch(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, 500).Error
+}
+
+// Update 更新通知
+func (r *NotificationRepository) Update(notification *model.Notification) error {
+	return r.db.Save(notification).Error
+}
+
+// FindUnread 查找 since 之后创建的、尚未读过的同类型文章通知，用于聚合通知
+func (r *NotificationRepository) FindUnread(userID uint, notificationType string, postID uint, since time.Time) (*model.Notification, error) {
+	var notification model.Notification
+	err := r.db.Where("user_id = ? AND type = ? AND post_id = ? AND read = ? AND created_at >= ?",
+		userID, notificationType, postID, false, since).
+		Order("created_at DESC").
+		First(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
 // MarkAsRead 将通知标记为已读
 func (r *NotificationRepository) MarkAsRead(id uint) error {
 	return r.db.Model(&model.Notification{}).Where("id = ?", id).Update("read", true).Error
@@ -22,9 +22,9 @@ func (r *PostRepository) Create(post *model.Post) error {
 	return r.DB.Create(post).Error
 }
 
-// Update 更新文章
+// Update 更新文章，附件通过 AttachmentRepository 单独维护
 func (r *PostRepository) Update(post *model.Post) error {
-	return r.DB.Save(post).Error
+	return r.DB.Omit("Attachments").Save(post).Error
 }
 
 // Delete 删除文章
@@ -35,7 +35,9 @@ func (r *PostRepository) Delete(id uint) error {
 // FindByID 根据ID查找文章
 func (r *PostRepository) FindByID(id uint) (*model.Post, error) {
 	var post model.Post
-	err := r.DB.Preload("Category").Preload("Tags").Preload("User").First(&post, id).Error
+	err := r.DB.Preload("Category").Preload("Tags").Preload("User").
+		Preload("Attachments", orderAttachments).
+		First(&post, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -47,18 +49,50 @@ func (r *PostRepository) List(page, pageSize int, conditions map[string]interfac
 	var posts []model.Post
 	var total int64
 
-	query := r.DB.Model(&model.Post{})
+	query := applyPostConditions(r.DB.Model(&model.Post{}), conditions)
 
+	// 获取总数
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	err = query.Preload("Category").
+		Preload("Tags").
+		Preload("User").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&posts).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+// applyPostConditions 应用文章列表的查询条件
+func applyPostConditions(query *gorm.DB, conditions map[string]interface{}) *gorm.DB {
 	// 应用查询条件
 	for key, value := range conditions {
 		if value != nil {
 			switch key {
 			case "status":
 				query = query.Where("status = ?", value)
+			case "visibility":
+				query = query.Where("visibility IN ?", value)
+			case "listed_or_personal":
+				// 不属于工作区的文章全部列出，工作区文章只列出指定可见性的已发布文章
+				query = query.Where("workspace_id IS NULL OR (status = 'published' AND visibility IN ?)", value)
 			case "category_id":
 				query = query.Where("category_id = ?", value)
+			case "category_tree":
+				query = query.Where("category_id IN ("+categorySubtreeSQL+")", value)
 			case "user_id":
 				query = query.Where("user_id = ?", value)
+			case "workspace_id":
+				query = query.Where("workspace_id = ?", value)
 			case "search":
 				searchTerm := "%" + value.(string) + "%"
 				query = query.Where("LOWER(title) LIKE LOWER(?) OR LOWER(content) LIKE LOWER(?) OR LOWER(summary) LIKE LOWER(?)",
@@ -73,7 +107,7 @@ func (r *PostRepository) List(page, pageSize int, conditions map[string]interfac
 				case "most_viewed":
 					query = query.Order("views DESC, published_at DESC")
 				case "most_commented":
-					query = query.Joins("LEFT JOIN comments ON posts.id = comments.post_id").
+					query = query.Joins("LEFT JOIN comments ON posts.id = comments.post_id AND comments.status <> 'pending'").
 						Group("posts.id").
 						Order("COUNT(comments.id) DESC, published_at DESC")
 				}
@@ -81,25 +115,30 @@ func (r *PostRepository) List(page, pageSize int, conditions map[string]interfac
 		}
 	}
 
-	// 获取总数
-	err := query.Count(&total).Error
-	if err != nil {
-		return nil, 0, err
-	}
+	return query
+}
 
-	// 获取分页数据
-	err = query.Preload("Category").
-		Preload("Tags").
-		Preload("User").
-		Offset((page - 1) * pageSize).
-		Limit(pageSize).
+// FindByIDs 根据ID列表查找文章
+func (r *PostRepository) FindByIDs(ids []uint) ([]model.Post, error) {
+	var posts []model.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	err := r.DB.Preload("Category").Preload("Tags").Preload("User").
+		Where("id IN ?", ids).
 		Find(&posts).Error
+	return posts, err
+}
 
-	if err != nil {
-		return nil, 0, err
+// FilterIDs 返回给定ID中满足查询条件的文章ID
+func (r *PostRepository) FilterIDs(ids []uint, conditions map[string]interface{}) ([]uint, error) {
+	var result []uint
+	if len(ids) == 0 {
+		return result, nil
 	}
-
-	return posts, total, nil
+	query := applyPostConditions(r.DB.Model(&model.Post{}), conditions).Where("posts.id IN ?", ids)
+	err := query.Distinct().Pluck("posts.id", &result).Error
+	return result, err
 }
 
 // CountByUserID 获取用户的文章数量
@@ -116,27 +155,90 @@ func (r *PostRepository) GetTotalViewsByUserID(userID uint) (int64, error) {
 	return totalViews, err
 }
 
-// GetCommentCount 获取文章的评论数量
+// GetCommentCount 获取文章的评论数量，不包括等待邮箱确认的访客评论
 func (r *PostRepository) GetCommentCount(postID uint) (int64, error) {
 	var count int64
-	err := r.DB.Model(&model.Comment{}).Where("post_id = ?", postID).Count(&count).Error
+	err := r.DB.Model(&model.Comment{}).Where("post_id = ? AND status <> ?", postID, "pending").Count(&count).Error
+	return count, err
+}
+
+// GetLikeCount 获取文章的点赞数量
+func (r *PostRepository) GetLikeCount(postID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.PostLike{}).Where("post_id = ?", postID).Count(&count).Error
 	return count, err
 }
 
-// IncrementViews 增加文章浏览量
-func (r *PostRepository) IncrementViews(postID uint) error {
-	return r.DB.Model(&model.Post{}).Where("id = ?", postID).
-		UpdateColumn("views", gorm.Expr("views + ?", 1)).Error
+// PostCounts 文章的评论、点赞和收藏数
+type PostCounts struct {
+	Comments  int64
+	Likes     int64
+	Bookmarks int64
+}
+
+// postCount 按文章分组统计的数量
+type postCount struct {
+	PostID uint
+	Count  int64
+}
+
+// CountByPosts 批量获取多篇文章的评论、点赞和收藏数，每种数量一次分组查询，评论数不包括等待邮箱确认的访客评论
+func (r *PostRepository) CountByPosts(postIDs []uint) (map[uint]PostCounts, error) {
+	counts := make(map[uint]PostCounts, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	comments, err := r.countGrouped(r.DB.Model(&model.Comment{}).Where("status <> ?", "pending"), postIDs)
+	if err != nil {
+		return nil, err
+	}
+	likes, err := r.countGrouped(r.DB.Model(&model.PostLike{}), postIDs)
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := r.countGrouped(r.DB.Model(&model.Bookmark{}), postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range postIDs {
+		counts[id] = PostCounts{
+			Comments:  comments[id],
+			Likes:     likes[id],
+			Bookmarks: bookmarks[id],
+		}
+	}
+	return counts, nil
+}
+
+// countGrouped 统计 query 中属于各篇文章的记录数
+func (r *PostRepository) countGrouped(query *gorm.DB, postIDs []uint) (map[uint]int64, error) {
+	var rows []postCount
+	err := query.Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.PostID] = row.Count
+	}
+	return counts, nil
 }
 
-// GetArchives 获取文章归档列表
-func (r *PostRepository) GetArchives() ([]map[string]interface{}, error) {
+// GetArchives 获取指定可见性的已发布文章的归档列表
+func (r *PostRepository) GetArchives(visibilities []string) ([]map[string]interface{}, error) {
 	var archives []map[string]interface{}
 
 	// 使用 PostgreSQL 的 to_char 函数按年月分组统计文章数量
 	err := r.DB.Model(&model.Post{}).
 		Select("to_char(published_at, 'YYYY-MM') as date, COUNT(*) as count").
 		Where("status = ? AND published_at IS NOT NULL", "published").
+		Where("visibility IN ?", visibilities).
 		Group("to_char(published_at, 'YYYY-MM')").
 		Order("date DESC").
 		Find(&archives).Error
@@ -148,12 +250,13 @@ func (r *PostRepository) GetArchives() ([]map[string]interface{}, error) {
 	return archives, nil
 }
 
-// GetPostsByArchive 获取指定归档日期的文章列表
-func (r *PostRepository) GetPostsByArchive(yearMonth string) ([]model.Post, error) {
+// GetPostsByArchive 获取指定归档日期和可见性的文章列表
+func (r *PostRepository) GetPostsByArchive(yearMonth string, visibilities []string) ([]model.Post, error) {
 	var posts []model.Post
 
 	err := r.DB.Model(&model.Post{}).
 		Where("status = ? AND to_char(published_at, 'YYYY-MM') = ?", "published", yearMonth).
+		Where("visibility IN ?", visibilities).
 		Preload("Category").
 		Preload("Tags").
 		Preload("User").
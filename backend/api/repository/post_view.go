@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ViewDelta 一篇文章在一天内新增的浏览记录
+type ViewDelta struct {
+	PostID    uint
+	Date      time.Time
+	Views     int64
+	Visitors  []string         // 访客标识的哈希，已出现过的访客不重复计入独立访客
+	Referrers map[string]int64 // 站外来源域名及其浏览量
+}
+
+// ReferrerCount 来源域名的浏览量
+type ReferrerCount struct {
+	Domain string
+	Views  int64
+}
+
+type PostViewRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostViewRepository() *PostViewRepository {
+	return &PostViewRepository{
+		DB: database.GetDB(),
+	}
+}
+
+// Apply 在事务中写入一批浏览记录：累加每日汇总、来源域名和文章的总浏览量，
+// 独立访客数按当天首次出现的访客计算。已删除文章的记录直接丢弃
+func (r *PostViewRepository) Apply(deltas []ViewDelta) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定仍存在的文章，写入期间文章不会被删除
+		postIDs := make([]uint, 0, len(deltas))
+		for _, delta := range deltas {
+			postIDs = append(postIDs, delta.PostID)
+		}
+		var existing []uint
+		err := tx.Model(&model.Post{}).
+			Clauses(clause.Locking{Strength: "KEY SHARE"}).
+			Where("id IN ?", postIDs).
+			Pluck("id", &existing).Error
+		if err != nil {
+			return err
+		}
+		exists := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			exists[id] = true
+		}
+
+		for _, delta := range deltas {
+			if !exists[delta.PostID] {
+				continue
+			}
+			var unique int64
+			if len(delta.Visitors) > 0 {
+				visitors := make([]model.PostViewVisitor, 0, len(delta.Visitors))
+				for _, visitor := range delta.Visitors {
+					visitors = append(visitors, model.PostViewVisitor{PostID: delta.PostID, Date: delta.Date, Visitor: visitor})
+				}
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&visitors)
+				if result.Error != nil {
+					return result.Error
+				}
+				unique = result.RowsAffected
+			}
+
+			stat := model.PostViewStat{PostID: delta.PostID, Date: delta.Date, Views: delta.Views, UniqueVisitors: unique}
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "post_id"}, {Name: "date"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"views":           gorm.Expr("post_view_stats.views + EXCLUDED.views"),
+					"unique_visitors": gorm.Expr("post_view_stats.unique_visitors + EXCLUDED.unique_visitors"),
+				}),
+			}).Create(&stat).Error
+			if err != nil {
+				return err
+			}
+
+			for domain, views := range delta.Referrers {
+				referrer := model.PostViewReferrer{PostID: delta.PostID, Date: delta.Date, Domain: domain, Views: views}
+				err := tx.Clauses(clause.OnConflict{
+					Columns: []clause.Column{{Name: "post_id"}, {Name: "date"}, {Name: "domain"}},
+					DoUpdates: clause.Assignments(map[string]interface{}{
+						"views": gorm.Expr("post_view_referrers.views + EXCLUDED.views"),
+					}),
+				}).Create(&referrer).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			err = tx.Model(&model.Post{}).Where("id = ?", delta.PostID).
+				UpdateColumn("views", gorm.Expr("views + ?", delta.Views)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteVisitorsBefore 删除指定日期之前的访客记录，返回删除的数量
+func (r *PostViewRepository) DeleteVisitorsBefore(date time.Time) (int64, error) {
+	result := r.DB.Where("date < ?", date).Delete(&model.PostViewVisitor{})
+	return result.RowsAffected, result.Error
+}
+
+// DailyStats 按日期顺序获取文章在 [from, to] 内的每日浏览汇总，没有浏览的日期不返回
+func (r *PostViewRepository) DailyStats(postID uint, from, to time.Time) ([]model.PostViewStat, error) {
+	var stats []model.PostViewStat
+	err := r.DB.Where("post_id = ? AND date BETWEEN ? AND ?", postID, from, to).
+		Order("date").
+		Find(&stats).Error
+	return stats, err
+}
+
+// TopReferrers 获取文章在 [from, to] 内浏览量最多的来源域名
+func (r *PostViewRepository) TopReferrers(postID uint, from, to time.Time, limit int) ([]ReferrerCount, error) {
+	var referrers []ReferrerCount
+	err := r.DB.Model(&model.PostViewReferrer{}).
+		Select("domain, SUM(views) AS views").
+		Where("post_id = ? AND date BETWEEN ? AND ?", postID, from, to).
+		Group("domain").
+		Order("views DESC, domain").
+		Limit(limit).
+		Scan(&referrers).Error
+	return referrers, err
+}
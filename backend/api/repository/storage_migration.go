@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"database/sql"
+	"notex/model"
+	"notex/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// storedURLColumns 可能包含存储文件URL的表和字段，媒体文件表单独处理
+var storedURLColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"posts", []string{"content", "cover"}},
+	{"drafts", []string{"content", "cover"}},
+	{"users", []string{"avatar"}},
+	{"categories", []string{"cover"}},
+	{"ai_images", []string{"url", "thumbnail_url"}},
+}
+
+type StorageMigrationRepository struct {
+	db *gorm.DB
+}
+
+func NewStorageMigrationRepository() *StorageMigrationRepository {
+	return &StorageMigrationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建迁移任务
+func (r *StorageMigrationRepository) Create(migration *model.StorageMigration) error {
+	return r.db.Create(migration).Error
+}
+
+// Update 更新迁移任务
+func (r *StorageMigrationRepository) Update(migration *model.StorageMigration) error {
+	return r.db.Save(migration).Error
+}
+
+// FindByID 根据ID查找迁移任务
+func (r *StorageMigrationRepository) FindByID(id uint) (*model.StorageMigration, error) {
+	var migration model.StorageMigration
+	if err := r.db.First(&migration, id).Error; err != nil {
+		return nil, err
+	}
+	return &migration, nil
+}
+
+// List 分页获取迁移任务，按创建时间倒序
+func (r *StorageMigrationRepository) List(page, pageSize int) ([]model.StorageMigration, int64, error) {
+	var migrations []model.StorageMigration
+	var total int64
+
+	if err := r.db.Model(&model.StorageMigration{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&migrations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return migrations, total, nil
+}
+
+// CreateItems 批量创建迁移对象
+func (r *StorageMigrationRepository) CreateItems(items []model.StorageMigrationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&items, 500).Error
+}
+
+// UpdateItem 更新迁移对象
+func (r *StorageMigrationRepository) UpdateItem(item *model.StorageMigrationItem) error {
+	return r.db.Save(item).Error
+}
+
+// CountItems 统计迁移任务中指定状态的对象数，status 为空时统计全部
+func (r *StorageMigrationRepository) CountItems(migrationID uint, status string) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.StorageMigrationItem{}).Where("migration_id = ?", migrationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// NextItems 按ID顺序获取指定状态的下一批迁移对象
+func (r *StorageMigrationRepository) NextItems(migrationID, afterID uint, statuses []string, limit int) ([]model.StorageMigrationItem, error) {
+	var items []model.StorageMigrationItem
+	err := r.db.Where("migration_id = ? AND id > ? AND status IN ?", migrationID, afterID, statuses).
+		Order("id").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// ListItems 分页获取迁移对象，status 为空时不过滤
+func (r *StorageMigrationRepository) ListItems(migrationID uint, status string, page, pageSize int) ([]model.StorageMigrationItem, int64, error) {
+	var items []model.StorageMigrationItem
+	var total int64
+
+	query := r.db.Model(&model.StorageMigrationItem{}).Where("migration_id = ?", migrationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// EachStoredText 遍历数据库中全部可能包含存储文件URL的字段值
+func (r *StorageMigrationRepository) EachStoredText(fn func(text string)) error {
+	for _, source := range storedURLColumns {
+		err := eachRow(r.db, source.table, source.columns, func(id uint, values []string) error {
+			for _, value := range values {
+				fn(value)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return eachMediaAsset(r.db, "", func(asset *model.MediaAsset) error {
+		fn(asset.URL)
+		fn(asset.ThumbnailURL)
+		for _, variant := range asset.Variants {
+			fn(variant.URL)
+		}
+		return nil
+	})
+}
+
+// RewriteURLs 在一个事务中用 rewrite 改写全部URL字段，并把全部URL都已复制的源存储媒体文件记录改为目标存储。
+// apply 为 false 时只统计会被改写的记录数，不修改数据。
+func (r *StorageMigrationRepository) RewriteURLs(source, target string, rewrite func(text string) string, apply bool) (int, error) {
+	rewritten := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, src := range storedURLColumns {
+			table, columns := src.table, src.columns
+			err := eachRow(tx, table, columns, func(id uint, values []string) error {
+				updates := make(map[string]interface{})
+				for i, value := range values {
+					if replaced := rewrite(value); replaced != value {
+						updates[columns[i]] = replaced
+					}
+				}
+				if len(updates) == 0 {
+					return nil
+				}
+				rewritten++
+				if !apply {
+					return nil
+				}
+				return tx.Table(table).Where("id = ?", id).UpdateColumns(updates).Error
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return eachMediaAsset(tx, source, func(asset *model.MediaAsset) error {
+			// 跳过复制失败的对象时，只有原图、缩略图和全部变体都已复制的文件才改为目标存储
+			copied := func(url string) bool {
+				return url == "" || rewrite(url) != url
+			}
+			if !copied(asset.URL) || !copied(asset.ThumbnailURL) {
+				return nil
+			}
+			variants := make(model.MediaVariants, len(asset.Variants))
+			for i, variant := range asset.Variants {
+				if !copied(variant.URL) {
+					return nil
+				}
+				variant.URL = rewrite(variant.URL)
+				variants[i] = variant
+			}
+
+			rewritten++
+			if !apply {
+				return nil
+			}
+			return tx.Model(&model.MediaAsset{}).Where("id = ?", asset.ID).UpdateColumns(map[string]interface{}{
+				"storage_type":  target,
+				"url":           rewrite(asset.URL),
+				"thumbnail_url": rewrite(asset.ThumbnailURL),
+				"variants":      variants,
+			}).Error
+		})
+	})
+	return rewritten, err
+}
+
+// eachRow 按ID游标分批遍历表中指定的文本字段，先读取整批再回调，回调中可以在同一事务内更新数据
+func eachRow(db *gorm.DB, table string, columns []string, fn func(id uint, values []string) error) error {
+	type row struct {
+		id     uint
+		values []string
+	}
+
+	var lastID uint
+	for {
+		rows, err := db.Table(table).
+			Select(append([]string{"id"}, columns...)).
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(200).
+			Rows()
+		if err != nil {
+			return err
+		}
+
+		var batch []row
+		for rows.Next() {
+			var id uint
+			values := make([]sql.NullString, len(columns))
+			dest := []interface{}{&id}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return err
+			}
+
+			strs := make([]string, len(values))
+			for i, value := range values {
+				strs[i] = value.String
+			}
+			batch = append(batch, row{id: id, values: strs})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, r := range batch {
+			if err := fn(r.id, r.values); err != nil {
+				return err
+			}
+		}
+		lastID = batch[len(batch)-1].id
+	}
+}
+
+// eachMediaAsset 按ID游标分批遍历未隔离的媒体文件，storageType 不为空时只遍历该存储的文件
+func eachMediaAsset(db *gorm.DB, storageType string, fn func(asset *model.MediaAsset) error) error {
+	var lastID uint
+	for {
+		var assets []model.MediaAsset
+		query := db.Select("id", "url", "thumbnail_url", "variants").
+			Where("id > ? AND quarantined_at IS NULL", lastID)
+		if storageType != "" {
+			query = query.Where("storage_type = ?", storageType)
+		}
+		if err := query.Order("id").Limit(200).Find(&assets).Error; err != nil {
+			return err
+		}
+		if len(assets) == 0 {
+			return nil
+		}
+
+		for i := range assets {
+			if err := fn(&assets[i]); err != nil {
+				return err
+			}
+		}
+		lastID = assets[len(assets)-1].ID
+	}
+}
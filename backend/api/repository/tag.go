@@ -3,8 +3,11 @@ package repository
 import (
 	"notex/model"
 	"notex/pkg/database"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TagRepository struct {
@@ -24,7 +27,24 @@ func (r *TagRepository) Create(tag *model.Tag) error {
 
 // Update 更新标签
 func (r *TagRepository) Update(tag *model.Tag) error {
-	return r.db.Save(tag).Error
+	return r.db.Omit("Aliases").Save(tag).Error
+}
+
+// Rename 在事务中更新标签，删除与新名称相同的自身别名，alias 不为空时将原名称保留为别名
+func (r *TagRepository) Rename(tag *model.Tag, alias *model.TagAlias) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ? AND LOWER(name) = ?", tag.ID, strings.ToLower(tag.Name)).
+			Delete(&model.TagAlias{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Aliases").Save(tag).Error; err != nil {
+			return err
+		}
+		if alias != nil {
+			return tx.Create(alias).Error
+		}
+		return nil
+	})
 }
 
 // Delete 删除标签
@@ -35,7 +55,7 @@ func (r *TagRepository) Delete(id uint) error {
 // FindByID 根据ID查找标签
 func (r *TagRepository) FindByID(id uint) (*model.Tag, error) {
 	var tag model.Tag
-	err := r.db.First(&tag, id).Error
+	err := r.db.Preload("Aliases", orderAliases).First(&tag, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +69,11 @@ func (r *TagRepository) List(page, pageSize int, search string) ([]model.Tag, in
 
 	query := r.db.Model(&model.Tag{})
 
+	// 搜索同时匹配别名，输入别名时也能找到对应的标签
 	if search != "" {
-		query = query.Where("name LIKE ?", "%"+search+"%")
+		pattern := "%" + strings.ToLower(search) + "%"
+		aliases := r.db.Model(&model.TagAlias{}).Select("tag_id").Where("LOWER(name) LIKE ?", pattern)
+		query = query.Where("LOWER(name) LIKE ? OR id IN (?)", pattern, aliases)
 	}
 
 	err := query.Count(&total).Error
@@ -58,7 +81,8 @@ func (r *TagRepository) List(page, pageSize int, search string) ([]model.Tag, in
 		return nil, 0, err
 	}
 
-	err = query.Offset((page - 1) * pageSize).
+	err = query.Preload("Aliases", orderAliases).
+		Offset((page - 1) * pageSize).
 		Limit(pageSize).
 		Order("created_at DESC").
 		Find(&tags).Error
@@ -70,6 +94,13 @@ func (r *TagRepository) List(page, pageSize int, search string) ([]model.Tag, in
 	return tags, total, nil
 }
 
+// ListAll 获取全部标签
+func (r *TagRepository) ListAll() ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.db.Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
 // GetPostCount 获取标签下的文章数量
 func (r *TagRepository) GetPostCount(tagID uint) (int64, error) {
 	var count int64
@@ -92,3 +123,148 @@ func (r *TagRepository) ListByPostCount(limit int) ([]model.Tag, error) {
 		Find(&tags).Error
 	return tags, err
 }
+
+// Resolve 根据名称或别名查找标签，不区分大小写
+func (r *TagRepository) Resolve(name string) (*model.Tag, error) {
+	name = strings.ToLower(name)
+	aliases := r.db.Model(&model.TagAlias{}).Select("tag_id").Where("LOWER(name) = ?", name)
+
+	var tag model.Tag
+	err := r.db.Preload("Aliases", orderAliases).
+		Where("LOWER(name) = ? OR id IN (?)", name, aliases).
+		First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// NameTaken 检查名称是否已被其他标签用作名称或别名，不区分大小写。excludeID 标签自身的名称和别名不计入
+func (r *TagRepository) NameTaken(name string, excludeID uint) (bool, error) {
+	name = strings.ToLower(name)
+
+	var count int64
+	err := r.db.Model(&model.Tag{}).Where("LOWER(name) = ? AND id <> ?", name, excludeID).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = r.db.Model(&model.TagAlias{}).Where("LOWER(name) = ? AND tag_id <> ?", name, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsBySlug 检查是否已有使用该 slug 的其他标签
+func (r *TagRepository) ExistsBySlug(slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Tag{}).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// FindByIDs 根据ID列表查找标签
+func (r *TagRepository) FindByIDs(ids []uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id ASC").Find(&tags).Error
+	return tags, err
+}
+
+// CreateAlias 创建标签别名
+func (r *TagRepository) CreateAlias(alias *model.TagAlias) error {
+	return r.db.Create(alias).Error
+}
+
+// FindAlias 查找标签的别名
+func (r *TagRepository) FindAlias(tagID, aliasID uint) (*model.TagAlias, error) {
+	var alias model.TagAlias
+	err := r.db.Where("id = ? AND tag_id = ?", aliasID, tagID).First(&alias).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+// DeleteAlias 删除标签别名
+func (r *TagRepository) DeleteAlias(aliasID uint) error {
+	return r.db.Delete(&model.TagAlias{}, aliasID).Error
+}
+
+// Merge 在事务中将标签合并到目标标签：文章和草稿改用目标标签，别名转给目标标签，
+// 被合并标签的名称成为目标标签的别名，最后删除被合并的标签。返回改用目标标签的文章和草稿数量
+func (r *TagRepository) Merge(target *model.Tag, sources []model.Tag) (posts, drafts int64, err error) {
+	ids := make([]uint, len(sources))
+	aliases := make([]model.TagAlias, len(sources))
+	for i, source := range sources {
+		ids[i] = source.ID
+		aliases[i] = model.TagAlias{TagID: target.ID, Name: source.Name}
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// 已经同时使用目标标签的文章和草稿只删除原关联
+		result := tx.Exec(`INSERT INTO post_tags (post_id, tag_id)
+			SELECT DISTINCT post_id, ? FROM post_tags WHERE tag_id IN ?
+			ON CONFLICT DO NOTHING`, target.ID, ids)
+		if result.Error != nil {
+			return result.Error
+		}
+		posts = result.RowsAffected
+		if err := tx.Exec("DELETE FROM post_tags WHERE tag_id IN ?", ids).Error; err != nil {
+			return err
+		}
+
+		result = tx.Exec(`INSERT INTO draft_tags (draft_id, tag_id)
+			SELECT DISTINCT draft_id, ? FROM draft_tags WHERE tag_id IN ?
+			ON CONFLICT DO NOTHING`, target.ID, ids)
+		if result.Error != nil {
+			return result.Error
+		}
+		drafts = result.RowsAffected
+		if err := tx.Exec("DELETE FROM draft_tags WHERE tag_id IN ?", ids).Error; err != nil {
+			return err
+		}
+
+		// 关注会随标签级联删除，先转移到目标标签，已关注目标标签的用户保留原关注
+		if err := tx.Exec(`INSERT INTO tag_follows (user_id, tag_id, created_at)
+			SELECT user_id, ?, MIN(created_at) FROM tag_follows WHERE tag_id IN ? GROUP BY user_id
+			ON CONFLICT DO NOTHING`, target.ID, ids).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.TagAlias{}).Where("tag_id IN ?", ids).
+			Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Tag{}, ids).Error; err != nil {
+			return err
+		}
+		return tx.Create(&aliases).Error
+	})
+	return posts, drafts, err
+}
+
+// ListUnused 获取在 before 之前创建、没有文章和草稿使用、也没有用户关注的标签
+func (r *TagRepository) ListUnused(before time.Time) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := unusedTags(r.db.Model(&model.Tag{}), before).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+// DeleteUnused 删除在 before 之前创建、没有文章和草稿使用、也没有用户关注的标签，返回被删除的标签
+func (r *TagRepository) DeleteUnused(before time.Time) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := unusedTags(r.db.Clauses(clause.Returning{}), before).Delete(&tags).Error
+	return tags, err
+}
+
+// unusedTags 限定没有文章和草稿使用、也没有用户关注的标签
+func unusedTags(query *gorm.DB, before time.Time) *gorm.DB {
+	return query.Where("created_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM post_tags WHERE post_tags.tag_id = tags.id)").
+		Where("NOT EXISTS (SELECT 1 FROM draft_tags WHERE draft_tags.tag_id = tags.id)").
+		Where("NOT EXISTS (SELECT 1 FROM tag_follows WHERE tag_follows.tag_id = tags.id)")
+}
+
+// orderAliases 按名称排列标签的别名
+func orderAliases(db *gorm.DB) *gorm.DB {
+	return db.Order("name ASC")
+}
@@ -53,6 +53,16 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	return &user, nil
 }
 
+// FindByUsernames 查找用户名在列表中的用户，不存在的用户名被忽略
+func (r *UserRepository) FindByUsernames(usernames []string) ([]model.User, error) {
+	var users []model.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("username IN ?", usernames).Find(&users).Error
+	return users, err
+}
+
 // FindByEmail 通过邮箱查找用户
 func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 	var user model.User
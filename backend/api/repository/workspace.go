@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"notex/model"
+	"notex/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository() *WorkspaceRepository {
+	return &WorkspaceRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建工作区，并将创建者加入为所有者
+func (r *WorkspaceRepository) Create(workspace *model.Workspace) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.OwnerID,
+			Role:        model.WorkspaceRoleOwner,
+		}).Error
+	})
+}
+
+// Update 更新工作区
+func (r *WorkspaceRepository) Update(workspace *model.Workspace) error {
+	return r.db.Omit("Members").Save(workspace).Error
+}
+
+// Delete 删除工作区，成员和邀请随之删除，内容由外键转为全站分类或作者个人所有
+func (r *WorkspaceRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Workspace{}, id).Error
+}
+
+// FindByID 根据ID查找工作区
+func (r *WorkspaceRepository) FindByID(id uint) (*model.Workspace, error) {
+	var workspace model.Workspace
+	if err := r.db.First(&workspace, id).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// ExistsBySlug 检查标识是否已被使用
+func (r *WorkspaceRepository) ExistsBySlug(slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Workspace{}).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListByUser 获取用户加入的工作区及其角色
+func (r *WorkspaceRepository) ListByUser(userID uint) ([]model.WorkspaceMember, error) {
+	var members []model.WorkspaceMember
+	err := r.db.Preload("Workspace").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&members).Error
+	return members, err
+}
+
+// FindMember 查找用户在工作区中的成员记录
+func (r *WorkspaceRepository) FindMember(workspaceID, userID uint) (*model.WorkspaceMember, error) {
+	var member model.WorkspaceMember
+	err := r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembers 获取工作区的成员列表
+func (r *WorkspaceRepository) ListMembers(workspaceID uint) ([]model.WorkspaceMember, error) {
+	var members []model.WorkspaceMember
+	err := r.db.Preload("User").
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at").
+		Find(&members).Error
+	return members, err
+}
+
+// CountOwners 统计工作区的所有者数量
+func (r *WorkspaceRepository) CountOwners(workspaceID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// HasRole 检查用户是否在任一工作区中具有指定角色之一
+func (r *WorkspaceRepository) HasRole(userID uint, roles []string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.WorkspaceMember{}).
+		Where("user_id = ? AND role IN ?", userID, roles).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateMemberRole 更新成员角色
+func (r *WorkspaceRepository) UpdateMemberRole(memberID uint, role string) error {
+	return r.db.Model(&model.WorkspaceMember{}).Where("id = ?", memberID).Update("role", role).Error
+}
+
+// DeleteMember 移除成员
+func (r *WorkspaceRepository) DeleteMember(memberID uint) error {
+	return r.db.Delete(&model.WorkspaceMember{}, memberID).Error
+}
+
+// CreateInvitation 创建邀请，同一邮箱尚未接受的旧邀请会被替换
+func (r *WorkspaceRepository) CreateInvitation(invitation *model.WorkspaceInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ? AND LOWER(email) = LOWER(?) AND accepted_at IS NULL",
+			invitation.WorkspaceID, invitation.Email).
+			Delete(&model.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(invitation).Error
+	})
+}
+
+// FindInvitation 根据ID查找工作区的邀请
+func (r *WorkspaceRepository) FindInvitation(workspaceID, id uint) (*model.WorkspaceInvitation, error) {
+	var invitation model.WorkspaceInvitation
+	err := r.db.Where("workspace_id = ?", workspaceID).First(&invitation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindInvitationByToken 根据令牌哈希查找邀请
+func (r *WorkspaceRepository) FindInvitationByToken(tokenHash string) (*model.WorkspaceInvitation, error) {
+	var invitation model.WorkspaceInvitation
+	err := r.db.Preload("Workspace").Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListPendingInvitations 获取工作区尚未接受且未过期的邀请
+func (r *WorkspaceRepository) ListPendingInvitations(workspaceID uint) ([]model.WorkspaceInvitation, error) {
+	var invitations []model.WorkspaceInvitation
+	err := r.db.Preload("Inviter").
+		Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// DeleteInvitation 删除邀请
+func (r *WorkspaceRepository) DeleteInvitation(id uint) error {
+	return r.db.Delete(&model.WorkspaceInvitation{}, id).Error
+}
+
+// AcceptInvitation 在同一事务中将邀请标记为已接受并加入成员，已是成员时保留原有角色
+func (r *WorkspaceRepository) AcceptInvitation(invitation *model.WorkspaceInvitation, userID uint, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.WorkspaceInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var count int64
+		if err := tx.Model(&model.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", invitation.WorkspaceID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return tx.Create(&model.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}).Error
+	})
+}
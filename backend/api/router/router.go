@@ -1,13 +1,21 @@
 package router
 
 import (
+	"context"
 	"log"
 	"notex/api/handler"
 	"notex/api/repository"
 	"notex/api/service"
 	"notex/config"
 	"notex/middleware"
+	"notex/pkg/ai"
+	"notex/pkg/captcha"
+	"notex/pkg/embedding"
+	"notex/pkg/scanner"
 	"notex/pkg/storage"
+	"notex/pkg/tus"
+	"strings"
+	"sync"
 
 	"time"
 
@@ -15,16 +23,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRouter 设置路由
-func SetupRouter(cfg *config.Config) *gin.Engine {
+// SetupRouter 设置路由并启动后台任务，后台任务在 ctx 结束后完成收尾工作并退出，jobs 用于等待它们全部退出
+func SetupRouter(ctx context.Context, cfg *config.Config, jobs *sync.WaitGroup) *gin.Engine {
 	r := gin.Default()
 
+	// background 在后台运行定期任务
+	background := func(run func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			run(ctx)
+		}()
+	}
+
 	// CORS 配置
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Tus-Resumable", "Upload-Length", "Upload-Offset", "Upload-Metadata", "X-Post-Token"},
+		ExposeHeaders:    []string{"Content-Length", "Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size", "Upload-Offset", "Upload-Length", "Upload-Metadata", "Upload-Expires"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -38,15 +55,35 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		r.Static(cfg.Storage.Local.URLPrefix, cfg.Storage.Local.UploadDir)
 	}
 
+	// 创建向量嵌入服务，并在后台加载已有向量
+	embeddingProvider, err := embedding.NewProvider(&cfg.Embedding)
+	if err != nil {
+		log.Fatal("Failed to create embedding provider:", err)
+	}
+	embeddingService := service.NewEmbeddingService(embeddingProvider, &cfg.Embedding)
+	go func() {
+		if err := embeddingService.Warmup(ctx); err != nil {
+			log.Printf("Failed to warm up embedding index: %v", err)
+		}
+	}()
+
+	// 创建访客评论使用的人机验证，未单独配置签名密钥时使用JWT密钥
+	guestCaptcha, err := captcha.NewCaptcha(&cfg.Captcha, cfg.JWT.SecretKey)
+	if err != nil {
+		log.Fatal("Failed to create captcha:", err)
+	}
+
 	adminService := service.NewAdminService()
 	authService := service.NewAuthService()
 	categoryService := service.NewCategoryService()
-	commentService := service.NewCommentService()
-	postService := service.NewPostService()
-	tagService := service.NewTagService()
-	verificationService := service.NewVerificationService()
+	commentService := service.NewCommentService(&cfg.Comments, guestCaptcha)
+	tagService := service.NewTagService(&cfg.Tags)
+	verificationService := service.NewVerificationService(commentService)
 	notificationService := service.NewNotificationService()
 	aiService := service.NewAIService()
+	aiGateway := service.NewAIGateway(ai.NewHTTPClient(&cfg.AI), &cfg.AI)
+	aiAssistService := service.NewAIAssistService(aiGateway)
+	aiAskService := service.NewAIAskService(embeddingService, aiGateway, repository.NewPostRepository(), repository.NewAIRepository())
 
 	// 创建存储实例
 	storageInstance, err := storage.DefaultFactory.CreateStorage(&cfg.Storage)
@@ -54,8 +91,58 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		log.Fatal("Failed to create storage instance:", err)
 	}
 
+	aiImageService := service.NewAIImageService(storageInstance, &cfg.Storage, &cfg.AI)
+
+	// 创建图片处理服务，未单独配置签名密钥时使用JWT密钥
+	signingKey := cfg.Image.SigningKey
+	if signingKey == "" {
+		signingKey = cfg.JWT.SecretKey
+	}
+	imageService := service.NewImageService(storageInstance, &cfg.Image, cfg.Storage.ThumbnailSize, signingKey)
+
+	// 图片动态缩放（公开访问，依靠URL签名防止滥用）
+	imageHandler := handler.NewImageHandler(imageService)
+	r.GET(strings.TrimSuffix(service.ImagePathPrefix, "/")+"/*key", imageHandler.Serve)
+
+	// 创建上传文件安全扫描器
+	uploadScanner, err := scanner.NewScanner(&cfg.Scanner)
+	if err != nil {
+		log.Fatal("Failed to create upload scanner:", err)
+	}
+
+	// 创建媒体库服务，并定期扫描引用、清理无引用的文件
+	mediaService := service.NewMediaService(storageInstance, &cfg.Storage, imageService, uploadScanner, &cfg.Scanner, &cfg.Media)
+	background(mediaService.Run)
+
+	// 创建附件服务，附件文件保存在媒体库中
+	attachmentService := service.NewAttachmentService(mediaService, &cfg.Attachment)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService)
+	postService := service.NewPostService(embeddingService, attachmentService)
+
+	// 创建可续传上传服务，数据暂存在本地磁盘，并定期清理过期的上传
+	tusStore, err := tus.NewStore(cfg.Tus.Dir, cfg.Tus.Expiration)
+	if err != nil {
+		log.Fatal("Failed to create tus store:", err)
+	}
+	resumableUploadService := service.NewResumableUploadService(tusStore, mediaService, attachmentService, &cfg.Tus)
+	background(resumableUploadService.Run)
+
+	// 创建浏览统计服务，浏览记录在后台批量写入
+	viewService := service.NewViewService(&cfg.Views)
+	background(viewService.Run)
+
+	// 创建作者统计服务，并定期重新计算汇总表
+	analyticsService := service.NewAnalyticsService(&cfg.Analytics)
+	background(analyticsService.Run)
+
+	// 定期清理过期未确认的访客评论
+	background(commentService.Run)
+
+	// 定期检查未使用的标签，按配置记录或删除
+	background(tagService.Run)
+
 	// 创建上传处理器
-	uploadHandler := handler.NewUploadHandler(storageInstance, &cfg.Storage)
+	uploadHandler := handler.NewUploadHandler(storageInstance, &cfg.Storage, mediaService)
 
 	// API路由组
 	api := r.Group("/api")
@@ -74,29 +161,42 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		}
 
 		// 公开接口，无需认证
-		postHandler := handler.NewPostHandler(postService)
+		postHandler := handler.NewPostHandler(postService, viewService)
 		commentHandler := handler.NewCommentHandler(commentService)
 		categoryHandler := handler.NewCategoryHandler(categoryService)
 		tagHandler := handler.NewTagHandler(tagService)
 		authHandler := handler.NewAuthHandler(authService, postService)
-		aiHandler := handler.NewAIHandler(aiService)
+		aiHandler := handler.NewAIHandler(aiService, aiGateway, aiAssistService, aiAskService, aiImageService)
 
 		// 公开接口组
 		public := api.Group("/public")
 		{
-			// 文章相关的公开接口
+			// 文章相关的公开接口，登录用户可以访问自己的草稿和私密文章
 			public.GET("/posts", postHandler.ListPublicPosts)
-			public.GET("/posts/:id", postHandler.GetPost)
-			public.GET("/posts/:id/comments", commentHandler.ListComments)
+			public.GET("/posts/:id", middleware.OptionalAuth(), postHandler.GetPost)
+			public.POST("/posts/:id/unlock", middleware.LoginRateLimit(), postHandler.UnlockPost)
+			public.GET("/posts/:id/comments", middleware.OptionalAuth(), commentHandler.ListComments)
+			public.GET("/posts/:id/comments/tree", middleware.OptionalAuth(), commentHandler.GetCommentTree)
+			public.POST("/posts/:id/comments", middleware.LoginRateLimit(), commentHandler.CreateGuestComment)
+			public.GET("/posts/:id/related", postHandler.GetRelatedPosts)
 			public.GET("/posts/archives", postHandler.GetArchives)
 			public.GET("/posts/archives/:yearMonth", postHandler.GetPostsByArchive)
 
 			// 评论回复接口
-			public.GET("/comments/:id/replies", commentHandler.GetCommentReplies)
+			public.GET("/comments/:id/replies", middleware.OptionalAuth(), commentHandler.GetCommentReplies)
+			public.GET("/comments/:id/revisions", middleware.OptionalAuth(), commentHandler.ListRevisions)
+
+			// 访客评论的人机验证和邮箱确认接口
+			public.GET("/captcha", commentHandler.GetCaptcha)
+			public.GET("/comments/verify", commentHandler.VerifyGuestComment)
 
-			// 分类和标签的公开接口
-			public.GET("/categories", categoryHandler.ListCategories)
+			// 附件下载接口，登录用户可以下载自己未发布文章的附件
+			public.GET("/attachments/:id", middleware.OptionalAuth(), attachmentHandler.Download)
+
+			// 分类和标签的公开接口，登录的工作区成员可以列出工作区的分类
+			public.GET("/categories", middleware.OptionalAuth(), categoryHandler.ListCategories)
 			public.GET("/categories/top", categoryHandler.GetTopCategories)
+			public.GET("/categories/tree", middleware.OptionalAuth(), categoryHandler.ListCategoryTree)
 			public.GET("/tags", tagHandler.ListTags)
 			public.GET("/tags/top", tagHandler.GetTopTags)
 
@@ -128,6 +228,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			{
 				// 用户评论相关路由
 				users.GET("/comments", commentHandler.ListUserComments)
+				users.POST("/comments/claim", commentHandler.ClaimGuestComments)
 			}
 
 			// 文件上传相关路由
@@ -138,55 +239,106 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				upload.GET("/credentials", uploadHandler.GetCredentials)
 			}
 
+			// 可续传上传路由（tus 1.0）
+			tusHandler := handler.NewTusHandler(resumableUploadService)
+			tusHandler.RegisterRoutes(api, authenticated)
+
+			// 附件相关路由
+			attachments := authenticated.Group("/attachments")
+			{
+				attachments.POST("", middleware.RequireEditor(), attachmentHandler.Upload)
+				attachments.DELETE("/:id", attachmentHandler.Delete)
+			}
+
+			// 工作区路由，文章、草稿和分类的修改权限由各服务按全站角色和工作区角色检查
+			workspaceHandler := handler.NewWorkspaceHandler(service.NewWorkspaceService())
+			workspaceHandler.RegisterRoutes(authenticated)
+
+			// 点赞、收藏和阅读列表路由
+			engagementHandler := handler.NewEngagementHandler(
+				service.NewLikeService(notificationService),
+				service.NewBookmarkService(postService),
+			)
+			engagementHandler.RegisterRoutes(authenticated)
+
+			// 作者统计路由
+			analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
+			analyticsHandler.RegisterRoutes(authenticated)
+
+			// 关注和关注动态路由，用户的关注者列表是公开的
+			followHandler := handler.NewFollowHandler(service.NewFollowService(postService))
+			followHandler.RegisterRoutes(public, authenticated)
+
 			// 文章相关路由（需要认证）
 			posts := authenticated.Group("/posts")
 			{
 				posts.GET("/recent", postHandler.GetRecentPosts)
 				posts.GET("", postHandler.ListPosts)
-				posts.POST("", middleware.RequireEditor(), postHandler.CreatePost)
-				posts.PUT("/:id", middleware.RequireEditor(), postHandler.UpdatePost)
-				posts.DELETE("/:id", middleware.RequireEditor(), postHandler.DeletePost)
+				posts.POST("", postHandler.CreatePost)
+				posts.PUT("/:id", postHandler.UpdatePost)
+				posts.DELETE("/:id", postHandler.DeletePost)
+				posts.GET("/:id/stats", postHandler.GetPostStats)
 
 				// 评论相关路由（需要认证）
 				posts.POST("/:id/comments", commentHandler.CreateComment)
+				posts.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
 				posts.DELETE("/:id/comments/:commentId", commentHandler.DeleteComment)
+				posts.PUT("/:id/comments/:commentId/reactions/:emoji", commentHandler.React)
+				posts.DELETE("/:id/comments/:commentId/reactions/:emoji", commentHandler.Unreact)
+				posts.PUT("/:id/comments/:commentId/hide", commentHandler.HideComment)
+				posts.DELETE("/:id/comments/:commentId/hide", commentHandler.UnhideComment)
 			}
 
 			// 分类相关路由（需要认证）
 			categories := authenticated.Group("/categories")
 			{
 				categories.GET("/:id", categoryHandler.GetCategory)
-				categories.POST("", middleware.RequireEditor(), categoryHandler.CreateCategory)
-				categories.PUT("/:id", middleware.RequireEditor(), categoryHandler.UpdateCategory)
-				categories.DELETE("/:id", middleware.RequireEditor(), categoryHandler.DeleteCategory)
+				categories.POST("", categoryHandler.CreateCategory)
+				categories.PUT("/:id", categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", categoryHandler.DeleteCategory)
+				categories.GET("/:id/editors", categoryHandler.ListEditors)
+				categories.PUT("/:id/editors", categoryHandler.ReplaceEditors)
 			}
 
 			// 标签相关路由（需要认证）
 			tags := authenticated.Group("/tags")
 			{
 				tags.GET("/:id", tagHandler.GetTag)
-				tags.POST("", middleware.RequireEditor(), tagHandler.CreateTag)
-				tags.PUT("/:id", middleware.RequireEditor(), tagHandler.UpdateTag)
-				tags.DELETE("/:id", middleware.RequireEditor(), tagHandler.DeleteTag)
+				tags.POST("", tagHandler.CreateTag)
+				tags.PUT("/:id", tagHandler.UpdateTag)
+				tags.DELETE("/:id", tagHandler.DeleteTag)
+				tags.POST("/:id/merge", tagHandler.MergeTags)
+				tags.POST("/:id/aliases", tagHandler.AddAlias)
+				tags.DELETE("/:id/aliases/:alias_id", tagHandler.DeleteAlias)
+				tags.GET("/unused", tagHandler.ListUnusedTags)
+				tags.DELETE("/unused", tagHandler.DeleteUnusedTags)
 			}
 
 			// 草稿相关路由（需要认证）
-			draftService := service.NewDraftService(repository.NewDraftRepository())
+			draftService := service.NewDraftService(repository.NewDraftRepository(), embeddingService, attachmentService)
 			draftHandler := handler.NewDraftHandler(draftService)
 			drafts := authenticated.Group("/drafts")
 			{
 				drafts.GET("", draftHandler.ListDrafts)
 				drafts.GET("/:id", draftHandler.GetDraft)
-				drafts.POST("", middleware.RequireEditor(), draftHandler.CreateDraft)
-				drafts.PUT("/:id", middleware.RequireEditor(), draftHandler.UpdateDraft)
-				drafts.DELETE("/:id", middleware.RequireEditor(), draftHandler.DeleteDraft)
-				drafts.POST("/:id/publish", middleware.RequireEditor(), draftHandler.PublishDraft)
+				drafts.POST("", draftHandler.CreateDraft)
+				drafts.PUT("/:id", draftHandler.UpdateDraft)
+				drafts.DELETE("/:id", draftHandler.DeleteDraft)
+				drafts.POST("/:id/publish", draftHandler.PublishDraft)
 			}
 
+			// 媒体库路由
+			mediaHandler := handler.NewMediaHandler(mediaService)
+			mediaHandler.RegisterRoutes(authenticated)
+
 			// 管理员路由
 			adminHandler := handler.NewAdminHandler(adminService)
 			adminHandler.RegisterRoutes(authenticated)
 
+			// 存储迁移路由
+			storageMigrationHandler := handler.NewStorageMigrationHandler(service.NewStorageMigrationService(&cfg.Storage))
+			storageMigrationHandler.RegisterRoutes(authenticated)
+
 			// 通知相关路由
 			notificationHandler := handler.NewNotificationHandler(notificationService)
 			authenticated.GET("/notifications", notificationHandler.ListNotifications)
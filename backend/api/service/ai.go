@@ -247,6 +247,7 @@ func (s *AIServiceImpl) SaveDefaultSetting(userID uint, req *dto.AIDefaultSettin
 		setting.ID = currentSetting.ID
 		setting.DefaultModel = currentSetting.DefaultModel
 		setting.DefaultImageModel = currentSetting.DefaultImageModel
+		setting.FallbackModels = currentSetting.FallbackModels
 	}
 
 	// 验证并更新默认文本模型
@@ -267,6 +268,16 @@ func (s *AIServiceImpl) SaveDefaultSetting(userID uint, req *dto.AIDefaultSettin
 		setting.DefaultImageModel = req.DefaultImageModel
 	}
 
+	// 验证并更新备用文本模型
+	if req.FallbackModels != nil {
+		for _, modelID := range req.FallbackModels {
+			if _, err := s.repo.GetModelByID(modelID); err != nil {
+				return nil, errors.New("指定的备用文本模型不存在: " + modelID)
+			}
+		}
+		setting.FallbackModels = req.FallbackModels
+	}
+
 	// 保存设置
 	err = s.repo.SaveDefaultSetting(setting)
 	if err != nil {
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/ai"
+	"strings"
+)
+
+var ErrAIAskNoRelevantPosts = errors.New("未找到与问题相关的文章")
+
+const (
+	defaultAskChunkLimit = 5
+	maxAskChunkLimit     = 10
+)
+
+// AIAskService 定义知识库问答的业务逻辑接口
+type AIAskService interface {
+	// Stream 检索相关文章并返回提供商的流式响应和引用，调用方负责关闭响应体
+	Stream(ctx context.Context, userID uint, req *dto.AIAskRequest) (*http.Response, []dto.AICitation, error)
+	// Ask 检索相关文章并返回完整回答
+	Ask(ctx context.Context, userID uint, req *dto.AIAskRequest) (*dto.AIAskResponse, error)
+}
+
+// ChunkRetriever 检索与问题相关的文章分块，由 EmbeddingService 实现
+type ChunkRetriever interface {
+	Retrieve(ctx context.Context, query string, limit int) ([]RetrievedChunk, error)
+}
+
+// AIChatter 发送聊天请求并返回最终使用的请求，由 AIGateway 实现
+type AIChatter interface {
+	Chat(ctx context.Context, userID uint, primary *ai.ChatRequest) (*http.Response, *ai.ChatRequest, error)
+}
+
+// PostFinder 按ID批量加载文章，由 PostRepository 实现
+type PostFinder interface {
+	FindByIDs(ids []uint) ([]model.Post, error)
+}
+
+// AIAskServiceImpl 实现AIAskService接口
+type AIAskServiceImpl struct {
+	aiRepo    repository.AIRepository
+	posts     PostFinder
+	retriever ChunkRetriever
+	chat      AIChatter
+}
+
+// NewAIAskService 创建一个新的AIAskService实例，aiRepo 提供提示词模板和用户的模型设置，
+// 各依赖都可以替换为测试用的实现
+func NewAIAskService(retriever ChunkRetriever, chat AIChatter, posts PostFinder, aiRepo repository.AIRepository) AIAskService {
+	return &AIAskServiceImpl{
+		aiRepo:    aiRepo,
+		posts:     posts,
+		retriever: retriever,
+		chat:      chat,
+	}
+}
+
+// Stream 检索相关文章并返回流式回答
+func (s *AIAskServiceImpl) Stream(ctx context.Context, userID uint, req *dto.AIAskRequest) (*http.Response, []dto.AICitation, error) {
+	chat, citations, err := s.prepare(ctx, userID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	chat.Stream = true
+
+	resp, _, err := s.chat.Chat(ctx, userID, chat)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, nil, fmt.Errorf("AI请求失败: %s", string(body))
+	}
+
+	return resp, citations, nil
+}
+
+// Ask 检索相关文章并返回完整回答
+func (s *AIAskServiceImpl) Ask(ctx context.Context, userID uint, req *dto.AIAskRequest) (*dto.AIAskResponse, error) {
+	chat, citations, err := s.prepare(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, used, err := s.chat.Chat(ctx, userID, chat)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI请求失败: %s", string(body))
+	}
+
+	answer, err := ai.ExtractText(used.Provider, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AIAskResponse{
+		Answer:    strings.TrimSpace(answer),
+		Citations: citations,
+		Model:     used.Model,
+	}, nil
+}
+
+// prepare 检索相关分块并构造带引用编号的提示词
+func (s *AIAskServiceImpl) prepare(ctx context.Context, userID uint, req *dto.AIAskRequest) (*ai.ChatRequest, []dto.AICitation, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAskChunkLimit
+	}
+	if limit > maxAskChunkLimit {
+		limit = maxAskChunkLimit
+	}
+
+	chunks, err := s.retriever.Retrieve(ctx, req.Question, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 加载文章并再次确认可见性
+	postIDs := make([]uint, 0, len(chunks))
+	for _, chunk := range chunks {
+		postIDs = append(postIDs, chunk.PostID)
+	}
+	posts, err := s.posts.FindByIDs(postIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	visible := make(map[uint]*model.Post, len(posts))
+	for i := range posts {
+		if posts[i].IsPublic() {
+			visible[posts[i].ID] = &posts[i]
+		}
+	}
+
+	// 同一文章的分块共用一个引用编号
+	citations := make([]dto.AICitation, 0)
+	citationIndex := make(map[uint]int)
+	var sources strings.Builder
+	for _, chunk := range chunks {
+		post, ok := visible[chunk.PostID]
+		if !ok {
+			continue
+		}
+		index, ok := citationIndex[post.ID]
+		if !ok {
+			index = len(citations) + 1
+			citationIndex[post.ID] = index
+			citations = append(citations, dto.AICitation{
+				Index:  index,
+				PostID: post.ID,
+				Slug:   post.Slug,
+				Title:  post.Title,
+				Score:  chunk.Score,
+			})
+		}
+		fmt.Fprintf(&sources, "[%d] %s\n%s\n\n", index, post.Title, chunk.Content)
+	}
+	if len(citations) == 0 {
+		return nil, nil, ErrAIAskNoRelevantPosts
+	}
+
+	tmpl, err := s.aiRepo.GetPromptTemplate(model.AIActionAsk, 0)
+	if err != nil {
+		return nil, nil, ErrAIPromptTemplateNotFound
+	}
+
+	chat, err := buildTemplateChat(s.aiRepo, userID, tmpl, promptData{
+		Question: req.Question,
+		Sources:  strings.TrimSpace(sources.String()),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return chat, citations, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/ai"
+	"strings"
+	"testing"
+)
+
+// fakeRetriever 返回固定的分块
+type fakeRetriever struct {
+	chunks []RetrievedChunk
+	limit  int
+}
+
+func (f *fakeRetriever) Retrieve(ctx context.Context, query string, limit int) ([]RetrievedChunk, error) {
+	f.limit = limit
+	return f.chunks, nil
+}
+
+// fakeChatter 记录请求并返回固定的响应
+type fakeChatter struct {
+	status int
+	body   string
+	req    *ai.ChatRequest
+}
+
+func (f *fakeChatter) Chat(ctx context.Context, userID uint, primary *ai.ChatRequest) (*http.Response, *ai.ChatRequest, error) {
+	f.req = primary
+	resp := &http.Response{
+		StatusCode: f.status,
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+	}
+	return resp, primary, nil
+}
+
+// fakePosts 按ID返回预设的文章
+type fakePosts map[uint]model.Post
+
+func (f fakePosts) FindByIDs(ids []uint) ([]model.Post, error) {
+	posts := make([]model.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := f[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+// fakeAIRepository 只实现问答用到的模板和模型设置查询
+type fakeAIRepository struct {
+	repository.AIRepository
+}
+
+func (f *fakeAIRepository) GetPromptTemplate(action string, version int) (*model.AIPromptTemplate, error) {
+	return &model.AIPromptTemplate{
+		Action:       action,
+		SystemPrompt: "Answer with citations.",
+		UserPrompt:   "Q: {{.Question}}\n{{.Sources}}",
+	}, nil
+}
+
+func (f *fakeAIRepository) GetDefaultSetting(userID uint) (*model.AIDefaultSetting, error) {
+	return &model.AIDefaultSetting{UserID: userID, DefaultModel: "gpt"}, nil
+}
+
+func (f *fakeAIRepository) GetModelByID(modelID string) (*model.AIModel, error) {
+	return &model.AIModel{Provider: "openai", ModelID: modelID, Type: "text"}, nil
+}
+
+func (f *fakeAIRepository) GetUserSettingByProvider(userID uint, providerID string) (*model.AIUserSetting, error) {
+	return &model.AIUserSetting{UserID: userID, ProviderID: providerID, APIKey: "key"}, nil
+}
+
+func askPosts() fakePosts {
+	post := func(id uint, title, status, visibility string) model.Post {
+		p := model.Post{Title: title, Slug: strings.ToLower(title), Status: status, Visibility: visibility}
+		p.ID = id
+		return p
+	}
+	return fakePosts{
+		1: post(1, "Deploy", "published", model.PostVisibilityPublic),
+		2: post(2, "Database", "published", model.PostVisibilityPublic),
+		3: post(3, "Secret", "published", model.PostVisibilityPrivate),
+		4: post(4, "Draft", "draft", model.PostVisibilityPublic),
+	}
+}
+
+func TestAIAskPrepare(t *testing.T) {
+	retriever := &fakeRetriever{chunks: []RetrievedChunk{
+		{PostID: 2, Content: "use postgres", Score: 0.9},
+		{PostID: 3, Content: "private notes", Score: 0.85},
+		{PostID: 1, Content: "run make deploy", Score: 0.8},
+		{PostID: 2, Content: "run migrations", Score: 0.7},
+		{PostID: 4, Content: "unpublished", Score: 0.6},
+		{PostID: 5, Content: "deleted post", Score: 0.5},
+	}}
+	svc := NewAIAskService(retriever, &fakeChatter{}, askPosts(), &fakeAIRepository{}).(*AIAskServiceImpl)
+
+	chat, citations, err := svc.prepare(context.Background(), 7, &dto.AIAskRequest{Question: "how to deploy?", Limit: 50})
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if retriever.limit != maxAskChunkLimit {
+		t.Fatalf("limit = %d, want %d", retriever.limit, maxAskChunkLimit)
+	}
+
+	want := []dto.AICitation{
+		{Index: 1, PostID: 2, Slug: "database", Title: "Database", Score: 0.9},
+		{Index: 2, PostID: 1, Slug: "deploy", Title: "Deploy", Score: 0.8},
+	}
+	if len(citations) != len(want) {
+		t.Fatalf("citations = %+v, want %+v", citations, want)
+	}
+	for i := range want {
+		if citations[i] != want[i] {
+			t.Fatalf("citations[%d] = %+v, want %+v", i, citations[i], want[i])
+		}
+	}
+
+	if chat.Provider != "openai" || chat.Model != "gpt" || chat.APIKey != "key" {
+		t.Fatalf("chat = %+v", chat)
+	}
+	prompt := chat.Messages[len(chat.Messages)-1]["content"]
+	for _, part := range []string{"Q: how to deploy?", "[1] Database\nuse postgres", "[2] Deploy\nrun make deploy", "[1] Database\nrun migrations"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("prompt missing %q:\n%s", part, prompt)
+		}
+	}
+	for _, hidden := range []string{"private notes", "unpublished", "deleted post"} {
+		if strings.Contains(prompt, hidden) {
+			t.Errorf("prompt contains chunk of non-public post %q", hidden)
+		}
+	}
+}
+
+func TestAIAskNoRelevantPosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []RetrievedChunk
+	}{
+		{name: "no chunks"},
+		{name: "only non-public posts", chunks: []RetrievedChunk{{PostID: 3, Content: "private"}, {PostID: 4, Content: "draft"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatter := &fakeChatter{status: http.StatusOK}
+			svc := NewAIAskService(&fakeRetriever{chunks: tt.chunks}, chatter, askPosts(), &fakeAIRepository{})
+			if _, err := svc.Ask(context.Background(), 7, &dto.AIAskRequest{Question: "q"}); !errors.Is(err, ErrAIAskNoRelevantPosts) {
+				t.Fatalf("Ask error = %v, want %v", err, ErrAIAskNoRelevantPosts)
+			}
+			if chatter.req != nil {
+				t.Fatal("the model was called without sources")
+			}
+		})
+	}
+}
+
+func TestAIAsk(t *testing.T) {
+	retriever := &fakeRetriever{chunks: []RetrievedChunk{{PostID: 1, Content: "run make deploy", Score: 0.8}}}
+
+	t.Run("answer", func(t *testing.T) {
+		chatter := &fakeChatter{status: http.StatusOK, body: `{"choices":[{"message":{"content":"  Run make deploy [1].\n"}}]}`}
+		svc := NewAIAskService(retriever, chatter, askPosts(), &fakeAIRepository{})
+
+		resp, err := svc.Ask(context.Background(), 7, &dto.AIAskRequest{Question: "how to deploy?"})
+		if err != nil {
+			t.Fatalf("Ask: %v", err)
+		}
+		if resp.Answer != "Run make deploy [1]." || resp.Model != "gpt" {
+			t.Fatalf("response = %+v", resp)
+		}
+		if len(resp.Citations) != 1 || resp.Citations[0].PostID != 1 {
+			t.Fatalf("citations = %+v", resp.Citations)
+		}
+		if chatter.req.Stream {
+			t.Fatal("Ask sent a streaming request")
+		}
+		if retriever.limit != defaultAskChunkLimit {
+			t.Fatalf("limit = %d, want %d", retriever.limit, defaultAskChunkLimit)
+		}
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		chatter := &fakeChatter{status: http.StatusTooManyRequests, body: "rate limited"}
+		svc := NewAIAskService(retriever, chatter, askPosts(), &fakeAIRepository{})
+		if _, err := svc.Ask(context.Background(), 7, &dto.AIAskRequest{Question: "q"}); err == nil || !strings.Contains(err.Error(), "rate limited") {
+			t.Fatalf("Ask error = %v", err)
+		}
+	})
+
+	t.Run("response without text", func(t *testing.T) {
+		chatter := &fakeChatter{status: http.StatusOK, body: `{"choices":[]}`}
+		svc := NewAIAskService(retriever, chatter, askPosts(), &fakeAIRepository{})
+		if _, err := svc.Ask(context.Background(), 7, &dto.AIAskRequest{Question: "q"}); err == nil {
+			t.Fatal("Ask succeeded without answer text")
+		}
+	})
+}
+
+func TestAIAskStream(t *testing.T) {
+	retriever := &fakeRetriever{chunks: []RetrievedChunk{{PostID: 1, Content: "run make deploy", Score: 0.8}}}
+
+	chatter := &fakeChatter{status: http.StatusOK, body: "data: {}\n\n"}
+	svc := NewAIAskService(retriever, chatter, askPosts(), &fakeAIRepository{})
+	resp, citations, err := svc.Stream(context.Background(), 7, &dto.AIAskRequest{Question: "q"})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if !chatter.req.Stream {
+		t.Fatal("Stream sent a non-streaming request")
+	}
+	if len(citations) != 1 || citations[0].Index != 1 {
+		t.Fatalf("citations = %+v", citations)
+	}
+
+	chatter = &fakeChatter{status: http.StatusBadGateway, body: "upstream down"}
+	svc = NewAIAskService(retriever, chatter, askPosts(), &fakeAIRepository{})
+	if _, _, err := svc.Stream(context.Background(), 7, &dto.AIAskRequest{Question: "q"}); err == nil || !strings.Contains(err.Error(), "upstream down") {
+		t.Fatalf("Stream error = %v", err)
+	}
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/ai"
+	"notex/pkg/policy"
+	"strings"
+	"text/template"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAIDefaultModelNotSet     = errors.New("未设置默认文本模型")
+	ErrAIProviderNotConfigured  = errors.New("未找到用户AI设置，请先配置API密钥")
+	ErrAIPromptTemplateNotFound = errors.New("未找到对应的提示词模板")
+	ErrAIAssistContentEmpty     = errors.New("没有可供处理的内容")
+)
+
+// AIAssistService 定义写作助手相关的业务逻辑接口
+type AIAssistService interface {
+	// Stream 执行写作动作并返回提供商的流式响应，调用方负责关闭响应体
+	Stream(ctx context.Context, userID uint, req *dto.AIAssistRequest) (*http.Response, error)
+	// Run 执行写作动作并返回完整结果
+	Run(ctx context.Context, userID uint, req *dto.AIAssistRequest) (*dto.AIAssistResponse, error)
+}
+
+// AIAssistServiceImpl 实现AIAssistService接口
+type AIAssistServiceImpl struct {
+	aiRepo    repository.AIRepository
+	draftRepo *repository.DraftRepository
+	postRepo  *repository.PostRepository
+	tagRepo   *repository.TagRepository
+	gateway   *AIGateway
+}
+
+// NewAIAssistService 创建一个新的AIAssistService实例
+func NewAIAssistService(gateway *AIGateway) AIAssistService {
+	return &AIAssistServiceImpl{
+		aiRepo:    repository.NewAIRepository(),
+		draftRepo: repository.NewDraftRepository(),
+		postRepo:  repository.NewPostRepository(),
+		tagRepo:   repository.NewTagRepository(),
+		gateway:   gateway,
+	}
+}
+
+// promptData 提示词模板可用的变量
+type promptData struct {
+	Title    string
+	Summary  string
+	Content  string
+	Language string
+	Tags     string
+	Question string
+	Sources  string
+}
+
+// assistCall 一次写作动作的上下文
+type assistCall struct {
+	draft    *model.Draft
+	post     *model.Post
+	template *model.AIPromptTemplate
+	tags     []model.Tag
+	chat     *ai.ChatRequest
+}
+
+// Stream 执行写作动作并返回提供商的流式响应
+func (s *AIAssistServiceImpl) Stream(ctx context.Context, userID uint, req *dto.AIAssistRequest) (*http.Response, error) {
+	call, err := s.prepare(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	call.chat.Stream = true
+
+	resp, _, err := s.gateway.Chat(ctx, userID, call.chat)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("AI请求失败: %s", string(body))
+	}
+
+	return resp, nil
+}
+
+// Run 执行写作动作并返回完整结果
+func (s *AIAssistServiceImpl) Run(ctx context.Context, userID uint, req *dto.AIAssistRequest) (*dto.AIAssistResponse, error) {
+	call, err := s.prepare(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, used, err := s.gateway.Chat(ctx, userID, call.chat)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI请求失败: %s", string(body))
+	}
+
+	text, err := ai.ExtractText(used.Provider, body)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	result := &dto.AIAssistResponse{
+		Action:          req.Action,
+		Result:          text,
+		Model:           used.Model,
+		TemplateVersion: call.template.Version,
+	}
+
+	if req.Action == model.AIActionSuggestTags {
+		result.Tags = matchSuggestedTags(text, call.tags)
+	}
+
+	if req.Apply {
+		applied, err := s.apply(call, req.Action, text)
+		if err != nil {
+			return nil, err
+		}
+		result.Applied = applied
+	}
+
+	return result, nil
+}
+
+// prepare 加载内容、模型和提示词模板，并构造聊天请求
+func (s *AIAssistServiceImpl) prepare(userID uint, req *dto.AIAssistRequest) (*assistCall, error) {
+	call := &assistCall{}
+	data := promptData{
+		Content:  req.Content,
+		Language: req.Language,
+	}
+	if data.Language == "" {
+		data.Language = "English"
+	}
+
+	// 加载草稿或文章内容
+	if req.DraftID != 0 {
+		draft, err := s.draftRepo.FindByID(req.DraftID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrDraftNotFound
+			}
+			return nil, err
+		}
+		if err := policy.Authorize(newSubject(userID, ""), policy.AIAssistApply, draftResource(draft)); err != nil {
+			return nil, ErrUnauthorized
+		}
+		call.draft = draft
+		data.Title = draft.Title
+		data.Summary = draft.Summary
+		if data.Content == "" {
+			data.Content = draft.Content
+		}
+	} else if req.PostID != 0 {
+		post, err := s.postRepo.FindByID(req.PostID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrPostNotFound
+			}
+			return nil, err
+		}
+		if err := policy.Authorize(newSubject(userID, ""), policy.AIAssistApply, postResource(post)); err != nil {
+			return nil, ErrUnauthorized
+		}
+		call.post = post
+		data.Title = post.Title
+		data.Summary = post.Summary
+		if data.Content == "" {
+			data.Content = post.Content
+		}
+	}
+	if strings.TrimSpace(data.Content) == "" {
+		return nil, ErrAIAssistContentEmpty
+	}
+
+	// 建议标签时只能从已有标签中选择
+	if req.Action == model.AIActionSuggestTags {
+		tags, err := s.tagRepo.ListAll()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(tags))
+		for i, tag := range tags {
+			names[i] = tag.Name
+		}
+		call.tags = tags
+		data.Tags = strings.Join(names, ", ")
+	}
+
+	// 渲染提示词模板
+	tmpl, err := s.aiRepo.GetPromptTemplate(req.Action, req.TemplateVersion)
+	if err != nil {
+		return nil, ErrAIPromptTemplateNotFound
+	}
+	call.template = tmpl
+
+	call.chat, err = buildTemplateChat(s.aiRepo, userID, tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return call, nil
+}
+
+// apply 将摘要或标题写回草稿或文章
+func (s *AIAssistServiceImpl) apply(call *assistCall, action, text string) (bool, error) {
+	if action != model.AIActionSummarize && action != model.AIActionSuggestTitle {
+		return false, nil
+	}
+
+	switch {
+	case call.draft != nil:
+		if action == model.AIActionSummarize {
+			call.draft.Summary = text
+		} else {
+			call.draft.Title = text
+		}
+		if err := s.draftRepo.Update(call.draft); err != nil {
+			return false, err
+		}
+	case call.post != nil:
+		if action == model.AIActionSummarize {
+			call.post.Summary = text
+		} else {
+			call.post.Title = text
+		}
+		if err := s.postRepo.Update(call.post); err != nil {
+			return false, err
+		}
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// buildTemplateChat 使用用户的默认文本模型和渲染后的提示词模板构造聊天请求
+func buildTemplateChat(repo repository.AIRepository, userID uint, tmpl *model.AIPromptTemplate, data promptData) (*ai.ChatRequest, error) {
+	defaultSetting, err := repo.GetDefaultSetting(userID)
+	if err != nil || defaultSetting.DefaultModel == "" {
+		return nil, ErrAIDefaultModelNotSet
+	}
+	aiModel, err := repo.GetModelByID(defaultSetting.DefaultModel)
+	if err != nil {
+		return nil, ErrAIDefaultModelNotSet
+	}
+	setting, err := repo.GetUserSettingByProvider(userID, aiModel.Provider)
+	if err != nil {
+		return nil, ErrAIProviderNotConfigured
+	}
+
+	userPrompt, err := renderPrompt(tmpl.Action, tmpl.UserPrompt, data)
+	if err != nil {
+		return nil, err
+	}
+	systemPrompt, err := renderPrompt(tmpl.Action, tmpl.SystemPrompt, data)
+	if err != nil {
+		return nil, err
+	}
+
+	chat := &ai.ChatRequest{
+		Provider: aiModel.Provider,
+		Endpoint: setting.Endpoint,
+		APIKey:   setting.APIKey,
+		Model:    aiModel.ModelID,
+		Messages: buildAssistMessages(aiModel.Provider, systemPrompt, userPrompt),
+	}
+	if aiModel.Provider == "anthropic" {
+		chat.Params = map[string]interface{}{"max_tokens": 4096}
+	}
+	return chat, nil
+}
+
+// renderPrompt 使用文本模板渲染提示词
+func renderPrompt(name, text string, data promptData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("解析提示词模板失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染提示词模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// buildAssistMessages 构造聊天消息，不支持 system 角色的提供商将系统提示合并到用户消息中
+func buildAssistMessages(provider, systemPrompt, userPrompt string) []map[string]string {
+	if systemPrompt == "" {
+		return []map[string]string{{"role": "user", "content": userPrompt}}
+	}
+
+	switch provider {
+	case "anthropic", "google":
+		return []map[string]string{{"role": "user", "content": systemPrompt + "\n\n" + userPrompt}}
+	default:
+		return []map[string]string{
+			{"role": "system", "content": systemPrompt},
+			{"role": "user", "content": userPrompt},
+		}
+	}
+}
+
+// matchSuggestedTags 将模型返回的标签名与已有标签匹配
+func matchSuggestedTags(text string, tags []model.Tag) []dto.TagInfo {
+	byName := make(map[string]model.Tag, len(tags))
+	for _, tag := range tags {
+		byName[strings.ToLower(tag.Name)] = tag
+	}
+
+	result := make([]dto.TagInfo, 0)
+	seen := make(map[uint]bool)
+	for _, name := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '，' || r == '\n'
+	}) {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), "#\"'"))
+		if tag, ok := byName[name]; ok && !seen[tag.ID] {
+			seen[tag.ID] = true
+			result = append(result, dto.TagInfo{ID: tag.ID, Name: tag.Name})
+		}
+	}
+	return result
+}
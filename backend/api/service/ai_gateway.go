@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"notex/api/repository"
+	"notex/config"
+	"notex/pkg/ai"
+)
+
+// AIGateway 发送AI请求，主模型不可用时按用户或站点配置的备用模型依次尝试
+type AIGateway struct {
+	repo           repository.AIRepository
+	client         ai.Client
+	fallbackModels []string
+}
+
+// NewAIGateway 创建AI请求网关，client 可替换为测试用的实现
+func NewAIGateway(client ai.Client, cfg *config.AIConfig) *AIGateway {
+	return &AIGateway{
+		repo:           repository.NewAIRepository(),
+		client:         client,
+		fallbackModels: cfg.FallbackModels,
+	}
+}
+
+// Client 返回底层AI客户端
+func (g *AIGateway) Client() ai.Client {
+	return g.client
+}
+
+// Chat 发送聊天请求并在失败时切换到备用模型，返回最终使用的请求
+func (g *AIGateway) Chat(ctx context.Context, userID uint, primary *ai.ChatRequest) (*http.Response, *ai.ChatRequest, error) {
+	return ai.ChatWithFallback(ctx, g.client, g.chain(userID, primary))
+}
+
+// chain 构造主模型加备用模型的请求链，跳过未启用或用户未配置密钥的模型
+func (g *AIGateway) chain(userID uint, primary *ai.ChatRequest) []*ai.ChatRequest {
+	chain := []*ai.ChatRequest{primary}
+
+	modelIDs := g.fallbackModels
+	if setting, err := g.repo.GetDefaultSetting(userID); err == nil && len(setting.FallbackModels) > 0 {
+		modelIDs = setting.FallbackModels
+	}
+
+	for _, modelID := range modelIDs {
+		if modelID == primary.Model {
+			continue
+		}
+		aiModel, err := g.repo.GetModelByID(modelID)
+		if err != nil || aiModel.Type != "text" {
+			continue
+		}
+		setting, err := g.repo.GetUserSettingByProvider(userID, aiModel.Provider)
+		if err != nil {
+			continue
+		}
+
+		params := make(map[string]interface{}, len(primary.Params))
+		for k, v := range primary.Params {
+			params[k] = v
+		}
+		if aiModel.Provider == "anthropic" {
+			if _, ok := params["max_tokens"]; !ok {
+				params["max_tokens"] = 4096
+			}
+		}
+
+		chain = append(chain, &ai.ChatRequest{
+			Provider: aiModel.Provider,
+			Endpoint: setting.Endpoint,
+			APIKey:   setting.APIKey,
+			Model:    aiModel.ModelID,
+			Messages: ai.AdaptMessages(aiModel.Provider, primary.Messages),
+			Stream:   primary.Stream,
+			Params:   params,
+		})
+	}
+
+	return chain
+}
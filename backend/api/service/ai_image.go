@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/policy"
+	"notex/pkg/storage"
+	"notex/pkg/types"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrAIImageTooLarge = errors.New("生成的图像超过最大文件大小")
+
+// imageExtensions 图像内容类型对应的扩展名
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// AIImageService 定义AI生成图像的持久化接口
+type AIImageService interface {
+	// SaveGeneratedImages 下载或解码生成的图像并保存到存储，可选地设置为草稿或文章封面
+	SaveGeneratedImages(ctx context.Context, userID uint, req *dto.AIImageGenerationRequest, images []string) ([]dto.AIImageResponse, error)
+}
+
+// AIImageServiceImpl 实现AIImageService接口
+type AIImageServiceImpl struct {
+	aiRepo    repository.AIRepository
+	draftRepo *repository.DraftRepository
+	postRepo  *repository.PostRepository
+	storage   storage.Storage
+	config    *types.StorageConfig
+	client    *http.Client // 下载提供商返回的图像URL，超时与AI请求相同
+}
+
+// NewAIImageService 创建一个新的AIImageService实例
+func NewAIImageService(storage storage.Storage, config *types.StorageConfig, aiConfig *config.AIConfig) AIImageService {
+	return &AIImageServiceImpl{
+		aiRepo:    repository.NewAIRepository(),
+		draftRepo: repository.NewDraftRepository(),
+		postRepo:  repository.NewPostRepository(),
+		storage:   storage,
+		config:    config,
+		client:    &http.Client{Timeout: aiConfig.Timeout},
+	}
+}
+
+// SaveGeneratedImages 保存生成的图像并记录提示词和模型
+func (s *AIImageServiceImpl) SaveGeneratedImages(ctx context.Context, userID uint, req *dto.AIImageGenerationRequest, images []string) ([]dto.AIImageResponse, error) {
+	// 先校验封面目标的所有权，避免保存后才发现无权修改
+	var draft *model.Draft
+	var post *model.Post
+	if req.DraftID != 0 {
+		d, err := s.draftRepo.FindByID(req.DraftID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrDraftNotFound
+			}
+			return nil, err
+		}
+		if err := policy.Authorize(newSubject(userID, ""), policy.AIImageSetCover, draftResource(d)); err != nil {
+			return nil, ErrUnauthorized
+		}
+		draft = d
+	} else if req.PostID != 0 {
+		p, err := s.postRepo.FindByID(req.PostID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrPostNotFound
+			}
+			return nil, err
+		}
+		if err := policy.Authorize(newSubject(userID, ""), policy.AIImageSetCover, postResource(p)); err != nil {
+			return nil, ErrUnauthorized
+		}
+		post = p
+	}
+
+	result := make([]dto.AIImageResponse, 0, len(images))
+	for _, source := range images {
+		data, contentType, err := s.fetchImage(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+
+		ext, ok := imageExtensions[contentType]
+		if !ok {
+			return nil, fmt.Errorf("不支持的图像类型: %s", contentType)
+		}
+
+		uploaded, err := s.storage.Put(bytes.NewReader(data), "generated"+ext, contentType, int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+
+		image := &model.AIImage{
+			UserID:      userID,
+			Provider:    req.Provider,
+			ModelID:     req.Model,
+			Prompt:      req.Prompt,
+			Size:        req.Size,
+			URL:         uploaded.URL,
+			ContentType: contentType,
+			FileSize:    int64(len(data)),
+		}
+
+		// 生成缩略图，失败时不影响原图的保存；存储已生成缩略图时直接使用
+		if uploaded.ThumbnailURL != "" {
+			image.ThumbnailURL = uploaded.ThumbnailURL
+		} else if thumbData, thumbType, err := storage.CreateThumbnail(data, s.config.ThumbnailSize); err != nil {
+			log.Printf("Failed to create thumbnail for generated image: %v", err)
+		} else if thumb, err := s.storage.Put(bytes.NewReader(thumbData), "generated_thumb"+imageExtensions[thumbType], thumbType, int64(len(thumbData))); err != nil {
+			log.Printf("Failed to save thumbnail for generated image: %v", err)
+		} else {
+			image.ThumbnailURL = thumb.URL
+		}
+
+		if err := s.aiRepo.CreateImage(image); err != nil {
+			return nil, err
+		}
+		result = append(result, dto.ConvertToAIImageResponse(image))
+	}
+
+	// 将第一张图像设为封面
+	if len(result) > 0 {
+		switch {
+		case draft != nil:
+			draft.Cover = result[0].URL
+			if err := s.draftRepo.Update(draft); err != nil {
+				return nil, err
+			}
+		case post != nil:
+			post.Cover = result[0].URL
+			if err := s.postRepo.Update(post); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// fetchImage 解码 data URI 或下载提供商返回的图像URL
+func (s *AIImageServiceImpl) fetchImage(ctx context.Context, source string) ([]byte, string, error) {
+	if strings.HasPrefix(source, "data:") {
+		comma := strings.Index(source, ",")
+		if comma < 0 || !strings.HasSuffix(source[:comma], ";base64") {
+			return nil, "", errors.New("无效的图像数据")
+		}
+		data, err := base64.StdEncoding.DecodeString(source[comma+1:])
+		if err != nil {
+			return nil, "", fmt.Errorf("解码图像失败: %w", err)
+		}
+		if s.config.MaxSize > 0 && int64(len(data)) > s.config.MaxSize {
+			return nil, "", ErrAIImageTooLarge
+		}
+		return data, http.DetectContentType(data), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("创建下载请求失败: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("下载图像失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("下载图像失败: %s", resp.Status)
+	}
+
+	reader := io.Reader(resp.Body)
+	if s.config.MaxSize > 0 {
+		reader = io.LimitReader(resp.Body, s.config.MaxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("下载图像失败: %w", err)
+	}
+	if s.config.MaxSize > 0 && int64(len(data)) > s.config.MaxSize {
+		return nil, "", ErrAIImageTooLarge
+	}
+
+	return data, http.DetectContentType(data), nil
+}
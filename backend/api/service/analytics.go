@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/config"
+	"notex/pkg/policy"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// analyticsMaxDays 统计区间的最大天数
+	analyticsMaxDays = 731
+	// analyticsTopLimit 排行类统计返回的条目数
+	analyticsTopLimit = 10
+)
+
+var (
+	ErrAnalyticsInvalidRange = errors.New("invalid date range")
+	ErrAnalyticsForbidden    = errors.New("no permission to view analytics of this user")
+)
+
+// AnalyticsService 作者统计。浏览量来自 ViewService 写入的每日汇总，
+// 评论数和作者每日汇总由 Run 定期重新计算
+type AnalyticsService struct {
+	repo   *repository.AnalyticsRepository
+	views  *repository.PostViewRepository
+	posts  *repository.PostRepository
+	config *config.AnalyticsConfig
+}
+
+func NewAnalyticsService(cfg *config.AnalyticsConfig) *AnalyticsService {
+	return &AnalyticsService{
+		repo:   repository.NewAnalyticsRepository(),
+		views:  repository.NewPostViewRepository(),
+		posts:  repository.NewPostRepository(),
+		config: cfg,
+	}
+}
+
+// Run 启动时重新计算全部汇总，之后定期重新计算最近几天的汇总，直到 ctx 结束
+func (s *AnalyticsService) Run(ctx context.Context) {
+	if err := s.repo.Refresh(time.Time{}); err != nil {
+		log.Printf("Failed to refresh analytics: %v", err)
+	}
+
+	ticker := time.NewTicker(s.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			from := viewDate(time.Now()).AddDate(0, 0, 1-s.config.RecomputeDays)
+			if err := s.repo.Refresh(from); err != nil {
+				log.Printf("Failed to refresh analytics: %v", err)
+			}
+		}
+	}
+}
+
+// GetAuthorAnalytics 获取作者全部文章的统计，默认查看当前用户，管理员可以查看其他作者
+func (s *AnalyticsService) GetAuthorAnalytics(userID uint, role string, query *dto.AnalyticsQuery) (*dto.AuthorAnalyticsResponse, error) {
+	authorID := userID
+	if query.UserID != 0 {
+		authorID = query.UserID
+	}
+	if err := policy.Authorize(newSubject(userID, role), policy.AnalyticsView, &policy.Resource{OwnerID: authorID}); err != nil {
+		return nil, ErrAnalyticsForbidden
+	}
+
+	from, to, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.repo.AuthorDaily(authorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	series := newAnalyticsSeries(from, to, query.Granularity)
+	for _, day := range daily {
+		series.add(day.Date, dto.AnalyticsTotals{
+			Views:          day.Views,
+			UniqueVisitors: day.UniqueVisitors,
+			Comments:       day.Comments,
+			NewFollowers:   day.NewFollowers,
+		})
+	}
+
+	topPosts, err := s.repo.TopPosts(authorID, from, to, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := s.repo.TopReferrers(authorID, from, to, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.TrafficByCategory(authorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.repo.TrafficByTag(authorID, from, to, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AuthorAnalyticsResponse{
+		UserID:       authorID,
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		Granularity:  query.Granularity,
+		Totals:       series.totals,
+		Series:       series.points,
+		TopPosts:     make([]dto.PostTrafficStat, 0, len(topPosts)),
+		TopReferrers: convertReferrers(referrers),
+		Categories:   convertGroupTraffic(categories),
+		Tags:         convertGroupTraffic(tags),
+	}
+	for _, post := range topPosts {
+		response.TopPosts = append(response.TopPosts, dto.PostTrafficStat{
+			PostID:         post.PostID,
+			Title:          post.Title,
+			Views:          post.Views,
+			UniqueVisitors: post.UniqueVisitors,
+		})
+	}
+	return response, nil
+}
+
+// GetPostAnalytics 获取单篇文章的统计，权限与浏览统计相同
+func (s *AnalyticsService) GetPostAnalytics(viewer *PostViewer, postID uint, query *dto.AnalyticsQuery) (*dto.PostAnalyticsResponse, error) {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if err := authorizePost(viewer, policy.PostStats, post); err != nil {
+		return nil, err
+	}
+
+	from, to, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.repo.PostDaily(postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	series := newAnalyticsSeries(from, to, query.Granularity)
+	for _, day := range daily {
+		series.add(day.Date, dto.AnalyticsTotals{Views: day.Views, UniqueVisitors: day.UniqueVisitors, Comments: day.Comments})
+	}
+
+	referrers, err := s.views.TopReferrers(postID, from, to, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PostAnalyticsResponse{
+		PostID:      postID,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Granularity: query.Granularity,
+		Totals:      series.totals,
+		Series:      series.points,
+		Referrers:   convertReferrers(referrers),
+	}, nil
+}
+
+// analyticsRange 解析统计区间，默认为截至今天的最近 30 天
+func analyticsRange(query *dto.AnalyticsQuery) (time.Time, time.Time, error) {
+	to := viewDate(time.Now())
+	if query.To != "" {
+		parsed, err := time.Parse("2006-01-02", query.To)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrAnalyticsInvalidRange
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -29)
+	if query.From != "" {
+		parsed, err := time.Parse("2006-01-02", query.From)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrAnalyticsInvalidRange
+		}
+		from = parsed
+	}
+
+	if from.After(to) || to.Sub(from) >= analyticsMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrAnalyticsInvalidRange
+	}
+	return from, to, nil
+}
+
+// analyticsSeries 按粒度把每日数据归入连续的周期
+type analyticsSeries struct {
+	granularity string
+	points      []dto.AnalyticsPoint
+	index       map[string]int
+	totals      dto.AnalyticsTotals
+}
+
+// newAnalyticsSeries 创建覆盖 [from, to] 的周期序列，没有数据的周期为零
+func newAnalyticsSeries(from, to time.Time, granularity string) *analyticsSeries {
+	series := &analyticsSeries{granularity: granularity, index: make(map[string]int)}
+	for period := periodStart(from, granularity); !period.After(to); period = nextPeriod(period, granularity) {
+		label := period.Format("2006-01-02")
+		series.index[label] = len(series.points)
+		series.points = append(series.points, dto.AnalyticsPoint{Period: label})
+	}
+	return series
+}
+
+// add 将一天的数据计入所在周期
+func (s *analyticsSeries) add(date time.Time, values dto.AnalyticsTotals) {
+	i, ok := s.index[periodStart(date, s.granularity).Format("2006-01-02")]
+	if !ok {
+		return
+	}
+	point := &s.points[i]
+	point.Views += values.Views
+	point.UniqueVisitors += values.UniqueVisitors
+	point.Comments += values.Comments
+	point.NewFollowers += values.NewFollowers
+
+	s.totals.Views += values.Views
+	s.totals.UniqueVisitors += values.UniqueVisitors
+	s.totals.Comments += values.Comments
+	s.totals.NewFollowers += values.NewFollowers
+}
+
+// periodStart 返回日期所在周期的第一天，周从周一开始
+func periodStart(date time.Time, granularity string) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case "week":
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case "month":
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
+
+// nextPeriod 返回下一个周期的第一天
+func nextPeriod(period time.Time, granularity string) time.Time {
+	switch granularity {
+	case "week":
+		return period.AddDate(0, 0, 7)
+	case "month":
+		return period.AddDate(0, 1, 0)
+	default:
+		return period.AddDate(0, 0, 1)
+	}
+}
+
+// convertReferrers 将来源域名统计转换为响应
+func convertReferrers(referrers []repository.ReferrerCount) []dto.ReferrerStat {
+	stats := make([]dto.ReferrerStat, 0, len(referrers))
+	for _, referrer := range referrers {
+		stats = append(stats, dto.ReferrerStat{Domain: referrer.Domain, Views: referrer.Views})
+	}
+	return stats
+}
+
+// convertGroupTraffic 将分类或标签的浏览量转换为响应
+func convertGroupTraffic(groups []repository.GroupTraffic) []dto.GroupTrafficStat {
+	stats := make([]dto.GroupTrafficStat, 0, len(groups))
+	for _, group := range groups {
+		stats = append(stats, dto.GroupTrafficStat{ID: group.ID, Name: group.Name, Views: group.Views})
+	}
+	return stats
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/policy"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAttachmentUnavailable = errors.New("attachment does not exist or is attached elsewhere")
+	ErrAttachmentTooMany     = errors.New("too many attachments")
+)
+
+const (
+	attachmentColumnPost  = "post_id"
+	attachmentColumnDraft = "draft_id"
+)
+
+// attachmentFormat 描述附件扩展名对应的类型及内容特征
+type attachmentFormat struct {
+	mimeType string
+	sniffed  string // http.DetectContentType 识别出的类型，为空时只检查 magic
+	magic    []byte
+}
+
+var (
+	oleMagic    = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1} // 旧版 Office 文档
+	sevenZMagic = []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}
+)
+
+// attachmentFormats 支持的附件扩展名，Office Open XML 和 OpenDocument 文档本身是 ZIP 压缩包
+var attachmentFormats = map[string]attachmentFormat{
+	"pdf":  {mimeType: "application/pdf", sniffed: "application/pdf"},
+	"zip":  {mimeType: "application/zip", sniffed: "application/zip"},
+	"7z":   {mimeType: "application/x-7z-compressed", magic: sevenZMagic},
+	"rar":  {mimeType: "application/vnd.rar", sniffed: "application/x-rar-compressed"},
+	"gz":   {mimeType: "application/gzip", sniffed: "application/x-gzip"},
+	"doc":  {mimeType: "application/msword", magic: oleMagic},
+	"xls":  {mimeType: "application/vnd.ms-excel", magic: oleMagic},
+	"ppt":  {mimeType: "application/vnd.ms-powerpoint", magic: oleMagic},
+	"docx": {mimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", sniffed: "application/zip"},
+	"xlsx": {mimeType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", sniffed: "application/zip"},
+	"pptx": {mimeType: "application/vnd.openxmlformats-officedocument.presentationml.presentation", sniffed: "application/zip"},
+	"odt":  {mimeType: "application/vnd.oasis.opendocument.text", sniffed: "application/zip"},
+	"ods":  {mimeType: "application/vnd.oasis.opendocument.spreadsheet", sniffed: "application/zip"},
+	"odp":  {mimeType: "application/vnd.oasis.opendocument.presentation", sniffed: "application/zip"},
+	"txt":  {mimeType: "text/plain; charset=utf-8", sniffed: "text/plain"},
+	"csv":  {mimeType: "text/csv; charset=utf-8", sniffed: "text/plain"},
+}
+
+// AttachmentDownload 附件下载内容，调用方负责关闭
+type AttachmentDownload struct {
+	Attachment *model.Attachment
+	Content    io.ReadSeekCloser
+}
+
+// AttachmentService 管理文章和草稿的附件，文件作为私有文件保存在媒体库中，只能通过 Open 检查权限后下载
+type AttachmentService struct {
+	repo   *repository.AttachmentRepository
+	media  *MediaService
+	config *config.AttachmentConfig
+}
+
+func NewAttachmentService(media *MediaService, cfg *config.AttachmentConfig) *AttachmentService {
+	for ext, size := range cfg.Types {
+		if _, ok := attachmentFormats[strings.ToLower(ext)]; !ok && size > 0 {
+			log.Printf("Unsupported attachment type %q will be rejected", ext)
+		}
+	}
+
+	return &AttachmentService{
+		repo:   repository.NewAttachmentRepository(),
+		media:  media,
+		config: cfg,
+	}
+}
+
+// Upload 按扩展名校验附件的类型和大小后保存到媒体库，并创建未关联的附件
+func (s *AttachmentService) Upload(ctx context.Context, userID uint, file multipart.File, header *multipart.FileHeader) (*dto.AttachmentResponse, error) {
+	return s.Import(ctx, userID, file, header.Filename, header.Size)
+}
+
+// MaxSize 返回文件名对应附件类型的大小上限，类型不允许时返回 ErrMediaTypeNotAllowed
+func (s *AttachmentService) MaxSize(filename string) (int64, error) {
+	ext := attachmentExt(filename)
+	if _, ok := attachmentFormats[ext]; !ok || s.config.Types[ext] <= 0 {
+		return 0, ErrMediaTypeNotAllowed
+	}
+	return s.config.Types[ext], nil
+}
+
+// Import 从 reader 读取附件并按 Upload 的规则校验和保存，size 为已知的文件大小，未知时传 -1
+func (s *AttachmentService) Import(ctx context.Context, userID uint, reader io.Reader, filename string, size int64) (*dto.AttachmentResponse, error) {
+	maxSize, err := s.MaxSize(filename)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, ErrMediaTooLarge
+	}
+	format := attachmentFormats[attachmentExt(filename)]
+
+	content, err := readUpload(reader, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if !format.matches(content.head) {
+		return nil, ErrMediaTypeMismatch
+	}
+
+	upload, err := s.media.storePrivate(ctx, userID, content, filename, format.mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &model.Attachment{
+		UserID:   userID,
+		AssetID:  upload.AssetID,
+		Filename: filepath.Base(filename),
+		MimeType: format.mimeType,
+		Size:     content.size,
+	}
+	if err := s.repo.Create(attachment); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ConvertToAttachmentResponse(attachment)
+	return &resp, nil
+}
+
+// Delete 删除附件，文件在媒体库失去引用后由垃圾回收清理
+func (s *AttachmentService) Delete(userID uint, role string, id uint) error {
+	attachment, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if err := policy.Authorize(newSubject(userID, role), policy.AttachmentDelete, &policy.Resource{OwnerID: attachment.UserID}); err != nil {
+		return ErrUnauthorized
+	}
+	return s.repo.Delete(id)
+}
+
+// BindPost 将文章的附件替换为 ids 指定的附件，tx 为空时单独开启事务
+func (s *AttachmentService) BindPost(tx *gorm.DB, postID, userID uint, ids []uint) error {
+	return s.bind(tx, attachmentColumnPost, postID, userID, ids)
+}
+
+// BindDraft 将草稿的附件替换为 ids 指定的附件，tx 为空时单独开启事务
+func (s *AttachmentService) BindDraft(tx *gorm.DB, draftID, userID uint, ids []uint) error {
+	return s.bind(tx, attachmentColumnDraft, draftID, userID, ids)
+}
+
+// ListPost 获取文章的附件
+func (s *AttachmentService) ListPost(postID uint) ([]model.Attachment, error) {
+	return s.repo.ListByOwner(attachmentColumnPost, postID)
+}
+
+// Open 检查访问权限后打开附件内容。上传者和管理员可以下载全部附件，已关联文章的附件按文章的可见性判断，
+// 密码保护文章的附件需要解锁令牌；无权访问时与不存在一样返回 ErrAttachmentNotFound
+func (s *AttachmentService) Open(viewer *PostViewer, id uint) (*AttachmentDownload, error) {
+	attachment, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := policy.Allowed(viewer.policySubject(), policy.AttachmentDownload, &policy.Resource{OwnerID: attachment.UserID})
+	if !visible && attachment.PostID != nil {
+		post, err := s.repo.FindPostVisibility(*attachment.PostID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		visible = post != nil && checkPostAccess(post, viewer) == nil
+	}
+	if !visible || attachment.Asset == nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	reader, err := s.media.open(attachment.Asset)
+	if err != nil {
+		return nil, err
+	}
+	content, err := seekable(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttachmentDownload{Attachment: attachment, Content: content}, nil
+}
+
+// RecordDownload 记录一次附件下载
+func (s *AttachmentService) RecordDownload(id uint) {
+	if err := s.repo.IncrementDownloads(id); err != nil {
+		log.Printf("Failed to count download of attachment %d: %v", id, err)
+	}
+}
+
+// bind 校验附件数量后替换文章或草稿的附件
+func (s *AttachmentService) bind(tx *gorm.DB, column string, ownerID, userID uint, ids []uint) error {
+	ids = uniqueIDs(ids)
+	if len(ids) > s.config.MaxPerDocument {
+		return ErrAttachmentTooMany
+	}
+	if tx == nil {
+		tx = s.repo.DB
+	}
+
+	err := s.repo.Bind(tx, column, ownerID, userID, ids)
+	if errors.Is(err, repository.ErrAttachmentUnavailable) {
+		return ErrAttachmentUnavailable
+	}
+	return err
+}
+
+// find 查找附件
+func (s *AttachmentService) find(id uint) (*model.Attachment, error) {
+	attachment, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// matches 检查文件开头的内容是否符合扩展名对应的格式
+func (f attachmentFormat) matches(data []byte) bool {
+	if f.magic != nil && !bytes.HasPrefix(data, f.magic) {
+		return false
+	}
+	if f.sniffed != "" && baseMediaType(http.DetectContentType(data)) != f.sniffed {
+		return false
+	}
+	return true
+}
+
+// attachmentExt 返回文件名的小写扩展名，不含点
+func attachmentExt(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// uniqueIDs 去除重复的ID并保持顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// tempFile 关闭时删除的临时文件
+type tempFile struct {
+	*os.File
+}
+
+func (f tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// seekable 返回支持随机读取的内容，对象存储的响应体会先写入临时文件以支持范围请求
+func seekable(reader io.ReadCloser) (io.ReadSeekCloser, error) {
+	if rsc, ok := reader.(io.ReadSeekCloser); ok {
+		return rsc, nil
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, err
+	}
+	file := tempFile{tmp}
+	if _, err := io.Copy(tmp, reader); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
@@ -12,6 +12,7 @@ type AuthService struct {
 	userRepo    *repository.UserRepository
 	postRepo    *repository.PostRepository
 	commentRepo *repository.CommentRepository
+	followRepo  *repository.FollowRepository
 }
 
 func NewAuthService() *AuthService {
@@ -19,6 +20,7 @@ func NewAuthService() *AuthService {
 		userRepo:    repository.NewUserRepository(),
 		postRepo:    repository.NewPostRepository(),
 		commentRepo: repository.NewCommentRepository(),
+		followRepo:  repository.NewFollowRepository(),
 	}
 }
 
@@ -179,19 +181,31 @@ func (s *AuthService) GetUserProfile(userID uint) (*dto.UserProfile, error) {
 		return nil, err
 	}
 
+	// 获取关注者和关注的作者数量
+	followerCount, err := s.followRepo.CountFollowers(model.FollowTargetUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	followingCount, err := s.followRepo.CountFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &dto.UserProfile{
-		ID:           user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		Role:         user.Role,
-		Status:       user.Status,
-		Bio:          user.Bio,
-		Avatar:       user.Avatar,
-		PostCount:    postCount,
-		CommentCount: commentCount,
-		ViewCount:    viewCount,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           user.Role,
+		Status:         user.Status,
+		Bio:            user.Bio,
+		Avatar:         user.Avatar,
+		PostCount:      postCount,
+		CommentCount:   commentCount,
+		ViewCount:      viewCount,
+		FollowerCount:  followerCount,
+		FollowingCount: followingCount,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
 	}, nil
 }
 
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/policy"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBookmarkNotFound        = errors.New("bookmark not found")
+	ErrReadingListNotFound     = errors.New("reading list not found")
+	ErrReadingListNameTaken    = errors.New("reading list name already exists")
+	ErrReadingListNameRequired = errors.New("reading list name is required")
+)
+
+type BookmarkService struct {
+	repo  *repository.BookmarkRepository
+	posts *PostService
+}
+
+func NewBookmarkService(posts *PostService) *BookmarkService {
+	return &BookmarkService{
+		repo:  repository.NewBookmarkRepository(),
+		posts: posts,
+	}
+}
+
+// Bookmark 收藏文章，已收藏时移动到指定的阅读列表
+func (s *BookmarkService) Bookmark(viewer *PostViewer, postID uint, req *dto.BookmarkRequest) (*dto.BookmarkResponse, error) {
+	post, err := findReactablePost(s.posts.repo, viewer, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ReadingListID != nil {
+		if _, err := s.findList(viewer.UserID, *req.ReadingListID); err != nil {
+			return nil, err
+		}
+	}
+
+	bookmark := &model.Bookmark{UserID: viewer.UserID, PostID: post.ID, ReadingListID: req.ReadingListID}
+	if err := s.repo.Save(bookmark); err != nil {
+		return nil, err
+	}
+
+	bookmark, err = s.repo.Find(viewer.UserID, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	bookmark.Post = post
+	return s.convertToResponse(viewer, bookmark)
+}
+
+// Unbookmark 取消收藏
+func (s *BookmarkService) Unbookmark(userID, postID uint) error {
+	deleted, err := s.repo.Delete(userID, postID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBookmarkNotFound
+	}
+	return nil
+}
+
+// ListBookmarks 获取当前用户的收藏，不再可见的文章只返回收藏记录
+func (s *BookmarkService) ListBookmarks(viewer *PostViewer, query *dto.BookmarkListQuery) ([]dto.BookmarkResponse, int64, error) {
+	if query.ReadingListID != nil && *query.ReadingListID != 0 {
+		if _, err := s.findList(viewer.UserID, *query.ReadingListID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	bookmarks, total, err := s.repo.ListByUser(viewer.UserID, query.ReadingListID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses, err := s.convertBookmarks(viewer, bookmarks)
+	if err != nil {
+		return nil, 0, err
+	}
+	return responses, total, nil
+}
+
+// ListReadingLists 获取当前用户的阅读列表
+func (s *BookmarkService) ListReadingLists(userID uint) ([]dto.ReadingListResponse, error) {
+	lists, err := s.repo.ListsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ReadingListResponse, 0, len(lists))
+	for _, list := range lists {
+		responses = append(responses, convertReadingList(&list.ReadingList, list.BookmarkCount))
+	}
+	return responses, nil
+}
+
+// CreateReadingList 创建阅读列表，同一用户的阅读列表不能重名
+func (s *BookmarkService) CreateReadingList(userID uint, req *dto.ReadingListRequest) (*dto.ReadingListResponse, error) {
+	list := &model.ReadingList{UserID: userID}
+	if err := s.applyReadingList(list, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateList(list); err != nil {
+		return nil, err
+	}
+
+	response := convertReadingList(list, 0)
+	return &response, nil
+}
+
+// UpdateReadingList 修改阅读列表
+func (s *BookmarkService) UpdateReadingList(userID, id uint, req *dto.ReadingListRequest) (*dto.ReadingListResponse, error) {
+	list, err := s.findList(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyReadingList(list, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateList(list); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountInList(list.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := convertReadingList(list, count)
+	return &response, nil
+}
+
+// DeleteReadingList 删除阅读列表，其中的收藏保留但不再归入阅读列表
+func (s *BookmarkService) DeleteReadingList(userID, id uint) error {
+	if _, err := s.findList(userID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteList(id)
+}
+
+// applyReadingList 校验并设置阅读列表的名称和描述
+func (s *BookmarkService) applyReadingList(list *model.ReadingList, req *dto.ReadingListRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrReadingListNameRequired
+	}
+	taken, err := s.repo.ListNameExists(list.UserID, name, list.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrReadingListNameTaken
+	}
+
+	list.Name = name
+	list.Description = strings.TrimSpace(req.Description)
+	return nil
+}
+
+// findList 查找用户自己的阅读列表，他人的阅读列表与不存在一样返回 ErrReadingListNotFound
+func (s *BookmarkService) findList(userID, id uint) (*model.ReadingList, error) {
+	list, err := s.repo.FindList(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReadingListNotFound
+		}
+		return nil, err
+	}
+	if err := policy.Authorize(newSubject(userID, ""), policy.ReadingListManage, &policy.Resource{OwnerID: list.UserID}); err != nil {
+		return nil, ErrReadingListNotFound
+	}
+	return list, nil
+}
+
+// convertToResponse 将收藏转换为响应，访问者不能再查看的文章不返回内容
+func (s *BookmarkService) convertToResponse(viewer *PostViewer, bookmark *model.Bookmark) (*dto.BookmarkResponse, error) {
+	responses, err := s.convertBookmarks(viewer, []model.Bookmark{*bookmark})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// convertBookmarks 将多条收藏转换为响应，访问者不能再查看的文章不返回内容，文章的数量统计批量查询
+func (s *BookmarkService) convertBookmarks(viewer *PostViewer, bookmarks []model.Bookmark) ([]dto.BookmarkResponse, error) {
+	responses := make([]dto.BookmarkResponse, len(bookmarks))
+	posts := make([]*model.Post, 0, len(bookmarks))
+	indexes := make([]int, 0, len(bookmarks))
+	locked := make([]bool, 0, len(bookmarks))
+	for i := range bookmarks {
+		bookmark := &bookmarks[i]
+		responses[i] = dto.BookmarkResponse{
+			ID:            bookmark.ID,
+			PostID:        bookmark.PostID,
+			ReadingListID: bookmark.ReadingListID,
+			CreatedAt:     bookmark.CreatedAt,
+			UpdatedAt:     bookmark.UpdatedAt,
+		}
+		if bookmark.Post == nil {
+			responses[i].Unavailable = true
+			continue
+		}
+
+		access := checkPostAccess(bookmark.Post, viewer)
+		if access != nil && !errors.Is(access, ErrPostLocked) {
+			responses[i].Unavailable = true
+			continue
+		}
+		posts = append(posts, bookmark.Post)
+		indexes = append(indexes, i)
+		locked = append(locked, access != nil)
+	}
+
+	converted, err := s.posts.convertPosts(posts)
+	if err != nil {
+		return nil, err
+	}
+	for k, post := range converted {
+		if locked[k] {
+			redactPost(post)
+		}
+		responses[indexes[k]].Post = post
+	}
+	return responses, nil
+}
+
+// convertReadingList 将阅读列表转换为响应
+func convertReadingList(list *model.ReadingList, bookmarkCount int64) dto.ReadingListResponse {
+	return dto.ReadingListResponse{
+		ID:            list.ID,
+		Name:          list.Name,
+		Description:   list.Description,
+		BookmarkCount: bookmarkCount,
+		CreatedAt:     list.CreatedAt,
+		UpdatedAt:     list.UpdatedAt,
+	}
+}
@@ -1,26 +1,66 @@
 package service
 
 import (
+	"errors"
 	"notex/api/dto"
 	"notex/api/repository"
 	"notex/model"
+	"notex/pkg/policy"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrCategoryForbidden    = errors.New("no permission to manage this category")
+	ErrCategoryUnavailable  = errors.New("category belongs to another workspace")
+	ErrCategoryEditorsScope = errors.New("only site-wide categories have editors")
+
+	ErrCategoryInvalidSlug     = errors.New("category slug may only contain lowercase letters, digits and hyphens")
+	ErrCategorySlugTaken       = errors.New("category slug is already taken")
+	ErrCategoryInvalidParent   = errors.New("parent category must belong to the same scope and cannot be the category itself or one of its subcategories")
+	ErrCategoryInvalidSettings = errors.New("invalid category comment mode or default visibility")
 )
 
 type CategoryService struct {
-	repo *repository.CategoryRepository
+	repo       *repository.CategoryRepository
+	workspaces *repository.WorkspaceRepository
 }
 
 func NewCategoryService() *CategoryService {
 	return &CategoryService{
-		repo: repository.NewCategoryRepository(),
+		repo:       repository.NewCategoryRepository(),
+		workspaces: repository.NewWorkspaceRepository(),
 	}
 }
 
 // CreateCategory 创建分类
-func (s *CategoryService) CreateCategory(req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
+func (s *CategoryService) CreateCategory(userID uint, role string, req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
 	category := &model.Category{
-		Name:        req.Name,
-		Description: req.Description,
+		Name:              req.Name,
+		Description:       req.Description,
+		Cover:             req.Cover,
+		ParentID:          req.ParentID,
+		SortOrder:         req.SortOrder,
+		CommentMode:       req.CommentMode,
+		DefaultVisibility: req.DefaultVisibility,
+		WorkspaceID:       req.WorkspaceID,
+	}
+	if err := s.authorize(category, newSubject(userID, role), policy.CategoryManage); err != nil {
+		return nil, err
+	}
+
+	if err := validateCategorySettings(category.CommentMode, category.DefaultVisibility); err != nil {
+		return nil, err
+	}
+	if category.ParentID != nil {
+		if err := checkCategoryParent(s.repo, category, *category.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.assignSlug(category, req.Slug); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.Create(category); err != nil {
@@ -30,12 +70,15 @@ func (s *CategoryService) CreateCategory(req *dto.CreateCategoryRequest) (*dto.C
 	return s.convertToResponse(category)
 }
 
-// UpdateCategory 更新分类
-func (s *CategoryService) UpdateCategory(id uint, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
-	category, err := s.repo.FindByID(id)
+// UpdateCategory 更新分类，修改上级分类时整个子树随之移动
+func (s *CategoryService) UpdateCategory(id, userID uint, role string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
+	category, err := s.find(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorize(category, newSubject(userID, role), policy.CategoryManage); err != nil {
+		return nil, err
+	}
 
 	if req.Name != "" {
 		category.Name = req.Name
@@ -43,6 +86,37 @@ func (s *CategoryService) UpdateCategory(id uint, req *dto.UpdateCategoryRequest
 	if req.Description != "" {
 		category.Description = req.Description
 	}
+	if req.Cover != nil {
+		category.Cover = *req.Cover
+	}
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+	if req.CommentMode != nil {
+		category.CommentMode = *req.CommentMode
+	}
+	if req.DefaultVisibility != nil {
+		category.DefaultVisibility = *req.DefaultVisibility
+	}
+	if err := validateCategorySettings(category.CommentMode, category.DefaultVisibility); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			if err := checkCategoryParent(s.repo, category, *req.ParentID); err != nil {
+				return nil, err
+			}
+			category.ParentID = req.ParentID
+		}
+	}
+	if req.Slug != "" {
+		if err := s.assignSlug(category, req.Slug); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repo.Update(category); err != nil {
 		return nil, err
@@ -51,23 +125,40 @@ func (s *CategoryService) UpdateCategory(id uint, req *dto.UpdateCategoryRequest
 	return s.convertToResponse(category)
 }
 
-// DeleteCategory 删除分类
-func (s *CategoryService) DeleteCategory(id uint) error {
-	return s.repo.Delete(id)
+// DeleteCategory 删除分类，下级分类移到被删除分类的上级分类下
+func (s *CategoryService) DeleteCategory(id, userID uint, role string) error {
+	category, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(category, newSubject(userID, role), policy.CategoryManage); err != nil {
+		return err
+	}
+	return s.repo.Delete(category)
 }
 
-// GetCategory 获取分类详情
-func (s *CategoryService) GetCategory(id uint) (*dto.CategoryResponse, error) {
-	category, err := s.repo.FindByID(id)
+// GetCategory 获取分类详情，工作区分类只对工作区成员可见
+func (s *CategoryService) GetCategory(id, userID uint, role string) (*dto.CategoryResponse, error) {
+	category, err := s.find(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorize(category, newSubject(userID, role), policy.CategoryView); err != nil {
+		return nil, ErrCategoryNotFound
+	}
 	return s.convertToResponse(category)
 }
 
-// ListCategories 获取分类列表
-func (s *CategoryService) ListCategories(query *dto.CategoryListQuery) ([]dto.CategoryResponse, int64, error) {
-	categories, total, err := s.repo.List(query.Page, query.PageSize, query.Search)
+// ListCategories 获取分类列表，指定工作区时列出该工作区的分类，需要是工作区成员
+func (s *CategoryService) ListCategories(query *dto.CategoryListQuery, userID uint, role string) ([]dto.CategoryResponse, int64, error) {
+	if query.WorkspaceID > 0 {
+		resource := &policy.Resource{WorkspaceID: &query.WorkspaceID}
+		if err := authorizeWorkspace(s.workspaces, newSubject(userID, role), policy.CategoryView, resource); err != nil {
+			return make([]dto.CategoryResponse, 0), 0, err
+		}
+	}
+
+	categories, total, err := s.repo.List(query.Page, query.PageSize, query.Search, query.WorkspaceID)
 	if err != nil {
 		return make([]dto.CategoryResponse, 0), 0, err
 	}
@@ -84,6 +175,22 @@ func (s *CategoryService) ListCategories(query *dto.CategoryListQuery) ([]dto.Ca
 	return responses, total, nil
 }
 
+// ListCategoryTree 获取全站或工作区的分类树，文章数量包括全部下级分类，工作区分类树需要是工作区成员
+func (s *CategoryService) ListCategoryTree(query *dto.CategoryTreeQuery, userID uint, role string) ([]*dto.CategoryNode, error) {
+	if query.WorkspaceID > 0 {
+		resource := &policy.Resource{WorkspaceID: &query.WorkspaceID}
+		if err := authorizeWorkspace(s.workspaces, newSubject(userID, role), policy.CategoryView, resource); err != nil {
+			return make([]*dto.CategoryNode, 0), err
+		}
+	}
+
+	tree, err := loadCategoryTree(s.repo, query.WorkspaceID)
+	if err != nil {
+		return make([]*dto.CategoryNode, 0), err
+	}
+	return tree.nodes(0), nil
+}
+
 // GetTopCategories 获取热门分类（按文章数量排序）
 func (s *CategoryService) GetTopCategories(limit int) ([]dto.CategoryResponse, error) {
 	categories, err := s.repo.ListByPostCount(limit)
@@ -103,19 +210,133 @@ func (s *CategoryService) GetTopCategories(limit int) ([]dto.CategoryResponse, e
 	return responses, nil
 }
 
+// ListEditors 获取全站分类的责任编辑
+func (s *CategoryService) ListEditors(id, userID uint, role string) ([]dto.UserInfo, error) {
+	category, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(category, newSubject(userID, role), policy.CategoryAssignEditors); err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.ListEditors(id)
+	if err != nil {
+		return nil, err
+	}
+	editors := make([]dto.UserInfo, 0, len(users))
+	for _, user := range users {
+		editors = append(editors, dto.UserInfo{ID: user.ID, Username: user.Username, Avatar: user.Avatar})
+	}
+	return editors, nil
+}
+
+// ReplaceEditors 替换全站分类的责任编辑，编辑可以修改其负责分类下的全部个人文章
+func (s *CategoryService) ReplaceEditors(id, userID uint, role string, editorIDs []uint) ([]dto.UserInfo, error) {
+	category, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if category.WorkspaceID != nil {
+		return nil, ErrCategoryEditorsScope
+	}
+	if err := s.authorize(category, newSubject(userID, role), policy.CategoryAssignEditors); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceEditors(category, editorIDs); err != nil {
+		return nil, err
+	}
+	return s.ListEditors(id, userID, role)
+}
+
+// assignSlug 设置分类的 slug，未指定时根据名称生成
+func (s *CategoryService) assignSlug(category *model.Category, slug string) error {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug == "" {
+		generated, err := uniqueCategorySlug(s.repo, category.WorkspaceID, category.Name, category.ID)
+		if err != nil {
+			return err
+		}
+		category.Slug = generated
+		return nil
+	}
+
+	// 与工作区 slug 的格式规则相同
+	if len(slug) > 100 || !workspaceSlugPattern.MatchString(slug) {
+		return ErrCategoryInvalidSlug
+	}
+	taken, err := s.repo.ExistsBySlug(category.WorkspaceID, slug, category.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrCategorySlugTaken
+	}
+	category.Slug = slug
+	return nil
+}
+
+// find 查找分类
+func (s *CategoryService) find(id uint) (*model.Category, error) {
+	category, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// authorize 通过策略检查用户能否对分类执行操作
+func (s *CategoryService) authorize(category *model.Category, subject *policy.Subject, action policy.Action) error {
+	resource := &policy.Resource{WorkspaceID: category.WorkspaceID}
+	if category.WorkspaceID != nil {
+		return authorizeWorkspace(s.workspaces, subject, action, resource)
+	}
+	if !policy.Allowed(subject, action, resource) {
+		return ErrCategoryForbidden
+	}
+	return nil
+}
+
+// checkCategoryScope 检查文章或草稿能否使用分类：全站分类可用于任何内容，工作区分类只能用于同一工作区的内容
+func checkCategoryScope(repo *repository.CategoryRepository, categoryID uint, workspaceID *uint) error {
+	if categoryID == 0 {
+		return nil
+	}
+	category, err := repo.FindByID(categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+	if category.WorkspaceID == nil {
+		return nil
+	}
+	if workspaceID == nil || *category.WorkspaceID != *workspaceID {
+		return ErrCategoryUnavailable
+	}
+	return nil
+}
+
 // convertToResponse 将分类模型转换为响应DTO
 func (s *CategoryService) convertToResponse(category *model.Category) (*dto.CategoryResponse, error) {
 	postCount, err := s.repo.GetPostCount(category.ID)
 	if err != nil {
 		return nil, err
 	}
+	totalPostCount, err := s.repo.GetTotalPostCount(category.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	return &dto.CategoryResponse{
-		ID:          category.ID,
-		Name:        category.Name,
-		Description: category.Description,
-		PostCount:   postCount,
-		CreatedAt:   category.CreatedAt,
-		UpdatedAt:   category.UpdatedAt,
-	}, nil
+	response := categoryResponse(category, postCount, totalPostCount)
+	response.EffectiveCommentMode, response.EffectiveDefaultVisibility, err = resolveCategorySettings(s.repo, category.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
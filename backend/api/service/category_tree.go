@@ -0,0 +1,197 @@
+package service
+
+import (
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"strconv"
+)
+
+// categoryTree 全站或一个工作区的全部分类，用于汇总下级分类的文章数量和计算继承的设置
+type categoryTree struct {
+	byID     map[uint]*model.Category
+	children map[uint][]*model.Category // 键为上级分类ID，顶级分类的键为 0
+	counts   map[uint]int64             // 直接属于分类的文章数量
+}
+
+// loadCategoryTree 加载全站或工作区的分类树，workspaceID 为 0 时加载全站分类
+func loadCategoryTree(repo *repository.CategoryRepository, workspaceID uint) (*categoryTree, error) {
+	categories, err := repo.ListScope(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := repo.CountPostsInScope(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &categoryTree{
+		byID:     make(map[uint]*model.Category, len(categories)),
+		children: make(map[uint][]*model.Category),
+		counts:   counts,
+	}
+	for i := range categories {
+		tree.byID[categories[i].ID] = &categories[i]
+	}
+	// 分类已按排序值排列，逐个追加即可保持同级顺序
+	for i := range categories {
+		category := &categories[i]
+		var parentID uint
+		if category.ParentID != nil {
+			if _, ok := tree.byID[*category.ParentID]; ok {
+				parentID = *category.ParentID
+			}
+		}
+		tree.children[parentID] = append(tree.children[parentID], category)
+	}
+	return tree, nil
+}
+
+// totalPostCount 返回分类及其全部下级分类中的文章数量
+func (t *categoryTree) totalPostCount(id uint) int64 {
+	total := t.counts[id]
+	for _, child := range t.children[id] {
+		total += t.totalPostCount(child.ID)
+	}
+	return total
+}
+
+// ancestors 返回分类及其全部上级分类，从分类本身开始逐级向上排列
+func (t *categoryTree) ancestors(id uint) []model.Category {
+	var chain []model.Category
+	for category, ok := t.byID[id]; ok; {
+		chain = append(chain, *category)
+		if category.ParentID == nil || len(chain) > len(t.byID) {
+			break
+		}
+		category, ok = t.byID[*category.ParentID]
+	}
+	return chain
+}
+
+// response 将分类转换为响应DTO，文章数量和生效的设置由分类树计算
+func (t *categoryTree) response(category *model.Category) dto.CategoryResponse {
+	response := categoryResponse(category, t.counts[category.ID], t.totalPostCount(category.ID))
+	response.EffectiveCommentMode, response.EffectiveDefaultVisibility = effectiveCategorySettings(t.ancestors(category.ID))
+	return response
+}
+
+// nodes 返回上级分类下的子树，parentID 为 0 时返回整棵树
+func (t *categoryTree) nodes(parentID uint) []*dto.CategoryNode {
+	nodes := make([]*dto.CategoryNode, 0, len(t.children[parentID]))
+	for _, category := range t.children[parentID] {
+		nodes = append(nodes, &dto.CategoryNode{
+			CategoryResponse: t.response(category),
+			Children:         t.nodes(category.ID),
+		})
+	}
+	return nodes
+}
+
+// categoryResponse 将分类模型转换为响应DTO，不包括生效的设置
+func categoryResponse(category *model.Category, postCount, totalPostCount int64) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		ID:                category.ID,
+		Name:              category.Name,
+		Slug:              category.Slug,
+		Description:       category.Description,
+		Cover:             category.Cover,
+		ParentID:          category.ParentID,
+		SortOrder:         category.SortOrder,
+		CommentMode:       category.CommentMode,
+		DefaultVisibility: category.DefaultVisibility,
+		WorkspaceID:       category.WorkspaceID,
+		PostCount:         postCount,
+		TotalPostCount:    totalPostCount,
+		CreatedAt:         category.CreatedAt,
+		UpdatedAt:         category.UpdatedAt,
+	}
+}
+
+// effectiveCategorySettings 沿分类及其上级分类取第一个设置过的评论方式和默认可见性，都未设置时分别为 open 和 public
+func effectiveCategorySettings(chain []model.Category) (commentMode, visibility string) {
+	for _, category := range chain {
+		if commentMode == "" {
+			commentMode = category.CommentMode
+		}
+		if visibility == "" {
+			visibility = category.DefaultVisibility
+		}
+	}
+	if commentMode == "" {
+		commentMode = model.CategoryCommentOpen
+	}
+	if visibility == "" {
+		visibility = model.PostVisibilityPublic
+	}
+	return commentMode, visibility
+}
+
+// resolveCategorySettings 返回分类生效的评论方式和默认可见性，未分类或分类不存在时使用默认设置
+func resolveCategorySettings(repo *repository.CategoryRepository, categoryID uint) (commentMode, visibility string, err error) {
+	var chain []model.Category
+	if categoryID != 0 {
+		if chain, err = repo.Ancestors(categoryID); err != nil {
+			return "", "", err
+		}
+	}
+	commentMode, visibility = effectiveCategorySettings(chain)
+	return commentMode, visibility, nil
+}
+
+// validateCategorySettings 检查分类的评论方式和默认可见性，空值表示继承上级分类
+func validateCategorySettings(commentMode, visibility string) error {
+	switch commentMode {
+	case "", model.CategoryCommentOpen, model.CategoryCommentApproval, model.CategoryCommentClosed:
+	default:
+		return ErrCategoryInvalidSettings
+	}
+	// 需要密码的文章必须单独设置密码，不能作为分类的默认可见性
+	switch visibility {
+	case "", model.PostVisibilityPublic, model.PostVisibilityUnlisted, model.PostVisibilityPrivate:
+	default:
+		return ErrCategoryInvalidSettings
+	}
+	return nil
+}
+
+// uniqueCategorySlug 根据名称生成在全站或工作区中唯一的 slug，重复时依次追加 -2、-3……
+func uniqueCategorySlug(repo *repository.CategoryRepository, workspaceID *uint, name string, excludeID uint) (string, error) {
+	base := slugFromName(name, "category")
+	slug := base
+	for i := 2; ; i++ {
+		taken, err := repo.ExistsBySlug(workspaceID, slug, excludeID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// checkCategoryParent 检查分类能否放在上级分类下：上级分类需要属于同一工作区，且不能是分类本身或其下级分类
+func checkCategoryParent(repo *repository.CategoryRepository, category *model.Category, parentID uint) error {
+	chain, err := repo.Ancestors(parentID)
+	if err != nil {
+		return err
+	}
+	if len(chain) == 0 {
+		return ErrCategoryInvalidParent
+	}
+
+	parent := chain[0]
+	if (parent.WorkspaceID == nil) != (category.WorkspaceID == nil) ||
+		(parent.WorkspaceID != nil && *parent.WorkspaceID != *category.WorkspaceID) {
+		return ErrCategoryInvalidParent
+	}
+	if category.ID != 0 {
+		for _, ancestor := range chain {
+			if ancestor.ID == category.ID {
+				return ErrCategoryInvalidParent
+			}
+		}
+	}
+	return nil
+}
@@ -1,30 +1,67 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"notex/api/dto"
 	"notex/api/repository"
+	"notex/config"
 	"notex/model"
+	"notex/pkg/captcha"
+	"notex/pkg/policy"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCommentNotFound    = errors.New("comment not found")
+	ErrCommentForbidden   = errors.New("no permission to moderate this comment")
+	ErrCommentEditExpired = errors.New("comment can no longer be edited")
+	ErrReactionNotAllowed = errors.New("reaction is not allowed")
+	ErrCommentsClosed     = errors.New("comments are closed for this post")
 )
 
 type CommentService struct {
 	repo            *repository.CommentRepository
 	notificationSvc *NotificationService
 	postRepo        *repository.PostRepository
+	userRepo        *repository.UserRepository
+	categories      *repository.CategoryRepository
+	captcha         captcha.Captcha // 访客评论的人机验证，为空时不验证
+	config          *config.CommentsConfig
 }
 
-func NewCommentService() *CommentService {
+func NewCommentService(cfg *config.CommentsConfig, guestCaptcha captcha.Captcha) *CommentService {
 	return &CommentService{
 		repo:            repository.NewCommentRepository(),
 		notificationSvc: NewNotificationService(),
 		postRepo:        repository.NewPostRepository(),
+		userRepo:        repository.NewUserRepository(),
+		categories:      repository.NewCategoryRepository(),
+		captcha:         guestCaptcha,
+		config:          cfg,
 	}
 }
 
 // CreateComment creates a new comment
-func (s *CommentService) CreateComment(userID uint, postID uint, req *dto.CommentRequest) (*dto.CommentResponse, error) {
-	// 获取文章信息
-	post, err := s.postRepo.FindByID(postID)
+func (s *CommentService) CreateComment(viewer *PostViewer, postID uint, req *dto.CommentRequest) (*dto.CommentResponse, error) {
+	// 获取文章信息，只能评论有权访问的文章
+	post, err := s.findPost(postID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	userID := viewer.UserID
+
+	// 分类要求审核时，不能管理评论的用户发表的评论先隐藏
+	status, err := s.newCommentStatus(post, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.resolveMentions(req.Content)
 	if err != nil {
 		return nil, err
 	}
@@ -32,11 +69,12 @@ func (s *CommentService) CreateComment(userID uint, postID uint, req *dto.Commen
 	// Create comment model
 	comment := &model.Comment{
 		Content:   req.Content,
-		UserID:    userID,
+		UserID:    &userID,
 		PostID:    postID,
 		ParentID:  req.ParentID,
 		ReplyToID: req.ReplyToID,
-		Status:    "active",
+		Status:    status,
+		Mentions:  mentions,
 	}
 
 	// Save comment
@@ -50,6 +88,14 @@ func (s *CommentService) CreateComment(userID uint, postID uint, req *dto.Commen
 		return nil, err
 	}
 
+	// 待审核的评论只通知文章作者，恢复后不再补发回复和提醒通知
+	if comment.Status == "hidden" {
+		if err := s.notificationSvc.CreateCommentNotification(userID, postID, comment.ID, post.Title, req.Content); err != nil {
+			log.Printf("Failed to notify author of post %d about comment %d: %v", postID, comment.ID, err)
+		}
+		return s.convertToResponse(createdComment)
+	}
+
 	// 处理通知
 	if req.ParentID != nil {
 		// 如果是回复评论，创建回复通知
@@ -72,7 +118,7 @@ func (s *CommentService) CreateComment(userID uint, postID uint, req *dto.Commen
 					}
 
 					// 如果被回复的用户不是父评论作者，且父评论存在，才发送通知给父评论作者
-					if replyToComment.UserID != parentComment.UserID {
+					if replyToComment.AuthorID() != parentComment.AuthorID() {
 						if err := s.notificationSvc.CreateReplyNotification(userID, *req.ParentID, comment.ID, req.Content); err != nil {
 							// 记录错误但不影响评论创建
 							// TODO: 添加日志记录
@@ -95,6 +141,19 @@ func (s *CommentService) CreateComment(userID uint, postID uint, req *dto.Commen
 		}
 	}
 
+	// 提醒评论中 @ 到的用户，已经收到评论或回复通知的用户不再重复提醒
+	notified := map[uint]bool{userID: true}
+	if createdComment.ParentID == nil {
+		notified[post.UserID] = true
+	}
+	if createdComment.Parent != nil {
+		notified[createdComment.Parent.AuthorID()] = true
+	}
+	if createdComment.ReplyTo != nil {
+		notified[createdComment.ReplyTo.AuthorID()] = true
+	}
+	s.notifyMentions(createdComment, post, notified)
+
 	// Convert to response
 	response, err := s.convertToResponse(createdComment)
 	if err != nil {
@@ -103,9 +162,281 @@ func (s *CommentService) CreateComment(userID uint, postID uint, req *dto.Commen
 	return response, nil
 }
 
+// UpdateComment 作者在修改期限内修改评论，修改前的内容保留为修订版本，新 @ 到的用户会收到提醒
+func (s *CommentService) UpdateComment(viewer *PostViewer, postID, commentID uint, req *dto.UpdateCommentRequest) (*dto.CommentResponse, error) {
+	post, comment, err := s.authorize(viewer, postID, commentID, policy.CommentUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(comment.CreatedAt) > s.config.EditWindow {
+		return nil, ErrCommentEditExpired
+	}
+
+	if req.Content != comment.Content {
+		mentions, err := s.resolveMentions(req.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		// 之前已经提醒过的用户不再重复提醒
+		notified := map[uint]bool{comment.AuthorID(): true}
+		for _, user := range comment.Mentions {
+			notified[user.ID] = true
+		}
+
+		previous := comment.Content
+		now := time.Now()
+		comment.Content = req.Content
+		comment.EditedAt = &now
+		comment.Mentions = mentions
+		if err := s.repo.UpdateContent(comment, previous); err != nil {
+			return nil, err
+		}
+		s.notifyMentions(comment, post, notified)
+	}
+
+	updated, err := s.repo.FindByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.convertToResponse(updated)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachReactions(viewer.UserID, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListRevisions 获取评论修改前的各个版本，被隐藏的评论只有作者和能管理评论的用户可以查看
+func (s *CommentService) ListRevisions(viewer *PostViewer, commentID uint) ([]dto.CommentRevisionResponse, error) {
+	comment, err := s.repo.FindByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	post, err := s.findPost(comment.PostID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Status != "active" {
+		resource := commentResource(post, comment)
+		subject := viewer.policySubject()
+		if !policy.Allowed(subject, policy.CommentUpdate, resource) && !policy.Allowed(subject, policy.CommentHide, resource) {
+			return nil, ErrCommentNotFound
+		}
+	}
+
+	revisions, err := s.repo.ListRevisions(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.CommentRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		responses = append(responses, dto.CommentRevisionResponse{
+			ID:         revision.ID,
+			Content:    revision.Content,
+			ReplacedAt: revision.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// React 对评论使用表情回应，重复使用同一表情不报错，返回评论的全部表情回应
+func (s *CommentService) React(viewer *PostViewer, postID, commentID uint, emoji string) ([]dto.ReactionSummary, error) {
+	if !slices.Contains(s.config.Reactions, emoji) {
+		return nil, ErrReactionNotAllowed
+	}
+	if err := s.findReactable(viewer, postID, commentID); err != nil {
+		return nil, err
+	}
+
+	reaction := &model.CommentReaction{CommentID: commentID, UserID: viewer.UserID, Emoji: emoji}
+	if err := s.repo.AddReaction(reaction); err != nil {
+		return nil, err
+	}
+	return s.reactions(commentID, viewer.UserID)
+}
+
+// Unreact 取消表情回应，没有使用该表情时不报错
+func (s *CommentService) Unreact(viewer *PostViewer, postID, commentID uint, emoji string) ([]dto.ReactionSummary, error) {
+	if err := s.findReactable(viewer, postID, commentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveReaction(commentID, viewer.UserID, emoji); err != nil {
+		return nil, err
+	}
+	return s.reactions(commentID, viewer.UserID)
+}
+
+// findReactable 检查访问者能否对评论使用表情回应，被隐藏的评论与不存在一样
+func (s *CommentService) findReactable(viewer *PostViewer, postID, commentID uint) error {
+	_, comment, err := s.authorize(viewer, postID, commentID, policy.CommentReact)
+	if err != nil {
+		return err
+	}
+	if comment.Status != "active" {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// reactions 获取一条评论的全部表情回应
+func (s *CommentService) reactions(commentID, userID uint) ([]dto.ReactionSummary, error) {
+	summaries, err := s.reactionSummaries([]uint{commentID}, userID)
+	if err != nil {
+		return nil, err
+	}
+	return summaries[commentID], nil
+}
+
+// reactionSummaries 获取多条评论的表情回应，userID 不为 0 时标记该用户使用过的表情
+func (s *CommentService) reactionSummaries(commentIDs []uint, userID uint) (map[uint][]dto.ReactionSummary, error) {
+	counts, err := s.repo.CountReactions(commentIDs)
+	if err != nil {
+		return nil, err
+	}
+	own, err := s.repo.ListUserReactions(commentIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	reacted := make(map[uint]map[string]bool)
+	for _, reaction := range own {
+		if reacted[reaction.CommentID] == nil {
+			reacted[reaction.CommentID] = make(map[string]bool)
+		}
+		reacted[reaction.CommentID][reaction.Emoji] = true
+	}
+
+	summaries := make(map[uint][]dto.ReactionSummary, len(commentIDs))
+	for _, id := range commentIDs {
+		summaries[id] = []dto.ReactionSummary{}
+	}
+	for _, count := range counts {
+		summaries[count.CommentID] = append(summaries[count.CommentID], dto.ReactionSummary{
+			Emoji:   count.Emoji,
+			Count:   count.Count,
+			Reacted: reacted[count.CommentID][count.Emoji],
+		})
+	}
+	return summaries, nil
+}
+
+// attachReactions 为评论响应填充表情回应
+func (s *CommentService) attachReactions(userID uint, responses ...*dto.CommentResponse) error {
+	ids := make([]uint, 0, len(responses))
+	for _, response := range responses {
+		ids = append(ids, response.ID)
+	}
+	summaries, err := s.reactionSummaries(ids, userID)
+	if err != nil {
+		return err
+	}
+	for _, response := range responses {
+		response.Reactions = summaries[response.ID]
+	}
+	return nil
+}
+
+// resolveMentions 查找评论内容中 @ 到的用户，不存在的用户名被忽略
+func (s *CommentService) resolveMentions(content string) ([]model.User, error) {
+	usernames := parseMentions(content, s.config.MaxMentions)
+	return s.userRepo.FindByUsernames(usernames)
+}
+
+// notifyMentions 提醒评论中 @ 到的用户，跳过 notified 中的用户，通知失败不影响评论
+func (s *CommentService) notifyMentions(comment *model.Comment, post *model.Post, notified map[uint]bool) {
+	var users []model.User
+	for _, user := range comment.Mentions {
+		if !notified[user.ID] {
+			users = append(users, user)
+		}
+	}
+	if err := s.notificationSvc.CreateMentionNotifications(comment, post, users); err != nil {
+		log.Printf("Failed to create mention notifications for comment %d: %v", comment.ID, err)
+	}
+}
+
 // DeleteComment 删除评论
-func (s *CommentService) DeleteComment(id uint) error {
-	return s.repo.Delete(id)
+func (s *CommentService) DeleteComment(viewer *PostViewer, postID, commentID uint) error {
+	if _, _, err := s.authorize(viewer, postID, commentID, policy.CommentDelete); err != nil {
+		return err
+	}
+	return s.repo.Delete(commentID)
+}
+
+// SetCommentHidden 隐藏或恢复评论，隐藏的评论不出现在评论列表中
+func (s *CommentService) SetCommentHidden(viewer *PostViewer, postID, commentID uint, hidden bool) error {
+	_, comment, err := s.authorize(viewer, postID, commentID, policy.CommentHide)
+	if err != nil {
+		return err
+	}
+
+	status := "active"
+	if hidden {
+		status = "hidden"
+	}
+	if comment.Status == status {
+		return nil
+	}
+	return s.repo.UpdateStatus(commentID, status)
+}
+
+// authorize 查找文章下的评论并通过策略检查访问者能否对其执行操作
+func (s *CommentService) authorize(viewer *PostViewer, postID, commentID uint, action policy.Action) (*model.Post, *model.Comment, error) {
+	post, err := s.findPost(postID, viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+	comment, err := s.repo.FindByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCommentNotFound
+		}
+		return nil, nil, err
+	}
+	if comment.PostID != post.ID {
+		return nil, nil, ErrCommentNotFound
+	}
+
+	if !policy.Allowed(viewer.policySubject(), action, commentResource(post, comment)) {
+		return nil, nil, ErrCommentForbidden
+	}
+	return post, comment, nil
+}
+
+// newCommentStatus 根据文章所在分类生效的评论方式返回新评论的状态：关闭评论时返回 ErrCommentsClosed，
+// 需要审核时能隐藏评论的用户（文章作者、工作区编辑和管理员）发表的评论直接公开，其他评论先隐藏
+func (s *CommentService) newCommentStatus(post *model.Post, viewer *PostViewer) (string, error) {
+	mode, _, err := resolveCategorySettings(s.categories, post.CategoryID)
+	if err != nil {
+		return "", err
+	}
+
+	switch mode {
+	case model.CategoryCommentClosed:
+		return "", ErrCommentsClosed
+	case model.CategoryCommentApproval:
+		resource := &policy.Resource{WorkspaceID: post.WorkspaceID, PostOwnerID: post.UserID}
+		if viewer == nil || !policy.Allowed(viewer.policySubject(), policy.CommentHide, resource) {
+			return "hidden", nil
+		}
+	}
+	return "active", nil
+}
+
+// commentResource 返回策略评估使用的评论属性
+func commentResource(post *model.Post, comment *model.Comment) *policy.Resource {
+	return &policy.Resource{
+		OwnerID:     comment.AuthorID(),
+		WorkspaceID: post.WorkspaceID,
+		PostOwnerID: post.UserID,
+	}
 }
 
 // GetComment 获取评论详情
@@ -118,62 +449,99 @@ func (s *CommentService) GetComment(id uint) (*dto.CommentResponse, error) {
 }
 
 // ListComments 获取文章评论列表
-func (s *CommentService) ListComments(postID uint, query *dto.CommentListQuery) ([]dto.CommentResponse, int64, error) {
+func (s *CommentService) ListComments(postID uint, query *dto.CommentListQuery, viewer *PostViewer) ([]dto.CommentResponse, int64, error) {
+	if _, err := s.findPost(postID, viewer); err != nil {
+		return nil, 0, err
+	}
+
 	comments, total, err := s.repo.ListByPostID(postID, query.Page, query.PageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	// 一次获取全部评论的回复数量
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	replyCounts, err := s.repo.CountReplies(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	responses := make([]dto.CommentResponse, 0)
 	for _, comment := range comments {
 		// 只处理顶级评论
 		if comment.ParentID == nil {
-			// 获取子评论数量
-			replyCount, err := s.repo.GetReplyCount(comment.ID)
-			if err != nil {
-				// 记录错误但不影响评论列表的返回
-				// TODO: 添加日志记录
-				replyCount = 0
-			}
-
 			response, err := s.convertToResponse(&comment)
 			if err != nil {
 				return nil, 0, err
 			}
-			response.ReplyCount = replyCount
+			response.ReplyCount = replyCounts[comment.ID]
 			responses = append(responses, *response)
 		}
 	}
 
+	pointers := make([]*dto.CommentResponse, len(responses))
+	for i := range responses {
+		pointers[i] = &responses[i]
+	}
+	if err := s.attachReactions(viewer.UserID, pointers...); err != nil {
+		return nil, 0, err
+	}
+
 	return responses, total, nil
 }
 
 // GetCommentReplies 获取评论的回复列表
-func (s *CommentService) GetCommentReplies(commentID uint) ([]*dto.CommentBrief, error) {
+func (s *CommentService) GetCommentReplies(commentID uint, viewer *PostViewer) ([]*dto.CommentBrief, error) {
+	comment, err := s.repo.FindByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.findPost(comment.PostID, viewer); err != nil {
+		return nil, err
+	}
+
 	replies, err := s.repo.ListReplies(commentID)
 	if err != nil {
 		return nil, err
 	}
 
+	ids := make([]uint, len(replies))
+	for i, reply := range replies {
+		ids[i] = reply.ID
+	}
+	reactions, err := s.reactionSummaries(ids, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 转换回复为简要信息
 	children := make([]*dto.CommentBrief, len(replies))
 	for i, reply := range replies {
-		user, err := s.convertToUserInfo(reply.User)
+		user, err := s.commentAuthor(&reply)
 		if err != nil {
 			return nil, err
 		}
 
 		brief := &dto.CommentBrief{
-			ID:        reply.ID,
-			Content:   reply.Content,
-			UserID:    reply.UserID,
-			CreatedAt: reply.CreatedAt,
-			User:      user,
+			ID:          reply.ID,
+			Content:     reply.Content,
+			ContentHTML: renderMentions(reply.Content, reply.Mentions),
+			UserID:      reply.AuthorID(),
+			CreatedAt:   reply.CreatedAt,
+			EditedAt:    reply.EditedAt,
+			User:        user,
+			Reactions:   reactions[reply.ID],
 		}
 
 		// 如果有被回复的评论，添加被回复评论的信息
 		if reply.ReplyTo != nil {
-			replyToUser, err := s.convertToUserInfo(reply.ReplyTo.User)
+			replyToUser, err := s.commentAuthor(reply.ReplyTo)
 			if err != nil {
 				return nil, err
 			}
@@ -202,27 +570,52 @@ func (s *CommentService) ListUserComments(userID uint, page, pageSize int) ([]dt
 	// 转换为响应格式
 	responses := make([]dto.CommentResponse, len(comments))
 	for i, comment := range comments {
-		user, err := s.convertToUserInfo(comment.User)
+		user, err := s.commentAuthor(&comment)
 		if err != nil {
 			return nil, 0, err
 		}
 		responses[i] = dto.CommentResponse{
-			ID:        comment.ID,
-			Content:   comment.Content,
-			PostID:    comment.PostID,
-			UserID:    comment.UserID,
-			User:      user,
-			CreatedAt: comment.CreatedAt,
-			UpdatedAt: comment.UpdatedAt,
+			ID:          comment.ID,
+			Content:     comment.Content,
+			ContentHTML: renderMentions(comment.Content, comment.Mentions),
+			PostID:      comment.PostID,
+			UserID:      comment.AuthorID(),
+			User:        user,
+			CreatedAt:   comment.CreatedAt,
+			UpdatedAt:   comment.UpdatedAt,
+			EditedAt:    comment.EditedAt,
 		}
 		if comment.Post != nil {
 			responses[i].PostTitle = comment.Post.Title
 		}
 	}
 
+	pointers := make([]*dto.CommentResponse, len(responses))
+	for i := range responses {
+		pointers[i] = &responses[i]
+	}
+	if err := s.attachReactions(userID, pointers...); err != nil {
+		return nil, 0, err
+	}
+
 	return responses, total, nil
 }
 
+// findPost 查找评论所属的文章并检查访问权限
+func (s *CommentService) findPost(postID uint, viewer *PostViewer) (*model.Post, error) {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if err := checkPostAccess(post, viewer); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
 // convertToResponse converts a comment model to a comment response
 func (s *CommentService) convertToResponse(comment *model.Comment) (*dto.CommentResponse, error) {
 	if comment == nil {
@@ -230,23 +623,34 @@ func (s *CommentService) convertToResponse(comment *model.Comment) (*dto.Comment
 	}
 
 	// Convert user information
-	user, err := s.convertToUserInfo(comment.User)
+	user, err := s.commentAuthor(comment)
 	if err != nil {
 		return nil, err
 	}
 
 	response := &dto.CommentResponse{
-		ID:        comment.ID,
-		Content:   comment.Content,
-		PostID:    comment.PostID,
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
-		User:      user,
+		ID:          comment.ID,
+		Content:     comment.Content,
+		ContentHTML: renderMentions(comment.Content, comment.Mentions),
+		PostID:      comment.PostID,
+		Status:      comment.Status,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+		EditedAt:    comment.EditedAt,
+		User:        user,
+		Reactions:   []dto.ReactionSummary{},
+	}
+	for i := range comment.Mentions {
+		mention, err := s.convertToUserInfo(&comment.Mentions[i])
+		if err != nil {
+			return nil, err
+		}
+		response.Mentions = append(response.Mentions, mention)
 	}
 
 	// Add parent comment if exists
 	if comment.Parent != nil {
-		parentUser, err := s.convertToUserInfo(comment.Parent.User)
+		parentUser, err := s.commentAuthor(comment.Parent)
 		if err != nil {
 			return nil, err
 		}
@@ -260,7 +664,7 @@ func (s *CommentService) convertToResponse(comment *model.Comment) (*dto.Comment
 
 	// Add reply_to comment if exists
 	if comment.ReplyTo != nil {
-		replyToUser, err := s.convertToUserInfo(comment.ReplyTo.User)
+		replyToUser, err := s.commentAuthor(comment.ReplyTo)
 		if err != nil {
 			return nil, err
 		}
@@ -275,7 +679,7 @@ func (s *CommentService) convertToResponse(comment *model.Comment) (*dto.Comment
 	// Add children comments if any
 	children := make([]*dto.CommentBrief, 0, len(comment.Children))
 	for _, child := range comment.Children {
-		childUser, err := s.convertToUserInfo(child.User)
+		childUser, err := s.commentAuthor(&child)
 		if err != nil {
 			return nil, err
 		}
@@ -291,6 +695,18 @@ func (s *CommentService) convertToResponse(comment *model.Comment) (*dto.Comment
 	return response, nil
 }
 
+// commentAuthor 返回评论作者的信息，访客评论使用访客填写的名称
+func (s *CommentService) commentAuthor(comment *model.Comment) (*dto.UserInfo, error) {
+	if comment.IsGuest() {
+		info := &dto.UserInfo{Guest: true}
+		if comment.GuestName != nil {
+			info.Username = *comment.GuestName
+		}
+		return info, nil
+	}
+	return s.convertToUserInfo(comment.User)
+}
+
 // convertToUserInfo converts a user model to a user info DTO
 func (s *CommentService) convertToUserInfo(user *model.User) (*dto.UserInfo, error) {
 	if user == nil {
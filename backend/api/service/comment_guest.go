@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"notex/api/dto"
+	"notex/model"
+	"notex/pkg/captcha"
+	"notex/pkg/email"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGuestCommentsDisabled = errors.New("guest comments are not allowed on this post")
+	ErrGuestNameInvalid      = errors.New("guest name cannot be empty")
+	ErrGuestEmailRegistered  = errors.New("email belongs to a registered account, please sign in to comment")
+	ErrGuestTokenInvalid     = errors.New("invalid or expired confirmation link")
+	ErrCaptchaFailed         = errors.New("captcha verification failed")
+	ErrEmailNotVerified      = errors.New("email is not verified")
+)
+
+// Run 定期删除确认链接已过期的访客评论，直到 ctx 结束
+func (s *CommentService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Guest.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteExpiredGuests(time.Now())
+			if err != nil {
+				log.Printf("Failed to delete expired guest comments: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Deleted %d unconfirmed guest comments", deleted)
+			}
+		}
+	}
+}
+
+// GetCaptcha 创建访客评论使用的人机验证挑战，未启用人机验证时返回 none
+func (s *CommentService) GetCaptcha(ctx context.Context) (*dto.CaptchaResponse, error) {
+	if s.captcha == nil {
+		return &dto.CaptchaResponse{Provider: "none"}, nil
+	}
+
+	challenge, err := s.captcha.Challenge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CaptchaResponse{
+		Provider: challenge.Provider,
+		Token:    challenge.Token,
+		Image:    challenge.Image,
+		SiteKey:  challenge.SiteKey,
+	}, nil
+}
+
+// CreateGuestComment 访客填写名称和邮箱发表评论。需要站点和文章都允许访客评论并通过人机验证，
+// 评论在访客点击确认邮件中的链接前不公开，也不会发送评论、回复和提醒通知
+func (s *CommentService) CreateGuestComment(ctx context.Context, viewer *PostViewer, postID uint, req *dto.GuestCommentRequest, remoteIP string) (*dto.GuestCommentResponse, error) {
+	post, err := s.findPost(postID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if !s.config.Guest.Enabled || post.GuestCommentsDisabled {
+		return nil, ErrGuestCommentsDisabled
+	}
+	if _, err := s.newCommentStatus(post, viewer); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrGuestNameInvalid
+	}
+	address := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := s.userRepo.FindByEmail(address); err == nil {
+		return nil, ErrGuestEmailRegistered
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if s.captcha != nil {
+		if err := s.captcha.Verify(ctx, req.CaptchaToken, req.CaptchaAnswer, remoteIP); err != nil {
+			if errors.Is(err, captcha.ErrFailed) {
+				return nil, ErrCaptchaFailed
+			}
+			return nil, err
+		}
+	}
+
+	// 只能回复同一篇文章下已公开的评论
+	for _, id := range []*uint{req.ParentID, req.ReplyToID} {
+		if id == nil {
+			continue
+		}
+		target, err := s.repo.FindByID(*id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrCommentNotFound
+			}
+			return nil, err
+		}
+		if target.PostID != postID || target.Status != "active" {
+			return nil, ErrCommentNotFound
+		}
+	}
+
+	mentions, err := s.resolveMentions(req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与工作区邀请使用相同格式的令牌，数据库中只保存哈希
+	token, err := generateInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	link, err := guestVerifyLink(s.config.Guest.VerifyURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.Comment{
+		Content:    req.Content,
+		GuestName:  &name,
+		GuestEmail: &address,
+		PostID:     postID,
+		ParentID:   req.ParentID,
+		ReplyToID:  req.ReplyToID,
+		Status:     "pending",
+		Mentions:   mentions,
+	}
+	verification := &model.GuestCommentVerification{
+		TokenHash: hashInvitationToken(token),
+		ExpiresAt: time.Now().Add(s.config.Guest.VerifyExpiration),
+	}
+	if err := s.repo.CreateGuest(comment, verification); err != nil {
+		return nil, err
+	}
+
+	locale := "zh-CN" // 默认使用中文
+	hours := max(int(s.config.Guest.VerifyExpiration/time.Hour), 1)
+	if err := email.SendGuestCommentEmail(address, name, post.Title, link, hours, locale); err != nil {
+		// 邮件未送达时删除评论，访客无法确认的评论没有保留的必要
+		if deleteErr := s.repo.Delete(comment.ID); deleteErr != nil {
+			log.Printf("Failed to delete guest comment %d: %v", comment.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	return &dto.GuestCommentResponse{ID: comment.ID, Status: comment.Status}, nil
+}
+
+// VerifyGuestComment 通过确认邮件中的令牌公开访客评论，文章所在分类要求审核时评论确认后先隐藏
+func (s *CommentService) VerifyGuestComment(token string) (*dto.CommentResponse, error) {
+	verification, err := s.repo.FindGuestVerification(hashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGuestTokenInvalid
+		}
+		return nil, err
+	}
+
+	comment, err := s.repo.FindByID(verification.CommentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGuestTokenInvalid
+		}
+		return nil, err
+	}
+	post, err := s.postRepo.FindByID(comment.PostID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGuestTokenInvalid
+		}
+		return nil, err
+	}
+	// 评论发表后分类关闭了评论时不再公开，评论在确认链接过期后被清理
+	status, err := s.newCommentStatus(post, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ConfirmGuest(comment.ID, status); err != nil {
+		return nil, err
+	}
+	comment.Status = status
+	return s.convertToResponse(comment)
+}
+
+// ClaimGuestComments 将与用户邮箱相同的访客评论归入用户，只有验证过邮箱的用户可以认领
+func (s *CommentService) ClaimGuestComments(userID uint) (*dto.ClaimGuestCommentsResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	claimed, err := s.claimGuests(user)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ClaimGuestCommentsResponse{Claimed: claimed}, nil
+}
+
+// claimGuests 将与用户邮箱相同的访客评论归入用户。等待确认的评论与 VerifyGuestComment 一样按文章所在分类的
+// 评论方式确定状态，分类已关闭评论或文章已删除时评论不被认领，在确认链接过期后被清理
+func (s *CommentService) claimGuests(user *model.User) (int64, error) {
+	email := strings.ToLower(user.Email)
+	postIDs, err := s.repo.PendingGuestPostIDs(email)
+	if err != nil {
+		return 0, err
+	}
+
+	viewer := &PostViewer{UserID: user.ID, Role: user.Role}
+	statuses := make(map[uint]string, len(postIDs))
+	for _, postID := range postIDs {
+		post, err := s.postRepo.FindByID(postID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		status, err := s.newCommentStatus(post, viewer)
+		if errors.Is(err, ErrCommentsClosed) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		statuses[postID] = status
+	}
+
+	return s.repo.ClaimGuests(user.ID, email, statuses)
+}
+
+// guestVerifyLink 在确认地址上附加令牌
+func guestVerifyLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
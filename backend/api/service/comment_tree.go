@@ -0,0 +1,159 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"notex/api/dto"
+	"notex/api/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrCommentInvalidCursor = errors.New("invalid comment cursor")
+
+// GetCommentTree 一次获取文章的评论树。每一层按 sort 排序并用游标分页，第一层最多 limit 条，
+// 每条评论下最多 reply_limit 条回复，超过 depth 层的回复只返回数量。
+// 查询次数与层数相关而与评论数量无关：每层一次，另外批量加载评论内容、回复数量和表情回应
+func (s *CommentService) GetCommentTree(viewer *PostViewer, postID uint, query *dto.CommentTreeQuery) (*dto.CommentTreeResponse, error) {
+	if _, err := s.findPost(postID, viewer); err != nil {
+		return nil, err
+	}
+
+	var parentID *uint
+	if query.ParentID != 0 {
+		parent, err := s.repo.FindByID(query.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrCommentNotFound
+			}
+			return nil, err
+		}
+		if parent.PostID != postID || parent.Status != "active" {
+			return nil, ErrCommentNotFound
+		}
+		parentID = &parent.ID
+	}
+
+	var cursor *repository.CommentKey
+	if query.Cursor != "" {
+		decoded, err := decodeCommentCursor(query.Sort, query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
+	}
+
+	// 第一层多取一条用于判断是否还有下一页
+	keys, err := s.repo.ListThread(postID, parentID, query.Sort, cursor, query.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.CommentTreeResponse{}
+	if len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+		response.NextCursor = encodeCommentCursor(query.Sort, &keys[len(keys)-1])
+	}
+
+	// 逐层加载回复，children 记录每条评论已加载的回复，more 记录还有更多回复的评论
+	all := append([]repository.CommentKey{}, keys...)
+	children := make(map[uint][]repository.CommentKey)
+	more := make(map[uint]string)
+	level := keys
+	for depth := 2; depth <= query.Depth && query.ReplyLimit > 0 && len(level) > 0; depth++ {
+		parentIDs := make([]uint, len(level))
+		for i, key := range level {
+			parentIDs[i] = key.ID
+		}
+		replies, err := s.repo.ListFirstReplies(parentIDs, query.Sort, query.ReplyLimit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		level = nil
+		for _, reply := range replies {
+			parent := *reply.ParentID
+			if len(children[parent]) == query.ReplyLimit {
+				last := children[parent][len(children[parent])-1]
+				more[parent] = encodeCommentCursor(query.Sort, &last)
+				continue
+			}
+			children[parent] = append(children[parent], reply)
+			level = append(level, reply)
+		}
+		all = append(all, level...)
+	}
+
+	ids := make([]uint, len(all))
+	for i, key := range all {
+		ids[i] = key.ID
+	}
+	comments, err := s.repo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	replyCounts, err := s.repo.CountReplies(ids)
+	if err != nil {
+		return nil, err
+	}
+	reactions, err := s.reactionSummaries(ids, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uint]*dto.CommentNode, len(comments))
+	for i := range comments {
+		comment, err := s.convertToResponse(&comments[i])
+		if err != nil {
+			return nil, err
+		}
+		comment.ParentID = comments[i].ParentID
+		comment.UserID = comments[i].AuthorID()
+		comment.ReplyCount = replyCounts[comments[i].ID]
+		comment.Reactions = reactions[comments[i].ID]
+		nodes[comments[i].ID] = &dto.CommentNode{CommentResponse: *comment, Replies: []*dto.CommentNode{}}
+	}
+
+	// 按加载顺序组装评论树，加载期间被删除的评论会被跳过
+	for _, key := range all {
+		node, ok := nodes[key.ID]
+		if !ok {
+			continue
+		}
+		node.RepliesCursor = more[key.ID]
+		for _, child := range children[key.ID] {
+			if childNode, ok := nodes[child.ID]; ok {
+				node.Replies = append(node.Replies, childNode)
+			}
+		}
+	}
+	response.Items = make([]*dto.CommentNode, 0, len(keys))
+	for _, key := range keys {
+		if node, ok := nodes[key.ID]; ok {
+			response.Items = append(response.Items, node)
+		}
+	}
+	return response, nil
+}
+
+// encodeCommentCursor 将评论的排序键编码为不透明的游标，游标只能用于相同的排序方式
+func encodeCommentCursor(sort string, key *repository.CommentKey) string {
+	raw := fmt.Sprintf("%s:%d:%d:%d", sort, key.CreatedAt.UnixMicro(), key.ReactionCount, key.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCommentCursor 解析 encodeCommentCursor 生成的游标
+func decodeCommentCursor(sort, value string) (*repository.CommentKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrCommentInvalidCursor
+	}
+
+	var micros, reactions int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), sort+":%d:%d:%d", &micros, &reactions, &id); err != nil {
+		return nil, ErrCommentInvalidCursor
+	}
+	return &repository.CommentKey{CreatedAt: time.UnixMicro(micros).UTC(), ReactionCount: reactions, ID: id}, nil
+}
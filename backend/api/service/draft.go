@@ -1,37 +1,60 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
 	"notex/api/dto"
 	"notex/api/repository"
 	"notex/model"
+	"notex/pkg/policy"
+
+	"gorm.io/gorm"
 )
 
 var (
-	ErrUnauthorized  = errors.New("unauthorized")
-	ErrDraftNotFound = errors.New("draft not found")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrDraftNotFound  = errors.New("draft not found")
+	ErrDraftForbidden = errors.New("no permission to modify this draft")
 )
 
 type DraftService struct {
-	draftRepo *repository.DraftRepository
+	draftRepo     *repository.DraftRepository
+	categories    *repository.CategoryRepository
+	workspaces    *repository.WorkspaceRepository
+	notifications *NotificationService
+	embeddings    *EmbeddingService
+	attachments   *AttachmentService
 }
 
-func NewDraftService(draftRepo *repository.DraftRepository) *DraftService {
+func NewDraftService(draftRepo *repository.DraftRepository, embeddings *EmbeddingService, attachments *AttachmentService) *DraftService {
 	return &DraftService{
-		draftRepo: draftRepo,
+		draftRepo:     draftRepo,
+		categories:    repository.NewCategoryRepository(),
+		workspaces:    repository.NewWorkspaceRepository(),
+		notifications: NewNotificationService(),
+		embeddings:    embeddings,
+		attachments:   attachments,
 	}
 }
 
-// ListDrafts 获取草稿列表
-func (s *DraftService) ListDrafts(userID uint, page, pageSize int, search string) ([]dto.DraftResponse, int64, error) {
-	conditions := map[string]interface{}{
-		"user_id": userID,
+// ListDrafts 获取草稿列表，指定工作区时列出该工作区的全部草稿，否则列出用户自己的草稿
+func (s *DraftService) ListDrafts(userID uint, role string, query *dto.DraftListQuery) ([]dto.DraftResponse, int64, error) {
+	conditions := make(map[string]interface{})
+	if query.WorkspaceID > 0 {
+		resource := &policy.Resource{WorkspaceID: &query.WorkspaceID}
+		if err := authorizeWorkspace(s.workspaces, newSubject(userID, role), policy.DraftView, resource); err != nil {
+			return nil, 0, err
+		}
+		conditions["workspace_id"] = query.WorkspaceID
+	} else {
+		conditions["user_id"] = userID
 	}
-	if search != "" {
-		conditions["search"] = search
+	if query.Search != "" {
+		conditions["search"] = query.Search
 	}
 
-	drafts, total, err := s.draftRepo.List(page, pageSize, conditions)
+	drafts, total, err := s.draftRepo.List(query.Page, query.PageSize, conditions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -46,35 +69,39 @@ func (s *DraftService) ListDrafts(userID uint, page, pageSize int, search string
 }
 
 // GetDraft 获取草稿详情
-func (s *DraftService) GetDraft(id, userID uint) (*model.Draft, error) {
-	draft, err := s.draftRepo.FindByID(id)
+func (s *DraftService) GetDraft(id, userID uint, role string) (*model.Draft, error) {
+	draft, err := s.find(id)
 	if err != nil {
-		if errors.Is(err, repository.ErrDraftNotFound) {
-			return nil, ErrDraftNotFound
-		}
 		return nil, err
 	}
-
-	// 验证所有权
-	if draft.UserID != userID {
-		return nil, ErrUnauthorized
+	if err := s.authorize(draft, userID, role, policy.DraftView); err != nil {
+		return nil, err
 	}
 
 	return draft, nil
 }
 
 // CreateDraft 创建草稿
-func (s *DraftService) CreateDraft(userID uint, req dto.CreateDraftRequest) (*dto.DraftResponse, error) {
+func (s *DraftService) CreateDraft(userID uint, role string, req dto.CreateDraftRequest) (*dto.DraftResponse, error) {
+	subject := newSubject(userID, role)
+	if !policy.Allowed(subject, policy.DraftCreate, &policy.Resource{OwnerID: userID, WorkspaceID: req.WorkspaceID}) {
+		return nil, deniedError(subject, req.WorkspaceID, ErrDraftForbidden)
+	}
+	if err := checkCategoryScope(s.categories, req.CategoryID, req.WorkspaceID); err != nil {
+		return nil, err
+	}
+
 	// 开启事务
 	tx := s.draftRepo.DB.Begin()
 
 	draft := &model.Draft{
-		Title:      req.Title,
-		Content:    req.Content,
-		Summary:    req.Summary,
-		Cover:      req.Cover,
-		CategoryID: req.CategoryID,
-		UserID:     userID,
+		Title:       req.Title,
+		Content:     req.Content,
+		Summary:     req.Summary,
+		Cover:       req.Cover,
+		CategoryID:  req.CategoryID,
+		UserID:      userID,
+		WorkspaceID: req.WorkspaceID,
 	}
 
 	// 创建草稿
@@ -96,6 +123,14 @@ func (s *DraftService) CreateDraft(userID uint, req dto.CreateDraftRequest) (*dt
 		}
 	}
 
+	// 关联附件
+	if len(req.AttachmentIDs) > 0 {
+		if err := s.attachments.BindDraft(tx, draft.ID, userID, req.AttachmentIDs); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
@@ -112,18 +147,13 @@ func (s *DraftService) CreateDraft(userID uint, req dto.CreateDraftRequest) (*dt
 }
 
 // UpdateDraft 更新草稿
-func (s *DraftService) UpdateDraft(id, userID uint, req dto.UpdateDraftRequest) (*model.Draft, error) {
-	draft, err := s.draftRepo.FindByID(id)
+func (s *DraftService) UpdateDraft(id, userID uint, role string, req dto.UpdateDraftRequest) (*model.Draft, error) {
+	draft, err := s.find(id)
 	if err != nil {
-		if errors.Is(err, repository.ErrDraftNotFound) {
-			return nil, ErrDraftNotFound
-		}
 		return nil, err
 	}
-
-	// 验证所有权
-	if draft.UserID != userID {
-		return nil, ErrUnauthorized
+	if err := s.authorize(draft, userID, role, policy.DraftUpdate); err != nil {
+		return nil, err
 	}
 
 	// 更新字段
@@ -140,6 +170,9 @@ func (s *DraftService) UpdateDraft(id, userID uint, req dto.UpdateDraftRequest)
 		draft.Cover = req.Cover
 	}
 	if req.CategoryID != 0 {
+		if err := checkCategoryScope(s.categories, req.CategoryID, draft.WorkspaceID); err != nil {
+			return nil, err
+		}
 		draft.CategoryID = req.CategoryID
 	}
 
@@ -155,57 +188,106 @@ func (s *DraftService) UpdateDraft(id, userID uint, req dto.UpdateDraftRequest)
 		}
 	}
 
-	// 重新获取完整的草稿信息（包括关联的标签）
+	// 替换附件，附件需由草稿作者上传
+	if req.AttachmentIDs != nil {
+		if err := s.attachments.BindDraft(nil, draft.ID, draft.UserID, *req.AttachmentIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// 重新获取完整的草稿信息（包括关联的标签和附件）
 	return s.draftRepo.FindByID(id)
 }
 
 // DeleteDraft 删除草稿
-func (s *DraftService) DeleteDraft(id, userID uint) error {
-	draft, err := s.draftRepo.FindByID(id)
+func (s *DraftService) DeleteDraft(id, userID uint, role string) error {
+	draft, err := s.find(id)
 	if err != nil {
 		return err
 	}
-
-	// 验证所有权
-	if draft.UserID != userID {
-		return ErrUnauthorized
+	if err := s.authorize(draft, userID, role, policy.DraftDelete); err != nil {
+		return err
 	}
 
 	return s.draftRepo.Delete(draft)
 }
 
-// PublishDraft 发布草稿
-func (s *DraftService) PublishDraft(id, userID uint) (*model.Post, error) {
-	draft, err := s.draftRepo.FindByID(id)
+// PublishDraft 发布草稿，工作区草稿发布为同一工作区的文章
+func (s *DraftService) PublishDraft(id, userID uint, role string) (*model.Post, error) {
+	draft, err := s.find(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorize(draft, userID, role, policy.DraftPublish); err != nil {
+		return nil, err
+	}
 
-	// 验证所有权
-	if draft.UserID != userID {
-		return nil, ErrUnauthorized
+	// 草稿没有可见性设置，发布为分类的默认可见性
+	_, visibility, err := resolveCategorySettings(s.categories, draft.CategoryID)
+	if err != nil {
+		return nil, err
 	}
 
 	// 发布草稿
-	post, err := s.draftRepo.PublishDraft(draft)
+	post, err := s.draftRepo.PublishDraft(draft, visibility)
 	if err != nil {
 		return nil, err
 	}
 
+	// 在后台为新文章生成向量
+	go func() {
+		if err := s.embeddings.IndexPost(context.Background(), post); err != nil {
+			log.Printf("Failed to embed post %d: %v", post.ID, err)
+		}
+	}()
+	notifyFollowers(s.notifications, post)
+
 	return post, nil
 }
 
+// find 查找草稿
+func (s *DraftService) find(id uint) (*model.Draft, error) {
+	draft, err := s.draftRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+	return draft, nil
+}
+
+// authorize 通过策略检查用户能否对草稿执行操作。看不到草稿的用户得到 ErrDraftNotFound，
+// 个人草稿的其他用户得到 ErrUnauthorized
+func (s *DraftService) authorize(draft *model.Draft, userID uint, role string, action policy.Action) error {
+	subject := newSubject(userID, role)
+	if policy.Allowed(subject, action, draftResource(draft)) {
+		return nil
+	}
+	if draft.WorkspaceID == nil {
+		if draft.UserID != userID {
+			return ErrUnauthorized
+		}
+		return ErrDraftForbidden
+	}
+	if !policy.Allowed(subject, policy.DraftView, draftResource(draft)) {
+		return ErrDraftNotFound
+	}
+	return ErrWorkspaceForbidden
+}
+
 // 辅助函数：转换草稿为响应格式
 func convertDraftToResponse(draft *model.Draft) dto.DraftResponse {
 	response := dto.DraftResponse{
-		ID:         draft.ID,
-		Title:      draft.Title,
-		Content:    draft.Content,
-		Summary:    draft.Summary,
-		Cover:      draft.Cover,
-		CategoryID: draft.CategoryID,
-		CreatedAt:  draft.CreatedAt,
-		UpdatedAt:  draft.UpdatedAt,
+		ID:          draft.ID,
+		Title:       draft.Title,
+		Content:     draft.Content,
+		Summary:     draft.Summary,
+		Cover:       draft.Cover,
+		CategoryID:  draft.CategoryID,
+		WorkspaceID: draft.WorkspaceID,
+		CreatedAt:   draft.CreatedAt,
+		UpdatedAt:   draft.UpdatedAt,
 	}
 
 	// 添加分类信息
@@ -221,5 +303,9 @@ func convertDraftToResponse(draft *model.Draft) dto.DraftResponse {
 		})
 	}
 
+	if len(draft.Attachments) > 0 {
+		response.Attachments = dto.ConvertToAttachmentResponses(draft.Attachments)
+	}
+
 	return response
 }
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"log"
+	"notex/api/repository"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/embedding"
+	"strings"
+)
+
+// RetrievedChunk 检索到的文章分块
+type RetrievedChunk struct {
+	PostID     uint
+	ChunkIndex int
+	Content    string
+	Score      float64
+}
+
+type EmbeddingService struct {
+	repo         *repository.EmbeddingRepository
+	postRepo     *repository.PostRepository
+	provider     embedding.Provider
+	index        *embedding.Index
+	chunkSize    int
+	chunkOverlap int
+}
+
+// NewEmbeddingService 创建向量服务，provider 通常由 embedding.NewProvider 根据配置创建，测试时可以传入假的提供者
+func NewEmbeddingService(provider embedding.Provider, cfg *config.EmbeddingConfig) *EmbeddingService {
+	return &EmbeddingService{
+		repo:         repository.NewEmbeddingRepository(),
+		postRepo:     repository.NewPostRepository(),
+		provider:     provider,
+		index:        embedding.NewIndex(),
+		chunkSize:    cfg.ChunkSize,
+		chunkOverlap: cfg.ChunkOverlap,
+	}
+}
+
+// Warmup 从数据库加载已有向量，并为尚未生成向量的已发布公开文章补建索引
+func (s *EmbeddingService) Warmup(ctx context.Context) error {
+	embeddings, err := s.repo.ListByModel(s.provider.Name())
+	if err != nil {
+		return err
+	}
+
+	vectors := make(map[uint][][]float32)
+	for _, e := range embeddings {
+		vectors[e.PostID] = append(vectors[e.PostID], e.Vector)
+	}
+	for postID, chunks := range vectors {
+		s.index.Set(postID, chunks)
+	}
+
+	ids, err := s.repo.ListUnindexedPostIDs(s.provider.Name())
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		post, err := s.postRepo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if err := s.IndexPost(ctx, post); err != nil {
+			log.Printf("Failed to embed post %d: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+// IndexPost 为文章生成分块向量，未发布或非公开的文章会从索引中移除，不参与搜索、问答和相关推荐
+func (s *EmbeddingService) IndexPost(ctx context.Context, post *model.Post) error {
+	if !post.IsPublic() {
+		return s.RemovePost(post.ID)
+	}
+
+	// 标题和摘要作为第一个分块的前缀，使短查询也能命中
+	text := strings.TrimSpace(strings.Join([]string{post.Title, post.Summary, post.Content}, "\n\n"))
+	chunks := embedding.Chunk(text, s.chunkSize, s.chunkOverlap)
+	if len(chunks) == 0 {
+		return s.RemovePost(post.ID)
+	}
+
+	vectors, err := s.provider.Embed(ctx, chunks)
+	if err != nil {
+		return err
+	}
+
+	embeddings := make([]model.PostEmbedding, len(chunks))
+	for i, chunk := range chunks {
+		embeddings[i] = model.PostEmbedding{
+			PostID:     post.ID,
+			ChunkIndex: i,
+			Content:    chunk,
+			Vector:     vectors[i],
+			Model:      s.provider.Name(),
+		}
+	}
+	if err := s.repo.ReplacePostEmbeddings(post.ID, embeddings); err != nil {
+		return err
+	}
+
+	s.index.Set(post.ID, vectors)
+	return nil
+}
+
+// RemovePost 删除文章的向量
+func (s *EmbeddingService) RemovePost(postID uint) error {
+	s.index.Remove(postID)
+	return s.repo.DeleteByPostID(postID)
+}
+
+// Search 按语义相似度检索文章，limit 为 0 时返回全部正相关结果
+func (s *EmbeddingService) Search(ctx context.Context, query string, limit int) ([]embedding.Match, error) {
+	vectors, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	return s.index.Search(vectors[0], limit), nil
+}
+
+// Retrieve 检索与查询最相关的文章分块及其内容
+func (s *EmbeddingService) Retrieve(ctx context.Context, query string, limit int) ([]RetrievedChunk, error) {
+	vectors, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := s.index.SearchChunks(vectors[0], limit)
+	postIDs := make([]uint, 0, len(matches))
+	seen := make(map[uint]bool)
+	for _, match := range matches {
+		if !seen[match.ID] {
+			seen[match.ID] = true
+			postIDs = append(postIDs, match.ID)
+		}
+	}
+
+	embeddings, err := s.repo.ListByPostIDs(s.provider.Name(), postIDs)
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[uint]map[int]string)
+	for _, e := range embeddings {
+		if contents[e.PostID] == nil {
+			contents[e.PostID] = make(map[int]string)
+		}
+		contents[e.PostID][e.ChunkIndex] = e.Content
+	}
+
+	chunks := make([]RetrievedChunk, 0, len(matches))
+	for _, match := range matches {
+		content, ok := contents[match.ID][match.Chunk]
+		if !ok {
+			continue
+		}
+		chunks = append(chunks, RetrievedChunk{
+			PostID:     match.ID,
+			ChunkIndex: match.Chunk,
+			Content:    content,
+			Score:      match.Score,
+		})
+	}
+	return chunks, nil
+}
+
+// Related 获取与指定文章语义最相近的文章
+func (s *EmbeddingService) Related(postID uint, limit int) []embedding.Match {
+	return s.index.Nearest(postID, limit)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"notex/config"
+	"testing"
+)
+
+// fakeEmbedder 按预设的表返回向量，未知文本返回零向量
+type fakeEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (f *fakeEmbedder) Name() string {
+	return "fake"
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector, ok := f.vectors[text]
+		if !ok {
+			vector = []float32{0, 0, 0}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func TestEmbeddingServiceSearch(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"deploy":   {1, 0, 0},
+		"database": {0, 1, 0},
+	}}
+	svc := NewEmbeddingService(embedder, &config.EmbeddingConfig{ChunkSize: 800, ChunkOverlap: 100})
+	svc.index.Set(1, [][]float32{{1, 0, 0}})
+	svc.index.Set(2, [][]float32{{0, 0.6, 0.8}, {0, 1, 0}})
+	svc.index.Set(3, [][]float32{{0, 0, 1}})
+
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		{query: "deploy", want: []uint{1}},
+		{query: "database", want: []uint{2}},
+		{query: "unknown", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches, err := svc.Search(context.Background(), tt.query, 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(matches) != len(tt.want) {
+				t.Fatalf("matches = %+v, want IDs %v", matches, tt.want)
+			}
+			for i, id := range tt.want {
+				if matches[i].ID != id {
+					t.Fatalf("matches = %+v, want IDs %v", matches, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddingServiceRelated(t *testing.T) {
+	svc := NewEmbeddingService(&fakeEmbedder{}, &config.EmbeddingConfig{ChunkSize: 800})
+	svc.index.Set(1, [][]float32{{1, 0, 0}})
+	svc.index.Set(2, [][]float32{{0.9, 0.1, 0}})
+	svc.index.Set(3, [][]float32{{0, 0, 1}})
+
+	related := svc.Related(1, 1)
+	if len(related) != 1 || related[0].ID != 2 {
+		t.Fatalf("Related = %+v, want post 2", related)
+	}
+}
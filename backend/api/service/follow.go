@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFollowInvalidType    = errors.New("invalid follow target type")
+	ErrFollowTargetNotFound = errors.New("follow target not found")
+	ErrFollowSelf           = errors.New("cannot follow yourself")
+	ErrFeedInvalidCursor    = errors.New("invalid feed cursor")
+)
+
+// followTargetTypes 路由中的关注对象类型
+var followTargetTypes = map[string]string{
+	"users":      model.FollowTargetUser,
+	"tags":       model.FollowTargetTag,
+	"categories": model.FollowTargetCategory,
+}
+
+// FollowService 关注作者、标签和分类，以及由关注对象的新文章组成的关注动态
+type FollowService struct {
+	repo       *repository.FollowRepository
+	users      *repository.UserRepository
+	tags       *repository.TagRepository
+	categories *repository.CategoryRepository
+	posts      *PostService
+}
+
+func NewFollowService(posts *PostService) *FollowService {
+	return &FollowService{
+		repo:       repository.NewFollowRepository(),
+		users:      repository.NewUserRepository(),
+		tags:       repository.NewTagRepository(),
+		categories: repository.NewCategoryRepository(),
+		posts:      posts,
+	}
+}
+
+// Follow 关注作者、标签或全站分类，重复关注不报错
+func (s *FollowService) Follow(userID uint, kind string, targetID uint) (*dto.FollowStatus, error) {
+	targetType, err := s.findTarget(kind, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if targetType == model.FollowTargetUser && targetID == userID {
+		return nil, ErrFollowSelf
+	}
+
+	if _, err := s.repo.Create(targetType, userID, targetID); err != nil {
+		return nil, err
+	}
+	return s.status(targetType, targetID, true)
+}
+
+// Unfollow 取消关注，没有关注时不报错
+func (s *FollowService) Unfollow(userID uint, kind string, targetID uint) (*dto.FollowStatus, error) {
+	targetType, ok := followTargetTypes[kind]
+	if !ok {
+		return nil, ErrFollowInvalidType
+	}
+
+	if _, err := s.repo.Delete(targetType, userID, targetID); err != nil {
+		return nil, err
+	}
+	return s.status(targetType, targetID, false)
+}
+
+// GetFollowStatus 获取用户对关注对象的关注状态
+func (s *FollowService) GetFollowStatus(userID uint, kind string, targetID uint) (*dto.FollowStatus, error) {
+	targetType, err := s.findTarget(kind, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	following, err := s.repo.Exists(targetType, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(targetType, targetID, following)
+}
+
+// ListFollowing 获取用户关注的作者、标签或分类
+func (s *FollowService) ListFollowing(userID uint, kind string, query *dto.FollowListQuery) ([]dto.FollowResponse, int64, error) {
+	targetType, ok := followTargetTypes[kind]
+	if !ok {
+		return nil, 0, ErrFollowInvalidType
+	}
+
+	targets, total, err := s.repo.ListFollowing(targetType, userID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return convertFollowTargets(targets), total, nil
+}
+
+// ListFollowers 获取关注用户的人
+func (s *FollowService) ListFollowers(userID uint, query *dto.FollowListQuery) ([]dto.FollowResponse, int64, error) {
+	if _, err := s.findTarget("users", userID); err != nil {
+		return nil, 0, err
+	}
+
+	targets, total, err := s.repo.ListFollowers(model.FollowTargetUser, userID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return convertFollowTargets(targets), total, nil
+}
+
+// GetFeed 获取关注动态：关注的作者发布的文章以及关注的标签和分类下的新文章，
+// 只包括出现在公开列表中的文章，按发布时间倒序用游标分页
+func (s *FollowService) GetFeed(userID uint, query *dto.FeedQuery) (*dto.FeedResponse, error) {
+	var cursor *repository.FeedCursor
+	if query.Cursor != "" {
+		decoded, err := decodeFeedCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
+	}
+
+	// 多取一篇用于判断是否还有下一页
+	posts, err := s.repo.Feed(userID, listedVisibilities, cursor, query.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.FeedResponse{}
+	if len(posts) > query.Limit {
+		posts = posts[:query.Limit]
+		last := posts[len(posts)-1]
+		response.NextCursor = encodeFeedCursor(&repository.FeedCursor{PublishedAt: last.PublishedAt, ID: last.ID})
+	}
+
+	if response.Items, err = s.posts.convertListed(posts); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// findTarget 检查关注对象是否存在，返回关注对象的类型。工作区分类不能关注
+func (s *FollowService) findTarget(kind string, targetID uint) (string, error) {
+	targetType, ok := followTargetTypes[kind]
+	if !ok {
+		return "", ErrFollowInvalidType
+	}
+
+	var err error
+	switch targetType {
+	case model.FollowTargetUser:
+		_, err = s.users.FindByID(targetID)
+	case model.FollowTargetTag:
+		_, err = s.tags.FindByID(targetID)
+	case model.FollowTargetCategory:
+		var category *model.Category
+		category, err = s.categories.FindByID(targetID)
+		if err == nil && category.WorkspaceID != nil {
+			return "", ErrFollowTargetNotFound
+		}
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrFollowTargetNotFound
+		}
+		return "", err
+	}
+	return targetType, nil
+}
+
+// status 返回关注状态
+func (s *FollowService) status(targetType string, targetID uint, following bool) (*dto.FollowStatus, error) {
+	count, err := s.repo.CountFollowers(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.FollowStatus{Following: following, FollowerCount: count}, nil
+}
+
+// convertFollowTargets 将关注列表转换为响应
+func convertFollowTargets(targets []repository.FollowTarget) []dto.FollowResponse {
+	responses := make([]dto.FollowResponse, 0, len(targets))
+	for _, target := range targets {
+		responses = append(responses, dto.FollowResponse{
+			ID:         target.ID,
+			Name:       target.Name,
+			Avatar:     target.Avatar,
+			FollowedAt: target.FollowedAt,
+		})
+	}
+	return responses
+}
+
+// encodeFeedCursor 将分页位置编码为不透明的游标
+func encodeFeedCursor(cursor *repository.FeedCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.PublishedAt.UnixMicro(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor 解析 encodeFeedCursor 生成的游标
+func decodeFeedCursor(value string) (*repository.FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrFeedInvalidCursor
+	}
+
+	var micros int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micros, &id); err != nil {
+		return nil, ErrFeedInvalidCursor
+	}
+	return &repository.FeedCursor{PublishedAt: time.UnixMicro(micros).UTC(), ID: id}, nil
+}
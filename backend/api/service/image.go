@@ -0,0 +1,310 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"net/url"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/imaging"
+	"notex/pkg/storage"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrImageInvalidSignature = errors.New("invalid image signature")
+	ErrImageInvalidParams    = errors.New("invalid image key, width or format")
+	ErrImageNotFound         = errors.New("image not found")
+)
+
+// ImagePathPrefix 动态缩放接口的路径前缀
+const ImagePathPrefix = "/img/"
+
+// ImageUpload 图片上传处理结果
+type ImageUpload struct {
+	Result   *storage.UploadResult
+	Width    int
+	Height   int
+	Variants model.MediaVariants
+}
+
+// ImageService 处理上传图片的元数据、方向和尺寸变体，并提供带签名的动态缩放
+type ImageService struct {
+	storage       storage.Storage
+	config        *config.ImageConfig
+	thumbnailSize int
+	signingKey    []byte
+}
+
+func NewImageService(storage storage.Storage, cfg *config.ImageConfig, thumbnailSize int, signingKey string) *ImageService {
+	return &ImageService{
+		storage:       storage,
+		config:        cfg,
+		thumbnailSize: thumbnailSize,
+		signingKey:    []byte(signingKey),
+	}
+}
+
+// Upload 去除元数据并转正后上传原图，再生成缩略图和尺寸变体。
+// data 不是可识别的图片时返回 image.ErrFormat，调用方可按普通文件上传。
+func (s *ImageService) Upload(data []byte, filename, contentType string) (*ImageUpload, error) {
+	img, err := imaging.Normalize(data, s.config.MaxPixels, s.config.Quality)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.storage.Put(bytes.NewReader(img.Data), filename, contentType, int64(len(img.Data)))
+	if err != nil {
+		return nil, err
+	}
+	upload := &ImageUpload{Result: result, Width: img.Width, Height: img.Height}
+
+	// GIF 保持原样以保留动画
+	if img.Format == "gif" {
+		return upload, nil
+	}
+
+	decoded, err := img.Decode()
+	if err != nil {
+		log.Printf("Failed to decode image %s for variants: %v", result.Key, err)
+		return upload, nil
+	}
+
+	// 部分存储在上传时已生成缩略图
+	if result.ThumbnailURL == "" {
+		if thumbData, thumbType, err := storage.CreateThumbnail(img.Data, s.thumbnailSize); err != nil {
+			log.Printf("Failed to create thumbnail for %s: %v", result.Key, err)
+		} else if thumb, err := s.storage.Put(bytes.NewReader(thumbData), variantName(filename, "thumb", filepath.Ext(filename)), thumbType, int64(len(thumbData))); err != nil {
+			log.Printf("Failed to upload thumbnail for %s: %v", result.Key, err)
+		} else {
+			result.ThumbnailURL = thumb.URL
+		}
+	}
+
+	upload.Variants = s.createVariants(decoded, img.Format, filename)
+	return upload, nil
+}
+
+// createVariants 按配置的宽度生成原格式变体，并为额外格式生成各宽度及原尺寸的变体
+func (s *ImageService) createVariants(img image.Image, format, filename string) model.MediaVariants {
+	originalWidth := img.Bounds().Dx()
+	var widths []int
+	for _, width := range s.config.Widths {
+		if width < originalWidth {
+			widths = append(widths, width)
+		}
+	}
+
+	var variants model.MediaVariants
+	for _, width := range widths {
+		if variant, err := s.putVariant(img, width, format, filename); err != nil {
+			log.Printf("Failed to create %s variant of %s at width %d: %v", format, filename, width, err)
+		} else {
+			variants = append(variants, *variant)
+		}
+	}
+
+	for _, extra := range s.config.Formats {
+		extra = imaging.NormalizeFormat(extra)
+		if extra == format {
+			continue
+		}
+		if _, ok := imaging.LookupEncoder(extra); !ok {
+			continue
+		}
+		for _, width := range append(widths, originalWidth) {
+			if variant, err := s.putVariant(img, width, extra, filename); err != nil {
+				log.Printf("Failed to create %s variant of %s at width %d: %v", extra, filename, width, err)
+			} else {
+				variants = append(variants, *variant)
+			}
+		}
+	}
+
+	return variants
+}
+
+// putVariant 缩放并编码图片后上传
+func (s *ImageService) putVariant(img image.Image, width int, format, filename string) (*model.MediaVariant, error) {
+	encoder, ok := imaging.LookupEncoder(format)
+	if !ok {
+		return nil, imaging.ErrUnsupportedFormat
+	}
+
+	resized := imaging.Resize(img, width)
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, resized, s.config.Quality); err != nil {
+		return nil, err
+	}
+
+	name := variantName(filename, "w"+strconv.Itoa(width), encoder.Ext)
+	result, err := s.storage.Put(&buf, name, encoder.ContentType, int64(buf.Len()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MediaVariant{
+		Width:  resized.Bounds().Dx(),
+		Height: resized.Bounds().Dy(),
+		Format: imaging.NormalizeFormat(format),
+		Key:    result.Key,
+		URL:    result.URL,
+		Size:   result.Size,
+	}, nil
+}
+
+// DeleteVariants 删除图片的全部变体和动态缩放缓存
+func (s *ImageService) DeleteVariants(key string, variants model.MediaVariants) {
+	for _, variant := range variants {
+		if err := s.storage.Delete(variant.URL); err != nil {
+			log.Printf("Failed to delete image variant %s: %v", variant.Key, err)
+		}
+	}
+
+	if dir, err := s.cacheDir(key); err == nil {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Failed to purge image cache of %s: %v", key, err)
+		}
+	}
+}
+
+// Path 返回图片在动态缩放接口下的路径，用于识别内容中对图片的引用
+func (s *ImageService) Path(key string) string {
+	return ImagePathPrefix + key
+}
+
+// SignedURL 返回带签名的动态缩放URL，format 为空时保持原格式
+func (s *ImageService) SignedURL(key string, width int, format string) (string, error) {
+	format = imaging.NormalizeFormat(format)
+	if err := s.validate(key, width, format); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("w", strconv.Itoa(width))
+	if format != "" {
+		query.Set("fmt", format)
+	}
+	query.Set("sig", s.sign(key, width, format))
+	return s.Path(key) + "?" + query.Encode(), nil
+}
+
+// Render 校验签名后返回缩放结果的缓存文件路径和内容类型，缓存不存在时从存储读取原图生成
+func (s *ImageService) Render(key string, width int, format, signature string) (string, string, error) {
+	format = imaging.NormalizeFormat(format)
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, width, format))) {
+		return "", "", ErrImageInvalidSignature
+	}
+	if err := s.validate(key, width, format); err != nil {
+		return "", "", err
+	}
+
+	if format == "" {
+		format = imaging.OutputFormat(path.Ext(key))
+	}
+	encoder, ok := imaging.LookupEncoder(format)
+	if !ok {
+		return "", "", ErrImageInvalidParams
+	}
+
+	dir, err := s.cacheDir(key)
+	if err != nil {
+		return "", "", err
+	}
+	cachePath := filepath.Join(dir, fmt.Sprintf("w%d%s", width, encoder.Ext))
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, encoder.ContentType, nil
+	}
+
+	// 读取原图并缩放
+	reader, err := s.storage.Get(key)
+	if err != nil {
+		log.Printf("Failed to read image %s: %v", key, err)
+		return "", "", ErrImageNotFound
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return "", "", err
+	}
+
+	img, err := imaging.Normalize(data, s.config.MaxPixels, s.config.Quality)
+	if err != nil {
+		return "", "", err
+	}
+	decoded, err := img.Decode()
+	if err != nil {
+		return "", "", err
+	}
+
+	// 先写入临时文件再重命名，避免并发请求读到不完整的缓存
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".render-*")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := encoder.Encode(tmp, imaging.Resize(decoded, width), s.config.Quality); err != nil {
+		tmp.Close()
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmp.Name(), cachePath); err != nil {
+		return "", "", err
+	}
+
+	return cachePath, encoder.ContentType, nil
+}
+
+// validate 检查对象键、宽度和格式是否合法，format 需已规范化
+func (s *ImageService) validate(key string, width int, format string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "..") {
+		return ErrImageInvalidParams
+	}
+	if width <= 0 || width > s.config.MaxWidth {
+		return ErrImageInvalidParams
+	}
+	if format != "" {
+		if _, ok := imaging.LookupEncoder(format); !ok {
+			return ErrImageInvalidParams
+		}
+	}
+	return nil
+}
+
+// cacheDir 返回图片缓存所在目录
+func (s *ImageService) cacheDir(key string) (string, error) {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return "", ErrImageInvalidParams
+	}
+	return filepath.Join(s.config.CacheDir, filepath.FromSlash(key)), nil
+}
+
+// sign 计算动态缩放参数的签名
+func (s *ImageService) sign(key string, width int, format string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%d\n%s", key, width, format)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// variantName 生成变体的文件名，用于确定扩展名
+func variantName(filename, suffix, ext string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	return base + "_" + suffix + ext
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/policy"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPostUnpublished = errors.New("post is not published")
+	ErrReactForbidden  = errors.New("sign in to like or bookmark posts")
+)
+
+type LikeService struct {
+	repo          *repository.LikeRepository
+	posts         *repository.PostRepository
+	notifications *NotificationService
+}
+
+func NewLikeService(notifications *NotificationService) *LikeService {
+	return &LikeService{
+		repo:          repository.NewLikeRepository(),
+		posts:         repository.NewPostRepository(),
+		notifications: notifications,
+	}
+}
+
+// Like 点赞文章，重复点赞不报错；首次点赞时通知作者
+func (s *LikeService) Like(viewer *PostViewer, postID uint) (*dto.LikeResponse, error) {
+	post, err := findReactablePost(s.posts, viewer, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	like := &model.PostLike{PostID: post.ID, UserID: viewer.UserID}
+	created, err := s.repo.Create(like)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		// 通知失败不影响点赞
+		if err := s.notifications.CreateLikeNotification(like, post); err != nil {
+			log.Printf("Failed to create like notification for post %d: %v", post.ID, err)
+		}
+	}
+
+	return s.status(post.ID, true)
+}
+
+// Unlike 取消点赞，没有点赞时不报错
+func (s *LikeService) Unlike(viewer *PostViewer, postID uint) (*dto.LikeResponse, error) {
+	post, err := findReactablePost(s.posts, viewer, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Delete(post.ID, viewer.UserID); err != nil {
+		return nil, err
+	}
+	return s.status(post.ID, false)
+}
+
+// status 返回点赞状态
+func (s *LikeService) status(postID uint, liked bool) (*dto.LikeResponse, error) {
+	count, err := s.posts.GetLikeCount(postID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LikeResponse{Liked: liked, LikeCount: count}, nil
+}
+
+// findReactablePost 查找可以点赞和收藏的文章：访问者必须已登录、能看到文章，且文章已发布
+func findReactablePost(posts *repository.PostRepository, viewer *PostViewer, postID uint) (*model.Post, error) {
+	if err := policy.Authorize(viewer.policySubject(), policy.PostReact, nil); err != nil {
+		return nil, ErrReactForbidden
+	}
+
+	post, err := posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if err := checkPostAccess(post, viewer); err != nil {
+		return nil, err
+	}
+	if post.Status != "published" {
+		return nil, ErrPostUnpublished
+	}
+	return post, nil
+}
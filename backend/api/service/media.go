@@ -0,0 +1,775 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/policy"
+	"notex/pkg/scanner"
+	"notex/pkg/storage"
+	"notex/pkg/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMediaNotFound        = errors.New("media asset not found")
+	ErrMediaInUse           = errors.New("media asset is still referenced")
+	ErrMediaTooLarge        = errors.New("file exceeds the maximum upload size")
+	ErrMediaTypeNotAllowed  = errors.New("file type not allowed")
+	ErrMediaTypeMismatch    = errors.New("file content does not match its declared type")
+	ErrMediaInfected        = errors.New("file was rejected by the security scanner")
+	ErrMediaScanUnavailable = errors.New("security scanner is unavailable")
+)
+
+// mediaURLPattern 匹配内容中的绝对URL和以 / 开头的相对路径
+var mediaURLPattern = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+|/[^\s"'<>()\[\]]+`)
+
+type MediaService struct {
+	repo          *repository.MediaRepository
+	storage       storage.Storage
+	storageConfig *types.StorageConfig
+	images        *ImageService
+	scanner       scanner.Scanner
+	scannerConfig *config.ScannerConfig
+	config        *config.MediaConfig
+}
+
+// NewMediaService 创建媒体库服务，scanner 为 nil 时不扫描上传文件
+func NewMediaService(storage storage.Storage, storageConfig *types.StorageConfig, images *ImageService, scanner scanner.Scanner, scannerConfig *config.ScannerConfig, config *config.MediaConfig) *MediaService {
+	return &MediaService{
+		repo:          repository.NewMediaRepository(),
+		storage:       storage,
+		storageConfig: storageConfig,
+		images:        images,
+		scanner:       scanner,
+		scannerConfig: scannerConfig,
+		config:        config,
+	}
+}
+
+// Upload 校验并扫描文件后上传到媒体库。同一存储中内容相同的文件只保存一份，
+// 受感染的文件移入隔离目录并返回 ErrMediaInfected，图片会经过处理并生成尺寸变体
+func (s *MediaService) Upload(ctx context.Context, userID uint, file multipart.File, header *multipart.FileHeader) (*dto.MediaUploadResponse, error) {
+	return s.Import(ctx, userID, file, header.Filename, header.Header.Get("Content-Type"))
+}
+
+// Import 从 reader 读取文件并按 Upload 的规则校验和保存，declared 为客户端声明的内容类型
+func (s *MediaService) Import(ctx context.Context, userID uint, reader io.Reader, filename, declared string) (*dto.MediaUploadResponse, error) {
+	content, err := readUpload(reader, s.storageConfig.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := s.detectContentType(content.head, filename, declared)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store(ctx, userID, content, filename, contentType)
+}
+
+// uploadContent 已检查大小的上传内容，保存时可以多次从头读取
+type uploadContent struct {
+	reader   io.ReadSeeker
+	size     int64
+	checksum string
+	head     []byte // 开头最多 512 字节，用于识别内容类型
+}
+
+// readUpload 检查上传内容不超过 maxSize 并计算 SHA-256 校验和。可定位的 reader（暂存文件、multipart.File）
+// 保存时直接从原处读取，不会整个载入内存；其他 reader 先读入内存
+func readUpload(reader io.Reader, maxSize int64) (*uploadContent, error) {
+	seeker, ok := reader.(io.ReadSeeker)
+	if !ok {
+		// 多读一个字节用于判断是否超过大小限制
+		data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		seeker = bytes.NewReader(data)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, io.LimitReader(seeker, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	content := &uploadContent{reader: seeker, size: size, checksum: hex.EncodeToString(hash.Sum(nil))}
+	if err := content.rewind(); err != nil {
+		return nil, err
+	}
+	head := make([]byte, min(size, 512))
+	if _, err := io.ReadFull(seeker, head); err != nil {
+		return nil, err
+	}
+	content.head = head
+	return content, content.rewind()
+}
+
+// rewind 回到内容开头
+func (c *uploadContent) rewind() error {
+	_, err := c.reader.Seek(0, io.SeekStart)
+	return err
+}
+
+// bytes 将内容读入内存，只用于需要解码的图片
+func (c *uploadContent) bytes() ([]byte, error) {
+	if err := c.rewind(); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(c.reader)
+}
+
+// MaxSize 返回媒体库允许的最大文件大小
+func (s *MediaService) MaxSize() int64 {
+	return s.storageConfig.MaxSize
+}
+
+// store 保存已校验类型的文件：复用内容相同的文件，扫描后上传并创建媒体文件记录。
+// 图片需要解码处理，读入内存；其他文件从 content 流式上传
+func (s *MediaService) store(ctx context.Context, userID uint, content *uploadContent, filename, contentType string) (*dto.MediaUploadResponse, error) {
+	checksum := content.checksum
+	// 复用同一存储中内容相同的文件
+	if existing, err := s.repo.FindByChecksum(string(s.storage.GetType()), checksum); err == nil {
+		return s.reuse(userID, existing, filename)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	scanStatus, err := s.scan(ctx, userID, content, filename, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := s.upload(content, filename, contentType)
+	if err != nil {
+		return nil, err
+	}
+	result := upload.Result
+
+	asset := &model.MediaAsset{
+		UserID:       userID,
+		StorageType:  string(s.storage.GetType()),
+		Key:          result.Key,
+		URL:          result.URL,
+		ThumbnailURL: result.ThumbnailURL,
+		Filename:     result.Filename,
+		Size:         result.Size,
+		MimeType:     contentType,
+		Width:        upload.Width,
+		Height:       upload.Height,
+		Checksum:     checksum,
+		Variants:     upload.Variants,
+		ScanStatus:   scanStatus,
+	}
+	if err := s.repo.Create(asset); err != nil {
+		return nil, err
+	}
+
+	return &dto.MediaUploadResponse{
+		UploadResult: result,
+		AssetID:      asset.ID,
+		Width:        asset.Width,
+		Height:       asset.Height,
+		Variants:     asset.Variants,
+	}, nil
+}
+
+// storePrivate 将已校验类型的文件保存到私有目录并创建媒体文件记录。私有文件没有URL，只能通过 open 读取；
+// 内容相同的私有文件只保存一份，不会复用公开存储中的文件
+func (s *MediaService) storePrivate(ctx context.Context, userID uint, content *uploadContent, filename, contentType string) (*dto.MediaUploadResponse, error) {
+	checksum := content.checksum
+	if existing, err := s.repo.FindByChecksum(model.MediaStoragePrivate, checksum); err == nil {
+		return s.reuse(userID, existing, filename)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	scanStatus, err := s.scan(ctx, userID, content, filename, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writePrivate(content); err != nil {
+		return nil, err
+	}
+
+	asset := &model.MediaAsset{
+		UserID:      userID,
+		StorageType: model.MediaStoragePrivate,
+		Key:         checksum,
+		Filename:    filepath.Base(filename),
+		Size:        content.size,
+		MimeType:    contentType,
+		Checksum:    checksum,
+		ScanStatus:  scanStatus,
+	}
+	if err := s.repo.Create(asset); err != nil {
+		return nil, err
+	}
+
+	return &dto.MediaUploadResponse{
+		UploadResult: &storage.UploadResult{
+			Key:      asset.Key,
+			Filename: asset.Filename,
+			Size:     asset.Size,
+			Type:     asset.MimeType,
+		},
+		AssetID: asset.ID,
+	}, nil
+}
+
+// writePrivate 将文件写入私有目录，以内容的校验和命名，写完后再移动到位以免读到不完整的文件
+func (s *MediaService) writePrivate(content *uploadContent) error {
+	if err := os.MkdirAll(s.config.PrivateDir, 0700); err != nil {
+		return err
+	}
+	if err := content.rewind(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.config.PrivateDir, content.checksum+"-*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, content.reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(s.config.PrivateDir, content.checksum))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// detectContentType 根据文件内容识别真实类型，并检查其与声明的类型和扩展名是否一致
+func (s *MediaService) detectContentType(data []byte, filename, declared string) (string, error) {
+	detected := baseMediaType(http.DetectContentType(data))
+	declared = baseMediaType(declared)
+	byExt := baseMediaType(mime.TypeByExtension(filepath.Ext(filename)))
+
+	// 纯文本无法进一步识别，信任声明的具体文本类型（HTML 除外）
+	if detected == "text/plain" {
+		for _, candidate := range []string{declared, byExt} {
+			if strings.HasPrefix(candidate, "text/") && candidate != "text/html" {
+				detected = candidate
+				break
+			}
+		}
+	}
+
+	allowed := false
+	for _, t := range s.storageConfig.AllowedTypes {
+		if sameMediaType(t, detected) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", ErrMediaTypeNotAllowed
+	}
+
+	if declared != "" && declared != "application/octet-stream" && !sameMediaType(declared, detected) {
+		return "", ErrMediaTypeMismatch
+	}
+	if byExt != "" && !sameMediaType(byExt, detected) {
+		return "", ErrMediaTypeMismatch
+	}
+
+	return detected, nil
+}
+
+// upload 上传文件内容，可识别的图片经过处理并生成尺寸变体
+func (s *MediaService) upload(content *uploadContent, filename, contentType string) (*ImageUpload, error) {
+	if strings.HasPrefix(contentType, "image/") {
+		data, err := content.bytes()
+		if err != nil {
+			return nil, err
+		}
+		upload, err := s.images.Upload(data, filename, contentType)
+		if !errors.Is(err, image.ErrFormat) {
+			return upload, err
+		}
+		// 不是可识别的图片（如 SVG），按普通文件保存
+	}
+
+	if err := content.rewind(); err != nil {
+		return nil, err
+	}
+	result, err := s.storage.Put(content.reader, filename, contentType, content.size)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageUpload{Result: result}, nil
+}
+
+// reuse 复用已存储的文件，同一用户直接返回原记录，其他用户创建共用存储对象的新记录
+func (s *MediaService) reuse(userID uint, existing *model.MediaAsset, filename string) (*dto.MediaUploadResponse, error) {
+	asset := existing
+	if existing.UserID != userID {
+		asset = &model.MediaAsset{
+			UserID:        userID,
+			StorageType:   existing.StorageType,
+			Key:           existing.Key,
+			URL:           existing.URL,
+			ThumbnailURL:  existing.ThumbnailURL,
+			Filename:      filename,
+			Size:          existing.Size,
+			MimeType:      existing.MimeType,
+			Width:         existing.Width,
+			Height:        existing.Height,
+			Checksum:      existing.Checksum,
+			Variants:      existing.Variants,
+			ScanStatus:    existing.ScanStatus,
+			ScanSignature: existing.ScanSignature,
+		}
+		if err := s.repo.Create(asset); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.MediaUploadResponse{
+		UploadResult: &storage.UploadResult{
+			URL:          asset.URL,
+			Key:          asset.Key,
+			ThumbnailURL: asset.ThumbnailURL,
+			Filename:     asset.Filename,
+			Size:         asset.Size,
+			Type:         asset.MimeType,
+		},
+		AssetID:      asset.ID,
+		Width:        asset.Width,
+		Height:       asset.Height,
+		Variants:     asset.Variants,
+		Deduplicated: true,
+	}, nil
+}
+
+// scan 扫描文件内容，受感染的文件移入隔离目录，返回应记录的扫描状态
+func (s *MediaService) scan(ctx context.Context, userID uint, content *uploadContent, filename, contentType string) (string, error) {
+	if s.scanner == nil {
+		return model.MediaScanUnscanned, nil
+	}
+
+	if err := content.rewind(); err != nil {
+		return "", err
+	}
+	result, err := s.scanner.Scan(ctx, content.reader)
+	if err != nil {
+		log.Printf("Failed to scan upload %s with %s: %v", filename, s.scanner.Name(), err)
+		if s.scannerConfig.FailOpen {
+			return model.MediaScanFailed, nil
+		}
+		return "", ErrMediaScanUnavailable
+	}
+	if !result.Infected {
+		return model.MediaScanClean, nil
+	}
+
+	log.Printf("Quarantined upload %s from user %d: %s", filename, userID, result.Signature)
+	if err := s.quarantine(userID, content, filename, contentType, result.Signature); err != nil {
+		log.Printf("Failed to quarantine upload %s: %v", filename, err)
+	}
+	return "", ErrMediaInfected
+}
+
+// quarantine 将受感染的文件保存到隔离目录并记录，隔离文件不会对外提供访问
+func (s *MediaService) quarantine(userID uint, content *uploadContent, filename, contentType, signature string) error {
+	if err := os.MkdirAll(s.scannerConfig.QuarantineDir, 0700); err != nil {
+		return err
+	}
+	if err := content.rewind(); err != nil {
+		return err
+	}
+	checksum := content.checksum
+	file, err := os.OpenFile(filepath.Join(s.scannerConfig.QuarantineDir, checksum), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, content.reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.repo.Create(&model.MediaAsset{
+		UserID:        userID,
+		StorageType:   model.MediaStorageQuarantine,
+		Key:           checksum,
+		Filename:      filename,
+		Size:          content.size,
+		MimeType:      contentType,
+		Checksum:      checksum,
+		ScanStatus:    model.MediaScanInfected,
+		ScanSignature: signature,
+		QuarantinedAt: &now,
+	})
+}
+
+// ListAssets 获取用户自己的媒体文件
+func (s *MediaService) ListAssets(userID uint, req *dto.MediaListRequest) (*dto.MediaListResponse, error) {
+	req.UserID = userID
+	return s.AdminListAssets(req)
+}
+
+// AdminListAssets 获取全部用户的媒体文件
+func (s *MediaService) AdminListAssets(req *dto.MediaListRequest) (*dto.MediaListResponse, error) {
+	assets, total, err := s.repo.List(req.Page, req.PageSize, repository.MediaListFilter{
+		UserID:   req.UserID,
+		Search:   req.Search,
+		MimeType: req.MimeType,
+		Orphaned: req.Orphaned,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.MediaAssetResponse, len(assets))
+	for i := range assets {
+		items[i] = dto.ConvertToMediaAssetResponse(&assets[i])
+	}
+
+	return &dto.MediaListResponse{
+		Total: total,
+		Items: items,
+	}, nil
+}
+
+// GetAsset 获取媒体文件详情，管理员以外的用户只能查看自己的文件
+func (s *MediaService) GetAsset(userID uint, role string, id uint) (*dto.MediaAssetResponse, error) {
+	asset, err := s.findAsset(userID, role, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.ConvertToMediaAssetResponse(asset)
+	return &resp, nil
+}
+
+// DeleteAsset 删除无引用的媒体文件，管理员以外的用户只能删除自己的文件
+func (s *MediaService) DeleteAsset(userID uint, role string, id uint) error {
+	asset, err := s.findAsset(userID, role, id)
+	if err != nil {
+		return err
+	}
+	if asset.RefCount > 0 {
+		return ErrMediaInUse
+	}
+
+	return s.remove(asset)
+}
+
+// ImageURL 返回图片指定宽度和格式的签名动态缩放URL
+func (s *MediaService) ImageURL(userID uint, role string, id uint, width int, format string) (string, error) {
+	asset, err := s.findAsset(userID, role, id)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(asset.MimeType, "image/") {
+		return "", ErrImageInvalidParams
+	}
+	return s.images.SignedURL(asset.Key, width, format)
+}
+
+// findAsset 查找媒体文件并检查用户能否管理该文件
+func (s *MediaService) findAsset(userID uint, role string, id uint) (*model.MediaAsset, error) {
+	asset, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	if err := policy.Authorize(newSubject(userID, role), policy.MediaManage, &policy.Resource{OwnerID: asset.UserID}); err != nil {
+		return nil, ErrUnauthorized
+	}
+	return asset, nil
+}
+
+// open 从当前存储或私有目录读取媒体文件，隔离文件或保存在其他存储中的文件无法读取
+func (s *MediaService) open(asset *model.MediaAsset) (io.ReadCloser, error) {
+	if asset.StorageType == model.MediaStoragePrivate {
+		file, err := os.Open(filepath.Join(s.config.PrivateDir, asset.Key))
+		if os.IsNotExist(err) {
+			return nil, ErrMediaNotFound
+		}
+		return file, err
+	}
+	if asset.QuarantinedAt != nil || asset.StorageType != string(s.storage.GetType()) {
+		return nil, ErrMediaNotFound
+	}
+	return s.storage.Get(asset.Key)
+}
+
+// remove 从存储和数据库中删除媒体文件，存储对象仍被其他记录共用时只删除记录
+func (s *MediaService) remove(asset *model.MediaAsset) error {
+	shared, err := s.repo.CountSharedKey(asset.StorageType, asset.Key, asset.ID)
+	if err != nil {
+		return err
+	}
+	if shared > 0 {
+		return s.repo.Delete(asset.ID)
+	}
+
+	if asset.StorageType == model.MediaStorageQuarantine {
+		if err := os.Remove(filepath.Join(s.scannerConfig.QuarantineDir, asset.Key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.repo.Delete(asset.ID)
+	}
+
+	if asset.StorageType == model.MediaStoragePrivate {
+		if err := os.Remove(filepath.Join(s.config.PrivateDir, asset.Key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.repo.Delete(asset.ID)
+	}
+
+	if asset.StorageType != string(s.storage.GetType()) {
+		return errors.New("media asset is stored in " + asset.StorageType + " which is not the active storage")
+	}
+
+	if err := s.storage.Delete(asset.URL); err != nil {
+		return err
+	}
+	if asset.ThumbnailURL != "" && asset.ThumbnailURL != asset.URL {
+		if err := s.storage.Delete(asset.ThumbnailURL); err != nil {
+			log.Printf("Failed to delete thumbnail of media asset %d: %v", asset.ID, err)
+		}
+	}
+	s.images.DeleteVariants(asset.Key, asset.Variants)
+
+	return s.repo.Delete(asset.ID)
+}
+
+// mediaURLColumns 直接保存文件URL的字段，扫描引用时与文章和草稿一起计入
+var mediaURLColumns = []struct {
+	refType string
+	field   string
+	table   interface{}
+	column  string
+}{
+	{model.MediaRefTypeUser, model.MediaRefFieldAvatar, &model.User{}, "avatar"},
+	{model.MediaRefTypeAIImage, model.MediaRefFieldURL, &model.AIImage{}, "url"},
+	{model.MediaRefTypeAIImage, model.MediaRefFieldThumbnail, &model.AIImage{}, "thumbnail_url"},
+	{model.MediaRefTypeCategory, model.MediaRefFieldCover, &model.Category{}, "cover"},
+}
+
+// ScanReferences 扫描全部文章和草稿的内容、封面及附件，以及用户头像等直接保存文件URL的字段，重建文件引用关系
+func (s *MediaService) ScanReferences() (*dto.MediaScanResponse, error) {
+	assets, err := s.repo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// 去重后多条记录可能共用同一URL，引用同时计入这些记录
+	byURL := make(map[string][]uint, len(assets)*2)
+	for _, asset := range assets {
+		// 私有文件没有URL，只通过附件关联
+		if asset.StorageType == model.MediaStoragePrivate {
+			continue
+		}
+		byURL[asset.URL] = append(byURL[asset.URL], asset.ID)
+		if asset.ThumbnailURL != "" && asset.ThumbnailURL != asset.URL {
+			byURL[asset.ThumbnailURL] = append(byURL[asset.ThumbnailURL], asset.ID)
+		}
+		for _, variant := range asset.Variants {
+			byURL[variant.URL] = append(byURL[variant.URL], asset.ID)
+		}
+		if asset.Key != "" {
+			path := s.images.Path(asset.Key)
+			byURL[path] = append(byURL[path], asset.ID)
+		}
+	}
+
+	refs := make([]model.MediaReference, 0)
+	referenced := make(map[uint]bool)
+	collect := func(refType string) func(doc repository.MediaDocument) {
+		return func(doc repository.MediaDocument) {
+			seen := make(map[uint]bool)
+			for _, candidate := range mediaURLPattern.FindAllString(doc.Content, -1) {
+				for _, id := range lookupMediaURL(byURL, candidate) {
+					if !seen[id] {
+						seen[id] = true
+						referenced[id] = true
+						refs = append(refs, model.MediaReference{AssetID: id, RefType: refType, RefID: doc.ID, Field: model.MediaRefFieldContent})
+					}
+				}
+			}
+			for _, id := range lookupMediaURL(byURL, doc.Cover) {
+				referenced[id] = true
+				refs = append(refs, model.MediaReference{AssetID: id, RefType: refType, RefID: doc.ID, Field: model.MediaRefFieldCover})
+			}
+		}
+	}
+
+	if err := s.repo.EachPost(collect(model.MediaRefTypePost)); err != nil {
+		return nil, err
+	}
+	if err := s.repo.EachDraft(collect(model.MediaRefTypeDraft)); err != nil {
+		return nil, err
+	}
+
+	// 附件直接关联媒体文件
+	attachments, err := s.repo.ListBoundAttachments()
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		referenced[attachment.AssetID] = true
+		ref := model.MediaReference{AssetID: attachment.AssetID, Field: model.MediaRefFieldAttachment}
+		if attachment.PostID != nil {
+			ref.RefType, ref.RefID = model.MediaRefTypePost, *attachment.PostID
+		} else {
+			ref.RefType, ref.RefID = model.MediaRefTypeDraft, *attachment.DraftID
+		}
+		refs = append(refs, ref)
+	}
+
+	// 头像、生成图片等字段直接保存文件URL
+	for _, source := range mediaURLColumns {
+		rows, err := s.repo.ListURLColumn(source.table, source.column)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			for _, id := range lookupMediaURL(byURL, row.URL) {
+				referenced[id] = true
+				refs = append(refs, model.MediaReference{AssetID: id, RefType: source.refType, RefID: row.ID, Field: source.field})
+			}
+		}
+	}
+
+	if err := s.repo.ReplaceReferences(refs, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &dto.MediaScanResponse{
+		Assets:     len(assets),
+		References: len(refs),
+		Orphaned:   len(assets) - len(referenced),
+	}, nil
+}
+
+// CollectGarbage 删除无引用时间超过保留期的文件
+func (s *MediaService) CollectGarbage() (*dto.MediaGCResponse, error) {
+	assets, err := s.repo.ListExpiredOrphans(time.Now().Add(-s.config.GracePeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := 0
+	for i := range assets {
+		if err := s.remove(&assets[i]); err != nil {
+			log.Printf("Failed to delete orphaned media asset %d: %v", assets[i].ID, err)
+			continue
+		}
+		deleted++
+	}
+
+	return &dto.MediaGCResponse{Deleted: deleted}, nil
+}
+
+// Run 定期扫描引用并清理无引用的文件，直到 ctx 结束
+func (s *MediaService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ScanReferences(); err != nil {
+				log.Printf("Failed to scan media references: %v", err)
+				continue
+			}
+			if _, err := s.CollectGarbage(); err != nil {
+				log.Printf("Failed to collect orphaned media: %v", err)
+			}
+		}
+	}
+}
+
+// lookupMediaURL 在文件URL表中查找候选URL，绝对URL同时尝试匹配其路径部分
+func lookupMediaURL(byURL map[string][]uint, candidate string) []uint {
+	if candidate == "" {
+		return nil
+	}
+	if ids, ok := byURL[candidate]; ok {
+		return ids
+	}
+
+	u, err := url.Parse(candidate)
+	if err != nil {
+		return nil
+	}
+	stripped := *u
+	stripped.RawQuery, stripped.Fragment = "", ""
+	if ids, ok := byURL[stripped.String()]; ok {
+		return ids
+	}
+	if u.IsAbs() && strings.HasPrefix(u.Path, "/") {
+		if ids, ok := byURL[u.Path]; ok {
+			return ids
+		}
+	}
+	return nil
+}
+
+// baseMediaType 去除内容类型中的参数并转为小写
+func baseMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// sameMediaType 判断两个内容类型是否等价，兼容常见的非标准别名
+func sameMediaType(a, b string) bool {
+	aliases := map[string]string{
+		"image/jpg":   "image/jpeg",
+		"image/pjpeg": "image/jpeg",
+		"image/x-png": "image/png",
+	}
+	a, b = baseMediaType(a), baseMediaType(b)
+	if alias, ok := aliases[a]; ok {
+		a = alias
+	}
+	if alias, ok := aliases[b]; ok {
+		b = alias
+	}
+	return a == b
+}
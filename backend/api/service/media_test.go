@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"notex/config"
+	"notex/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadUpload(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		maxSize int64
+		wantErr error
+	}{
+		{name: "within limit", data: "hello", maxSize: 10},
+		{name: "exact limit", data: "hello", maxSize: 5},
+		{name: "empty", data: "", maxSize: 5},
+		{name: "too large", data: "hello!", maxSize: 5, wantErr: ErrMediaTooLarge},
+	}
+
+	// 不可定位的 reader 读入内存，文件直接从原处读取
+	readers := map[string]func(t *testing.T, data string) io.Reader{
+		"stream": func(t *testing.T, data string) io.Reader {
+			return io.MultiReader(strings.NewReader(data))
+		},
+		"file": func(t *testing.T, data string) io.Reader {
+			path := filepath.Join(t.TempDir(), "upload")
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { file.Close() })
+			return file
+		},
+	}
+
+	for kind, newReader := range readers {
+		for _, tt := range tests {
+			t.Run(kind+" "+tt.name, func(t *testing.T) {
+				content, err := readUpload(newReader(t, tt.data), tt.maxSize)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("readUpload error = %v, want %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				sum := sha256.Sum256([]byte(tt.data))
+				if content.size != int64(len(tt.data)) || content.checksum != hex.EncodeToString(sum[:]) {
+					t.Fatalf("readUpload = %d bytes, %s", content.size, content.checksum)
+				}
+				if !bytes.Equal(content.head, []byte(tt.data)) {
+					t.Fatalf("head = %q, want %q", content.head, tt.data)
+				}
+				// 内容可以多次从头读取
+				for i := 0; i < 2; i++ {
+					data, err := content.bytes()
+					if err != nil || string(data) != tt.data {
+						t.Fatalf("bytes() = %q, %v", data, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestReadUploadHead(t *testing.T) {
+	data := strings.Repeat("a", 600)
+	content, err := readUpload(strings.NewReader(data), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content.head) != 512 {
+		t.Fatalf("head length = %d, want 512", len(content.head))
+	}
+	rest, err := io.ReadAll(content.reader)
+	if err != nil || string(rest) != data {
+		t.Fatalf("reader is not rewound after readUpload: read %d bytes, %v", len(rest), err)
+	}
+}
+
+func TestPrivateFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "private")
+	s := &MediaService{config: &config.MediaConfig{PrivateDir: dir}}
+
+	content, err := readUpload(io.MultiReader(strings.NewReader("secret")), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一内容写入两次只保留一个文件
+	for i := 0; i < 2; i++ {
+		if err := s.writePrivate(content); err != nil {
+			t.Fatalf("writePrivate: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || entries[0].Name() != content.checksum {
+		t.Fatalf("private dir = %v, %v", entries, err)
+	}
+
+	reader, err := s.open(&model.MediaAsset{StorageType: model.MediaStoragePrivate, Key: content.checksum})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil || string(data) != "secret" {
+		t.Fatalf("open read %q, %v", data, err)
+	}
+	if _, ok := reader.(io.ReadSeekCloser); !ok {
+		t.Fatal("private file does not support range requests")
+	}
+
+	if _, err := s.open(&model.MediaAsset{StorageType: model.MediaStoragePrivate, Key: "missing"}); !errors.Is(err, ErrMediaNotFound) {
+		t.Fatalf("open missing = %v, want ErrMediaNotFound", err)
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"notex/model"
+	"regexp"
+	"strings"
+)
+
+// mentionPattern 匹配 @用户名，@ 前不能是字母或数字，避免把邮箱地址当作提醒
+var mentionPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_])@([\p{L}\p{N}_.\-]+)`)
+
+// mentionMatch 内容中的一个 @用户名
+type mentionMatch struct {
+	start, end int // @用户名 在内容中的位置，不包括结尾的标点
+	username   string
+}
+
+// findMentions 按出现顺序找出内容中的 @用户名，用户名结尾的 . 和 - 视为标点
+func findMentions(content string) []mentionMatch {
+	var matches []mentionMatch
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(content, -1) {
+		username := strings.TrimRight(content[loc[4]:loc[5]], ".-")
+		if username == "" {
+			continue
+		}
+		start := loc[4] - 1
+		matches = append(matches, mentionMatch{start: start, end: loc[4] + len(username), username: username})
+	}
+	return matches
+}
+
+// parseMentions 返回内容中 @ 到的用户名，去重后最多 limit 个
+func parseMentions(content string, limit int) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, match := range findMentions(content) {
+		if len(usernames) >= limit {
+			break
+		}
+		if !seen[match.username] {
+			seen[match.username] = true
+			usernames = append(usernames, match.username)
+		}
+	}
+	return usernames
+}
+
+// renderMentions 将评论内容转义为 HTML，并把 @ 到的用户链接到其主页
+func renderMentions(content string, mentions []model.User) string {
+	users := make(map[string]uint, len(mentions))
+	for _, user := range mentions {
+		users[user.Username] = user.ID
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range findMentions(content) {
+		id, ok := users[match.username]
+		if !ok {
+			continue
+		}
+		b.WriteString(html.EscapeString(content[last:match.start]))
+		fmt.Fprintf(&b, `<a href="/users/%d" class="mention">@%s</a>`, id, html.EscapeString(match.username))
+		last = match.end
+	}
+	b.WriteString(html.EscapeString(content[last:]))
+	return b.String()
+}
@@ -1,16 +1,29 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"notex/api/dto"
 	"notex/api/repository"
 	"notex/model"
+	"notex/pkg/policy"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// likeNotificationWindow 同一篇文章在该时间内的点赞聚合到同一条未读通知中
+const likeNotificationWindow = 24 * time.Hour
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
 type NotificationService struct {
 	repo        *repository.NotificationRepository
 	postRepo    *repository.PostRepository
 	commentRepo *repository.CommentRepository
+	likeRepo    *repository.LikeRepository
+	followRepo  *repository.FollowRepository
 }
 
 func NewNotificationService() *NotificationService {
@@ -18,6 +31,8 @@ func NewNotificationService() *NotificationService {
 		repo:        repository.NewNotificationRepository(),
 		postRepo:    repository.NewPostRepository(),
 		commentRepo: repository.NewCommentRepository(),
+		likeRepo:    repository.NewLikeRepository(),
+		followRepo:  repository.NewFollowRepository(),
 	}
 }
 
@@ -52,11 +67,11 @@ func (s *NotificationService) CreateReplyNotification(actorID, parentCommentID,
 		return err
 	}
 
-	// 如果回复者不是父评论作者，则创建通知
-	if actorID != parentComment.UserID {
+	// 如果回复者不是父评论作者，则创建通知，访客没有账户，不会收到通知
+	if !parentComment.IsGuest() && actorID != parentComment.AuthorID() {
 		notification := &model.Notification{
 			Type:      model.NotificationTypeCommentReply,
-			UserID:    parentComment.UserID,
+			UserID:    parentComment.AuthorID(),
 			ActorID:   actorID,
 			PostID:    &parentComment.PostID,
 			CommentID: &commentID,
@@ -67,6 +82,95 @@ func (s *NotificationService) CreateReplyNotification(actorID, parentCommentID,
 	return nil
 }
 
+// CreateLikeNotification 创建点赞通知。作者尚未读过的点赞通知在聚合时间内会被合并，
+// 合并后的人数按通知创建以来点赞文章的人数重新计算，反复取消和点赞不会重复计数
+func (s *NotificationService) CreateLikeNotification(like *model.PostLike, post *model.Post) error {
+	if like.UserID == post.UserID {
+		return nil
+	}
+
+	existing, err := s.repo.FindUnread(post.UserID, model.NotificationTypePostLike, post.ID, time.Now().Add(-likeNotificationWindow))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		notification := &model.Notification{
+			Type:       model.NotificationTypePostLike,
+			UserID:     post.UserID,
+			ActorID:    like.UserID,
+			PostID:     &post.ID,
+			Content:    fmt.Sprintf("赞了你的文章《%s》", post.Title),
+			ActorCount: 1,
+			CreatedAt:  like.CreatedAt, // 与点赞时间一致，合并时以此统计之后的点赞
+		}
+		return s.repo.Create(notification)
+	}
+	if err != nil {
+		return err
+	}
+
+	count, err := s.likeRepo.CountSince(post.ID, existing.CreatedAt, post.UserID)
+	if err != nil {
+		return err
+	}
+	if count < 1 {
+		count = 1
+	}
+	existing.ActorID = like.UserID
+	existing.ActorCount = int(count)
+	existing.Content = fmt.Sprintf("等 %d 人赞了你的文章《%s》", count, post.Title)
+	if count == 1 {
+		existing.Content = fmt.Sprintf("赞了你的文章《%s》", post.Title)
+	}
+	return s.repo.Update(existing)
+}
+
+// CreateFollowedPostNotifications 通知作者的关注者有新文章发布，只通知出现在公开列表中的文章
+func (s *NotificationService) CreateFollowedPostNotifications(post *model.Post) error {
+	if post.Status != "published" || !slices.Contains(listedVisibilities, post.Visibility) {
+		return nil
+	}
+
+	followerIDs, err := s.followRepo.FollowerIDs(post.UserID)
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]model.Notification, 0, len(followerIDs))
+	for _, followerID := range followerIDs {
+		notifications = append(notifications, model.Notification{
+			Type:    model.NotificationTypeFollowedPost,
+			UserID:  followerID,
+			ActorID: post.UserID,
+			PostID:  &post.ID,
+			Content: fmt.Sprintf("发布了新文章《%s》", post.Title),
+		})
+	}
+	return s.repo.CreateBatch(notifications)
+}
+
+// CreateMentionNotifications 通知评论中 @ 到的用户，看不到文章的用户不会收到通知。
+// 访客评论没有可以作为通知发起人的用户，不发送提醒
+func (s *NotificationService) CreateMentionNotifications(comment *model.Comment, post *model.Post, users []model.User) error {
+	if comment.IsGuest() {
+		return nil
+	}
+
+	notifications := make([]model.Notification, 0, len(users))
+	for _, user := range users {
+		access := checkPostAccess(post, &PostViewer{UserID: user.ID, Role: user.Role})
+		if access != nil && !errors.Is(access, ErrPostLocked) {
+			continue
+		}
+		notifications = append(notifications, model.Notification{
+			Type:      model.NotificationTypeMention,
+			UserID:    user.ID,
+			ActorID:   comment.AuthorID(),
+			PostID:    &post.ID,
+			CommentID: &comment.ID,
+			Content:   fmt.Sprintf("在文章《%s》的评论中提到了你: %s", post.Title, comment.Content),
+		})
+	}
+	return s.repo.CreateBatch(notifications)
+}
+
 // ListNotifications 获取用户的通知列表
 func (s *NotificationService) ListNotifications(userID uint, query *dto.NotificationListQuery) ([]dto.NotificationResponse, int64, error) {
 	notifications, total, err := s.repo.ListByUser(userID, query.Page, query.PageSize, query.Unread, query.Type)
@@ -82,8 +186,19 @@ func (s *NotificationService) ListNotifications(userID uint, query *dto.Notifica
 	return responses, total, nil
 }
 
-// MarkAsRead 将通知标记为已读
-func (s *NotificationService) MarkAsRead(id uint) error {
+// MarkAsRead 将通知标记为已读，只能操作自己的通知
+func (s *NotificationService) MarkAsRead(userID, id uint) error {
+	notification, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+	// 不区分他人的通知和不存在的通知
+	if err := policy.Authorize(newSubject(userID, ""), policy.NotificationUpdate, &policy.Resource{OwnerID: notification.UserID}); err != nil {
+		return ErrNotificationNotFound
+	}
 	return s.repo.MarkAsRead(id)
 }
 
@@ -100,11 +215,12 @@ func (s *NotificationService) GetUnreadCount(userID uint) (int64, error) {
 // convertToResponse 将通知模型转换为响应DTO
 func (s *NotificationService) convertToResponse(notification *model.Notification) dto.NotificationResponse {
 	response := dto.NotificationResponse{
-		ID:        notification.ID,
-		Type:      notification.Type,
-		Content:   notification.Content,
-		IsRead:    notification.Read,
-		CreatedAt: notification.CreatedAt,
+		ID:         notification.ID,
+		Type:       notification.Type,
+		Content:    notification.Content,
+		IsRead:     notification.Read,
+		ActorCount: notification.ActorCount,
+		CreatedAt:  notification.CreatedAt,
 	}
 
 	// 设置可选字段
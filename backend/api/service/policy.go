@@ -0,0 +1,98 @@
+package service
+
+import (
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/policy"
+)
+
+// policyFacts 为策略评估按需查询用户的工作区角色和分类职责，结果在本次请求内缓存
+type policyFacts struct {
+	userID         uint
+	workspaceRoles map[uint]string
+	categories     map[uint]bool
+	writer         *bool
+}
+
+// newSubject 创建策略评估使用的用户
+func newSubject(userID uint, role string) *policy.Subject {
+	return &policy.Subject{
+		UserID: userID,
+		Role:   role,
+		Facts:  &policyFacts{userID: userID},
+	}
+}
+
+// WorkspaceRole 查询用户在工作区中的角色，查询失败时按非成员处理
+func (f *policyFacts) WorkspaceRole(workspaceID uint) string {
+	if role, ok := f.workspaceRoles[workspaceID]; ok {
+		return role
+	}
+
+	role := ""
+	if member, err := repository.NewWorkspaceRepository().FindMember(workspaceID, f.userID); err == nil {
+		role = member.Role
+	}
+	if f.workspaceRoles == nil {
+		f.workspaceRoles = make(map[uint]string)
+	}
+	f.workspaceRoles[workspaceID] = role
+	return role
+}
+
+// WritesInAnyWorkspace 查询用户是否在任一工作区中具有撰稿人以上角色
+func (f *policyFacts) WritesInAnyWorkspace() bool {
+	if f.writer == nil {
+		writer, err := repository.NewWorkspaceRepository().HasRole(f.userID, []string{
+			model.WorkspaceRoleOwner, model.WorkspaceRoleMaintainer, model.WorkspaceRoleWriter,
+		})
+		writer = writer && err == nil
+		f.writer = &writer
+	}
+	return *f.writer
+}
+
+// EditsCategory 查询用户是否是分类的责任编辑
+func (f *policyFacts) EditsCategory(categoryID uint) bool {
+	if edits, ok := f.categories[categoryID]; ok {
+		return edits
+	}
+
+	edits, err := repository.NewCategoryRepository().IsEditor(categoryID, f.userID)
+	edits = edits && err == nil
+	if f.categories == nil {
+		f.categories = make(map[uint]bool)
+	}
+	f.categories[categoryID] = edits
+	return edits
+}
+
+// postResource 文章中与授权相关的属性
+func postResource(post *model.Post) *policy.Resource {
+	return &policy.Resource{
+		OwnerID:     post.UserID,
+		WorkspaceID: post.WorkspaceID,
+		CategoryID:  post.CategoryID,
+	}
+}
+
+// draftResource 草稿中与授权相关的属性
+func draftResource(draft *model.Draft) *policy.Resource {
+	return &policy.Resource{
+		OwnerID:     draft.UserID,
+		WorkspaceID: draft.WorkspaceID,
+		CategoryID:  draft.CategoryID,
+	}
+}
+
+// deniedError 将策略拒绝转换为服务错误：涉及工作区时，非成员与工作区不存在一样返回 ErrWorkspaceNotFound，
+// 成员角色不足返回 ErrWorkspaceForbidden；其他情况返回 fallback
+func deniedError(subject *policy.Subject, workspaceID *uint, fallback error) error {
+	if workspaceID == nil || subject.Role == model.RoleAdmin {
+		return fallback
+	}
+	if subject.WorkspaceRole(*workspaceID) == "" {
+		return ErrWorkspaceNotFound
+	}
+	return ErrWorkspaceForbidden
+}
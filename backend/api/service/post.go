@@ -1,42 +1,86 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
 	"notex/api/dto"
 	"notex/api/repository"
 	"notex/model"
+	"notex/pkg/auth"
+	"notex/pkg/policy"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+var ErrPostNotFound = errors.New("post not found")
+
 type PostService struct {
-	repo *repository.PostRepository
+	repo          *repository.PostRepository
+	categories    *repository.CategoryRepository
+	workspaces    *repository.WorkspaceRepository
+	likes         *repository.LikeRepository
+	bookmarks     *repository.BookmarkRepository
+	notifications *NotificationService
+	embeddings    *EmbeddingService
+	attachments   *AttachmentService
 }
 
-func NewPostService() *PostService {
+func NewPostService(embeddings *EmbeddingService, attachments *AttachmentService) *PostService {
 	return &PostService{
-		repo: repository.NewPostRepository(),
+		repo:          repository.NewPostRepository(),
+		categories:    repository.NewCategoryRepository(),
+		workspaces:    repository.NewWorkspaceRepository(),
+		likes:         repository.NewLikeRepository(),
+		bookmarks:     repository.NewBookmarkRepository(),
+		notifications: NewNotificationService(),
+		embeddings:    embeddings,
+		attachments:   attachments,
 	}
 }
 
 // CreatePost 创建文章
-func (s *PostService) CreatePost(req *dto.CreatePostRequest) (*dto.PostResponse, error) {
-	// 开启事务
-	tx := s.repo.DB.Begin()
+func (s *PostService) CreatePost(viewer *PostViewer, req *dto.CreatePostRequest) (*dto.PostResponse, error) {
+	subject := viewer.policySubject()
+	if !policy.Allowed(subject, policy.PostCreate, &policy.Resource{OwnerID: viewer.UserID, WorkspaceID: req.WorkspaceID}) {
+		return nil, deniedError(subject, req.WorkspaceID, ErrPostForbidden)
+	}
+	if err := checkCategoryScope(s.categories, req.CategoryID, req.WorkspaceID); err != nil {
+		return nil, err
+	}
 
 	post := &model.Post{
-		Title:      req.Title,
-		Content:    req.Content,
-		Summary:    req.Summary,
-		Cover:      req.Cover,
-		Slug:       req.Slug,
-		CategoryID: req.CategoryID,
-		Status:     req.Status,
-		UserID:     req.UserID,
-	}
+		Title:       req.Title,
+		Content:     req.Content,
+		Summary:     req.Summary,
+		Cover:       req.Cover,
+		Slug:        req.Slug,
+		CategoryID:  req.CategoryID,
+		Status:      req.Status,
+		UserID:      req.UserID,
+		WorkspaceID: req.WorkspaceID,
+	}
+	post.GuestCommentsDisabled = req.GuestCommentsDisabled
 
 	if req.Status == "published" {
 		post.PublishedAt = time.Now()
 	}
+	// 未指定可见性时使用分类的默认可见性
+	visibility := req.Visibility
+	if visibility == "" {
+		_, defaultVisibility, err := resolveCategorySettings(s.categories, req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		visibility = defaultVisibility
+	}
+	if err := applyPostVisibility(post, visibility, req.Password); err != nil {
+		return nil, err
+	}
+
+	// 开启事务
+	tx := s.repo.DB.Begin()
 
 	// 创建文章
 	if err := tx.Create(post).Error; err != nil {
@@ -59,25 +103,42 @@ func (s *PostService) CreatePost(req *dto.CreatePostRequest) (*dto.PostResponse,
 		}
 	}
 
+	// 关联附件
+	if len(req.AttachmentIDs) > 0 {
+		if err := s.attachments.BindPost(tx, post.ID, req.UserID, req.AttachmentIDs); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
 	// 重新加载文章以获取完整的关联数据
-	if err := s.repo.DB.Preload("Category").Preload("Tags").First(post, post.ID).Error; err != nil {
+	post, err := s.repo.FindByID(post.ID)
+	if err != nil {
 		return nil, err
 	}
 
+	s.reindex(post)
+	if post.Status == "published" {
+		notifyFollowers(s.notifications, post)
+	}
+
 	return s.convertToResponse(post)
 }
 
-// UpdatePost 更新文章
-func (s *PostService) UpdatePost(id uint, req *dto.UpdatePostRequest) (*dto.PostResponse, error) {
-	post, err := s.repo.FindByID(id)
+// UpdatePost 更新文章，文章所属的工作区不能修改
+func (s *PostService) UpdatePost(id uint, viewer *PostViewer, req *dto.UpdatePostRequest) (*dto.PostResponse, error) {
+	post, err := s.find(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := authorizePost(viewer, policy.PostUpdate, post); err != nil {
+		return nil, err
+	}
 
 	if req.Title != "" {
 		post.Title = req.Title
@@ -95,44 +156,171 @@ func (s *PostService) UpdatePost(id uint, req *dto.UpdatePostRequest) (*dto.Post
 		post.Slug = req.Slug
 	}
 	if req.CategoryID != 0 {
+		if err := checkCategoryScope(s.categories, req.CategoryID, post.WorkspaceID); err != nil {
+			return nil, err
+		}
 		post.CategoryID = req.CategoryID
+		// 编辑只能把文章移入自己负责的分类
+		if err := authorizePost(viewer, policy.PostUpdate, post); err != nil {
+			return nil, err
+		}
 	}
+	published := req.Status == "published" && post.Status != "published"
 	if req.Status != "" {
-		if req.Status == "published" && post.Status != "published" {
+		if published {
 			post.PublishedAt = time.Now()
 		}
 		post.Status = req.Status
 	}
+	if err := applyPostVisibility(post, req.Visibility, req.Password); err != nil {
+		return nil, err
+	}
+	if req.GuestCommentsDisabled != nil {
+		post.GuestCommentsDisabled = *req.GuestCommentsDisabled
+	}
 
 	if err := s.repo.Update(post); err != nil {
 		return nil, err
 	}
 
+	// 替换附件，附件需由文章作者上传
+	if req.AttachmentIDs != nil {
+		if err := s.attachments.BindPost(nil, post.ID, post.UserID, *req.AttachmentIDs); err != nil {
+			return nil, err
+		}
+		if post.Attachments, err = s.attachments.ListPost(post.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.reindex(post)
+	if published {
+		notifyFollowers(s.notifications, post)
+	}
+
 	return s.convertToResponse(post)
 }
 
 // DeletePost 删除文章
-func (s *PostService) DeletePost(id uint) error {
-	return s.repo.Delete(id)
+func (s *PostService) DeletePost(id uint, viewer *PostViewer) error {
+	post, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if err := authorizePost(viewer, policy.PostDelete, post); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	return s.embeddings.RemovePost(id)
 }
 
-// GetPost 获取文章详情
-func (s *PostService) GetPost(id uint) (*dto.PostResponse, error) {
+// reindex 在后台重新生成文章的向量
+func (s *PostService) reindex(post *model.Post) {
+	go func() {
+		if err := s.embeddings.IndexPost(context.Background(), post); err != nil {
+			log.Printf("Failed to embed post %d: %v", post.ID, err)
+		}
+	}()
+}
+
+// notifyFollowers 在后台通知作者的关注者有新文章发布
+func notifyFollowers(notifications *NotificationService, post *model.Post) {
+	go func() {
+		if err := notifications.CreateFollowedPostNotifications(post); err != nil {
+			log.Printf("Failed to notify followers of post %d: %v", post.ID, err)
+		}
+	}()
+}
+
+// GetPost 获取文章详情，密码保护的文章未解锁时只返回基本信息并标记为 protected
+func (s *PostService) GetPost(id uint, viewer *PostViewer) (*dto.PostResponse, error) {
+	post, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	access := checkPostAccess(post, viewer)
+	if access != nil && !errors.Is(access, ErrPostLocked) {
+		return nil, access
+	}
+
+	response, err := s.convertToResponse(post)
+	if err != nil {
+		return nil, err
+	}
+	if access != nil {
+		redactPost(response)
+		return response, nil
+	}
+
+	// 登录用户返回自己的点赞和收藏状态
+	if viewer != nil && viewer.UserID != 0 {
+		if response.Liked, err = s.likes.Exists(post.ID, viewer.UserID); err != nil {
+			return nil, err
+		}
+		if response.Bookmarked, err = s.bookmarks.Exists(viewer.UserID, post.ID); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// UnlockPost 验证密码保护文章的密码，返回访问文章时使用的解锁令牌
+func (s *PostService) UnlockPost(id uint, password string) (*dto.UnlockPostResponse, error) {
+	post, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != "published" || post.Visibility != model.PostVisibilityPassword {
+		return nil, ErrPostNotFound
+	}
+	if !post.CheckPassword(password) {
+		return nil, ErrPostWrongPassword
+	}
+
+	token, expiresAt, err := auth.GeneratePostToken(post.ID, passwordFingerprint(post))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UnlockPostResponse{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// RequireWorkspaceReader 检查访问者是否是工作区成员，成员可以列出工作区的全部文章
+func (s *PostService) RequireWorkspaceReader(workspaceID uint, viewer *PostViewer) error {
+	return authorizeWorkspace(s.workspaces, viewer.policySubject(), policy.WorkspaceView, &policy.Resource{WorkspaceID: &workspaceID})
+}
+
+// find 查找文章
+func (s *PostService) find(id uint) (*model.Post, error) {
 	post, err := s.repo.FindByID(id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
 		return nil, err
 	}
-	return s.convertToResponse(post)
+	return post, nil
 }
 
-// ListPosts 获取文章列表
+// ListPosts 获取文章列表。Listed 为真时只列出已发布的公开和密码保护文章，其中密码保护文章的内容会被隐藏，
+// 搜索结果只包含公开文章
 func (s *PostService) ListPosts(query *dto.PostListQuery) ([]dto.PostResponse, int64, error) {
-	conditions := make(map[string]interface{})
+	if query.Mode == "semantic" && query.Search != "" {
+		return s.semanticSearch(query)
+	}
+
+	conditions := listConditions(query)
 
 	if query.Status != "" {
 		conditions["status"] = query.Status
 	}
-	if query.CategoryID > 0 {
+	if query.CategoryID > 0 && query.IncludeSubcategories {
+		conditions["category_tree"] = query.CategoryID
+	} else if query.CategoryID > 0 {
 		conditions["category_id"] = query.CategoryID
 	}
 	if query.TagID > 0 {
@@ -147,6 +335,9 @@ func (s *PostService) ListPosts(query *dto.PostListQuery) ([]dto.PostResponse, i
 	if query.UserID > 0 {
 		conditions["user_id"] = query.UserID
 	}
+	if query.WorkspaceID > 0 {
+		conditions["workspace_id"] = query.WorkspaceID
+	}
 
 	posts, total, err := s.repo.List(query.Page, query.PageSize, conditions)
 	if err != nil {
@@ -154,49 +345,181 @@ func (s *PostService) ListPosts(query *dto.PostListQuery) ([]dto.PostResponse, i
 	}
 
 	// 转换为 DTO
-	responses := make([]dto.PostResponse, 0)
-	for _, post := range posts {
-		response, err := s.convertToResponse(&post)
-		if err != nil {
-			return nil, 0, err
+	responses, err := s.convertListed(posts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return responses, total, nil
+}
+
+// listConditions 根据 Listed 构造可见性条件并强制只列出已发布的文章，搜索时排除密码保护的文章以免内容被匹配。
+// AllPersonal 为真时不属于工作区的文章不受这些限制
+func listConditions(query *dto.PostListQuery) map[string]interface{} {
+	conditions := make(map[string]interface{})
+	if !query.Listed {
+		return conditions
+	}
+
+	visibilities := listedVisibilities
+	if query.Search != "" {
+		visibilities = []string{model.PostVisibilityPublic}
+	}
+	if query.AllPersonal {
+		conditions["listed_or_personal"] = visibilities
+		return conditions
+	}
+
+	query.Status = "published"
+	conditions["visibility"] = visibilities
+	return conditions
+}
+
+// convertListed 将列表中的文章转换为响应DTO，并隐藏密码保护文章的内容
+func (s *PostService) convertListed(posts []model.Post) ([]dto.PostResponse, error) {
+	pointers := make([]*model.Post, len(posts))
+	for i := range posts {
+		pointers[i] = &posts[i]
+	}
+	converted, err := s.convertPosts(pointers)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PostResponse, len(converted))
+	for i, response := range converted {
+		if posts[i].Visibility == model.PostVisibilityPassword {
+			redactPost(response)
+		}
+		responses[i] = *response
+	}
+	return responses, nil
+}
+
+// semanticSearch 按语义相似度检索文章，结果按相似度排序
+func (s *PostService) semanticSearch(query *dto.PostListQuery) ([]dto.PostResponse, int64, error) {
+	matches, err := s.embeddings.Search(context.Background(), query.Search, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+
+	// 应用其他过滤条件
+	conditions := listConditions(query)
+	if query.Status != "" {
+		conditions["status"] = query.Status
+	}
+	if query.CategoryID > 0 && query.IncludeSubcategories {
+		conditions["category_tree"] = query.CategoryID
+	} else if query.CategoryID > 0 {
+		conditions["category_id"] = query.CategoryID
+	}
+	if query.TagID > 0 {
+		conditions["tag_id"] = query.TagID
+	}
+	if query.UserID > 0 {
+		conditions["user_id"] = query.UserID
+	}
+	if query.WorkspaceID > 0 {
+		conditions["workspace_id"] = query.WorkspaceID
+	}
+	allowedIDs, err := s.repo.FilterIDs(ids, conditions)
+	if err != nil {
+		return nil, 0, err
+	}
+	allowed := make(map[uint]bool, len(allowedIDs))
+	for _, id := range allowedIDs {
+		allowed[id] = true
+	}
+
+	ranked := make([]uint, 0, len(allowedIDs))
+	for _, id := range ids {
+		if allowed[id] {
+			ranked = append(ranked, id)
 		}
-		responses = append(responses, *response)
+	}
+	total := int64(len(ranked))
+
+	// 分页
+	start := (query.Page - 1) * query.PageSize
+	if start < 0 || start >= len(ranked) {
+		return make([]dto.PostResponse, 0), total, nil
+	}
+	end := start + query.PageSize
+	if end > len(ranked) {
+		end = len(ranked)
 	}
 
+	responses, err := s.loadOrdered(ranked[start:end])
+	if err != nil {
+		return nil, 0, err
+	}
 	return responses, total, nil
 }
 
-// GetRecentPosts 获取最新文章列表
-func (s *PostService) GetRecentPosts(limit int) ([]dto.PostResponse, error) {
-	conditions := map[string]interface{}{
-		"status": "published",
-		"sort":   "newest",
+// GetRelatedPosts 获取语义相关的已发布公开文章
+func (s *PostService) GetRelatedPosts(id uint, limit int) ([]dto.PostResponse, error) {
+	matches := s.embeddings.Related(id, limit)
+
+	ids := make([]uint, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
 	}
-	posts, _, err := s.repo.List(1, limit, conditions)
+	return s.loadOrdered(ids)
+}
+
+// loadOrdered 按给定ID顺序加载已发布的公开文章
+func (s *PostService) loadOrdered(ids []uint) ([]dto.PostResponse, error) {
+	posts, err := s.repo.FindByIDs(ids)
 	if err != nil {
-		return make([]dto.PostResponse, 0), err
+		return nil, err
 	}
 
-	responses := make([]dto.PostResponse, 0)
-	for _, post := range posts {
-		response, err := s.convertToResponse(&post)
-		if err != nil {
-			return responses, err
+	byID := make(map[uint]*model.Post, len(posts))
+	for i := range posts {
+		byID[posts[i].ID] = &posts[i]
+	}
+
+	ordered := make([]*model.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok && post.IsPublic() {
+			ordered = append(ordered, post)
 		}
-		responses = append(responses, *response)
+	}
+	converted, err := s.convertPosts(ordered)
+	if err != nil {
+		return nil, err
 	}
 
+	responses := make([]dto.PostResponse, len(converted))
+	for i, response := range converted {
+		responses[i] = *response
+	}
 	return responses, nil
 }
 
-// IncrementViews 增加文章浏览量
-func (s *PostService) IncrementViews(id uint) error {
-	return s.repo.IncrementViews(id)
+// GetRecentPosts 获取最新的公开文章列表
+func (s *PostService) GetRecentPosts(limit int) ([]dto.PostResponse, error) {
+	conditions := map[string]interface{}{
+		"status":     "published",
+		"visibility": []string{model.PostVisibilityPublic},
+		"sort":       "newest",
+	}
+	posts, _, err := s.repo.List(1, limit, conditions)
+	if err != nil {
+		return make([]dto.PostResponse, 0), err
+	}
+
+	return s.convertListed(posts)
 }
 
 // GetArchives 获取文章归档列表
 func (s *PostService) GetArchives() ([]dto.ArchiveResponse, error) {
-	archives, err := s.repo.GetArchives()
+	archives, err := s.repo.GetArchives(listedVisibilities)
 	if err != nil {
 		return nil, err
 	}
@@ -217,21 +540,12 @@ func (s *PostService) GetArchives() ([]dto.ArchiveResponse, error) {
 
 // GetPostsByArchive 获取指定归档日期的文章列表
 func (s *PostService) GetPostsByArchive(yearMonth string) ([]dto.PostResponse, error) {
-	posts, err := s.repo.GetPostsByArchive(yearMonth)
+	posts, err := s.repo.GetPostsByArchive(yearMonth, listedVisibilities)
 	if err != nil {
 		return nil, err
 	}
 
-	responses := make([]dto.PostResponse, 0)
-	for _, post := range posts {
-		response, err := s.convertToResponse(&post)
-		if err != nil {
-			return nil, err
-		}
-		responses = append(responses, *response)
-	}
-
-	return responses, nil
+	return s.convertListed(posts)
 }
 
 // ListUserPosts 获取用户的文章列表
@@ -241,24 +555,31 @@ func (s *PostService) ListUserPosts(userID uint, page, pageSize int) ([]*dto.Pos
 		return nil, 0, err
 	}
 
+	// 批量获取评论、点赞和收藏数
+	ids := make([]uint, len(posts))
+	for i := range posts {
+		ids[i] = posts[i].ID
+	}
+	counts, err := s.repo.CountByPosts(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	responses := make([]*dto.PostResponse, len(posts))
 	for i, post := range posts {
-		// 获取评论数
-		commentCount, err := s.repo.GetCommentCount(post.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-
 		responses[i] = &dto.PostResponse{
-			ID:           post.ID,
-			Title:        post.Title,
-			Summary:      post.Summary,
-			Views:        post.Views,
-			CommentCount: commentCount,
-			CreatedAt:    post.CreatedAt,
-			UpdatedAt:    post.UpdatedAt,
-			Status:       post.Status,
-			PublishedAt:  post.PublishedAt,
+			ID:            post.ID,
+			Title:         post.Title,
+			Summary:       post.Summary,
+			Views:         post.Views,
+			CommentCount:  counts[post.ID].Comments,
+			LikeCount:     counts[post.ID].Likes,
+			BookmarkCount: counts[post.ID].Bookmarks,
+			CreatedAt:     post.CreatedAt,
+			UpdatedAt:     post.UpdatedAt,
+			Status:        post.Status,
+			Visibility:    post.Visibility,
+			PublishedAt:   post.PublishedAt,
 		}
 	}
 
@@ -270,27 +591,51 @@ func (s *PostService) convertToResponse(post *model.Post) (*dto.PostResponse, er
 	if post == nil {
 		return nil, errors.New("post is nil")
 	}
+	responses, err := s.convertPosts([]*model.Post{post})
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
 
-	// 获取评论数
-	commentCount, err := s.repo.GetCommentCount(post.ID)
+// convertPosts 将多篇文章转换为响应DTO，评论、点赞和收藏数按文章分组一次查询
+func (s *PostService) convertPosts(posts []*model.Post) ([]*dto.PostResponse, error) {
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	counts, err := s.repo.CountByPosts(ids)
 	if err != nil {
 		return nil, err
 	}
 
+	responses := make([]*dto.PostResponse, len(posts))
+	for i, post := range posts {
+		responses[i] = newPostResponse(post, counts[post.ID])
+	}
+	return responses, nil
+}
+
+// newPostResponse 使用已统计的数量构建文章响应DTO
+func newPostResponse(post *model.Post, counts repository.PostCounts) *dto.PostResponse {
 	response := &dto.PostResponse{
-		ID:           post.ID,
-		Title:        post.Title,
-		Content:      post.Content,
-		Summary:      post.Summary,
-		Cover:        post.Cover,
-		Slug:         post.Slug,
-		CategoryID:   post.CategoryID,
-		Status:       post.Status,
-		Views:        post.Views,
-		CommentCount: commentCount,
-		PublishedAt:  post.PublishedAt,
-		CreatedAt:    post.CreatedAt,
-		UpdatedAt:    post.UpdatedAt,
+		ID:            post.ID,
+		Title:         post.Title,
+		Content:       post.Content,
+		Summary:       post.Summary,
+		Cover:         post.Cover,
+		Slug:          post.Slug,
+		CategoryID:    post.CategoryID,
+		WorkspaceID:   post.WorkspaceID,
+		Status:        post.Status,
+		Visibility:    post.Visibility,
+		Views:         post.Views,
+		CommentCount:  counts.Comments,
+		LikeCount:     counts.Likes,
+		BookmarkCount: counts.Bookmarks,
+		PublishedAt:   post.PublishedAt,
+		CreatedAt:     post.CreatedAt,
+		UpdatedAt:     post.UpdatedAt,
 		Author: &dto.UserInfo{
 			ID:       post.User.ID,
 			Username: post.User.Username,
@@ -298,10 +643,15 @@ func (s *PostService) convertToResponse(post *model.Post) (*dto.PostResponse, er
 		},
 	}
 
+	response.GuestCommentsDisabled = post.GuestCommentsDisabled
 	if post.Category.ID != 0 {
 		response.Category = post.Category.Name
 	}
 
+	if len(post.Attachments) > 0 {
+		response.Attachments = dto.ConvertToAttachmentResponses(post.Attachments)
+	}
+
 	tags := make([]dto.TagInfo, 0)
 	for _, tag := range post.Tags {
 		tags = append(tags, dto.TagInfo{
@@ -311,5 +661,5 @@ func (s *PostService) convertToResponse(post *model.Post) (*dto.PostResponse, er
 	}
 	response.Tags = tags
 
-	return response, nil
+	return response
 }
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"notex/api/dto"
+	"notex/model"
+	"notex/pkg/auth"
+	"notex/pkg/policy"
+)
+
+var (
+	ErrPostLocked           = errors.New("post is password protected")
+	ErrPostWrongPassword    = errors.New("incorrect post password")
+	ErrPostPasswordRequired = errors.New("password is required for password protected posts")
+	ErrPostForbidden        = errors.New("no permission to modify this post")
+)
+
+// listedVisibilities 出现在公开列表和归档中的可见性，密码保护的文章在列表中只展示基本信息
+var listedVisibilities = []string{model.PostVisibilityPublic, model.PostVisibilityPassword}
+
+// PostViewer 访问文章的用户，未登录时 UserID 为 0
+type PostViewer struct {
+	UserID uint
+	Role   string
+	Token  string // 密码保护文章的解锁令牌
+
+	subject *policy.Subject // 策略评估使用的用户，首次使用时创建
+}
+
+// IsAdmin 检查访问者是否是管理员
+func (v *PostViewer) IsAdmin() bool {
+	return v != nil && v.Role == model.RoleAdmin
+}
+
+// IsEditor 检查访问者是否是编辑或管理员，编辑可以访问不属于工作区的全部文章
+func (v *PostViewer) IsEditor() bool {
+	return v != nil && (v.Role == model.RoleEditor || v.Role == model.RoleAdmin)
+}
+
+// owns 检查访问者是否是文章作者
+func (v *PostViewer) owns(post *model.Post) bool {
+	return v != nil && v.UserID != 0 && v.UserID == post.UserID
+}
+
+// policySubject 返回策略评估使用的用户，同一访问者的关系查询结果在本次请求内共享
+func (v *PostViewer) policySubject() *policy.Subject {
+	if v == nil {
+		return &policy.Subject{}
+	}
+	if v.subject == nil {
+		v.subject = newSubject(v.UserID, v.Role)
+	}
+	return v.subject
+}
+
+// inWorkspace 检查访问者是否是文章所属工作区的成员
+func (v *PostViewer) inWorkspace(post *model.Post) bool {
+	return post.WorkspaceID != nil && v.policySubject().WorkspaceRole(*post.WorkspaceID) != ""
+}
+
+// checkPostAccess 检查访问者能否查看文章。作者和所属工作区的成员可以访问全部文章，编辑和管理员可以访问
+// 不属于工作区的全部文章；草稿和私密文章对其他人与不存在一样返回 ErrPostNotFound，
+// 密码保护的文章没有有效的解锁令牌时返回 ErrPostLocked
+func checkPostAccess(post *model.Post, viewer *PostViewer) error {
+	if viewer.owns(post) || (post.WorkspaceID == nil && viewer.IsEditor()) || viewer.inWorkspace(post) {
+		return nil
+	}
+	if post.Status != "published" || post.Visibility == model.PostVisibilityPrivate {
+		return ErrPostNotFound
+	}
+	if post.Visibility == model.PostVisibilityPassword && !unlocked(post, viewer) {
+		return ErrPostLocked
+	}
+	return nil
+}
+
+// authorizePost 通过策略检查访问者能否对文章执行操作，看不到文章的访问者得到 ErrPostNotFound
+func authorizePost(viewer *PostViewer, action policy.Action, post *model.Post) error {
+	if policy.Allowed(viewer.policySubject(), action, postResource(post)) {
+		return nil
+	}
+	if err := checkPostAccess(post, viewer); errors.Is(err, ErrPostNotFound) {
+		return err
+	}
+	return ErrPostForbidden
+}
+
+// unlocked 检查访问者持有的令牌能否解锁密码保护的文章
+func unlocked(post *model.Post, viewer *PostViewer) bool {
+	if viewer == nil || viewer.Token == "" {
+		return false
+	}
+	postID, fingerprint, err := auth.ParsePostToken(viewer.Token)
+	return err == nil && postID == post.ID && fingerprint == passwordFingerprint(post)
+}
+
+// passwordFingerprint 由密码哈希派生令牌中的指纹，修改密码后旧的解锁令牌随之失效
+func passwordFingerprint(post *model.Post) string {
+	sum := sha256.Sum256([]byte(post.PasswordHash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// applyPostVisibility 设置文章的可见性，切换为密码保护时必须提供密码，已有密码时可以省略
+func applyPostVisibility(post *model.Post, visibility, password string) error {
+	if visibility != "" {
+		post.Visibility = visibility
+	}
+	if post.Visibility == "" {
+		post.Visibility = model.PostVisibilityPublic
+	}
+
+	if post.Visibility != model.PostVisibilityPassword {
+		post.PasswordHash = ""
+		return nil
+	}
+	if password != "" {
+		return post.SetPassword(password)
+	}
+	if post.PasswordHash == "" {
+		return ErrPostPasswordRequired
+	}
+	return nil
+}
+
+// redactPost 隐藏密码保护文章的内容，只保留标题、作者等基本信息
+func redactPost(response *dto.PostResponse) {
+	response.Content = ""
+	response.Summary = ""
+	response.Attachments = nil
+	response.Protected = true
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"notex/api/dto"
+	"notex/model"
+	"reflect"
+	"testing"
+)
+
+// testViewer 创建工作区角色已知的访问者，避免查询数据库
+func testViewer(userID uint, role string, workspaceRoles map[uint]string) *PostViewer {
+	viewer := &PostViewer{UserID: userID, Role: role}
+	viewer.subject = newSubject(userID, role)
+	viewer.subject.Facts.(*policyFacts).workspaceRoles = workspaceRoles
+	return viewer
+}
+
+func TestCheckPostAccess(t *testing.T) {
+	workspaceID := uint(7)
+	personal := func(status, visibility string) *model.Post {
+		return &model.Post{ID: 1, UserID: 1, Status: status, Visibility: visibility}
+	}
+	inWorkspace := func(status, visibility string) *model.Post {
+		post := personal(status, visibility)
+		post.WorkspaceID = &workspaceID
+		return post
+	}
+
+	editor := testViewer(2, model.RoleEditor, map[uint]string{workspaceID: ""})
+	admin := testViewer(3, model.RoleAdmin, map[uint]string{workspaceID: ""})
+	member := testViewer(4, model.RoleUser, map[uint]string{workspaceID: model.WorkspaceRoleReader})
+	stranger := testViewer(5, model.RoleUser, map[uint]string{workspaceID: ""})
+	author := testViewer(1, model.RoleUser, map[uint]string{workspaceID: ""})
+
+	tests := []struct {
+		name   string
+		post   *model.Post
+		viewer *PostViewer
+		want   error
+	}{
+		{name: "editor sees personal draft", post: personal("draft", model.PostVisibilityPublic), viewer: editor},
+		{name: "admin sees personal private post", post: personal("published", model.PostVisibilityPrivate), viewer: admin},
+		{name: "editor cannot see workspace draft", post: inWorkspace("draft", model.PostVisibilityPublic), viewer: editor, want: ErrPostNotFound},
+		{name: "admin cannot see workspace private post", post: inWorkspace("published", model.PostVisibilityPrivate), viewer: admin, want: ErrPostNotFound},
+		{name: "editor sees published workspace post", post: inWorkspace("published", model.PostVisibilityPublic), viewer: editor},
+		{name: "member sees workspace draft", post: inWorkspace("draft", model.PostVisibilityPrivate), viewer: member},
+		{name: "author sees own workspace draft", post: inWorkspace("draft", model.PostVisibilityPublic), viewer: author},
+		{name: "stranger cannot see personal draft", post: personal("draft", model.PostVisibilityPublic), viewer: stranger, want: ErrPostNotFound},
+		{name: "stranger needs password", post: personal("published", model.PostVisibilityPassword), viewer: stranger, want: ErrPostLocked},
+		{name: "anonymous sees public post", post: personal("published", model.PostVisibilityPublic), viewer: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkPostAccess(tt.post, tt.viewer); !errors.Is(err, tt.want) {
+				t.Fatalf("checkPostAccess = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      dto.PostListQuery
+		want       map[string]interface{}
+		wantStatus string
+	}{
+		{name: "unrestricted", query: dto.PostListQuery{}, want: map[string]interface{}{}},
+		{
+			name:       "listed",
+			query:      dto.PostListQuery{Listed: true},
+			want:       map[string]interface{}{"visibility": listedVisibilities},
+			wantStatus: "published",
+		},
+		{
+			name:       "listed search",
+			query:      dto.PostListQuery{Listed: true, Search: "go"},
+			want:       map[string]interface{}{"visibility": []string{model.PostVisibilityPublic}},
+			wantStatus: "published",
+		},
+		{
+			name:       "editor keeps status filter",
+			query:      dto.PostListQuery{Listed: true, AllPersonal: true, Status: "draft"},
+			want:       map[string]interface{}{"listed_or_personal": listedVisibilities},
+			wantStatus: "draft",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if got := listConditions(&query); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("listConditions = %v, want %v", got, tt.want)
+			}
+			if query.Status != tt.wantStatus {
+				t.Fatalf("status = %q, want %q", query.Status, tt.wantStatus)
+			}
+		})
+	}
+}
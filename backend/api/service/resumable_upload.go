@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"notex/api/dto"
+	"notex/config"
+	"notex/pkg/tus"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// UploadTargetMedia 上传完成后保存到媒体库
+	UploadTargetMedia = "media"
+	// UploadTargetAttachment 上传完成后创建附件
+	UploadTargetAttachment = "attachment"
+)
+
+var (
+	ErrUploadInvalidTarget = errors.New("upload target must be media or attachment")
+	ErrUploadNoFilename    = errors.New("filename metadata is required")
+)
+
+// MediaImporter 将上传完成的文件保存到媒体库，由 MediaService 实现
+type MediaImporter interface {
+	MaxSize() int64
+	Import(ctx context.Context, userID uint, reader io.Reader, filename, declared string) (*dto.MediaUploadResponse, error)
+}
+
+// AttachmentImporter 将上传完成的文件保存为附件，由 AttachmentService 实现
+type AttachmentImporter interface {
+	MaxSize(filename string) (int64, error)
+	Import(ctx context.Context, userID uint, reader io.Reader, filename string, size int64) (*dto.AttachmentResponse, error)
+}
+
+// ResumableUploadService 管理可续传上传，数据暂存在本地磁盘，全部接收后移交给媒体库或附件
+type ResumableUploadService struct {
+	store       *tus.Store
+	media       MediaImporter
+	attachments AttachmentImporter
+	config      *config.TusConfig
+}
+
+// NewResumableUploadService 创建可续传上传服务，media 和 attachments 可替换为测试用的实现
+func NewResumableUploadService(store *tus.Store, media MediaImporter, attachments AttachmentImporter, cfg *config.TusConfig) *ResumableUploadService {
+	return &ResumableUploadService{
+		store:       store,
+		media:       media,
+		attachments: attachments,
+		config:      cfg,
+	}
+}
+
+// MaxSize 返回单个上传允许声明的最大长度
+func (s *ResumableUploadService) MaxSize() int64 {
+	return s.config.MaxSize
+}
+
+// Create 创建上传。元数据 filename 为必填，target 为 media（默认）或 attachment，
+// 声明的长度在创建时即按目标的大小限制检查，避免上传完成后才被拒绝
+func (s *ResumableUploadService) Create(userID uint, canAttach bool, length int64, metadata map[string]string) (*tus.Upload, error) {
+	filename := filepath.Base(metadata["filename"])
+	if metadata["filename"] == "" || filename == "." || filename == string(filepath.Separator) {
+		return nil, ErrUploadNoFilename
+	}
+	metadata["filename"] = filename
+
+	maxSize := s.config.MaxSize
+	switch metadata["target"] {
+	case "", UploadTargetMedia:
+		metadata["target"] = UploadTargetMedia
+		maxSize = min(maxSize, s.media.MaxSize())
+	case UploadTargetAttachment:
+		if !canAttach {
+			return nil, ErrUnauthorized
+		}
+		limit, err := s.attachments.MaxSize(filename)
+		if err != nil {
+			return nil, err
+		}
+		maxSize = min(maxSize, limit)
+	default:
+		return nil, ErrUploadInvalidTarget
+	}
+	if length > maxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	return s.store.Create(userID, length, metadata)
+}
+
+// Get 获取上传，不属于当前用户的上传按不存在处理
+func (s *ResumableUploadService) Get(userID uint, id string) (*tus.Upload, error) {
+	upload, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userID {
+		return nil, tus.ErrNotFound
+	}
+	return upload, nil
+}
+
+// Append 从 offset 处追加数据，数据全部接收后移交给上传目标，结果记录在上传信息中
+func (s *ResumableUploadService) Append(ctx context.Context, userID uint, id string, offset int64, body io.Reader) (*tus.Upload, error) {
+	if _, err := s.Get(userID, id); err != nil {
+		return nil, err
+	}
+
+	return s.store.Append(id, offset, body, func(upload *tus.Upload, data *os.File) (interface{}, error) {
+		filename := upload.Metadata["filename"]
+		if upload.Metadata["target"] == UploadTargetAttachment {
+			return s.attachments.Import(ctx, upload.UserID, data, filename, upload.Length)
+		}
+		return s.media.Import(ctx, upload.UserID, data, filename, upload.Metadata["filetype"])
+	})
+}
+
+// Terminate 终止上传并删除已接收的数据
+func (s *ResumableUploadService) Terminate(userID uint, id string) error {
+	if _, err := s.Get(userID, id); err != nil {
+		return err
+	}
+	return s.store.Delete(id)
+}
+
+// Run 定期清理过期的上传，直到 ctx 结束
+func (s *ResumableUploadService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := s.store.DeleteExpired(now)
+			if err != nil {
+				log.Printf("Failed to clean up expired uploads: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Deleted %d expired uploads", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"notex/api/dto"
+	"notex/config"
+	"notex/pkg/storage"
+	"notex/pkg/tus"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMediaImporter 记录导入媒体库的文件
+type fakeMediaImporter struct {
+	maxSize  int64
+	userID   uint
+	data     string
+	filename string
+	declared string
+}
+
+func (f *fakeMediaImporter) MaxSize() int64 {
+	return f.maxSize
+}
+
+func (f *fakeMediaImporter) Import(ctx context.Context, userID uint, reader io.Reader, filename, declared string) (*dto.MediaUploadResponse, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	f.userID, f.data, f.filename, f.declared = userID, string(data), filename, declared
+	return &dto.MediaUploadResponse{UploadResult: &storage.UploadResult{URL: "/uploads/" + filename}, AssetID: 1}, nil
+}
+
+// fakeAttachmentImporter 记录导入的附件，只允许 pdf
+type fakeAttachmentImporter struct {
+	maxSize  int64
+	userID   uint
+	data     string
+	filename string
+	size     int64
+}
+
+func (f *fakeAttachmentImporter) MaxSize(filename string) (int64, error) {
+	if !strings.HasSuffix(filename, ".pdf") {
+		return 0, ErrMediaTypeNotAllowed
+	}
+	return f.maxSize, nil
+}
+
+func (f *fakeAttachmentImporter) Import(ctx context.Context, userID uint, reader io.Reader, filename string, size int64) (*dto.AttachmentResponse, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	f.userID, f.data, f.filename, f.size = userID, string(data), filename, size
+	return &dto.AttachmentResponse{ID: 2, Filename: filename, Size: size}, nil
+}
+
+func newTestUploadService(t *testing.T, media *fakeMediaImporter, attachments *fakeAttachmentImporter) *ResumableUploadService {
+	t.Helper()
+	store, err := tus.NewStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewResumableUploadService(store, media, attachments, &config.TusConfig{MaxSize: 100, CleanupInterval: time.Minute})
+}
+
+func TestResumableUploadCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		canAttach  bool
+		length     int64
+		metadata   map[string]string
+		wantErr    error
+		wantTarget string
+		wantName   string
+	}{
+		{name: "media by default", length: 10, metadata: map[string]string{"filename": "a.png"}, wantTarget: UploadTargetMedia, wantName: "a.png"},
+		{name: "path stripped from filename", length: 10, metadata: map[string]string{"filename": "../../a.png"}, wantTarget: UploadTargetMedia, wantName: "a.png"},
+		{name: "missing filename", length: 10, metadata: map[string]string{}, wantErr: ErrUploadNoFilename},
+		{name: "directory filename", length: 10, metadata: map[string]string{"filename": "/"}, wantErr: ErrUploadNoFilename},
+		{name: "unknown target", length: 10, metadata: map[string]string{"filename": "a.png", "target": "avatar"}, wantErr: ErrUploadInvalidTarget},
+		{name: "over media limit", length: 51, metadata: map[string]string{"filename": "a.png"}, wantErr: ErrMediaTooLarge},
+		{name: "attachment", canAttach: true, length: 20, metadata: map[string]string{"filename": "a.pdf", "target": UploadTargetAttachment}, wantTarget: UploadTargetAttachment, wantName: "a.pdf"},
+		{name: "attachment without permission", length: 20, metadata: map[string]string{"filename": "a.pdf", "target": UploadTargetAttachment}, wantErr: ErrUnauthorized},
+		{name: "attachment type not allowed", canAttach: true, length: 20, metadata: map[string]string{"filename": "a.exe", "target": UploadTargetAttachment}, wantErr: ErrMediaTypeNotAllowed},
+		{name: "over attachment type limit", canAttach: true, length: 21, metadata: map[string]string{"filename": "a.pdf", "target": UploadTargetAttachment}, wantErr: ErrMediaTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestUploadService(t, &fakeMediaImporter{maxSize: 50}, &fakeAttachmentImporter{maxSize: 20})
+			upload, err := svc.Create(7, tt.canAttach, tt.length, tt.metadata)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if upload.Metadata["target"] != tt.wantTarget || upload.Metadata["filename"] != tt.wantName || upload.Length != tt.length {
+				t.Fatalf("Create = %+v", upload)
+			}
+		})
+	}
+}
+
+func TestResumableUploadFinish(t *testing.T) {
+	t.Run("media", func(t *testing.T) {
+		media := &fakeMediaImporter{maxSize: 50}
+		svc := newTestUploadService(t, media, &fakeAttachmentImporter{maxSize: 20})
+		upload, err := svc.Create(7, false, 11, map[string]string{"filename": "a.txt", "filetype": "text/plain"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := svc.Append(context.Background(), 7, upload.ID, 0, strings.NewReader("hello ")); err != nil {
+			t.Fatal(err)
+		}
+		if media.data != "" {
+			t.Fatal("media imported before the upload completed")
+		}
+		got, err := svc.Append(context.Background(), 7, upload.ID, 6, strings.NewReader("world"))
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if !got.Completed || !strings.Contains(string(got.Result), `"asset_id":1`) {
+			t.Fatalf("Append = %+v, result %s", got, got.Result)
+		}
+		if media.userID != 7 || media.data != "hello world" || media.filename != "a.txt" || media.declared != "text/plain" {
+			t.Fatalf("imported = %+v", media)
+		}
+	})
+
+	t.Run("attachment", func(t *testing.T) {
+		attachments := &fakeAttachmentImporter{maxSize: 20}
+		svc := newTestUploadService(t, &fakeMediaImporter{maxSize: 50}, attachments)
+		upload, err := svc.Create(7, true, 8, map[string]string{"filename": "a.pdf", "target": UploadTargetAttachment})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := svc.Append(context.Background(), 7, upload.ID, 0, strings.NewReader("%PDF-1.7"))
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if !got.Completed || !strings.Contains(string(got.Result), `"id":2`) {
+			t.Fatalf("Append = %+v, result %s", got, got.Result)
+		}
+		if attachments.userID != 7 || attachments.data != "%PDF-1.7" || attachments.filename != "a.pdf" || attachments.size != 8 {
+			t.Fatalf("imported = %+v", attachments)
+		}
+	})
+}
+
+func TestResumableUploadOwnership(t *testing.T) {
+	svc := newTestUploadService(t, &fakeMediaImporter{maxSize: 50}, &fakeAttachmentImporter{maxSize: 20})
+	upload, err := svc.Create(7, false, 3, map[string]string{"filename": "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Get(8, upload.ID); !errors.Is(err, tus.ErrNotFound) {
+		t.Fatalf("Get by other user error = %v, want %v", err, tus.ErrNotFound)
+	}
+	if _, err := svc.Append(context.Background(), 8, upload.ID, 0, strings.NewReader("abc")); !errors.Is(err, tus.ErrNotFound) {
+		t.Fatalf("Append by other user error = %v, want %v", err, tus.ErrNotFound)
+	}
+	if err := svc.Terminate(8, upload.ID); !errors.Is(err, tus.ErrNotFound) {
+		t.Fatalf("Terminate by other user error = %v, want %v", err, tus.ErrNotFound)
+	}
+
+	if err := svc.Terminate(7, upload.ID); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	if _, err := svc.Get(7, upload.ID); !errors.Is(err, tus.ErrNotFound) {
+		t.Fatalf("Get after Terminate error = %v, want %v", err, tus.ErrNotFound)
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+var slugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugFromName 将名称转换为只包含小写字母、数字和连字符的 slug，留出追加序号的长度。
+// 名称中没有可用字符（如中文名称）时使用 fallback
+func slugFromName(name, fallback string) string {
+	slug := strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > 90 {
+		slug = strings.TrimRight(slug[:90], "-")
+	}
+	if slug == "" {
+		slug = fallback
+	}
+	return slug
+}
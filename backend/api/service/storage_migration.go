@@ -0,0 +1,441 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/storage"
+	"notex/pkg/types"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrStorageMigrationNotFound = errors.New("storage migration not found")
+	ErrStorageMigrationRunning  = errors.New("another storage migration is running")
+	ErrStorageMigrationInvalid  = errors.New("source and target storage must be different")
+	ErrStorageMigrationFinished = errors.New("storage migration has already completed")
+	ErrStorageMigrationConfig   = errors.New("storage is not configured")
+)
+
+// StorageMigrationService 在存储后端之间复制文件并改写数据库中的URL，
+// 复制进度按对象记录在数据库中，任务中断或失败后可以继续执行
+type StorageMigrationService struct {
+	repo   *repository.StorageMigrationRepository
+	config *types.StorageConfig
+
+	mu     sync.Mutex
+	active uint // 当前进程中正在执行的任务ID
+}
+
+func NewStorageMigrationService(config *types.StorageConfig) *StorageMigrationService {
+	return &StorageMigrationService{
+		repo:   repository.NewStorageMigrationRepository(),
+		config: config,
+	}
+}
+
+// Start 创建迁移任务并在后台执行
+func (s *StorageMigrationService) Start(userID uint, req *dto.StorageMigrationRequest) (*dto.StorageMigrationResponse, error) {
+	if req.Source == req.Target {
+		return nil, ErrStorageMigrationInvalid
+	}
+	// 提前检查两端的配置，避免创建无法执行的任务
+	for _, storageType := range []string{req.Source, req.Target} {
+		if _, err := s.open(storageType); err != nil {
+			return nil, err
+		}
+	}
+
+	migration := &model.StorageMigration{
+		Source:    req.Source,
+		Target:    req.Target,
+		DryRun:    req.DryRun,
+		Status:    model.StorageMigrationPending,
+		CreatedBy: userID,
+	}
+	if err := s.begin(migration, true, false); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ConvertToStorageMigrationResponse(migration)
+	return &resp, nil
+}
+
+// Resume 继续执行失败或被中断的迁移任务，skipFailed 为 true 时跳过复制失败的对象直接改写其余URL
+func (s *StorageMigrationService) Resume(id uint, skipFailed bool) (*dto.StorageMigrationResponse, error) {
+	migration, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Status == model.StorageMigrationCompleted {
+		return nil, ErrStorageMigrationFinished
+	}
+
+	if err := s.begin(migration, false, skipFailed); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ConvertToStorageMigrationResponse(migration)
+	return &resp, nil
+}
+
+// GetMigration 获取迁移任务及进度
+func (s *StorageMigrationService) GetMigration(id uint) (*dto.StorageMigrationResponse, error) {
+	migration, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.ConvertToStorageMigrationResponse(migration)
+	return &resp, nil
+}
+
+// ListMigrations 分页获取迁移任务
+func (s *StorageMigrationService) ListMigrations(req *dto.StorageMigrationListRequest) (*dto.StorageMigrationListResponse, error) {
+	migrations, total, err := s.repo.List(req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.StorageMigrationResponse, len(migrations))
+	for i := range migrations {
+		items[i] = dto.ConvertToStorageMigrationResponse(&migrations[i])
+	}
+
+	return &dto.StorageMigrationListResponse{
+		Total: total,
+		Items: items,
+	}, nil
+}
+
+// ListItems 分页获取迁移任务中的对象，可按状态过滤
+func (s *StorageMigrationService) ListItems(id uint, req *dto.StorageMigrationItemListRequest) (*dto.StorageMigrationItemListResponse, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.repo.ListItems(id, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.StorageMigrationItemListResponse{
+		Total: total,
+		Items: make([]dto.StorageMigrationItemResponse, len(items)),
+	}
+	for i := range items {
+		resp.Items[i] = dto.ConvertToStorageMigrationItemResponse(&items[i])
+	}
+	return resp, nil
+}
+
+// find 查找迁移任务
+func (s *StorageMigrationService) find(id uint) (*model.StorageMigration, error) {
+	migration, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStorageMigrationNotFound
+		}
+		return nil, err
+	}
+	return migration, nil
+}
+
+// begin 标记任务开始并在后台执行，同一时间只允许一个任务运行
+func (s *StorageMigrationService) begin(migration *model.StorageMigration, create, skipFailed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active != 0 {
+		return ErrStorageMigrationRunning
+	}
+
+	now := time.Now()
+	migration.Status = model.StorageMigrationRunning
+	migration.StartedAt = &now
+	migration.FinishedAt = nil
+	migration.LastError = ""
+
+	var err error
+	if create {
+		err = s.repo.Create(migration)
+	} else {
+		err = s.repo.Update(migration)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.active = migration.ID
+	snapshot := *migration
+	go s.run(&snapshot, skipFailed)
+	return nil
+}
+
+// run 执行迁移：规划对象、复制（或试运行时检查）并改写URL
+func (s *StorageMigrationService) run(migration *model.StorageMigration, skipFailed bool) {
+	defer func() {
+		s.mu.Lock()
+		s.active = 0
+		s.mu.Unlock()
+	}()
+
+	if err := s.execute(migration, skipFailed); err != nil {
+		log.Printf("Storage migration %d failed: %v", migration.ID, err)
+		migration.Status = model.StorageMigrationFailed
+		migration.LastError = err.Error()
+	} else {
+		migration.Status = model.StorageMigrationCompleted
+	}
+
+	now := time.Now()
+	migration.FinishedAt = &now
+	if err := s.repo.Update(migration); err != nil {
+		log.Printf("Failed to save storage migration %d: %v", migration.ID, err)
+	}
+}
+
+// execute 执行迁移的各个阶段
+func (s *StorageMigrationService) execute(migration *model.StorageMigration, skipFailed bool) error {
+	src, err := s.open(migration.Source)
+	if err != nil {
+		return err
+	}
+	dst, err := s.open(migration.Target)
+	if err != nil {
+		return err
+	}
+
+	// 首次执行时扫描数据库，记录需要迁移的对象
+	planned, err := s.repo.CountItems(migration.ID, "")
+	if err != nil {
+		return err
+	}
+	if planned == 0 {
+		if err := s.plan(migration, src); err != nil {
+			return err
+		}
+	}
+
+	if migration.DryRun {
+		return s.dryRun(migration, src)
+	}
+
+	if err := s.copyAll(migration, src, dst); err != nil {
+		return err
+	}
+	if migration.Failed > 0 && !skipFailed {
+		return fmt.Errorf("%d objects failed to copy, resume to retry or skip them", migration.Failed)
+	}
+
+	// 全部复制完成后在一个事务中改写URL
+	mapping, err := s.urlMapping(migration.ID)
+	if err != nil {
+		return err
+	}
+	rewritten, err := s.repo.RewriteURLs(migration.Source, migration.Target, func(text string) string {
+		return rewriteStoredURLs(text, mapping)
+	}, true)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite urls: %w", err)
+	}
+	migration.Rewritten = rewritten
+	log.Printf("Storage migration %d completed: %d objects copied, %d records rewritten", migration.ID, migration.Copied, rewritten)
+	return nil
+}
+
+// plan 收集数据库中属于源存储的URL，为每个对象创建迁移记录
+func (s *StorageMigrationService) plan(migration *model.StorageMigration, src storage.Storage) error {
+	seen := make(map[string]bool)
+	var items []model.StorageMigrationItem
+	err := s.repo.EachStoredText(func(text string) {
+		for _, candidate := range mediaURLPattern.FindAllString(text, -1) {
+			sourceURL := stripURLQuery(candidate)
+			key, ok := src.KeyFromURL(sourceURL)
+			if !ok || seen[sourceURL] {
+				continue
+			}
+			seen[sourceURL] = true
+			items = append(items, model.StorageMigrationItem{
+				MigrationID: migration.ID,
+				Key:         key,
+				SourceURL:   sourceURL,
+				Status:      model.StorageMigrationItemPending,
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateItems(items); err != nil {
+		return err
+	}
+	migration.Total = len(items)
+	return s.repo.Update(migration)
+}
+
+// dryRun 检查每个对象在源存储中是否存在，并统计将被改写的记录数
+func (s *StorageMigrationService) dryRun(migration *model.StorageMigration, src storage.Storage) error {
+	pending := []string{model.StorageMigrationItemPending}
+	err := s.eachItem(migration.ID, pending, func(item *model.StorageMigrationItem) error {
+		if item.TargetURL != "" {
+			return nil
+		}
+		reader, err := src.Get(item.Key)
+		if err != nil {
+			item.Status = model.StorageMigrationItemMissing
+			item.Error = err.Error()
+			migration.Missing++
+		} else {
+			reader.Close()
+			// 试运行不复制文件，使用占位URL以统计会被改写的记录
+			item.TargetURL = migration.Target + ":" + item.Key
+		}
+		if err := s.repo.UpdateItem(item); err != nil {
+			return err
+		}
+		return s.repo.Update(migration)
+	})
+	if err != nil {
+		return err
+	}
+
+	mapping := make(map[string]string)
+	err = s.eachItem(migration.ID, pending, func(item *model.StorageMigrationItem) error {
+		mapping[item.SourceURL] = item.TargetURL
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	migration.Rewritten, err = s.repo.RewriteURLs(migration.Source, migration.Target, func(text string) string {
+		return rewriteStoredURLs(text, mapping)
+	}, false)
+	return err
+}
+
+// copyAll 复制尚未完成的对象，之前失败的对象会重试
+func (s *StorageMigrationService) copyAll(migration *model.StorageMigration, src, dst storage.Storage) error {
+	migration.Failed = 0
+	statuses := []string{model.StorageMigrationItemPending, model.StorageMigrationItemFailed}
+	return s.eachItem(migration.ID, statuses, func(item *model.StorageMigrationItem) error {
+		targetURL, err := copyObject(src, dst, item.Key)
+		if err != nil {
+			log.Printf("Storage migration %d failed to copy %s: %v", migration.ID, item.Key, err)
+			item.Status = model.StorageMigrationItemFailed
+			item.Error = err.Error()
+			migration.Failed++
+		} else {
+			item.Status = model.StorageMigrationItemCopied
+			item.TargetURL = targetURL
+			item.Error = ""
+			migration.Copied++
+		}
+		if err := s.repo.UpdateItem(item); err != nil {
+			return err
+		}
+
+		// 每个对象完成后保存进度
+		return s.repo.Update(migration)
+	})
+}
+
+// eachItem 按ID顺序遍历指定状态的迁移对象
+func (s *StorageMigrationService) eachItem(migrationID uint, statuses []string, fn func(item *model.StorageMigrationItem) error) error {
+	var lastID uint
+	for {
+		items, err := s.repo.NextItems(migrationID, lastID, statuses, 100)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			if err := fn(&items[i]); err != nil {
+				return err
+			}
+		}
+		lastID = items[len(items)-1].ID
+	}
+}
+
+// urlMapping 返回已复制对象的源URL到目标URL的映射
+func (s *StorageMigrationService) urlMapping(migrationID uint) (map[string]string, error) {
+	mapping := make(map[string]string)
+	err := s.eachItem(migrationID, []string{model.StorageMigrationItemCopied}, func(item *model.StorageMigrationItem) error {
+		mapping[item.SourceURL] = item.TargetURL
+		return nil
+	})
+	return mapping, err
+}
+
+// open 根据存储类型创建存储实例，其余配置沿用当前配置
+func (s *StorageMigrationService) open(storageType string) (storage.Storage, error) {
+	cfg := *s.config
+	cfg.Type = storageType
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrStorageMigrationConfig, storageType, err)
+	}
+	return storage.DefaultFactory.CreateStorage(&cfg)
+}
+
+// copyObject 从源存储读取对象并以相同的键写入目标存储，返回目标URL
+func copyObject(src, dst storage.Storage, key string) (string, error) {
+	reader, err := src.Get(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return "", err
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	result, err := dst.PutObject(key, bytes.NewReader(data), contentType, int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+// rewriteStoredURLs 替换文本中属于映射表的URL，保留原URL的查询参数和片段
+func rewriteStoredURLs(text string, mapping map[string]string) string {
+	if text == "" || len(mapping) == 0 {
+		return text
+	}
+	return mediaURLPattern.ReplaceAllStringFunc(text, func(candidate string) string {
+		base := stripURLQuery(candidate)
+		target, ok := mapping[base]
+		if !ok {
+			return candidate
+		}
+		return target + candidate[len(base):]
+	})
+}
+
+// stripURLQuery 去除URL中的查询参数和片段
+func stripURLQuery(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
@@ -1,58 +1,347 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"notex/api/dto"
 	"notex/api/repository"
+	"notex/config"
 	"notex/model"
+	"notex/pkg/policy"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagForbidden    = errors.New("no permission to manage tags")
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrTagNameInvalid  = errors.New("tag name must be 1 to 50 characters")
+	ErrTagNameTaken    = errors.New("tag name is already used by another tag or alias")
+	ErrTagInvalidSlug  = errors.New("tag slug may only contain lowercase letters, digits and hyphens")
+	ErrTagSlugTaken    = errors.New("tag slug is already taken")
+	ErrTagMergeInvalid = errors.New("tags to merge must exist and differ from the target tag")
 )
 
 type TagService struct {
-	repo *repository.TagRepository
+	repo      *repository.TagRepository
+	auditLogs *repository.AuditLogRepository
+	users     *repository.UserRepository
+	config    *config.TagsConfig
 }
 
-func NewTagService() *TagService {
+func NewTagService(cfg *config.TagsConfig) *TagService {
 	return &TagService{
-		repo: repository.NewTagRepository(),
+		repo:      repository.NewTagRepository(),
+		auditLogs: repository.NewAuditLogRepository(),
+		users:     repository.NewUserRepository(),
+		config:    cfg,
 	}
 }
 
-// CreateTag 创建标签
-func (s *TagService) CreateTag(req *dto.CreateTagRequest) (*dto.TagResponse, error) {
+// CreateTag 创建标签。名称或别名与已有标签相同（不区分大小写）时不创建新标签，返回已有标签，created 为 false
+func (s *TagService) CreateTag(userID uint, role string, req *dto.CreateTagRequest) (response *dto.TagResponse, created bool, err error) {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagCreate, nil); err != nil {
+		return nil, false, ErrTagForbidden
+	}
+
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := s.repo.Resolve(name)
+	if err == nil {
+		response, err := s.convertToResponse(existing)
+		return response, false, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
 	tag := &model.Tag{
-		Name: req.Name,
+		Name:        name,
+		Description: req.Description,
+	}
+	if err := s.assignSlug(tag, req.Slug); err != nil {
+		return nil, false, err
 	}
 
 	if err := s.repo.Create(tag); err != nil {
+		return nil, false, err
+	}
+	s.audit(userID, "create", tag.ID, map[string]interface{}{"name": tag.Name, "slug": tag.Slug})
+
+	response, err = s.convertToResponse(tag)
+	return response, true, err
+}
+
+// UpdateTag 更新标签，标签由全部工作区共用。重命名时可以将原名称保留为别名
+func (s *TagService) UpdateTag(id, userID uint, role string, req *dto.UpdateTagRequest) (*dto.TagResponse, error) {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return nil, ErrTagForbidden
+	}
+
+	tag, err := s.find(id)
+	if err != nil {
 		return nil, err
 	}
+	oldName := tag.Name
 
+	if req.Description != nil {
+		tag.Description = *req.Description
+	}
+	if req.Slug != "" {
+		if err := s.assignSlug(tag, req.Slug); err != nil {
+			return nil, err
+		}
+	}
+
+	var alias *model.TagAlias
+	if req.Name != "" {
+		name, err := normalizeTagName(req.Name)
+		if err != nil {
+			return nil, err
+		}
+		// 标签自身的别名可以改为名称，原别名随之删除
+		taken, err := s.repo.NameTaken(name, tag.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrTagNameTaken
+		}
+		// 只改变大小写时原名称与新名称冲突，不能保留为别名
+		if req.KeepAlias && !strings.EqualFold(name, oldName) {
+			alias = &model.TagAlias{TagID: tag.ID, Name: oldName}
+		}
+		tag.Name = name
+	}
+
+	if err := s.repo.Rename(tag, alias); err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{"name": tag.Name, "slug": tag.Slug}
+	if tag.Name != oldName {
+		details["old_name"] = oldName
+		details["keep_alias"] = alias != nil
+		s.audit(userID, "rename", tag.ID, details)
+	} else {
+		s.audit(userID, "update", tag.ID, details)
+	}
+
+	tag, err = s.find(id)
+	if err != nil {
+		return nil, err
+	}
 	return s.convertToResponse(tag)
 }
 
-// UpdateTag 更新标签
-func (s *TagService) UpdateTag(id uint, req *dto.UpdateTagRequest) (*dto.TagResponse, error) {
-	tag, err := s.repo.FindByID(id)
+// DeleteTag 删除标签，文章和草稿不再使用该标签
+func (s *TagService) DeleteTag(id, userID uint, role string) error {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return ErrTagForbidden
+	}
+
+	tag, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.audit(userID, "delete", tag.ID, map[string]interface{}{"name": tag.Name})
+	return nil
+}
+
+// MergeTags 将多个标签合并到目标标签，例如将 "golang" 和 "go-lang" 合并到 "Go"。
+// 使用被合并标签的文章和草稿改用目标标签，被合并标签的名称和别名成为目标标签的别名
+func (s *TagService) MergeTags(targetID, userID uint, role string, req *dto.MergeTagsRequest) (*dto.MergeTagsResponse, error) {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return nil, ErrTagForbidden
+	}
+
+	target, err := s.find(targetID)
 	if err != nil {
 		return nil, err
 	}
 
-	tag.Name = req.Name
+	sourceIDs := slices.Compact(slices.Sorted(slices.Values(req.SourceIDs)))
+	if slices.Contains(sourceIDs, targetID) {
+		return nil, ErrTagMergeInvalid
+	}
+	sources, err := s.repo.FindByIDs(sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) != len(sourceIDs) {
+		return nil, ErrTagMergeInvalid
+	}
 
-	if err := s.repo.Update(tag); err != nil {
+	posts, drafts, err := s.repo.Merge(target, sources)
+	if err != nil {
 		return nil, err
 	}
 
+	merged := make([]map[string]interface{}, len(sources))
+	for i, source := range sources {
+		merged[i] = map[string]interface{}{"id": source.ID, "name": source.Name}
+	}
+	s.audit(userID, "merge", target.ID, map[string]interface{}{
+		"name":   target.Name,
+		"merged": merged,
+		"posts":  posts,
+		"drafts": drafts,
+	})
+
+	target, err = s.find(targetID)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.convertToResponse(target)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MergeTagsResponse{Tag: response, Posts: posts, Drafts: drafts}, nil
+}
+
+// AddAlias 为标签添加别名，别名不能与任何标签的名称或别名相同（不区分大小写）
+func (s *TagService) AddAlias(tagID, userID uint, role string, req *dto.TagAliasRequest) (*dto.TagResponse, error) {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return nil, ErrTagForbidden
+	}
+
+	tag, err := s.find(tagID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := s.repo.NameTaken(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrTagNameTaken
+	}
+
+	alias := &model.TagAlias{TagID: tag.ID, Name: name}
+	if err := s.repo.CreateAlias(alias); err != nil {
+		return nil, err
+	}
+	s.audit(userID, "alias_add", tag.ID, map[string]interface{}{"name": tag.Name, "alias": alias.Name})
+
+	tag, err = s.find(tagID)
+	if err != nil {
+		return nil, err
+	}
 	return s.convertToResponse(tag)
 }
 
-// DeleteTag 删除标签
-func (s *TagService) DeleteTag(id uint) error {
-	return s.repo.Delete(id)
+// DeleteAlias 删除标签别名
+func (s *TagService) DeleteAlias(tagID, aliasID, userID uint, role string) error {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return ErrTagForbidden
+	}
+
+	alias, err := s.repo.FindAlias(tagID, aliasID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTagNotFound
+		}
+		return err
+	}
+	if err := s.repo.DeleteAlias(alias.ID); err != nil {
+		return err
+	}
+	s.audit(userID, "alias_delete", tagID, map[string]interface{}{"alias": alias.Name})
+	return nil
+}
+
+// ListUnusedTags 列出创建超过 unused_after 且没有文章和草稿使用、也没有用户关注的标签
+func (s *TagService) ListUnusedTags(userID uint, role string) ([]dto.TagResponse, error) {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return make([]dto.TagResponse, 0), ErrTagForbidden
+	}
+
+	tags, err := s.repo.ListUnused(time.Now().Add(-s.config.UnusedAfter))
+	if err != nil {
+		return make([]dto.TagResponse, 0), err
+	}
+	responses := make([]dto.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		response, err := s.convertToResponse(&tag)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// DeleteUnusedTags 立即删除未使用的标签，返回删除的数量
+func (s *TagService) DeleteUnusedTags(userID uint, role string) (int, error) {
+	if err := policy.Authorize(newSubject(userID, role), policy.TagManage, nil); err != nil {
+		return 0, ErrTagForbidden
+	}
+	return s.cleanupUnused(userID)
+}
+
+// Run 定期检查未使用的标签，按配置记录或删除，直到 ctx 结束
+func (s *TagService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.config.UnusedAction == "delete" {
+				if _, err := s.cleanupUnused(0); err != nil {
+					log.Printf("Failed to delete unused tags: %v", err)
+				}
+				continue
+			}
+
+			tags, err := s.repo.ListUnused(time.Now().Add(-s.config.UnusedAfter))
+			if err != nil {
+				log.Printf("Failed to list unused tags: %v", err)
+				continue
+			}
+			if len(tags) == 0 {
+				continue
+			}
+			log.Printf("Found %d unused tags", len(tags))
+			s.audit(0, "report_unused", 0, map[string]interface{}{"tags": tagSummaries(tags)})
+		}
+	}
+}
+
+// cleanupUnused 删除未使用的标签并记录审计日志，userID 为 0 时表示由后台任务执行
+func (s *TagService) cleanupUnused(userID uint) (int, error) {
+	tags, err := s.repo.DeleteUnused(time.Now().Add(-s.config.UnusedAfter))
+	if err != nil {
+		return 0, err
+	}
+	if len(tags) > 0 {
+		log.Printf("Deleted %d unused tags", len(tags))
+		s.audit(userID, "delete_unused", 0, map[string]interface{}{"tags": tagSummaries(tags)})
+	}
+	return len(tags), nil
 }
 
 // GetTag 获取标签详情
 func (s *TagService) GetTag(id uint) (*dto.TagResponse, error) {
-	tag, err := s.repo.FindByID(id)
+	tag, err := s.find(id)
 	if err != nil {
 		return nil, err
 	}
@@ -97,6 +386,99 @@ func (s *TagService) GetTopTags(limit int) ([]dto.TagResponse, error) {
 	return responses, nil
 }
 
+// find 查找标签
+func (s *TagService) find(id uint) (*model.Tag, error) {
+	tag, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// assignSlug 设置标签的 slug，未指定时根据名称生成，重复时依次追加 -2、-3……
+func (s *TagService) assignSlug(tag *model.Tag, slug string) error {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug != "" {
+		// 与工作区 slug 的格式规则相同
+		if len(slug) > 100 || !workspaceSlugPattern.MatchString(slug) {
+			return ErrTagInvalidSlug
+		}
+		taken, err := s.repo.ExistsBySlug(slug, tag.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrTagSlugTaken
+		}
+		tag.Slug = slug
+		return nil
+	}
+
+	base := slugFromName(tag.Name, "tag")
+	slug = base
+	for i := 2; ; i++ {
+		taken, err := s.repo.ExistsBySlug(slug, tag.ID)
+		if err != nil {
+			return err
+		}
+		if !taken {
+			tag.Slug = slug
+			return nil
+		}
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// audit 记录标签管理操作，userID 为 0 时表示由后台任务执行。记录失败只写入日志，不影响操作本身
+func (s *TagService) audit(userID uint, action string, tagID uint, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	entry := &model.AuditLog{
+		UserID:   userID,
+		Action:   action,
+		Resource: "tag",
+		Details:  string(detailsJSON),
+		Status:   "success",
+	}
+	if tagID > 0 {
+		entry.ResourceID = strconv.FormatUint(uint64(tagID), 10)
+	}
+
+	var err error
+	if userID == 0 {
+		entry.Username = "system"
+		err = s.auditLogs.CreateSystem(entry)
+	} else {
+		if user, findErr := s.users.FindByID(userID); findErr == nil {
+			entry.Username = user.Username
+		}
+		err = s.auditLogs.Create(entry)
+	}
+	if err != nil {
+		log.Printf("Failed to record tag %s audit log: %v", action, err)
+	}
+}
+
+// normalizeTagName 去除名称首尾的空白并将连续空白合并为一个空格，名称保留原有大小写
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > 50 {
+		return "", ErrTagNameInvalid
+	}
+	return name, nil
+}
+
+// tagSummaries 返回审计日志中记录的标签ID和名称
+func tagSummaries(tags []model.Tag) []map[string]interface{} {
+	summaries := make([]map[string]interface{}, len(tags))
+	for i, tag := range tags {
+		summaries[i] = map[string]interface{}{"id": tag.ID, "name": tag.Name}
+	}
+	return summaries
+}
+
 // convertToResponse 将标签模型转换为响应DTO
 func (s *TagService) convertToResponse(tag *model.Tag) (*dto.TagResponse, error) {
 	postCount, err := s.repo.GetPostCount(tag.ID)
@@ -104,11 +486,19 @@ func (s *TagService) convertToResponse(tag *model.Tag) (*dto.TagResponse, error)
 		return nil, err
 	}
 
+	aliases := make([]dto.TagAliasInfo, 0, len(tag.Aliases))
+	for _, alias := range tag.Aliases {
+		aliases = append(aliases, dto.TagAliasInfo{ID: alias.ID, Name: alias.Name})
+	}
+
 	return &dto.TagResponse{
-		ID:        tag.ID,
-		Name:      tag.Name,
-		PostCount: postCount,
-		CreatedAt: tag.CreatedAt,
-		UpdatedAt: tag.UpdatedAt,
+		ID:          tag.ID,
+		Name:        tag.Name,
+		Slug:        tag.Slug,
+		Description: tag.Description,
+		Aliases:     aliases,
+		PostCount:   postCount,
+		CreatedAt:   tag.CreatedAt,
+		UpdatedAt:   tag.UpdatedAt,
 	}, nil
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"notex/api/dto"
 	"notex/api/repository"
@@ -14,12 +15,15 @@ import (
 type VerificationService struct {
 	repo     *repository.VerificationRepository
 	userRepo *repository.UserRepository
+	comments *CommentService
 }
 
-func NewVerificationService() *VerificationService {
+// NewVerificationService 创建验证服务，邮箱验证后通过 comments 认领访客评论
+func NewVerificationService(comments *CommentService) *VerificationService {
 	return &VerificationService{
 		repo:     repository.NewVerificationRepository(),
 		userRepo: repository.NewUserRepository(),
+		comments: comments,
 	}
 }
 
@@ -96,7 +100,15 @@ func (s *VerificationService) VerifyEmail(req *dto.VerifyEmailRequest) error {
 	}
 
 	// 标记邮箱为已验证
-	return s.userRepo.MarkEmailAsVerified(user.ID)
+	if err := s.userRepo.MarkEmailAsVerified(user.ID); err != nil {
+		return err
+	}
+
+	// 邮箱已确认属于该用户，认领之前用该邮箱发表的访客评论，失败不影响验证
+	if _, err := s.comments.claimGuests(user); err != nil {
+		log.Printf("Failed to claim guest comments for user %d: %v", user.ID, err)
+	}
+	return nil
 }
 
 // UpdateEmail 更新邮箱
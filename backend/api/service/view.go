@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/config"
+	"notex/model"
+	"notex/pkg/policy"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// topReferrerLimit 浏览统计中返回的来源域名数量
+const topReferrerLimit = 10
+
+// ViewEvent 一次文章浏览
+type ViewEvent struct {
+	PostID    uint
+	UserID    uint // 登录用户，未登录时为 0
+	IP        string
+	UserAgent string
+	Referrer  string
+	Host      string // 请求的主机名，来自本站的访问不计入来源
+}
+
+// viewRecord 缓冲中的浏览记录
+type viewRecord struct {
+	postID   uint
+	visitor  string
+	referrer string
+	at       time.Time
+}
+
+// ViewService 统计文章浏览量。浏览记录先缓冲在内存中，由 Run 定期或在缓冲达到批量大小时批量写入，
+// 同一访客在去重窗口内重复访问同一文章只计一次，爬虫不计入
+type ViewService struct {
+	repo   *repository.PostViewRepository
+	posts  *repository.PostRepository
+	config *config.ViewsConfig
+
+	mu      sync.Mutex
+	records []viewRecord
+	seen    map[string]time.Time // 文章和访客最近一次计入浏览的时间
+	full    chan struct{}        // 缓冲达到批量大小时通知 Run 写入
+
+	cleanedBefore time.Time // 已清理该日期之前的访客记录
+}
+
+func NewViewService(cfg *config.ViewsConfig) *ViewService {
+	return &ViewService{
+		repo:   repository.NewPostViewRepository(),
+		posts:  repository.NewPostRepository(),
+		config: cfg,
+		seen:   make(map[string]time.Time),
+		full:   make(chan struct{}, 1),
+	}
+}
+
+// Record 记录一次浏览，不访问数据库
+func (s *ViewService) Record(event ViewEvent) {
+	if s.isBot(event.UserAgent) {
+		return
+	}
+
+	now := time.Now()
+	visitor := visitorID(event)
+	key := fmt.Sprintf("%d:%s", event.PostID, visitor)
+
+	s.mu.Lock()
+	if last, ok := s.seen[key]; ok && now.Sub(last) < s.config.DedupWindow {
+		s.mu.Unlock()
+		return
+	}
+	if len(s.records) >= s.config.MaxBuffer {
+		s.mu.Unlock()
+		return
+	}
+	s.seen[key] = now
+	s.records = append(s.records, viewRecord{
+		postID:   event.PostID,
+		visitor:  visitor,
+		referrer: referrerDomain(event.Referrer, event.Host),
+		at:       now,
+	})
+	full := len(s.records) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run 定期将缓冲的浏览记录写入数据库，直到 ctx 结束，结束前写入剩余的记录
+func (s *ViewService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Flush(); err != nil {
+				log.Printf("Failed to flush post views: %v", err)
+			}
+			return
+		case <-ticker.C:
+		case <-s.full:
+		}
+
+		if err := s.Flush(); err != nil {
+			log.Printf("Failed to flush post views: %v", err)
+		}
+		s.cleanupVisitors()
+	}
+}
+
+// Flush 将缓冲的浏览记录按文章和日期汇总后写入数据库，失败时记录放回缓冲等待下次写入
+func (s *ViewService) Flush() error {
+	now := time.Now()
+
+	s.mu.Lock()
+	records := s.records
+	s.records = nil
+	for key, last := range s.seen {
+		if now.Sub(last) >= s.config.DedupWindow {
+			delete(s.seen, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Apply(aggregateViews(records)); err != nil {
+		s.mu.Lock()
+		s.records = append(records, s.records...)
+		if len(s.records) > s.config.MaxBuffer {
+			s.records = s.records[:s.config.MaxBuffer]
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// cleanupVisitors 每天清理一次前一天之前的访客记录，这些日期的独立访客数已不会再变化
+func (s *ViewService) cleanupVisitors() {
+	before := viewDate(time.Now()).AddDate(0, 0, -1)
+	if !before.After(s.cleanedBefore) {
+		return
+	}
+	if _, err := s.repo.DeleteVisitorsBefore(before); err != nil {
+		log.Printf("Failed to clean up post view visitors: %v", err)
+		return
+	}
+	s.cleanedBefore = before
+}
+
+// GetPostStats 获取文章最近 days 天的浏览统计，作者、工作区维护者和管理员可以查看
+func (s *ViewService) GetPostStats(viewer *PostViewer, postID uint, days int) (*dto.PostViewStatsResponse, error) {
+	post, err := s.posts.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if err := authorizePost(viewer, policy.PostStats, post); err != nil {
+		return nil, err
+	}
+
+	to := viewDate(time.Now())
+	from := to.AddDate(0, 0, 1-days)
+
+	stats, err := s.repo.DailyStats(postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := s.repo.TopReferrers(postID, from, to, topReferrerLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PostViewStatsResponse{
+		PostID:     postID,
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		TotalViews: post.Views,
+		Daily:      fillDailyViews(stats, from, days),
+		Referrers:  convertReferrers(referrers),
+	}
+	for _, day := range response.Daily {
+		response.Views += day.Views
+		response.UniqueVisitors += day.UniqueVisitors
+	}
+	return response, nil
+}
+
+// isBot 根据 User-Agent 判断是否是爬虫，没有 User-Agent 的请求同样视为爬虫
+func (s *ViewService) isBot(userAgent string) bool {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+	for _, pattern := range s.config.BotPatterns {
+		if strings.Contains(userAgent, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregateViews 按文章和日期汇总浏览记录
+func aggregateViews(records []viewRecord) []repository.ViewDelta {
+	type deltaKey struct {
+		postID uint
+		date   time.Time
+	}
+
+	index := make(map[deltaKey]int)
+	visitors := make(map[deltaKey]map[string]struct{})
+	var deltas []repository.ViewDelta
+	for _, record := range records {
+		key := deltaKey{postID: record.postID, date: viewDate(record.at)}
+		i, ok := index[key]
+		if !ok {
+			i = len(deltas)
+			index[key] = i
+			visitors[key] = make(map[string]struct{})
+			deltas = append(deltas, repository.ViewDelta{PostID: key.postID, Date: key.date, Referrers: make(map[string]int64)})
+		}
+
+		delta := &deltas[i]
+		delta.Views++
+		if _, ok := visitors[key][record.visitor]; !ok {
+			visitors[key][record.visitor] = struct{}{}
+			delta.Visitors = append(delta.Visitors, record.visitor)
+		}
+		if record.referrer != "" {
+			delta.Referrers[record.referrer]++
+		}
+	}
+	return deltas
+}
+
+// fillDailyViews 将每日汇总展开为从 from 开始连续 days 天的序列，没有浏览的日期补零
+func fillDailyViews(stats []model.PostViewStat, from time.Time, days int) []dto.DailyViewStat {
+	byDate := make(map[string]model.PostViewStat, len(stats))
+	for _, stat := range stats {
+		byDate[stat.Date.Format("2006-01-02")] = stat
+	}
+
+	daily := make([]dto.DailyViewStat, 0, days)
+	for i := 0; i < days; i++ {
+		date := from.AddDate(0, 0, i).Format("2006-01-02")
+		stat := byDate[date]
+		daily = append(daily, dto.DailyViewStat{Date: date, Views: stat.Views, UniqueVisitors: stat.UniqueVisitors})
+	}
+	return daily
+}
+
+// visitorID 由登录用户或 IP 和 User-Agent 生成访客标识的哈希，不保存原始 IP
+func visitorID(event ViewEvent) string {
+	identity := "ip:" + event.IP + "|" + event.UserAgent
+	if event.UserID != 0 {
+		identity = fmt.Sprintf("user:%d", event.UserID)
+	}
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:])
+}
+
+// referrerDomain 提取站外来源的域名，忽略 www 前缀；直接访问和站内跳转返回空字符串
+func referrerDomain(referrer, host string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if domain == "" || domain == strings.TrimPrefix(strings.ToLower(host), "www.") {
+		return ""
+	}
+	if len(domain) > 255 {
+		domain = domain[:255]
+	}
+	return domain
+}
+
+// viewDate 返回时间所在的 UTC 日期
+func viewDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"notex/api/dto"
+	"notex/api/repository"
+	"notex/model"
+	"notex/pkg/email"
+	"notex/pkg/policy"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWorkspaceNotFound          = errors.New("workspace not found")
+	ErrWorkspaceForbidden         = errors.New("insufficient workspace permissions")
+	ErrWorkspaceSlugTaken         = errors.New("workspace slug is already taken")
+	ErrWorkspaceInvalidSlug       = errors.New("workspace slug may only contain lowercase letters, digits and hyphens")
+	ErrWorkspaceLastOwner         = errors.New("workspace must keep at least one owner")
+	ErrWorkspaceMemberNotFound    = errors.New("workspace member not found")
+	ErrWorkspaceMemberExists      = errors.New("user is already a member of the workspace")
+	ErrWorkspaceInvitationInvalid = errors.New("invitation is invalid or has expired")
+)
+
+// invitationExpiration 工作区邀请的有效期
+const invitationExpiration = 7 * 24 * time.Hour
+
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+type WorkspaceService struct {
+	repo     *repository.WorkspaceRepository
+	userRepo *repository.UserRepository
+}
+
+func NewWorkspaceService() *WorkspaceService {
+	return &WorkspaceService{
+		repo:     repository.NewWorkspaceRepository(),
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// CreateWorkspace 创建工作区，创建者成为所有者
+func (s *WorkspaceService) CreateWorkspace(userID uint, req *dto.CreateWorkspaceRequest) (*dto.WorkspaceResponse, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !workspaceSlugPattern.MatchString(slug) {
+		return nil, ErrWorkspaceInvalidSlug
+	}
+	exists, err := s.repo.ExistsBySlug(slug, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrWorkspaceSlugTaken
+	}
+
+	workspace := &model.Workspace{
+		Name:        req.Name,
+		Slug:        slug,
+		Description: req.Description,
+		OwnerID:     userID,
+	}
+	if err := s.repo.Create(workspace); err != nil {
+		return nil, err
+	}
+
+	return convertWorkspaceToResponse(workspace, model.WorkspaceRoleOwner), nil
+}
+
+// ListWorkspaces 获取用户加入的工作区
+func (s *WorkspaceService) ListWorkspaces(userID uint) ([]dto.WorkspaceResponse, error) {
+	members, err := s.repo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WorkspaceResponse, 0, len(members))
+	for _, member := range members {
+		if member.Workspace == nil {
+			continue
+		}
+		responses = append(responses, *convertWorkspaceToResponse(member.Workspace, member.Role))
+	}
+	return responses, nil
+}
+
+// GetWorkspace 获取工作区详情，需要是工作区成员
+func (s *WorkspaceService) GetWorkspace(id, userID uint, role string) (*dto.WorkspaceResponse, error) {
+	subject := newSubject(userID, role)
+	if err := s.authorize(subject, policy.WorkspaceView, id, ""); err != nil {
+		return nil, err
+	}
+	workspace, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return convertWorkspaceToResponse(workspace, subject.WorkspaceRole(id)), nil
+}
+
+// UpdateWorkspace 更新工作区信息
+func (s *WorkspaceService) UpdateWorkspace(id, userID uint, role string, req *dto.UpdateWorkspaceRequest) (*dto.WorkspaceResponse, error) {
+	subject := newSubject(userID, role)
+	if err := s.authorize(subject, policy.WorkspaceUpdate, id, ""); err != nil {
+		return nil, err
+	}
+	workspace, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		workspace.Name = req.Name
+	}
+	if req.Description != nil {
+		workspace.Description = *req.Description
+	}
+	if err := s.repo.Update(workspace); err != nil {
+		return nil, err
+	}
+
+	return convertWorkspaceToResponse(workspace, subject.WorkspaceRole(id)), nil
+}
+
+// DeleteWorkspace 删除工作区
+func (s *WorkspaceService) DeleteWorkspace(id, userID uint, role string) error {
+	if err := s.authorize(newSubject(userID, role), policy.WorkspaceDelete, id, ""); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// ListMembers 获取工作区成员列表
+func (s *WorkspaceService) ListMembers(id, userID uint, role string) ([]dto.WorkspaceMemberResponse, error) {
+	if err := s.authorize(newSubject(userID, role), policy.WorkspaceView, id, ""); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WorkspaceMemberResponse, 0, len(members))
+	for _, member := range members {
+		response := dto.WorkspaceMemberResponse{
+			ID:        member.ID,
+			UserID:    member.UserID,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		}
+		if member.User != nil {
+			response.Username = member.User.Username
+			response.Avatar = member.User.Avatar
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// UpdateMember 修改成员角色，需要同时有权管理成员的原角色和新角色
+func (s *WorkspaceService) UpdateMember(id, memberUserID, userID uint, role string, newRole string) error {
+	subject := newSubject(userID, role)
+	if err := s.authorize(subject, policy.WorkspaceView, id, ""); err != nil {
+		return err
+	}
+	member, err := s.findMember(id, memberUserID)
+	if err != nil {
+		return err
+	}
+	for _, target := range []string{member.Role, newRole} {
+		if err := s.authorize(subject, policy.WorkspaceManageMembers, id, target); err != nil {
+			return err
+		}
+	}
+	if member.Role == newRole {
+		return nil
+	}
+	if member.Role == model.WorkspaceRoleOwner {
+		if err := s.ensureAnotherOwner(id); err != nil {
+			return err
+		}
+	}
+	return s.repo.UpdateMemberRole(member.ID, newRole)
+}
+
+// RemoveMember 移除成员，成员可以自行退出，工作区必须保留至少一名所有者
+func (s *WorkspaceService) RemoveMember(id, memberUserID, userID uint, role string) error {
+	subject := newSubject(userID, role)
+	if err := s.authorize(subject, policy.WorkspaceView, id, ""); err != nil {
+		return err
+	}
+	member, err := s.findMember(id, memberUserID)
+	if err != nil {
+		return err
+	}
+
+	resource := &policy.Resource{OwnerID: member.UserID, WorkspaceID: &id, TargetRole: member.Role}
+	if !policy.Allowed(subject, policy.WorkspaceLeave, resource) &&
+		!policy.Allowed(subject, policy.WorkspaceManageMembers, resource) {
+		return ErrWorkspaceForbidden
+	}
+	if member.Role == model.WorkspaceRoleOwner {
+		if err := s.ensureAnotherOwner(id); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteMember(member.ID)
+}
+
+// InviteMember 邀请用户加入工作区，邀请令牌通过邮件发送，同一邮箱的旧邀请随之失效
+func (s *WorkspaceService) InviteMember(id, userID uint, role string, req *dto.InviteWorkspaceMemberRequest) (*dto.WorkspaceInvitationResponse, error) {
+	if err := s.authorize(newSubject(userID, role), policy.WorkspaceManageMembers, id, req.Role); err != nil {
+		return nil, err
+	}
+	workspace, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	address := strings.ToLower(strings.TrimSpace(req.Email))
+	if invitee, err := s.userRepo.FindByEmail(address); err == nil {
+		if _, err := s.repo.FindMember(id, invitee.ID); err == nil {
+			return nil, ErrWorkspaceMemberExists
+		}
+	}
+
+	inviter, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &model.WorkspaceInvitation{
+		WorkspaceID: id,
+		Email:       address,
+		Role:        req.Role,
+		TokenHash:   hashInvitationToken(token),
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().Add(invitationExpiration),
+	}
+	if err := s.repo.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	locale := "zh-CN" // 默认使用中文
+	days := int(invitationExpiration / (24 * time.Hour))
+	if err := email.SendWorkspaceInvitationEmail(address, inviter.Username, workspace.Name, req.Role, token, days, locale); err != nil {
+		// 邮件未送达时撤销邀请，避免留下无人知晓的有效令牌
+		if deleteErr := s.repo.DeleteInvitation(invitation.ID); deleteErr != nil {
+			log.Printf("Failed to delete workspace invitation %d: %v", invitation.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	invitation.Inviter = inviter
+	return convertInvitationToResponse(invitation), nil
+}
+
+// ListInvitations 获取工作区尚未接受的邀请
+func (s *WorkspaceService) ListInvitations(id, userID uint, role string) ([]dto.WorkspaceInvitationResponse, error) {
+	if err := s.authorize(newSubject(userID, role), policy.WorkspaceManageMembers, id, ""); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.repo.ListPendingInvitations(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WorkspaceInvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, *convertInvitationToResponse(&invitations[i]))
+	}
+	return responses, nil
+}
+
+// RevokeInvitation 撤销邀请
+func (s *WorkspaceService) RevokeInvitation(id, invitationID, userID uint, role string) error {
+	subject := newSubject(userID, role)
+	if err := s.authorize(subject, policy.WorkspaceManageMembers, id, ""); err != nil {
+		return err
+	}
+	invitation, err := s.repo.FindInvitation(id, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWorkspaceInvitationInvalid
+		}
+		return err
+	}
+	if err := s.authorize(subject, policy.WorkspaceManageMembers, id, invitation.Role); err != nil {
+		return err
+	}
+	return s.repo.DeleteInvitation(invitation.ID)
+}
+
+// AcceptInvitation 接受邀请，邀请只能由受邀邮箱对应的用户接受
+func (s *WorkspaceService) AcceptInvitation(userID uint, token string) (*dto.WorkspaceResponse, error) {
+	invitation, err := s.repo.FindInvitationByToken(hashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceInvitationInvalid
+		}
+		return nil, err
+	}
+	if !invitation.IsPending() || invitation.Workspace == nil {
+		return nil, ErrWorkspaceInvitationInvalid
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrWorkspaceInvitationInvalid
+	}
+
+	if err := s.repo.AcceptInvitation(invitation, userID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceInvitationInvalid
+		}
+		return nil, err
+	}
+
+	member, err := s.repo.FindMember(invitation.WorkspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return convertWorkspaceToResponse(invitation.Workspace, member.Role), nil
+}
+
+// authorize 通过策略检查用户能否对工作区执行操作
+func (s *WorkspaceService) authorize(subject *policy.Subject, action policy.Action, workspaceID uint, targetRole string) error {
+	return authorizeWorkspace(s.repo, subject, action, &policy.Resource{WorkspaceID: &workspaceID, TargetRole: targetRole})
+}
+
+// find 查找工作区
+func (s *WorkspaceService) find(id uint) (*model.Workspace, error) {
+	workspace, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// findMember 查找工作区成员
+func (s *WorkspaceService) findMember(workspaceID, userID uint) (*model.WorkspaceMember, error) {
+	member, err := s.repo.FindMember(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// ensureAnotherOwner 确认移除或降级一名所有者后工作区仍有所有者
+func (s *WorkspaceService) ensureAnotherOwner(workspaceID uint) error {
+	owners, err := s.repo.CountOwners(workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrWorkspaceLastOwner
+	}
+	return nil
+}
+
+// authorizeWorkspace 通过策略检查用户能否对工作区或其中的内容执行操作，供各服务共用。
+// 工作区不存在或用户不是成员时返回 ErrWorkspaceNotFound，角色不足时返回 ErrWorkspaceForbidden
+func authorizeWorkspace(repo *repository.WorkspaceRepository, subject *policy.Subject, action policy.Action, resource *policy.Resource) error {
+	if _, err := repo.FindByID(*resource.WorkspaceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWorkspaceNotFound
+		}
+		return err
+	}
+	if policy.Allowed(subject, action, resource) {
+		return nil
+	}
+	return deniedError(subject, resource.WorkspaceID, ErrWorkspaceForbidden)
+}
+
+// generateInvitationToken 生成随机邀请令牌
+func generateInvitationToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashInvitationToken 计算邀请令牌的哈希，数据库中只保存哈希
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// convertWorkspaceToResponse 将工作区模型转换为响应DTO
+func convertWorkspaceToResponse(workspace *model.Workspace, role string) *dto.WorkspaceResponse {
+	return &dto.WorkspaceResponse{
+		ID:          workspace.ID,
+		Name:        workspace.Name,
+		Slug:        workspace.Slug,
+		Description: workspace.Description,
+		OwnerID:     workspace.OwnerID,
+		Role:        role,
+		CreatedAt:   workspace.CreatedAt,
+		UpdatedAt:   workspace.UpdatedAt,
+	}
+}
+
+// convertInvitationToResponse 将邀请模型转换为响应DTO
+func convertInvitationToResponse(invitation *model.WorkspaceInvitation) *dto.WorkspaceInvitationResponse {
+	response := &dto.WorkspaceInvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+	if invitation.Inviter != nil {
+		response.InvitedBy = &dto.UserInfo{
+			ID:       invitation.Inviter.ID,
+			Username: invitation.Inviter.Username,
+			Avatar:   invitation.Inviter.Avatar,
+		}
+	}
+	return response
+}
@@ -8,23 +8,39 @@ import (
 	"strings"
 	"time"
 
+	"notex/pkg/imaging"
 	"notex/pkg/types"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server    ServerConfig        `yaml:"server" json:"server"`
-	Database  DatabaseConfig      `yaml:"database" json:"database"`
-	Email     EmailConfig         `yaml:"email" json:"email"`
-	JWT       JWTConfig           `yaml:"jwt" json:"jwt"`
-	RateLimit RateLimitConfig     `yaml:"rate_limit" json:"rate_limit"`
-	Storage   types.StorageConfig `yaml:"storage" json:"storage"`
+	Server     ServerConfig        `yaml:"server" json:"server"`
+	Database   DatabaseConfig      `yaml:"database" json:"database"`
+	Email      EmailConfig         `yaml:"email" json:"email"`
+	JWT        JWTConfig           `yaml:"jwt" json:"jwt"`
+	RateLimit  RateLimitConfig     `yaml:"rate_limit" json:"rate_limit"`
+	Storage    types.StorageConfig `yaml:"storage" json:"storage"`
+	Embedding  EmbeddingConfig     `yaml:"embedding" json:"embedding"`
+	AI         AIConfig            `yaml:"ai" json:"ai"`
+	Media      MediaConfig         `yaml:"media" json:"media"`
+	Image      ImageConfig         `yaml:"image" json:"image"`
+	Scanner    ScannerConfig       `yaml:"scanner" json:"scanner"`
+	Attachment AttachmentConfig    `yaml:"attachment" json:"attachment"`
+	Tus        TusConfig           `yaml:"tus" json:"tus"`
+	Views      ViewsConfig         `yaml:"views" json:"views"`
+	Analytics  AnalyticsConfig     `yaml:"analytics" json:"analytics"`
+	Comments   CommentsConfig      `yaml:"comments" json:"comments"`
+	Captcha    CaptchaConfig       `yaml:"captcha" json:"captcha"`
+	Tags       TagsConfig          `yaml:"tags" json:"tags"`
 }
 
 type ServerConfig struct {
 	Port int    `yaml:"port" json:"port"`
 	Host string `yaml:"host" json:"host"`
+
+	// 收到退出信号后等待进行中的请求完成的最长时间
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"`
 }
 
 type DatabaseConfig struct {
@@ -67,11 +83,127 @@ type RateLimitConfig struct {
 	Login RateLimitItem `yaml:"login" json:"login"`
 }
 
+// EmbeddingConfig 向量嵌入配置
+type EmbeddingConfig struct {
+	Provider     string `yaml:"provider" json:"provider"`           // 提供者：local, openai
+	Model        string `yaml:"model" json:"model"`                 // 模型名称
+	APIKey       string `yaml:"api_key" json:"api_key"`             // API密钥
+	Endpoint     string `yaml:"endpoint" json:"endpoint"`           // 接口地址
+	Dimension    int    `yaml:"dimension" json:"dimension"`         // 本地提供者的向量维度
+	ChunkSize    int    `yaml:"chunk_size" json:"chunk_size"`       // 分块长度（字符）
+	ChunkOverlap int    `yaml:"chunk_overlap" json:"chunk_overlap"` // 分块重叠长度（字符）
+
+	Timeout time.Duration `yaml:"timeout" json:"timeout"` // 调用接口的超时（openai 提供者使用）
+}
+
+// AIConfig AI提供商请求配置
+type AIConfig struct {
+	Timeout          time.Duration `yaml:"timeout" json:"timeout"`                     // 非流式请求超时
+	StreamTimeout    time.Duration `yaml:"stream_timeout" json:"stream_timeout"`       // 流式请求超时
+	MaxRetries       int           `yaml:"max_retries" json:"max_retries"`             // 可重试错误的最大重试次数
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay" json:"retry_base_delay"`   // 重试的初始退避时间
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay" json:"retry_max_delay"`     // 重试的最大退避时间
+	BreakerThreshold int           `yaml:"breaker_threshold" json:"breaker_threshold"` // 触发熔断的连续失败次数
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" json:"breaker_cooldown"`   // 熔断后的冷却时间
+	FallbackModels   []string      `yaml:"fallback_models" json:"fallback_models"`     // 站点级备用文本模型，用户未配置时使用
+}
+
+// MediaConfig 媒体库配置
+type MediaConfig struct {
+	ScanInterval time.Duration `yaml:"scan_interval" json:"scan_interval"` // 引用扫描和垃圾回收的间隔
+	GracePeriod  time.Duration `yaml:"grace_period" json:"grace_period"`   // 未被引用的文件保留多久后删除
+	PrivateDir   string        `yaml:"private_dir" json:"private_dir"`     // 文章附件等需要鉴权下载的文件的本地目录，不应位于对外提供访问的目录下
+}
+
+// ImageConfig 图片处理配置
+type ImageConfig struct {
+	Widths     []int    `yaml:"widths" json:"widths"`           // 上传时生成的宽度变体
+	Formats    []string `yaml:"formats" json:"formats"`         // 除原格式外额外生成的格式，必须已注册编码器
+	Quality    int      `yaml:"quality" json:"quality"`         // 有损编码质量（1-100）
+	MaxWidth   int      `yaml:"max_width" json:"max_width"`     // 动态缩放允许的最大宽度
+	MaxPixels  int      `yaml:"max_pixels" json:"max_pixels"`   // 允许处理的最大像素数
+	CacheDir   string   `yaml:"cache_dir" json:"cache_dir"`     // 动态缩放结果的磁盘缓存目录
+	SigningKey string   `yaml:"signing_key" json:"signing_key"` // 图片URL签名密钥，为空时使用JWT密钥
+}
+
+// ScannerConfig 上传文件安全扫描配置
+type ScannerConfig struct {
+	Provider      string        `yaml:"provider" json:"provider"`             // 扫描器：none, clamav
+	Address       string        `yaml:"address" json:"address"`               // clamd 地址：tcp://host:port 或 unix:///path
+	Timeout       time.Duration `yaml:"timeout" json:"timeout"`               // 单个文件的扫描超时
+	FailOpen      bool          `yaml:"fail_open" json:"fail_open"`           // 扫描服务不可用时是否仍允许上传
+	QuarantineDir string        `yaml:"quarantine_dir" json:"quarantine_dir"` // 受感染文件的隔离目录
+}
+
+// AttachmentConfig 文章附件配置
+type AttachmentConfig struct {
+	MaxPerDocument int              `yaml:"max_per_document" json:"max_per_document"` // 每篇文章或草稿的附件数上限
+	Types          map[string]int64 `yaml:"types" json:"types"`                       // 允许的扩展名及对应的大小上限（字节），为 0 时禁用该类型
+}
+
+// TusConfig 可续传上传配置
+type TusConfig struct {
+	Dir             string        `yaml:"dir" json:"dir"`                           // 上传数据的本地暂存目录，不应位于对外提供访问的目录下
+	MaxSize         int64         `yaml:"max_size" json:"max_size"`                 // 单个上传允许声明的最大长度（字节）
+	Expiration      time.Duration `yaml:"expiration" json:"expiration"`             // 上传在最后一次写入后保留多久
+	CleanupInterval time.Duration `yaml:"cleanup_interval" json:"cleanup_interval"` // 清理过期上传的间隔
+}
+
+// ViewsConfig 文章浏览统计配置
+type ViewsConfig struct {
+	FlushInterval time.Duration `yaml:"flush_interval" json:"flush_interval"` // 缓冲的浏览记录写入数据库的间隔
+	BatchSize     int           `yaml:"batch_size" json:"batch_size"`         // 缓冲达到该数量时立即写入
+	MaxBuffer     int           `yaml:"max_buffer" json:"max_buffer"`         // 缓冲上限，数据库不可用导致积压超过上限时丢弃新的浏览记录
+	DedupWindow   time.Duration `yaml:"dedup_window" json:"dedup_window"`     // 同一访客在该时间内重复访问同一文章只计一次
+	BotPatterns   []string      `yaml:"bot_patterns" json:"bot_patterns"`     // User-Agent 包含其中任一关键字（不区分大小写）时视为爬虫，不计入浏览量
+}
+
+// AnalyticsConfig 作者统计配置
+type AnalyticsConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval" json:"refresh_interval"` // 重新计算汇总表的间隔
+	RecomputeDays   int           `yaml:"recompute_days" json:"recompute_days"`     // 每次重新计算最近多少天的汇总，启动时重新计算全部历史
+}
+
+// CommentsConfig 评论配置
+type CommentsConfig struct {
+	EditWindow  time.Duration       `yaml:"edit_window" json:"edit_window"`   // 评论发表后作者可以修改的时间，为 0 时不能修改
+	Reactions   []string            `yaml:"reactions" json:"reactions"`       // 允许使用的表情回应
+	MaxMentions int                 `yaml:"max_mentions" json:"max_mentions"` // 一条评论中最多解析的 @用户名 数量，超出的不提醒
+	Guest       GuestCommentsConfig `yaml:"guest" json:"guest"`
+}
+
+// GuestCommentsConfig 访客评论配置
+type GuestCommentsConfig struct {
+	Enabled          bool          `yaml:"enabled" json:"enabled"`                     // 是否允许未登录的访客评论，作者还可以在每篇文章中单独关闭
+	VerifyURL        string        `yaml:"verify_url" json:"verify_url"`               // 确认邮件中的链接地址，令牌作为 token 查询参数附加
+	VerifyExpiration time.Duration `yaml:"verify_expiration" json:"verify_expiration"` // 确认链接的有效期，过期未确认的评论会被删除
+	CleanupInterval  time.Duration `yaml:"cleanup_interval" json:"cleanup_interval"`   // 清理过期未确认评论的间隔
+}
+
+// CaptchaConfig 人机验证配置，目前用于访客评论
+type CaptchaConfig struct {
+	Provider   string        `yaml:"provider" json:"provider"`       // 验证方式：none, math（内置算式图片）, hcaptcha
+	Expiration time.Duration `yaml:"expiration" json:"expiration"`   // 内置验证码的有效期
+	SigningKey string        `yaml:"signing_key" json:"signing_key"` // 内置验证码的签名密钥，为空时使用JWT密钥
+	SiteKey    string        `yaml:"site_key" json:"site_key"`       // hCaptcha 站点密钥
+	SecretKey  string        `yaml:"secret_key" json:"secret_key"`   // hCaptcha 密钥
+	VerifyURL  string        `yaml:"verify_url" json:"verify_url"`   // hCaptcha 兼容的校验接口，本地开发时可指向桩服务
+	Timeout    time.Duration `yaml:"timeout" json:"timeout"`         // 调用校验接口的超时
+}
+
+// TagsConfig 标签配置
+type TagsConfig struct {
+	CleanupInterval time.Duration `yaml:"cleanup_interval" json:"cleanup_interval"` // 检查未使用标签的间隔
+	UnusedAction    string        `yaml:"unused_action" json:"unused_action"`       // 发现未使用标签时的处理：report（记录到日志和审计日志）, delete（删除）
+	UnusedAfter     time.Duration `yaml:"unused_after" json:"unused_after"`         // 创建超过该时间且没有文章和草稿使用的标签才视为未使用
+}
+
 var (
 	DefaultConfig = Config{
 		Server: ServerConfig{
-			Port: 8080,
-			Host: "localhost",
+			Port:            8080,
+			Host:            "localhost",
+			ShutdownTimeout: 30 * time.Second,
 		},
 		Database: DatabaseConfig{
 			Host:     "localhost",
@@ -126,6 +258,104 @@ var (
 				URLPrefix: "/uploads/",
 			},
 		},
+		Embedding: EmbeddingConfig{
+			Provider:     "local",
+			Dimension:    256,
+			ChunkSize:    800,
+			ChunkOverlap: 100,
+			Timeout:      30 * time.Second,
+		},
+		AI: AIConfig{
+			Timeout:          60 * time.Second,
+			StreamTimeout:    5 * time.Minute,
+			MaxRetries:       2,
+			RetryBaseDelay:   500 * time.Millisecond,
+			RetryMaxDelay:    5 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Media: MediaConfig{
+			ScanInterval: time.Hour,
+			GracePeriod:  7 * 24 * time.Hour,
+			PrivateDir:   "private",
+		},
+		Image: ImageConfig{
+			Widths:    []int{320, 640, 1280, 1920},
+			Formats:   []string{"webp"},
+			Quality:   85,
+			MaxWidth:  2560,
+			MaxPixels: 40000000,
+			CacheDir:  "cache/images",
+		},
+		Scanner: ScannerConfig{
+			Provider:      "none",
+			Address:       "tcp://127.0.0.1:3310",
+			Timeout:       30 * time.Second,
+			QuarantineDir: "quarantine",
+		},
+		Attachment: AttachmentConfig{
+			MaxPerDocument: 20,
+			Types: map[string]int64{
+				"pdf":  20 << 20,
+				"zip":  50 << 20,
+				"7z":   50 << 20,
+				"rar":  50 << 20,
+				"gz":   50 << 20,
+				"doc":  20 << 20,
+				"docx": 20 << 20,
+				"xls":  20 << 20,
+				"xlsx": 20 << 20,
+				"ppt":  50 << 20,
+				"pptx": 50 << 20,
+				"odt":  20 << 20,
+				"ods":  20 << 20,
+				"odp":  50 << 20,
+				"txt":  5 << 20,
+				"csv":  10 << 20,
+			},
+		},
+		Tus: TusConfig{
+			Dir:             "tmp/tus",
+			MaxSize:         1 << 30,
+			Expiration:      24 * time.Hour,
+			CleanupInterval: time.Hour,
+		},
+		Views: ViewsConfig{
+			FlushInterval: 10 * time.Second,
+			BatchSize:     500,
+			MaxBuffer:     50000,
+			DedupWindow:   30 * time.Minute,
+			BotPatterns: []string{
+				"bot", "crawl", "spider", "slurp", "curl", "wget", "python-requests", "go-http-client",
+				"headless", "lighthouse", "facebookexternalhit", "scrapy",
+			},
+		},
+		Analytics: AnalyticsConfig{
+			RefreshInterval: 15 * time.Minute,
+			RecomputeDays:   3,
+		},
+		Comments: CommentsConfig{
+			EditWindow:  15 * time.Minute,
+			Reactions:   []string{"👍", "👎", "😄", "🎉", "😕", "❤️", "🚀", "👀"},
+			MaxMentions: 10,
+			Guest: GuestCommentsConfig{
+				Enabled:          false,
+				VerifyURL:        "http://localhost:8080/api/public/comments/verify",
+				VerifyExpiration: 24 * time.Hour,
+				CleanupInterval:  time.Hour,
+			},
+		},
+		Captcha: CaptchaConfig{
+			Provider:   "math",
+			Expiration: 10 * time.Minute,
+			VerifyURL:  "https://api.hcaptcha.com/siteverify",
+			Timeout:    10 * time.Second,
+		},
+		Tags: TagsConfig{
+			CleanupInterval: 24 * time.Hour,
+			UnusedAction:    "report",
+			UnusedAfter:     7 * 24 * time.Hour,
+		},
 	}
 	LoadedConfig Config
 )
@@ -162,6 +392,66 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("storage config error: %v", err)
 	}
 
+	// 验证向量嵌入配置
+	if err := c.Embedding.Validate(); err != nil {
+		return fmt.Errorf("embedding config error: %v", err)
+	}
+
+	// 验证AI请求配置
+	if err := c.AI.Validate(); err != nil {
+		return fmt.Errorf("ai config error: %v", err)
+	}
+
+	// 验证媒体库配置
+	if err := c.Media.Validate(); err != nil {
+		return fmt.Errorf("media config error: %v", err)
+	}
+
+	// 验证图片处理配置
+	if err := c.Image.Validate(); err != nil {
+		return fmt.Errorf("image config error: %v", err)
+	}
+
+	// 验证安全扫描配置
+	if err := c.Scanner.Validate(); err != nil {
+		return fmt.Errorf("scanner config error: %v", err)
+	}
+
+	// 验证附件配置
+	if err := c.Attachment.Validate(); err != nil {
+		return fmt.Errorf("attachment config error: %v", err)
+	}
+
+	// 验证可续传上传配置
+	if err := c.Tus.Validate(); err != nil {
+		return fmt.Errorf("tus config error: %v", err)
+	}
+
+	// 验证浏览统计配置
+	if err := c.Views.Validate(); err != nil {
+		return fmt.Errorf("views config error: %v", err)
+	}
+
+	// 验证作者统计配置
+	if err := c.Analytics.Validate(); err != nil {
+		return fmt.Errorf("analytics config error: %v", err)
+	}
+
+	// 验证评论配置
+	if err := c.Comments.Validate(); err != nil {
+		return fmt.Errorf("comments config error: %v", err)
+	}
+
+	// 验证人机验证配置
+	if err := c.Captcha.Validate(); err != nil {
+		return fmt.Errorf("captcha config error: %v", err)
+	}
+
+	// 验证标签配置
+	if err := c.Tags.Validate(); err != nil {
+		return fmt.Errorf("tags config error: %v", err)
+	}
+
 	return nil
 }
 
@@ -175,6 +465,10 @@ func (c *ServerConfig) Validate() error {
 		return fmt.Errorf("host cannot be empty")
 	}
 
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("shutdown_timeout should be positive")
+	}
+
 	return nil
 }
 
@@ -292,6 +586,294 @@ func (c *RateLimitConfig) Validate() error {
 	return nil
 }
 
+// Validate 验证向量嵌入配置
+func (c *EmbeddingConfig) Validate() error {
+	switch c.Provider {
+	case "local":
+		if c.Dimension <= 0 {
+			return fmt.Errorf("dimension should be positive for local provider")
+		}
+	case "openai":
+		if c.APIKey == "" {
+			return fmt.Errorf("api_key cannot be empty for openai provider")
+		}
+		if c.Timeout <= 0 {
+			return fmt.Errorf("timeout should be positive for openai provider")
+		}
+	default:
+		return fmt.Errorf("unsupported embedding provider: %s", c.Provider)
+	}
+
+	if c.ChunkSize <= 0 {
+		return fmt.Errorf("chunk_size should be positive")
+	}
+
+	if c.ChunkOverlap < 0 || c.ChunkOverlap >= c.ChunkSize {
+		return fmt.Errorf("chunk_overlap should be between 0 and chunk_size")
+	}
+
+	return nil
+}
+
+// Validate 验证AI请求配置
+func (c *AIConfig) Validate() error {
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout should be positive")
+	}
+
+	if c.StreamTimeout <= 0 {
+		return fmt.Errorf("stream_timeout should be positive")
+	}
+
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max_retries should not be negative")
+	}
+
+	if c.RetryBaseDelay <= 0 || c.RetryMaxDelay < c.RetryBaseDelay {
+		return fmt.Errorf("retry_max_delay should be greater than or equal to retry_base_delay")
+	}
+
+	if c.BreakerThreshold <= 0 {
+		return fmt.Errorf("breaker_threshold should be positive")
+	}
+
+	if c.BreakerCooldown <= 0 {
+		return fmt.Errorf("breaker_cooldown should be positive")
+	}
+
+	return nil
+}
+
+// Validate 验证媒体库配置
+func (c *MediaConfig) Validate() error {
+	if c.ScanInterval <= 0 {
+		return fmt.Errorf("scan_interval should be positive")
+	}
+
+	if c.GracePeriod <= 0 {
+		return fmt.Errorf("grace_period should be positive")
+	}
+
+	if c.PrivateDir == "" {
+		return fmt.Errorf("private_dir cannot be empty")
+	}
+
+	return nil
+}
+
+// Validate 验证图片处理配置
+func (c *ImageConfig) Validate() error {
+	if c.MaxWidth <= 0 {
+		return fmt.Errorf("max_width should be positive")
+	}
+
+	for _, width := range c.Widths {
+		if width <= 0 || width > c.MaxWidth {
+			return fmt.Errorf("widths should be between 1 and max_width: %d", width)
+		}
+	}
+
+	// 只接受已注册编码器的格式，内置 JPEG、PNG 和 WebP
+	for _, format := range c.Formats {
+		if _, ok := imaging.LookupEncoder(format); !ok {
+			return fmt.Errorf("no encoder registered for image format: %s", format)
+		}
+	}
+
+	if c.Quality < 1 || c.Quality > 100 {
+		return fmt.Errorf("quality should be between 1 and 100")
+	}
+
+	if c.MaxPixels <= 0 {
+		return fmt.Errorf("max_pixels should be positive")
+	}
+
+	if c.CacheDir == "" {
+		return fmt.Errorf("cache_dir cannot be empty")
+	}
+
+	return nil
+}
+
+// Validate 验证安全扫描配置
+func (c *ScannerConfig) Validate() error {
+	switch c.Provider {
+	case "none":
+	case "clamav":
+		if c.Address == "" {
+			return fmt.Errorf("address cannot be empty for clamav")
+		}
+		if c.Timeout <= 0 {
+			return fmt.Errorf("timeout should be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported scanner provider: %s", c.Provider)
+	}
+
+	if c.QuarantineDir == "" {
+		return fmt.Errorf("quarantine_dir cannot be empty")
+	}
+
+	return nil
+}
+
+// Validate 验证附件配置
+func (c *AttachmentConfig) Validate() error {
+	if c.MaxPerDocument <= 0 {
+		return fmt.Errorf("max_per_document should be positive")
+	}
+
+	for ext, size := range c.Types {
+		if ext == "" || strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("types should be keyed by extension without dot: %q", ext)
+		}
+		if size < 0 {
+			return fmt.Errorf("max size of %s cannot be negative", ext)
+		}
+	}
+
+	return nil
+}
+
+// Validate 验证可续传上传配置
+func (c *TusConfig) Validate() error {
+	if c.Dir == "" {
+		return fmt.Errorf("dir cannot be empty")
+	}
+
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("max_size should be positive")
+	}
+
+	if c.Expiration <= 0 {
+		return fmt.Errorf("expiration should be positive")
+	}
+
+	if c.CleanupInterval <= 0 {
+		return fmt.Errorf("cleanup_interval should be positive")
+	}
+
+	return nil
+}
+
+// Validate 验证浏览统计配置
+func (c *ViewsConfig) Validate() error {
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("flush_interval should be positive")
+	}
+
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch_size should be positive")
+	}
+
+	if c.MaxBuffer < c.BatchSize {
+		return fmt.Errorf("max_buffer should be greater than or equal to batch_size")
+	}
+
+	if c.DedupWindow < 0 {
+		return fmt.Errorf("dedup_window should not be negative")
+	}
+
+	for _, pattern := range c.BotPatterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("bot_patterns cannot contain empty patterns")
+		}
+	}
+
+	return nil
+}
+
+// Validate 验证作者统计配置
+func (c *AnalyticsConfig) Validate() error {
+	if c.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh_interval should be positive")
+	}
+
+	if c.RecomputeDays <= 0 {
+		return fmt.Errorf("recompute_days should be positive")
+	}
+
+	return nil
+}
+
+// Validate 验证评论配置
+func (c *CommentsConfig) Validate() error {
+	if c.EditWindow < 0 {
+		return fmt.Errorf("edit_window should not be negative")
+	}
+
+	seen := make(map[string]bool, len(c.Reactions))
+	for _, reaction := range c.Reactions {
+		if strings.TrimSpace(reaction) == "" || len(reaction) > 32 {
+			return fmt.Errorf("reactions must be non-empty and at most 32 bytes")
+		}
+		if seen[reaction] {
+			return fmt.Errorf("duplicate reaction: %s", reaction)
+		}
+		seen[reaction] = true
+	}
+
+	if c.MaxMentions < 0 {
+		return fmt.Errorf("max_mentions should not be negative")
+	}
+
+	if c.Guest.Enabled && c.Guest.VerifyURL == "" {
+		return fmt.Errorf("guest.verify_url cannot be empty when guest comments are enabled")
+	}
+	if c.Guest.VerifyExpiration <= 0 {
+		return fmt.Errorf("guest.verify_expiration should be positive")
+	}
+	if c.Guest.CleanupInterval <= 0 {
+		return fmt.Errorf("guest.cleanup_interval should be positive")
+	}
+
+	return nil
+}
+
+// Validate 验证人机验证配置
+func (c *CaptchaConfig) Validate() error {
+	switch c.Provider {
+	case "none":
+	case "math":
+		if c.Expiration <= 0 {
+			return fmt.Errorf("expiration should be positive")
+		}
+	case "hcaptcha":
+		if c.SiteKey == "" || c.SecretKey == "" {
+			return fmt.Errorf("site_key and secret_key cannot be empty for hcaptcha")
+		}
+		if c.VerifyURL == "" {
+			return fmt.Errorf("verify_url cannot be empty for hcaptcha")
+		}
+		if c.Timeout <= 0 {
+			return fmt.Errorf("timeout should be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported captcha provider: %s", c.Provider)
+	}
+
+	return nil
+}
+
+// Validate 验证标签配置
+func (c *TagsConfig) Validate() error {
+	if c.CleanupInterval <= 0 {
+		return fmt.Errorf("cleanup_interval should be positive")
+	}
+
+	switch c.UnusedAction {
+	case "report", "delete":
+	default:
+		return fmt.Errorf("unsupported unused_action: %s", c.UnusedAction)
+	}
+
+	if c.UnusedAfter < 0 {
+		return fmt.Errorf("unused_after should not be negative")
+	}
+
+	return nil
+}
+
 // isValidEmail 验证邮箱格式是否正确
 func isValidEmail(email string) bool {
 	parts := strings.Split(email, "@")
@@ -514,6 +1096,60 @@ func overrideWithEnv(cfg *Config) {
 			cfg.Storage.MinIO.UseSSL = useSSL
 		}
 	}
+
+	// S3配置
+	if s3Endpoint := os.Getenv("STORAGE_S3_ENDPOINT"); s3Endpoint != "" {
+		cfg.Storage.S3.Endpoint = s3Endpoint
+	}
+	if s3AccessKey := os.Getenv("STORAGE_S3_ACCESS_KEY"); s3AccessKey != "" {
+		cfg.Storage.S3.AccessKey = s3AccessKey
+	}
+	if s3AccessSecret := os.Getenv("STORAGE_S3_ACCESS_SECRET"); s3AccessSecret != "" {
+		cfg.Storage.S3.AccessSecret = s3AccessSecret
+	}
+	if s3BucketName := os.Getenv("STORAGE_S3_BUCKET_NAME"); s3BucketName != "" {
+		cfg.Storage.S3.BucketName = s3BucketName
+	}
+	if s3Region := os.Getenv("STORAGE_S3_REGION"); s3Region != "" {
+		cfg.Storage.S3.Region = s3Region
+	}
+	if s3URLPrefix := os.Getenv("STORAGE_S3_URL_PREFIX"); s3URLPrefix != "" {
+		cfg.Storage.S3.URLPrefix = s3URLPrefix
+	}
+
+	// 向量嵌入配置
+	if embeddingProvider := os.Getenv("EMBEDDING_PROVIDER"); embeddingProvider != "" {
+		cfg.Embedding.Provider = embeddingProvider
+	}
+	if embeddingAPIKey := os.Getenv("EMBEDDING_API_KEY"); embeddingAPIKey != "" {
+		cfg.Embedding.APIKey = embeddingAPIKey
+	}
+
+	// 媒体库配置
+	if mediaPrivateDir := os.Getenv("MEDIA_PRIVATE_DIR"); mediaPrivateDir != "" {
+		cfg.Media.PrivateDir = mediaPrivateDir
+	}
+
+	// 图片处理配置
+	if imageCacheDir := os.Getenv("IMAGE_CACHE_DIR"); imageCacheDir != "" {
+		cfg.Image.CacheDir = imageCacheDir
+	}
+	if imageSigningKey := os.Getenv("IMAGE_SIGNING_KEY"); imageSigningKey != "" {
+		cfg.Image.SigningKey = imageSigningKey
+	}
+
+	// 安全扫描配置
+	if scannerProvider := os.Getenv("SCANNER_PROVIDER"); scannerProvider != "" {
+		cfg.Scanner.Provider = scannerProvider
+	}
+	if scannerAddress := os.Getenv("SCANNER_ADDRESS"); scannerAddress != "" {
+		cfg.Scanner.Address = scannerAddress
+	}
+
+	// 可续传上传配置
+	if tusDir := os.Getenv("TUS_DIR"); tusDir != "" {
+		cfg.Tus.Dir = tusDir
+	}
 }
 
 // GetConfig 获取当前配置
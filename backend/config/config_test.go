@@ -0,0 +1,40 @@
+package config
+
+import (
+	"image"
+	"io"
+	"testing"
+
+	"notex/pkg/imaging"
+)
+
+func TestImageConfigValidateFormats(t *testing.T) {
+	imaging.RegisterEncoder("test-format", imaging.Encoder{
+		ContentType: "image/x-test",
+		Ext:         ".test",
+		Encode:      func(w io.Writer, img image.Image, quality int) error { return nil },
+	})
+	t.Cleanup(func() { imaging.UnregisterEncoder("test-format") })
+
+	tests := []struct {
+		name    string
+		formats []string
+		wantErr bool
+	}{
+		{name: "default", formats: DefaultConfig.Image.Formats},
+		{name: "builtin", formats: []string{"jpg", "PNG", "webp"}},
+		{name: "registered", formats: []string{"test-format"}},
+		{name: "unknown", formats: []string{"bmp"}, wantErr: true},
+		{name: "avif without encoder", formats: []string{"png", "avif"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig.Image
+			cfg.Formats = tt.formats
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ module notex
 go 1.23.0
 
 require (
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/aliyun/alibaba-cloud-sdk-go v1.63.93
 	github.com/aliyun/aliyun-oss-go-sdk v3.0.2+incompatible
 	github.com/gin-contrib/cors v1.7.3
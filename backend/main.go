@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"notex/api/router"
 	"notex/config"
 	"notex/middleware"
 	"notex/migrations"
 	"notex/pkg/database"
 	"notex/pkg/email"
+	"os/signal"
 	"path/filepath"
+	"sync"
+	"syscall"
 )
 
 func main() {
@@ -52,11 +57,39 @@ func main() {
 	// 初始化限流器
 	middleware.InitRateLimiters(&cfg.RateLimit)
 
-	// 设置路由
-	r := router.SetupRouter(cfg)
+	// 设置路由并启动后台任务
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	r := router.SetupRouter(jobsCtx, cfg, &jobs)
 
 	// 启动服务器
 	serverAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	log.Printf("Server starting on %s", serverAddr)
-	log.Fatal(r.Run(serverAddr))
+	server := &http.Server{Addr: serverAddr, Handler: r}
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on %s", serverAddr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// 收到退出信号后先停止接收请求，再结束后台任务，等待缓冲的浏览记录等写入数据库
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var runErr error
+	select {
+	case runErr = <-serverErr:
+	case <-signals.Done():
+		log.Println("Shutting down server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down server gracefully: %v", err)
+		}
+		cancel()
+	}
+
+	stopJobs()
+	jobs.Wait()
+	if runErr != nil {
+		log.Fatalf("Server stopped: %v", runErr)
+	}
+	log.Println("Server exited")
 }
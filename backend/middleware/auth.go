@@ -54,6 +54,22 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth 可选认证中间件，携带有效令牌时写入用户信息，否则按匿名访问继续处理
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := auth.ParseToken(parts[1]); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("role", claims.Role)
+			}
+		}
+
+		c.Next()
+	}
+}
+
 // RequireRoles 检查用户是否具有指定角色之一
 func RequireRoles(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,2 @@
+-- 删除AI写作助手提示词模板表
+DROP TABLE IF EXISTS ai_prompt_templates;
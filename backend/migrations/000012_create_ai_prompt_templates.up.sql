@@ -0,0 +1,46 @@
+-- 创建AI写作助手提示词模板表
+CREATE TABLE IF NOT EXISTS ai_prompt_templates (
+    id SERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    system_prompt TEXT,
+    user_prompt TEXT NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_ai_prompt_templates_action_version UNIQUE (action, version)
+);
+
+-- 插入初始版本的提示词模板
+INSERT INTO ai_prompt_templates (action, version, system_prompt, user_prompt, is_active)
+VALUES
+('summarize', 1, 'You are an editor for a blogging platform. Reply in the same language as the article.',
+ 'Summarize the following article in no more than 3 sentences. Reply with the summary only.
+
+Title: {{.Title}}
+
+{{.Content}}', true),
+('suggest_title', 1, 'You are an editor for a blogging platform. Reply in the same language as the article.',
+ 'Suggest one concise, engaging title for the following article. Reply with the title only, without quotes.
+
+{{.Content}}', true),
+('suggest_tags', 1, 'You are an editor for a blogging platform.',
+ 'Choose up to 5 tags for the following article from this list of existing tags: {{.Tags}}.
+Reply with the chosen tag names separated by commas, and nothing else.
+
+Title: {{.Title}}
+
+{{.Content}}', true),
+('translate', 1, 'You are a professional translator. Preserve Markdown formatting.',
+ 'Translate the following text into {{.Language}}. Reply with the translation only.
+
+{{.Content}}', true),
+('fix_grammar', 1, 'You are a careful proofreader. Preserve Markdown formatting and the author''s voice.',
+ 'Fix spelling, grammar and punctuation in the following text. Reply with the corrected text only.
+
+{{.Content}}', true),
+('continue', 1, 'You are a writing assistant. Match the tone, style and language of the author.',
+ 'Continue writing the following text with one or two more paragraphs. Reply with the new text only.
+
+{{.Content}}', true)
+ON CONFLICT DO NOTHING;
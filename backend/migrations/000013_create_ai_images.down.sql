@@ -0,0 +1,5 @@
+-- 删除索引
+DROP INDEX IF EXISTS idx_ai_images_user_id;
+
+-- 删除表
+DROP TABLE IF EXISTS ai_images;
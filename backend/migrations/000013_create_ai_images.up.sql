@@ -0,0 +1,17 @@
+-- 创建AI生成图像表，记录生成时使用的提示词和模型
+CREATE TABLE IF NOT EXISTS ai_images (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    model_id VARCHAR(100) NOT NULL,
+    prompt TEXT NOT NULL,
+    size VARCHAR(20),
+    url VARCHAR(500) NOT NULL,
+    thumbnail_url VARCHAR(500),
+    content_type VARCHAR(100),
+    file_size BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_images_user_id ON ai_images(user_id);
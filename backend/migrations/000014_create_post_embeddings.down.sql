@@ -0,0 +1,6 @@
+-- 删除索引
+DROP INDEX IF EXISTS idx_post_embeddings_model;
+DROP INDEX IF EXISTS idx_post_embeddings_post_id;
+
+-- 删除表
+DROP TABLE IF EXISTS post_embeddings;
@@ -0,0 +1,14 @@
+-- 创建文章向量嵌入表
+CREATE TABLE IF NOT EXISTS post_embeddings (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    content TEXT,
+    vector JSONB NOT NULL,
+    model VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_post_embeddings_post_id ON post_embeddings(post_id);
+CREATE INDEX IF NOT EXISTS idx_post_embeddings_model ON post_embeddings(model);
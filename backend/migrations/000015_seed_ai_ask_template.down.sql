@@ -0,0 +1,2 @@
+-- 删除知识库问答的提示词模板
+DELETE FROM ai_prompt_templates WHERE action = 'ask';
@@ -0,0 +1,10 @@
+-- 插入知识库问答的提示词模板
+INSERT INTO ai_prompt_templates (action, version, system_prompt, user_prompt, is_active)
+VALUES
+('ask', 1, 'You answer questions about a team knowledge base. Use only the numbered sources provided. Cite sources inline as [n] using their numbers. If the sources do not contain the answer, say that you do not know. Reply in the same language as the question.',
+ 'Sources:
+
+{{.Sources}}
+
+Question: {{.Question}}', true)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+-- 从ai_default_settings表中删除fallback_models字段
+ALTER TABLE ai_default_settings DROP COLUMN fallback_models;
@@ -0,0 +1,2 @@
+-- 添加fallback_models字段到ai_default_settings表
+ALTER TABLE ai_default_settings ADD COLUMN fallback_models JSON;
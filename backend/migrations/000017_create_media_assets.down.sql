@@ -0,0 +1,3 @@
+-- 删除表
+DROP TABLE IF EXISTS media_references;
+DROP TABLE IF EXISTS media_assets;
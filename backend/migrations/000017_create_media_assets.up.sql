@@ -0,0 +1,39 @@
+-- 创建媒体文件表，记录上传文件的存储位置和元数据
+CREATE TABLE IF NOT EXISTS media_assets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    storage_type VARCHAR(20) NOT NULL,
+    key VARCHAR(500) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    thumbnail_url VARCHAR(500),
+    filename VARCHAR(255),
+    size BIGINT DEFAULT 0,
+    mime_type VARCHAR(100),
+    width INTEGER DEFAULT 0,
+    height INTEGER DEFAULT 0,
+    checksum VARCHAR(64),
+    ref_count INTEGER DEFAULT 0,
+    orphaned_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_user_id ON media_assets(user_id);
+CREATE INDEX IF NOT EXISTS idx_media_assets_url ON media_assets(url);
+CREATE INDEX IF NOT EXISTS idx_media_assets_checksum ON media_assets(checksum);
+CREATE INDEX IF NOT EXISTS idx_media_assets_orphaned_at ON media_assets(orphaned_at);
+
+-- 创建媒体引用表，记录文章和草稿对文件的引用
+CREATE TABLE IF NOT EXISTS media_references (
+    id SERIAL PRIMARY KEY,
+    asset_id INTEGER NOT NULL,
+    ref_type VARCHAR(20) NOT NULL,
+    ref_id INTEGER NOT NULL,
+    field VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (asset_id) REFERENCES media_assets(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_references_asset_id ON media_references(asset_id);
+CREATE INDEX IF NOT EXISTS idx_media_references_ref ON media_references(ref_type, ref_id);
@@ -0,0 +1,2 @@
+-- 从media_assets表中删除variants字段
+ALTER TABLE media_assets DROP COLUMN variants;
@@ -0,0 +1,2 @@
+-- 添加variants字段到media_assets表，记录图片的尺寸和格式变体
+ALTER TABLE media_assets ADD COLUMN variants JSON;
@@ -0,0 +1,6 @@
+-- 从media_assets表中删除安全扫描相关字段
+DROP INDEX IF EXISTS idx_media_assets_storage_key;
+
+ALTER TABLE media_assets DROP COLUMN quarantined_at;
+ALTER TABLE media_assets DROP COLUMN scan_signature;
+ALTER TABLE media_assets DROP COLUMN scan_status;
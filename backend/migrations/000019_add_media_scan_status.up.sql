@@ -0,0 +1,6 @@
+-- 添加安全扫描状态和隔离时间字段到media_assets表
+ALTER TABLE media_assets ADD COLUMN scan_status VARCHAR(20) DEFAULT 'unscanned';
+ALTER TABLE media_assets ADD COLUMN scan_signature VARCHAR(255);
+ALTER TABLE media_assets ADD COLUMN quarantined_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_storage_key ON media_assets(storage_type, key);
@@ -0,0 +1,3 @@
+-- 删除存储迁移相关表
+DROP TABLE IF EXISTS storage_migration_items;
+DROP TABLE IF EXISTS storage_migrations;
@@ -0,0 +1,36 @@
+-- 创建存储迁移任务表
+CREATE TABLE IF NOT EXISTS storage_migrations (
+    id SERIAL PRIMARY KEY,
+    source VARCHAR(20) NOT NULL,
+    target VARCHAR(20) NOT NULL,
+    dry_run BOOLEAN DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total INTEGER DEFAULT 0,
+    copied INTEGER DEFAULT 0,
+    failed INTEGER DEFAULT 0,
+    missing INTEGER DEFAULT 0,
+    rewritten INTEGER DEFAULT 0,
+    last_error TEXT,
+    created_by INTEGER,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- 创建存储迁移对象表，记录每个文件的复制进度，用于断点续传
+CREATE TABLE IF NOT EXISTS storage_migration_items (
+    id SERIAL PRIMARY KEY,
+    migration_id INTEGER NOT NULL,
+    key VARCHAR(500) NOT NULL,
+    source_url VARCHAR(500) NOT NULL,
+    target_url VARCHAR(500),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (migration_id) REFERENCES storage_migrations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_migration_items_migration ON storage_migration_items(migration_id, status);
@@ -0,0 +1,2 @@
+-- 删除附件表
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,24 @@
+-- 创建附件表，附件文件保存在媒体库中，未关联文章或草稿时 post_id 和 draft_id 均为空
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    asset_id INTEGER NOT NULL,
+    post_id INTEGER,
+    draft_id INTEGER,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100),
+    size BIGINT DEFAULT 0,
+    position INTEGER DEFAULT 0,
+    downloads BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES media_assets(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE SET NULL,
+    FOREIGN KEY (draft_id) REFERENCES drafts(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_asset_id ON attachments(asset_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_post_id ON attachments(post_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_draft_id ON attachments(draft_id);
@@ -0,0 +1,5 @@
+-- 从 posts 表中删除可见性和访问密码字段
+DROP INDEX IF EXISTS idx_posts_status_visibility;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS password_hash;
+ALTER TABLE posts DROP COLUMN IF EXISTS visibility;
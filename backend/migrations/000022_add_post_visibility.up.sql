@@ -0,0 +1,5 @@
+-- 为 posts 表添加可见性和访问密码字段
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS password_hash VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_posts_status_visibility ON posts(status, visibility);
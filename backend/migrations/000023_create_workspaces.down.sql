@@ -0,0 +1,13 @@
+-- 删除内容的工作区字段
+DROP INDEX IF EXISTS idx_drafts_workspace_id;
+DROP INDEX IF EXISTS idx_posts_workspace_id;
+DROP INDEX IF EXISTS idx_categories_workspace_id;
+
+ALTER TABLE drafts DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS workspace_id;
+
+-- 删除工作区相关表
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
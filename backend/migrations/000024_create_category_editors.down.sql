@@ -0,0 +1,3 @@
+-- 删除分类责任编辑表
+DROP INDEX IF EXISTS idx_category_editors_user_id;
+DROP TABLE IF EXISTS category_editors;
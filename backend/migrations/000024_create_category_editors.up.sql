@@ -0,0 +1,10 @@
+-- 创建分类责任编辑表，编辑可以修改其负责分类下的全部文章
+CREATE TABLE IF NOT EXISTS category_editors (
+    category_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    PRIMARY KEY (category_id, user_id),
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_editors_user_id ON category_editors(user_id);
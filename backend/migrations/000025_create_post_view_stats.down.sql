@@ -0,0 +1,4 @@
+-- 删除文章浏览统计相关的表
+DROP TABLE IF EXISTS post_view_visitors;
+DROP TABLE IF EXISTS post_view_referrers;
+DROP TABLE IF EXISTS post_view_stats;
@@ -0,0 +1,32 @@
+-- 创建文章每日浏览汇总表，日期按 UTC 计算
+CREATE TABLE IF NOT EXISTS post_view_stats (
+    post_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_visitors BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, date),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_view_stats_date ON post_view_stats(date);
+
+-- 创建文章每日来源域名表，只记录来自站外的访问
+CREATE TABLE IF NOT EXISTS post_view_referrers (
+    post_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    domain VARCHAR(255) NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, date, domain),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- 创建文章每日访客表，保存访客标识的哈希值，用于统计独立访客，过期的记录会被定期清理
+CREATE TABLE IF NOT EXISTS post_view_visitors (
+    post_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    visitor CHAR(64) NOT NULL,
+    PRIMARY KEY (post_id, date, visitor),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_view_visitors_date ON post_view_visitors(date);
@@ -0,0 +1,3 @@
+-- 删除作者统计汇总表
+DROP TABLE IF EXISTS author_daily_stats;
+DROP TABLE IF EXISTS post_comment_stats;
@@ -0,0 +1,23 @@
+-- 创建文章每日评论数汇总表，由后台任务根据评论表定期重新计算
+CREATE TABLE IF NOT EXISTS post_comment_stats (
+    post_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    comments BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, date),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_comment_stats_date ON post_comment_stats(date);
+
+-- 创建作者每日汇总表，由后台任务根据文章的浏览和评论汇总定期重新计算
+CREATE TABLE IF NOT EXISTS author_daily_stats (
+    user_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_visitors BIGINT NOT NULL DEFAULT 0,
+    comments BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, date),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_author_daily_stats_date ON author_daily_stats(date);
@@ -0,0 +1,5 @@
+-- 删除点赞、收藏和阅读列表
+ALTER TABLE notifications DROP COLUMN IF EXISTS actor_count;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS reading_lists;
+DROP TABLE IF EXISTS post_likes;
@@ -0,0 +1,6 @@
+-- 删除关注表和作者每日汇总的新增关注者数
+ALTER TABLE author_daily_stats DROP COLUMN IF EXISTS new_followers;
+DROP INDEX IF EXISTS idx_posts_published_at_id;
+DROP TABLE IF EXISTS category_follows;
+DROP TABLE IF EXISTS tag_follows;
+DROP TABLE IF EXISTS user_follows;
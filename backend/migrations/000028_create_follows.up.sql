@@ -0,0 +1,42 @@
+-- 创建用户关注表，follower_id 关注 followee_id
+CREATE TABLE IF NOT EXISTS user_follows (
+    follower_id INTEGER NOT NULL,
+    followee_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee_id ON user_follows(followee_id);
+
+-- 创建标签关注表
+CREATE TABLE IF NOT EXISTS tag_follows (
+    user_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, tag_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tag_follows_tag_id ON tag_follows(tag_id);
+
+-- 创建分类关注表
+CREATE TABLE IF NOT EXISTS category_follows (
+    user_id INTEGER NOT NULL,
+    category_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, category_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_follows_category_id ON category_follows(category_id);
+
+-- 关注动态按发布时间倒序分页
+CREATE INDEX IF NOT EXISTS idx_posts_published_at_id ON posts(published_at DESC, id DESC);
+
+-- 作者每日汇总增加新增关注者数
+ALTER TABLE author_daily_stats ADD COLUMN IF NOT EXISTS new_followers BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- 删除评论修订、表情回应和提醒
+DROP TABLE IF EXISTS comment_mentions;
+DROP TABLE IF EXISTS comment_reactions;
+DROP TABLE IF EXISTS comment_revisions;
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
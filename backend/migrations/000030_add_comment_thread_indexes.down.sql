@@ -0,0 +1,3 @@
+-- 删除评论树分页索引
+DROP INDEX IF EXISTS idx_comments_parent_created;
+DROP INDEX IF EXISTS idx_comments_post_parent_created;
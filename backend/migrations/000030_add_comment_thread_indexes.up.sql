@@ -0,0 +1,3 @@
+-- 评论树按层分页：第一层按文章和父评论过滤，回复按父评论过滤，均按时间排序
+CREATE INDEX IF NOT EXISTS idx_comments_post_parent_created ON comments(post_id, parent_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_created ON comments(parent_id, created_at, id);
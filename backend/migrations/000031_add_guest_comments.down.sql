@@ -0,0 +1,11 @@
+-- 删除访客评论及其确认记录，恢复评论必须属于用户的约束
+ALTER TABLE posts DROP COLUMN IF EXISTS guest_comments_disabled;
+DROP TABLE IF EXISTS guest_comment_verifications;
+UPDATE comments SET parent_id = NULL WHERE parent_id IN (SELECT id FROM comments WHERE user_id IS NULL);
+UPDATE comments SET reply_to_id = NULL WHERE reply_to_id IN (SELECT id FROM comments WHERE user_id IS NULL);
+DELETE FROM comments WHERE user_id IS NULL;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS chk_comments_author;
+DROP INDEX IF EXISTS idx_comments_guest_email;
+ALTER TABLE comments DROP COLUMN IF EXISTS guest_email;
+ALTER TABLE comments DROP COLUMN IF EXISTS guest_name;
+ALTER TABLE comments ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,23 @@
+-- 访客评论没有用户，保存访客填写的名称和邮箱
+ALTER TABLE comments ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS guest_name VARCHAR(50);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS guest_email VARCHAR(100);
+ALTER TABLE comments ADD CONSTRAINT chk_comments_author
+    CHECK (user_id IS NOT NULL OR (guest_name IS NOT NULL AND guest_email IS NOT NULL));
+
+-- 用于注册用户认领同一邮箱的访客评论
+CREATE INDEX IF NOT EXISTS idx_comments_guest_email ON comments(guest_email) WHERE user_id IS NULL;
+
+-- 创建访客评论确认表，数据库中只保存确认令牌的哈希
+CREATE TABLE IF NOT EXISTS guest_comment_verifications (
+    comment_id INTEGER PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_guest_comment_verifications_expires_at ON guest_comment_verifications(expires_at);
+
+-- 作者可以单独关闭文章的访客评论
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS guest_comments_disabled BOOLEAN NOT NULL DEFAULT FALSE;
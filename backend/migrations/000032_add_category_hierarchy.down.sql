@@ -0,0 +1,9 @@
+-- 删除分类层次、排序、slug、封面和默认设置
+ALTER TABLE categories DROP COLUMN IF EXISTS default_visibility;
+ALTER TABLE categories DROP COLUMN IF EXISTS comment_mode;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP INDEX IF EXISTS idx_categories_scope_slug;
+ALTER TABLE categories DROP COLUMN IF EXISTS slug;
+ALTER TABLE categories DROP COLUMN IF EXISTS cover;
+ALTER TABLE categories DROP COLUMN IF EXISTS sort_order;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,21 @@
+-- 分类支持多级层次，同级分类按 sort_order 升序排列
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS sort_order INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS cover VARCHAR(255);
+
+-- 为已有分类生成 slug，附加ID保证唯一，无法转换的名称（如中文）使用 category 前缀
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+UPDATE categories
+SET slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(name, '[^a-zA-Z0-9]+', '-', 'g'))), ''), 'category') || '-' || id
+WHERE slug IS NULL;
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+-- slug 在全站分类或同一工作区的分类中唯一
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_scope_slug ON categories(COALESCE(workspace_id, 0), slug);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id, sort_order);
+
+-- 分类的默认设置，为空时继承上级分类
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS comment_mode VARCHAR(20)
+    CHECK (comment_mode IN ('open', 'approval', 'closed'));
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS default_visibility VARCHAR(20)
+    CHECK (default_visibility IN ('public', 'unlisted', 'private'));
@@ -0,0 +1,6 @@
+-- 删除标签别名、slug、描述和不区分大小写的唯一索引，已合并的标签无法恢复
+DROP TABLE IF EXISTS tag_aliases;
+DROP INDEX IF EXISTS idx_tags_slug;
+ALTER TABLE tags DROP COLUMN IF EXISTS slug;
+ALTER TABLE tags DROP COLUMN IF EXISTS description;
+DROP INDEX IF EXISTS idx_tags_lower_name;
@@ -0,0 +1,42 @@
+-- 合并名称只有大小写或首尾空白不同的已有标签，保留ID最小的标签
+CREATE TEMP TABLE tag_duplicates AS
+SELECT t.id AS tag_id, keep.id AS keep_id
+FROM tags t
+JOIN (
+    SELECT MIN(id) AS id, LOWER(TRIM(name)) AS name FROM tags GROUP BY LOWER(TRIM(name))
+) keep ON LOWER(TRIM(t.name)) = keep.name
+WHERE t.id <> keep.id;
+
+INSERT INTO post_tags (post_id, tag_id)
+SELECT pt.post_id, d.keep_id FROM post_tags pt JOIN tag_duplicates d ON pt.tag_id = d.tag_id
+ON CONFLICT DO NOTHING;
+INSERT INTO draft_tags (draft_id, tag_id)
+SELECT dt.draft_id, d.keep_id FROM draft_tags dt JOIN tag_duplicates d ON dt.tag_id = d.tag_id
+ON CONFLICT DO NOTHING;
+DELETE FROM tags WHERE id IN (SELECT tag_id FROM tag_duplicates);
+DROP TABLE tag_duplicates;
+
+UPDATE tags SET name = TRIM(name) WHERE name <> TRIM(name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_lower_name ON tags(LOWER(name));
+
+-- 标签描述和 slug，为已有标签根据名称生成 slug，无法转换或重复时附加ID
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+UPDATE tags
+SET slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(name, '[^a-zA-Z0-9]+', '-', 'g'))), ''), 'tag-' || id)
+WHERE slug IS NULL;
+UPDATE tags t SET slug = t.slug || '-' || t.id
+WHERE EXISTS (SELECT 1 FROM tags o WHERE o.slug = t.slug AND o.id < t.id);
+ALTER TABLE tags ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_slug ON tags(slug);
+
+-- 标签别名，输入别名时使用对应的标签；别名与标签名称一样不区分大小写
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    id SERIAL PRIMARY KEY,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_aliases_lower_name ON tag_aliases(LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_id ON tag_aliases(tag_id);
@@ -69,6 +69,32 @@ func (j *JSONMap) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// StringList 用于以JSON数组存储字符串列表
+type StringList []string
+
+// Value 实现driver.Valuer接口
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan 实现sql.Scanner接口
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
 // AIUserSetting 表示用户的AI设置
 type AIUserSetting struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
@@ -89,15 +115,63 @@ func (AIUserSetting) TableName() string {
 
 // AIDefaultSetting 表示用户的默认AI设置
 type AIDefaultSetting struct {
-	ID                uint      `gorm:"primaryKey" json:"id"`
-	UserID            uint      `gorm:"not null;uniqueIndex" json:"userId"`
-	DefaultModel      string    `gorm:"size:100" json:"defaultModel"`
-	DefaultImageModel string    `gorm:"size:100" json:"defaultImageModel"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null;uniqueIndex" json:"userId"`
+	DefaultModel      string     `gorm:"size:100" json:"defaultModel"`
+	DefaultImageModel string     `gorm:"size:100" json:"defaultImageModel"`
+	FallbackModels    StringList `gorm:"type:json" json:"fallbackModels"` // 默认文本模型不可用时依次尝试的备用模型
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
 }
 
 // TableName 指定AIDefaultSetting的表名
 func (AIDefaultSetting) TableName() string {
 	return "ai_default_settings"
 }
+
+const (
+	AIActionSummarize    = "summarize"
+	AIActionSuggestTitle = "suggest_title"
+	AIActionSuggestTags  = "suggest_tags"
+	AIActionTranslate    = "translate"
+	AIActionFixGrammar   = "fix_grammar"
+	AIActionContinue     = "continue"
+	AIActionAsk          = "ask"
+)
+
+// AIPromptTemplate 表示写作助手使用的版本化提示词模板
+type AIPromptTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Action       string    `gorm:"size:50;not null;uniqueIndex:idx_ai_prompt_templates_action_version" json:"action"`
+	Version      int       `gorm:"not null;uniqueIndex:idx_ai_prompt_templates_action_version" json:"version"`
+	SystemPrompt string    `gorm:"type:text" json:"systemPrompt"`
+	UserPrompt   string    `gorm:"type:text;not null" json:"userPrompt"`
+	IsActive     bool      `gorm:"default:true" json:"isActive"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TableName 指定AIPromptTemplate的表名
+func (AIPromptTemplate) TableName() string {
+	return "ai_prompt_templates"
+}
+
+// AIImage 表示AI生成并保存到存储中的图像
+type AIImage struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"userId"`
+	Provider     string    `gorm:"size:50;not null" json:"provider"`
+	ModelID      string    `gorm:"size:100;not null" json:"modelId"`
+	Prompt       string    `gorm:"type:text;not null" json:"prompt"`
+	Size         string    `gorm:"size:20" json:"size"`
+	URL          string    `gorm:"size:500;not null" json:"url"`
+	ThumbnailURL string    `gorm:"size:500" json:"thumbnailUrl"`
+	ContentType  string    `gorm:"size:100" json:"contentType"`
+	FileSize     int64     `json:"fileSize"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TableName 指定AIImage的表名
+func (AIImage) TableName() string {
+	return "ai_images"
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// PostCommentStat 文章每日新增的评论数
+type PostCommentStat struct {
+	PostID   uint      `json:"post_id" gorm:"primaryKey"`
+	Date     time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Comments int64     `json:"comments" gorm:"not null;default:0"`
+}
+
+// AuthorDailyStat 作者全部文章的每日汇总，UniqueVisitors 是各文章独立访客数之和
+type AuthorDailyStat struct {
+	UserID         uint      `json:"user_id" gorm:"primaryKey"`
+	Date           time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Views          int64     `json:"views" gorm:"not null;default:0"`
+	UniqueVisitors int64     `json:"unique_visitors" gorm:"not null;default:0"`
+	Comments       int64     `json:"comments" gorm:"not null;default:0"`
+	NewFollowers   int64     `json:"new_followers" gorm:"not null;default:0"`
+}
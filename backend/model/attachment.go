@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Attachment 表示文章或草稿的附件，文件本身保存在媒体库中。
+// 上传后尚未关联文章或草稿时 PostID 和 DraftID 均为空。
+type Attachment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	AssetID   uint      `json:"asset_id" gorm:"not null;index"`
+	PostID    *uint     `json:"post_id" gorm:"index"`
+	DraftID   *uint     `json:"draft_id" gorm:"index"`
+	Filename  string    `json:"filename" gorm:"size:255;not null"` // 下载时使用的文件名
+	MimeType  string    `json:"mime_type" gorm:"size:100"`
+	Size      int64     `json:"size" gorm:"default:0"`
+	Position  int       `json:"position" gorm:"default:0"`  // 在文章中的排列顺序
+	Downloads int64     `json:"downloads" gorm:"default:0"` // 下载次数
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联
+	Asset *MediaAsset `json:"-" gorm:"foreignKey:AssetID"`
+}
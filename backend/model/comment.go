@@ -8,16 +8,19 @@ import (
 
 // Comment 评论模型
 type Comment struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Content   string         `json:"content" gorm:"type:text;not null"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	PostID    uint           `json:"post_id" gorm:"not null"`
-	ParentID  *uint          `json:"parent_id"`
-	ReplyToID *uint          `json:"reply_to_id"`
-	Status    string         `json:"status" gorm:"size:20;not null;default:'active'"` // active, hidden, deleted
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Content    string         `json:"content" gorm:"type:text;not null"`
+	UserID     *uint          `json:"user_id"`                   // 访客评论为空
+	GuestName  *string        `json:"guest_name" gorm:"size:50"` // 访客填写的名称
+	GuestEmail *string        `json:"-" gorm:"size:100"`         // 访客填写的邮箱，小写保存，不对外公开
+	PostID     uint           `json:"post_id" gorm:"not null"`
+	ParentID   *uint          `json:"parent_id"`
+	ReplyToID  *uint          `json:"reply_to_id"`
+	Status     string         `json:"status" gorm:"size:20;not null;default:'active'"` // active, hidden, deleted, pending（访客评论等待邮箱确认）
+	EditedAt   *time.Time     `json:"edited_at"`                                       // 作者最后一次修改的时间
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联
 	User     *User     `json:"user" gorm:"foreignKey:UserID"`
@@ -25,9 +28,53 @@ type Comment struct {
 	Parent   *Comment  `json:"parent" gorm:"foreignKey:ParentID"`
 	ReplyTo  *Comment  `json:"reply_to" gorm:"foreignKey:ReplyToID"`
 	Children []Comment `json:"children" gorm:"foreignKey:ParentID"`
+	Mentions []User    `json:"mentions" gorm:"many2many:comment_mentions"` // 内容中 @ 到的用户
 }
 
 // TableName specifies the table name for Comment model
 func (Comment) TableName() string {
 	return "comments"
 }
+
+// AuthorID 返回评论作者的用户ID，访客评论返回 0
+func (c *Comment) AuthorID() uint {
+	if c.UserID == nil {
+		return 0
+	}
+	return *c.UserID
+}
+
+// IsGuest 检查评论是否由访客发表且尚未被注册用户认领
+func (c *Comment) IsGuest() bool {
+	return c.UserID == nil
+}
+
+// GuestCommentVerification 访客评论的邮箱确认，只保存确认令牌的哈希
+type GuestCommentVerification struct {
+	CommentID uint      `json:"comment_id" gorm:"primaryKey"`
+	TokenHash string    `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentRevision 评论修改前的内容，CreatedAt 为该版本被替换的时间
+type CommentRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CommentID uint      `json:"comment_id" gorm:"not null;index"`
+	Content   string    `json:"content" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentReaction 用户对评论的表情回应，同一用户可以对一条评论使用多个不同的表情
+type CommentReaction struct {
+	CommentID uint      `json:"comment_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	Emoji     string    `json:"emoji" gorm:"primaryKey;size:32"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentMention 评论内容中 @ 到的用户
+type CommentMention struct {
+	CommentID uint `json:"comment_id" gorm:"primaryKey"`
+	UserID    uint `json:"user_id" gorm:"primaryKey;index"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Vector 以JSON数组形式存储的向量
+type Vector []float32
+
+// Value 实现driver.Valuer接口
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan 实现sql.Scanner接口
+func (v *Vector) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	default:
+		return errors.New("unsupported type for vector")
+	}
+}
+
+// PostEmbedding 文章分块的向量嵌入
+type PostEmbedding struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PostID     uint      `json:"post_id" gorm:"not null;index"`
+	ChunkIndex int       `json:"chunk_index" gorm:"not null"`
+	Content    string    `json:"content" gorm:"type:text"`
+	Vector     Vector    `json:"-" gorm:"type:jsonb;not null"`
+	Model      string    `json:"model" gorm:"size:150;not null"` // 生成向量的提供者和模型
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// PostLike 用户对文章的点赞，每个用户对每篇文章只能点赞一次
+type PostLike struct {
+	PostID    uint      `json:"post_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReadingList 用户用于分组收藏文章的阅读列表
+type ReadingList struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_reading_lists_user_name"`
+	Name        string    `json:"name" gorm:"size:100;not null;uniqueIndex:idx_reading_lists_user_name"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Bookmark 用户收藏的文章，ReadingListID 为空时未归入阅读列表
+type Bookmark struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_bookmarks_user_post"`
+	PostID        uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_bookmarks_user_post"`
+	ReadingListID *uint     `json:"reading_list_id" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// 关联
+	Post *Post `json:"post" gorm:"foreignKey:PostID"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// 关注对象的类型
+const (
+	FollowTargetUser     = "user"
+	FollowTargetTag      = "tag"
+	FollowTargetCategory = "category"
+)
+
+// UserFollow 用户对作者的关注，FollowerID 关注 FolloweeID
+type UserFollow struct {
+	FollowerID uint      `json:"follower_id" gorm:"primaryKey"`
+	FolloweeID uint      `json:"followee_id" gorm:"primaryKey;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TagFollow 用户对标签的关注
+type TagFollow struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	TagID     uint      `json:"tag_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategoryFollow 用户对分类的关注
+type CategoryFollow struct {
+	UserID     uint      `json:"user_id" gorm:"primaryKey"`
+	CategoryID uint      `json:"category_id" gorm:"primaryKey;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
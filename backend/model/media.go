@@ -0,0 +1,107 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	MediaRefTypePost     = "post"
+	MediaRefTypeDraft    = "draft"
+	MediaRefTypeUser     = "user"
+	MediaRefTypeAIImage  = "ai_image"
+	MediaRefTypeCategory = "category"
+
+	MediaRefFieldContent    = "content"
+	MediaRefFieldCover      = "cover"
+	MediaRefFieldAttachment = "attachment"
+	MediaRefFieldAvatar     = "avatar"
+	MediaRefFieldURL        = "url"
+	MediaRefFieldThumbnail  = "thumbnail"
+
+	MediaScanUnscanned = "unscanned" // 未启用扫描
+	MediaScanClean     = "clean"
+	MediaScanInfected  = "infected"
+	MediaScanFailed    = "failed" // 扫描服务不可用，按配置放行
+
+	// MediaStorageQuarantine 隔离文件的存储类型，文件保存在隔离目录中且不对外提供访问
+	MediaStorageQuarantine = "quarantine"
+	// MediaStoragePrivate 私有文件的存储类型，文件保存在私有目录中，只能通过鉴权的接口下载
+	MediaStoragePrivate = "private"
+)
+
+// MediaAsset 表示用户上传的文件
+type MediaAsset struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	UserID        uint          `json:"user_id" gorm:"not null;index"`
+	StorageType   string        `json:"storage_type" gorm:"size:20;not null"`
+	Key           string        `json:"key" gorm:"size:500;not null"`
+	URL           string        `json:"url" gorm:"size:500;not null;index"`
+	ThumbnailURL  string        `json:"thumbnail_url" gorm:"size:500"`
+	Filename      string        `json:"filename" gorm:"size:255"`
+	Size          int64         `json:"size" gorm:"default:0"`
+	MimeType      string        `json:"mime_type" gorm:"size:100"`
+	Width         int           `json:"width" gorm:"default:0"`
+	Height        int           `json:"height" gorm:"default:0"`
+	Checksum      string        `json:"checksum" gorm:"size:64;index"` // SHA-256
+	Variants      MediaVariants `json:"variants" gorm:"type:json"`
+	ScanStatus    string        `json:"scan_status" gorm:"size:20;default:unscanned"`
+	ScanSignature string        `json:"scan_signature" gorm:"size:255"` // 命中的病毒特征名称
+	QuarantinedAt *time.Time    `json:"quarantined_at"`
+	RefCount      int           `json:"ref_count" gorm:"default:0"`
+	OrphanedAt    *time.Time    `json:"orphaned_at"` // 最近一次扫描发现无引用的时间
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+
+	// 关联
+	User       *User            `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	References []MediaReference `json:"references,omitempty" gorm:"foreignKey:AssetID"`
+}
+
+// MediaReference 表示文章、草稿、用户头像、生成图片或分类封面等记录对文件的引用
+type MediaReference struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	AssetID   uint      `json:"asset_id" gorm:"not null;index"`
+	RefType   string    `json:"ref_type" gorm:"size:20;not null"` // post, draft, user, ai_image, category
+	RefID     uint      `json:"ref_id" gorm:"not null"`
+	Field     string    `json:"field" gorm:"size:20;not null"` // content, cover, attachment, avatar, url, thumbnail
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MediaVariant 表示图片按宽度或格式生成的变体
+type MediaVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"` // jpeg, png, webp 等
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+}
+
+// MediaVariants 用于以JSON数组存储图片变体
+type MediaVariants []MediaVariant
+
+// Value 实现driver.Valuer接口
+func (v MediaVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan 实现sql.Scanner接口
+func (v *MediaVariants) Scan(value interface{}) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, v)
+}
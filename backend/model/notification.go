@@ -9,21 +9,25 @@ import (
 const (
 	NotificationTypeCommentReply = "comment_reply"
 	NotificationTypePostComment  = "post_comment"
+	NotificationTypePostLike     = "post_like"     // 同一篇文章的点赞聚合为一条通知
+	NotificationTypeFollowedPost = "followed_post" // 关注的作者发布了新文章
+	NotificationTypeMention      = "mention"       // 在评论中被 @
 )
 
 // Notification 通知模型
 type Notification struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Type      string         `json:"type" gorm:"size:50;not null"` // 通知类型
-	UserID    uint           `json:"user_id" gorm:"not null"`      // 接收通知的用户ID
-	ActorID   uint           `json:"actor_id" gorm:"not null"`     // 触发通知的用户ID
-	PostID    *uint          `json:"post_id"`                      // 相关文章ID
-	CommentID *uint          `json:"comment_id"`                   // 相关评论ID
-	Content   string         `json:"content" gorm:"type:text"`     // 通知内容
-	Read      bool           `json:"read" gorm:"default:false"`    // 是否已读
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Type       string         `json:"type" gorm:"size:50;not null"`          // 通知类型
+	UserID     uint           `json:"user_id" gorm:"not null"`               // 接收通知的用户ID
+	ActorID    uint           `json:"actor_id" gorm:"not null"`              // 触发通知的用户ID
+	PostID     *uint          `json:"post_id"`                               // 相关文章ID
+	CommentID  *uint          `json:"comment_id"`                            // 相关评论ID
+	Content    string         `json:"content" gorm:"type:text"`              // 通知内容
+	Read       bool           `json:"read" gorm:"default:false"`             // 是否已读
+	ActorCount int            `json:"actor_count" gorm:"not null;default:1"` // 聚合通知中触发通知的人数，ActorID 为最近一位
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联
 	User    *User    `json:"user" gorm:"foreignKey:UserID"`       // 接收通知的用户
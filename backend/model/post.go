@@ -1,40 +1,107 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PostVisibilityPublic   = "public"   // 公开，出现在列表、订阅和搜索中
+	PostVisibilityUnlisted = "unlisted" // 不公开列出，知道链接即可访问
+	PostVisibilityPrivate  = "private"  // 仅作者和编辑可见
+	PostVisibilityPassword = "password" // 需要输入密码才能查看内容
+)
 
 // Post 表示一篇文章/笔记
 type Post struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Title       string    `json:"title" gorm:"not null"`
-	Content     string    `json:"content" gorm:"type:text"`
-	Summary     string    `json:"summary" gorm:"type:text"`
-	Cover       string    `json:"cover" gorm:"type:varchar(255)"` // 文章封面图片URL
-	Slug        string    `json:"slug" gorm:"uniqueIndex"`
-	UserID      uint      `json:"user_id" gorm:"not null"`
-	CategoryID  uint      `json:"category_id"`
-	Category    Category  `json:"category" gorm:"foreignKey:CategoryID"`
-	User        *User     `json:"user" gorm:"foreignKey:UserID"`
-	Tags        []Tag     `json:"tags" gorm:"many2many:post_tags;"`
-	Status      string    `json:"status" gorm:"default:'draft'"` // draft, published
-	Views       int64     `json:"views" gorm:"default:0"`        // 浏览量
-	PublishedAt time.Time `json:"published_at"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	Title        string       `json:"title" gorm:"not null"`
+	Content      string       `json:"content" gorm:"type:text"`
+	Summary      string       `json:"summary" gorm:"type:text"`
+	Cover        string       `json:"cover" gorm:"type:varchar(255)"` // 文章封面图片URL
+	Slug         string       `json:"slug" gorm:"uniqueIndex"`
+	UserID       uint         `json:"user_id" gorm:"not null"`
+	WorkspaceID  *uint        `json:"workspace_id" gorm:"index"` // 所属工作区，为空时属于作者个人
+	CategoryID   uint         `json:"category_id"`
+	Category     Category     `json:"category" gorm:"foreignKey:CategoryID"`
+	User         *User        `json:"user" gorm:"foreignKey:UserID"`
+	Tags         []Tag        `json:"tags" gorm:"many2many:post_tags;"`
+	Attachments  []Attachment `json:"attachments" gorm:"foreignKey:PostID"`
+	Status       string       `json:"status" gorm:"default:'draft'"`              // draft, published
+	Visibility   string       `json:"visibility" gorm:"size:20;default:'public'"` // public, unlisted, private, password
+	PasswordHash string       `json:"-" gorm:"size:100"`                          // 访问密码的 bcrypt 哈希
+	Views        int64        `json:"views" gorm:"default:0"`                     // 浏览量
+	PublishedAt  time.Time    `json:"published_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+
+	GuestCommentsDisabled bool `json:"guest_comments_disabled" gorm:"not null;default:false"` // 作者关闭了本文的访客评论
+}
+
+// SetPassword 设置文章的访问密码（加密）
+func (p *Post) SetPassword(password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	p.PasswordHash = string(hashed)
+	return nil
+}
+
+// CheckPassword 验证文章的访问密码
+func (p *Post) CheckPassword(password string) bool {
+	return p.PasswordHash != "" && bcrypt.CompareHashAndPassword([]byte(p.PasswordHash), []byte(password)) == nil
 }
 
-// Category 表示文章分类
+// IsPublic 检查文章是否已发布且公开，只有公开文章会进入列表、搜索和向量索引
+func (p *Post) IsPublic() bool {
+	return p.Status == "published" && (p.Visibility == "" || p.Visibility == PostVisibilityPublic)
+}
+
+// 分类的评论方式
+const (
+	CategoryCommentOpen     = "open"     // 评论直接公开
+	CategoryCommentApproval = "approval" // 评论先隐藏，由文章作者或管理者恢复后公开
+	CategoryCommentClosed   = "closed"   // 不允许评论
+)
+
+// Category 表示文章分类，分类可以有任意层级的下级分类
 type Category struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Name              string    `json:"name" gorm:"not null"`
+	Slug              string    `json:"slug" gorm:"size:100;not null"` // 在全站分类或同一工作区的分类中唯一
+	Description       string    `json:"description"`
+	Cover             string    `json:"cover" gorm:"size:255"`                // 分类封面图片URL
+	ParentID          *uint     `json:"parent_id" gorm:"index"`               // 上级分类，为空时为顶级分类
+	SortOrder         int       `json:"sort_order" gorm:"not null;default:0"` // 同级分类按此升序排列
+	CommentMode       string    `json:"comment_mode" gorm:"size:20"`          // 分类下文章的评论方式，为空时继承上级分类，顶级分类默认为 open
+	DefaultVisibility string    `json:"default_visibility" gorm:"size:20"`    // 新文章未指定可见性时使用，为空时继承上级分类，顶级分类默认为 public
+	WorkspaceID       *uint     `json:"workspace_id" gorm:"index"`            // 所属工作区，为空时为全站分类
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// 关联
+	Editors []User `json:"-" gorm:"many2many:category_editors"` // 责任编辑
+}
+
+// Tag 表示文章标签，名称不区分大小写唯一
+type Tag struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"not null"`
-	Description string    `json:"description"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex"`
+	Slug        string    `json:"slug" gorm:"size:100;not null;uniqueIndex"`
+	Description string    `json:"description" gorm:"not null;default:''"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	Aliases []TagAlias `json:"aliases,omitempty" gorm:"foreignKey:TagID"`
 }
 
-// Tag 表示文章标签
-type Tag struct {
+// TagAlias 标签的别名，输入别名时解析为对应的标签。合并标签时被合并标签的名称成为别名
+type TagAlias struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name" gorm:"not null;uniqueIndex"`
+	TagID     uint      `json:"tag_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:50;not null"` // 与标签名称一样不区分大小写唯一，也不能与标签名称相同
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// PostViewStat 文章每日浏览汇总，日期按 UTC 计算
+type PostViewStat struct {
+	PostID         uint      `json:"post_id" gorm:"primaryKey"`
+	Date           time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Views          int64     `json:"views" gorm:"not null;default:0"`
+	UniqueVisitors int64     `json:"unique_visitors" gorm:"not null;default:0"`
+}
+
+// PostViewReferrer 文章每日来自某个站外域名的浏览量
+type PostViewReferrer struct {
+	PostID uint      `json:"post_id" gorm:"primaryKey"`
+	Date   time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Domain string    `json:"domain" gorm:"primaryKey;size:255"`
+	Views  int64     `json:"views" gorm:"not null;default:0"`
+}
+
+// PostViewVisitor 文章每日的访客，Visitor 是访客标识的 SHA-256 哈希，只用于统计独立访客
+type PostViewVisitor struct {
+	PostID  uint      `gorm:"primaryKey"`
+	Date    time.Time `gorm:"primaryKey;type:date"`
+	Visitor string    `gorm:"primaryKey;size:64"`
+}
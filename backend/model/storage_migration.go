@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const (
+	StorageMigrationPending   = "pending"
+	StorageMigrationRunning   = "running"
+	StorageMigrationCompleted = "completed"
+	StorageMigrationFailed    = "failed"
+
+	StorageMigrationItemPending = "pending"
+	StorageMigrationItemCopied  = "copied"
+	StorageMigrationItemFailed  = "failed"
+	StorageMigrationItemMissing = "missing" // 试运行时发现源存储中不存在
+)
+
+// StorageMigration 表示一次在存储后端之间迁移文件的任务
+type StorageMigration struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Source     string     `json:"source" gorm:"size:20;not null"`
+	Target     string     `json:"target" gorm:"size:20;not null"`
+	DryRun     bool       `json:"dry_run" gorm:"default:false"`
+	Status     string     `json:"status" gorm:"size:20;not null;default:pending"`
+	Total      int        `json:"total" gorm:"default:0"`     // 需要迁移的文件数
+	Copied     int        `json:"copied" gorm:"default:0"`    // 已复制的文件数
+	Failed     int        `json:"failed" gorm:"default:0"`    // 复制失败的文件数
+	Missing    int        `json:"missing" gorm:"default:0"`   // 试运行时源中不存在的文件数
+	Rewritten  int        `json:"rewritten" gorm:"default:0"` // 改写URL的数据库记录数，试运行时为将被改写的记录数
+	LastError  string     `json:"last_error" gorm:"type:text"`
+	CreatedBy  uint       `json:"created_by"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// StorageMigrationItem 表示迁移任务中的一个存储对象
+type StorageMigrationItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	MigrationID uint      `json:"migration_id" gorm:"not null;index"`
+	Key         string    `json:"key" gorm:"size:500;not null"`
+	SourceURL   string    `json:"source_url" gorm:"size:500;not null"`
+	TargetURL   string    `json:"target_url" gorm:"size:500"`
+	Status      string    `json:"status" gorm:"size:20;not null;default:pending"`
+	Error       string    `json:"error" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+const (
+	WorkspaceRoleOwner      = "owner"      // 管理工作区设置、成员和全部内容，可以删除工作区
+	WorkspaceRoleMaintainer = "maintainer" // 管理成员、分类和全部内容
+	WorkspaceRoleWriter     = "writer"     // 撰写和管理自己的文章与草稿
+	WorkspaceRoleReader     = "reader"     // 查看工作区的全部内容
+)
+
+// workspaceRoleRanks 工作区角色的权限等级，等级高的角色包含等级低的角色的全部权限
+var workspaceRoleRanks = map[string]int{
+	WorkspaceRoleReader:     1,
+	WorkspaceRoleWriter:     2,
+	WorkspaceRoleMaintainer: 3,
+	WorkspaceRoleOwner:      4,
+}
+
+// WorkspaceRoleAtLeast 检查角色是否具有 minRole 的全部权限，未知角色不具有任何权限
+func WorkspaceRoleAtLeast(role, minRole string) bool {
+	rank, ok := workspaceRoleRanks[role]
+	return ok && rank >= workspaceRoleRanks[minRole]
+}
+
+// Workspace 团队工作区，拥有自己的分类、文章和草稿
+type Workspace struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	Slug        string    `json:"slug" gorm:"size:100;not null;uniqueIndex"`
+	Description string    `json:"description" gorm:"size:500"`
+	OwnerID     uint      `json:"owner_id" gorm:"not null"` // 创建者
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	Members []WorkspaceMember `json:"members,omitempty" gorm:"foreignKey:WorkspaceID"`
+}
+
+// WorkspaceMember 工作区成员及其角色
+type WorkspaceMember struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint      `json:"workspace_id" gorm:"not null;uniqueIndex:idx_workspace_members_workspace_user"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_workspace_members_workspace_user;index"`
+	Role        string    `json:"role" gorm:"size:20;not null"` // owner, maintainer, writer, reader
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Workspace *Workspace `json:"workspace,omitempty" gorm:"foreignKey:WorkspaceID"`
+}
+
+// WorkspaceInvitation 通过邮件发出的工作区邀请，只保存邀请令牌的哈希
+type WorkspaceInvitation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	WorkspaceID uint       `json:"workspace_id" gorm:"not null;index"`
+	Email       string     `json:"email" gorm:"size:100;not null"`
+	Role        string     `json:"role" gorm:"size:20;not null"`
+	TokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"` // SHA-256
+	InvitedBy   uint       `json:"invited_by" gorm:"not null"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// 关联
+	Workspace *Workspace `json:"workspace,omitempty" gorm:"foreignKey:WorkspaceID"`
+	Inviter   *User      `json:"inviter,omitempty" gorm:"foreignKey:InvitedBy"`
+}
+
+// IsPending 检查邀请是否仍可接受
+func (i *WorkspaceInvitation) IsPending() bool {
+	return i.AcceptedAt == nil && time.Now().Before(i.ExpiresAt)
+}
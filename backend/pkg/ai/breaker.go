@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 提供商连续失败后处于熔断状态
+var ErrCircuitOpen = errors.New("AI提供商暂时不可用，请稍后重试")
+
+// Breaker 按提供商和接口主机统计连续失败次数的熔断器
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  map[string]int
+	openUntil map[string]time.Time
+	probing   map[string]bool
+}
+
+// NewBreaker 创建熔断器，连续失败 threshold 次后熔断 cooldown 时长
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		failures:  make(map[string]int),
+		openUntil: make(map[string]time.Time),
+		probing:   make(map[string]bool),
+	}
+}
+
+// breakerKey 返回熔断统计的键，不同用户配置的接口地址分别统计
+func breakerKey(provider, host string) string {
+	return provider + "@" + host
+}
+
+// Allow 判断是否允许发送请求，冷却结束后只放行一个探测请求
+func (b *Breaker) Allow(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until, open := b.openUntil[key]
+	if !open {
+		return nil
+	}
+	if time.Now().Before(until) || b.probing[key] {
+		return ErrCircuitOpen
+	}
+	b.probing[key] = true
+	return nil
+}
+
+// Success 记录一次成功请求并关闭熔断
+func (b *Breaker) Success(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.failures, key)
+	delete(b.openUntil, key)
+	delete(b.probing, key)
+}
+
+// Failure 记录一次失败请求，达到阈值或探测失败时熔断
+func (b *Breaker) Failure(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures[key]++
+	if b.probing[key] || b.failures[key] >= b.threshold {
+		b.openUntil[key] = time.Now().Add(b.cooldown)
+		delete(b.probing, key)
+	}
+}
+
+// Release 结束探测但不记录结果，用于调用方取消等无法判断提供商状态的情况，
+// 之后的请求可以重新探测
+func (b *Breaker) Release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.probing, key)
+}
+
+type skipBreakerKey struct{}
+
+// WithoutBreaker 返回不经过熔断器的请求上下文，用于测试连接等由用户主动发起、
+// 结果不应影响其他请求的调用
+func WithoutBreaker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipBreakerKey{}, true)
+}
+
+// skipBreaker 判断请求上下文是否要求绕过熔断器
+func skipBreaker(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipBreakerKey{}).(bool)
+	return skip
+}
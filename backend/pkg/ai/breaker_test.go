@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(b *Breaker)
+		key   string
+		want  error
+	}{
+		{
+			name:  "below threshold",
+			steps: func(b *Breaker) { b.Failure("openai@a") },
+			key:   "openai@a",
+			want:  nil,
+		},
+		{
+			name: "open after threshold",
+			steps: func(b *Breaker) {
+				b.Failure("openai@a")
+				b.Failure("openai@a")
+			},
+			key:  "openai@a",
+			want: ErrCircuitOpen,
+		},
+		{
+			name: "other host unaffected",
+			steps: func(b *Breaker) {
+				b.Failure("openai@a")
+				b.Failure("openai@a")
+			},
+			key:  "openai@b",
+			want: nil,
+		},
+		{
+			name: "success resets failures",
+			steps: func(b *Breaker) {
+				b.Failure("openai@a")
+				b.Success("openai@a")
+				b.Failure("openai@a")
+			},
+			key:  "openai@a",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(2, time.Hour)
+			tt.steps(b)
+			if err := b.Allow(tt.key); !errors.Is(err, tt.want) {
+				t.Fatalf("Allow(%q) = %v, want %v", tt.key, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(b *Breaker, key string)
+		want   error
+	}{
+		{name: "probe released", finish: func(b *Breaker, key string) { b.Release(key) }, want: nil},
+		{name: "probe succeeded", finish: func(b *Breaker, key string) { b.Success(key) }, want: nil},
+		{name: "probe failed", finish: func(b *Breaker, key string) { b.Failure(key) }, want: ErrCircuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const key = "openai@a"
+			b := NewBreaker(1, time.Millisecond)
+			b.Failure(key)
+			time.Sleep(2 * time.Millisecond)
+
+			if err := b.Allow(key); err != nil {
+				t.Fatalf("probe not allowed: %v", err)
+			}
+			if err := b.Allow(key); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("second request during probe = %v, want ErrCircuitOpen", err)
+			}
+			tt.finish(b, key)
+			if err := b.Allow(key); !errors.Is(err, tt.want) {
+				t.Fatalf("Allow after probe = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
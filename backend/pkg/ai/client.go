@@ -0,0 +1,351 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"notex/config"
+	"strconv"
+	"time"
+)
+
+// Client 发送AI请求的客户端，可替换为测试用的实现
+type Client interface {
+	// Chat 发送聊天请求，调用方负责关闭返回的响应体
+	Chat(ctx context.Context, req *ChatRequest) (*http.Response, error)
+	// Do 向指定提供商发送已构造好的请求，调用方负责关闭返回的响应体
+	Do(req *http.Request, provider string, stream bool) (*http.Response, error)
+}
+
+// HTTPClient 通过HTTP调用AI提供商的客户端，支持超时、带抖动的重试和按提供商及接口主机熔断
+type HTTPClient struct {
+	HTTP           *http.Client
+	Timeout        time.Duration
+	StreamTimeout  time.Duration
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	Breaker        *Breaker
+}
+
+// NewHTTPClient 根据配置创建AI客户端
+func NewHTTPClient(cfg *config.AIConfig) *HTTPClient {
+	return &HTTPClient{
+		HTTP:           &http.Client{},
+		Timeout:        cfg.Timeout,
+		StreamTimeout:  cfg.StreamTimeout,
+		MaxRetries:     cfg.MaxRetries,
+		RetryBaseDelay: cfg.RetryBaseDelay,
+		RetryMaxDelay:  cfg.RetryMaxDelay,
+		Breaker:        NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// ChatRequest 表示发往AI提供商的聊天请求
+type ChatRequest struct {
+	Provider string
+	Endpoint string
+	APIKey   string
+	Model    string
+	Messages []map[string]string
+	Stream   bool
+	Params   map[string]interface{}
+}
+
+// GetProviderEndpoint 获取AI提供商的API端点
+func GetProviderEndpoint(provider string) string {
+	endpoints := map[string]string{
+		"openai":    "https://api.openai.com/v1/chat/completions",
+		"anthropic": "https://api.anthropic.com/v1/messages",
+		"google":    "https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent",
+		"deepseek":  "https://api.deepseek.com/v1/chat/completions",
+	}
+	return endpoints[provider]
+}
+
+// GetProviderHeaders 获取AI提供商的请求头
+func GetProviderHeaders(provider, apiKey string) map[string]string {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	switch provider {
+	case "openai":
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
+	case "stabilityai":
+		headers["Accept"] = "application/json"
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
+	case "anthropic":
+		headers["x-api-key"] = apiKey
+	case "google":
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
+	case "deepseek":
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
+	case "custom":
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
+	}
+
+	return headers
+}
+
+// FormatMessages 格式化消息以适应不同提供商的格式
+func FormatMessages(messages []map[string]string, provider string) interface{} {
+	switch provider {
+	case "anthropic":
+		formatted := make([]map[string]interface{}, len(messages))
+		for i, msg := range messages {
+			formatted[i] = map[string]interface{}{
+				"role":    msg["role"],
+				"content": msg["content"],
+			}
+		}
+		return formatted
+	case "google":
+		formatted := make([]map[string]interface{}, len(messages))
+		for i, msg := range messages {
+			formatted[i] = map[string]interface{}{
+				"role": msg["role"],
+				"parts": []map[string]string{
+					{"text": msg["content"]},
+				},
+			}
+		}
+		return formatted
+	default:
+		return messages
+	}
+}
+
+// Chat 向AI提供商发送聊天请求
+func (c *HTTPClient) Chat(ctx context.Context, req *ChatRequest) (*http.Response, error) {
+	endpoint := req.Endpoint
+	if endpoint == "" {
+		endpoint = GetProviderEndpoint(req.Provider)
+		if endpoint == "" {
+			return nil, errors.New("无效的提供商或缺少端点")
+		}
+	}
+
+	// 准备请求体
+	requestBody := map[string]interface{}{
+		"messages": FormatMessages(req.Messages, req.Provider),
+		"model":    req.Model,
+		"stream":   req.Stream,
+	}
+
+	// 添加其他参数
+	for k, v := range req.Params {
+		requestBody[k] = v
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("请求体序列化失败: %w", err)
+	}
+
+	// 创建请求
+	proxyReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 设置请求头
+	for k, v := range GetProviderHeaders(req.Provider, req.APIKey) {
+		proxyReq.Header.Set(k, v)
+	}
+
+	return c.Do(proxyReq, req.Provider, req.Stream)
+}
+
+// Do 发送请求，对网络错误、429 和 5xx 响应按指数退避重试；
+// 超时覆盖全部重试及响应体读取，请求上下文取消时上游调用随之取消
+func (c *HTTPClient) Do(req *http.Request, provider string, stream bool) (*http.Response, error) {
+	timeout := c.Timeout
+	if stream {
+		timeout = c.StreamTimeout
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+
+	breaker := c.Breaker
+	if skipBreaker(req.Context()) {
+		breaker = nil
+	}
+	key := breakerKey(provider, req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		if breaker != nil {
+			if err := breaker.Allow(key); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+
+		attemptReq := req.Clone(ctx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				release(breaker, key)
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := c.HTTP.Do(attemptReq)
+		if err != nil {
+			// 调用方取消时不计入提供商失败，但要结束可能正在进行的探测
+			if req.Context().Err() != nil {
+				release(breaker, key)
+				cancel()
+				return nil, req.Context().Err()
+			}
+			if breaker != nil {
+				breaker.Failure(key)
+			}
+		} else if resp.StatusCode >= http.StatusInternalServerError {
+			if breaker != nil {
+				breaker.Failure(key)
+			}
+		} else if breaker != nil {
+			breaker.Success(key)
+		}
+
+		retryable := err != nil || IsRetryableStatus(resp.StatusCode)
+		canRetry := attempt < c.MaxRetries && (req.GetBody != nil || req.Body == nil || req.Body == http.NoBody)
+		if !retryable || !canRetry || ctx.Err() != nil {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if after := retryAfter(resp); after > delay && after <= c.RetryMaxDelay {
+				delay = after
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			release(breaker, key)
+			cancel()
+			if req.Context().Err() != nil {
+				return nil, req.Context().Err()
+			}
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// release 结束请求占用的探测名额
+func release(breaker *Breaker, key string) {
+	if breaker != nil {
+		breaker.Release(key)
+	}
+}
+
+// backoff 计算第 attempt 次重试前的等待时间，在指数退避的基础上加入随机抖动
+func (c *HTTPClient) backoff(attempt int) time.Duration {
+	delay := c.RetryBaseDelay << attempt
+	if delay <= 0 || delay > c.RetryMaxDelay {
+		delay = c.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// IsRetryableStatus 判断响应状态码是否表示提供商暂时不可用
+func IsRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter 解析以秒为单位的 Retry-After 响应头
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// cancelOnClose 在关闭响应体时释放请求上下文
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close 关闭响应体并取消上下文
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// ExtractText 从非流式响应中提取生成的文本
+func ExtractText(provider string, body []byte) (string, error) {
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	switch provider {
+	case "anthropic":
+		if content, ok := result["content"].([]interface{}); ok {
+			for _, item := range content {
+				if block, ok := item.(map[string]interface{}); ok {
+					if text, ok := block["text"].(string); ok {
+						return text, nil
+					}
+				}
+			}
+		}
+	case "google":
+		if candidates, ok := result["candidates"].([]interface{}); ok && len(candidates) > 0 {
+			if candidate, ok := candidates[0].(map[string]interface{}); ok {
+				if content, ok := candidate["content"].(map[string]interface{}); ok {
+					if parts, ok := content["parts"].([]interface{}); ok && len(parts) > 0 {
+						if part, ok := parts[0].(map[string]interface{}); ok {
+							if text, ok := part["text"].(string); ok {
+								return text, nil
+							}
+						}
+					}
+				}
+			}
+		}
+	default:
+		if choices, ok := result["choices"].([]interface{}); ok && len(choices) > 0 {
+			if choice, ok := choices[0].(map[string]interface{}); ok {
+				if message, ok := choice["message"].(map[string]interface{}); ok {
+					if text, ok := message["content"].(string); ok {
+						return text, nil
+					}
+				}
+			}
+		}
+	}
+
+	return "", errors.New("响应中未找到生成的文本")
+}
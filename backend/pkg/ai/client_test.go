@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestClient() *HTTPClient {
+	return &HTTPClient{
+		HTTP:           &http.Client{},
+		Timeout:        5 * time.Second,
+		StreamTimeout:  5 * time.Second,
+		MaxRetries:     0,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+		Breaker:        NewBreaker(1, time.Millisecond),
+	}
+}
+
+func hostOf(t *testing.T, raw string) string {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
+
+// openAndExpire 让熔断器对主机熔断并等待冷却结束，下一个请求将成为探测请求
+func openAndExpire(c *HTTPClient, key string) {
+	c.Breaker.Failure(key)
+	time.Sleep(2 * time.Millisecond)
+}
+
+func TestDoReleasesProbeOnCancel(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c := newTestClient()
+	key := breakerKey("openai", hostOf(t, server.URL))
+	openAndExpire(c, key)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := c.Do(req, "openai", false); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do = %v, want context.Canceled", err)
+	}
+
+	if err := c.Breaker.Allow(key); err != nil {
+		t.Fatalf("probe still held after cancel: %v", err)
+	}
+}
+
+func TestDoReleasesProbeOnCancelDuringRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := newTestClient()
+	c.MaxRetries = 1
+	c.RetryBaseDelay = time.Second
+	c.RetryMaxDelay = time.Second
+	key := breakerKey("openai", hostOf(t, server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := c.Do(req, "openai", true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do = %v, want context.DeadlineExceeded", err)
+	}
+
+	if err := c.Breaker.Allow(key); err != nil {
+		t.Fatalf("breaker blocked after cancel during retry wait: %v", err)
+	}
+}
+
+func TestDoBreakerKeyedByHost(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	c := newTestClient()
+	c.Breaker = NewBreaker(1, time.Hour)
+
+	req, _ := http.NewRequest(http.MethodGet, failing.URL, nil)
+	resp, err := c.Do(req, "openai", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodGet, failing.URL, nil)
+	if _, err := c.Do(req, "openai", false); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("failing host = %v, want ErrCircuitOpen", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, healthy.URL, nil)
+	resp, err = c.Do(req, "openai", false)
+	if err != nil {
+		t.Fatalf("healthy host blocked: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ = http.NewRequestWithContext(WithoutBreaker(context.Background()), http.MethodGet, failing.URL, nil)
+	resp, err = c.Do(req, "openai", false)
+	if err != nil {
+		t.Fatalf("request without breaker blocked: %v", err)
+	}
+	resp.Body.Close()
+}
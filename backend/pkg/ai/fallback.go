@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ChatWithFallback 依次尝试主模型和备用模型，当前模型不可用（网络错误、熔断、429 或 5xx）时切换到下一个；
+// 返回最终使用的请求，所有模型都不可用时返回最后一个响应或错误
+func ChatWithFallback(ctx context.Context, client Client, chain []*ChatRequest) (*http.Response, *ChatRequest, error) {
+	var lastErr error
+	var lastResp *http.Response
+	var lastReq *ChatRequest
+
+	for _, req := range chain {
+		if lastResp != nil {
+			io.Copy(io.Discard, lastResp.Body)
+			lastResp.Body.Close()
+			lastResp = nil
+		}
+
+		resp, err := client.Chat(ctx, req)
+		lastReq = req
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, req, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		if IsRetryableStatus(resp.StatusCode) {
+			lastResp = resp
+			continue
+		}
+		return resp, req, nil
+	}
+
+	if lastResp != nil {
+		return lastResp, lastReq, nil
+	}
+	return nil, lastReq, lastErr
+}
+
+// AdaptMessages 将消息转换为提供商支持的形式，不支持 system 角色的提供商将系统提示合并到第一条用户消息中
+func AdaptMessages(provider string, messages []map[string]string) []map[string]string {
+	if provider != "anthropic" && provider != "google" {
+		return messages
+	}
+
+	var system []string
+	adapted := make([]map[string]string, 0, len(messages))
+	for _, msg := range messages {
+		if msg["role"] == "system" {
+			system = append(system, msg["content"])
+			continue
+		}
+		adapted = append(adapted, msg)
+	}
+	if len(system) == 0 {
+		return messages
+	}
+
+	prefix := strings.Join(system, "\n\n")
+	for i, msg := range adapted {
+		if msg["role"] == "user" {
+			adapted[i] = map[string]string{"role": "user", "content": prefix + "\n\n" + msg["content"]}
+			return adapted
+		}
+	}
+	return append([]map[string]string{{"role": "user", "content": prefix}}, adapted...)
+}
@@ -11,6 +11,8 @@ import (
 var (
 	secretKey     = []byte("your-secret-key") // 在生产环境中应该从配置文件或环境变量中读取
 	tokenDuration = 24 * time.Hour            // 访问令牌有效期
+
+	postTokenDuration = 24 * time.Hour // 文章解锁令牌有效期
 )
 
 // GenerateToken 生成 JWT 令牌
@@ -80,3 +82,46 @@ func ParseRefreshToken(tokenString string) (uint, error) {
 
 	return 0, errors.New("invalid refresh token")
 }
+
+// GeneratePostToken 生成密码保护文章的解锁令牌，fingerprint 由文章密码派生，修改密码后旧令牌失效
+func GeneratePostToken(postID uint, fingerprint string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(postTokenDuration)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"post_id":     postID,
+		"fingerprint": fingerprint,
+		"exp":         expiresAt.Unix(),
+		"iat":         time.Now().Unix(),
+	})
+
+	signed, err := token.SignedString(postSecretKey())
+	return signed, expiresAt, err
+}
+
+// ParsePostToken 解析文章解锁令牌，返回文章ID和密码指纹
+func ParsePostToken(tokenString string) (uint, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return postSecretKey(), nil
+	})
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		postID, _ := claims["post_id"].(float64)
+		fingerprint, _ := claims["fingerprint"].(string)
+		if postID > 0 && fingerprint != "" {
+			return uint(postID), fingerprint, nil
+		}
+	}
+
+	return 0, "", errors.New("invalid post token")
+}
+
+// postSecretKey 解锁令牌使用单独派生的密钥，避免被当作访问令牌使用
+func postSecretKey() []byte {
+	return append(append([]byte{}, secretKey...), ":post"...)
+}
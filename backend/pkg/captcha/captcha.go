@@ -0,0 +1,52 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"notex/config"
+)
+
+// ErrFailed 验证未通过：答案错误、挑战已过期或已被使用
+var ErrFailed = errors.New("captcha verification failed")
+
+// Challenge 发给客户端的验证挑战
+type Challenge struct {
+	Provider string // 验证方式
+	Token    string // 内置验证码的挑战令牌，提交答案时原样带回
+	Image    string // 内置验证码的题目图片（data URL）
+	SiteKey  string // hCaptcha 站点密钥，客户端用它渲染验证组件
+}
+
+// Captcha 人机验证接口
+type Captcha interface {
+	// Name 返回验证方式的名称
+	Name() string
+
+	// Challenge 创建新的验证挑战
+	Challenge(ctx context.Context) (*Challenge, error)
+
+	// Verify 校验客户端提交的答案，未通过时返回 ErrFailed，验证服务不可用时返回其他错误。
+	// token 为挑战令牌，answer 为用户的答案或第三方组件返回的响应
+	Verify(ctx context.Context, token, answer, remoteIP string) error
+}
+
+// NewCaptcha 根据配置创建人机验证，未启用时返回 nil。signingKey 在配置未指定签名密钥时使用
+func NewCaptcha(cfg *config.CaptchaConfig, signingKey string) (Captcha, error) {
+	switch cfg.Provider {
+	case "", "none":
+		return nil, nil
+	case "math":
+		if cfg.SigningKey != "" {
+			signingKey = cfg.SigningKey
+		}
+		if signingKey == "" {
+			return nil, fmt.Errorf("captcha signing key cannot be empty")
+		}
+		return NewMathCaptcha([]byte(signingKey), cfg.Expiration), nil
+	case "hcaptcha":
+		return NewHCaptcha(cfg.SiteKey, cfg.SecretKey, cfg.VerifyURL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("captcha provider not found: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,81 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HCaptcha 通过 hCaptcha 兼容的 siteverify 接口校验客户端组件返回的响应
+type HCaptcha struct {
+	siteKey   string
+	secretKey string
+	verifyURL string
+	client    *http.Client
+}
+
+// NewHCaptcha 创建 hCaptcha 校验器，verifyURL 可以指向任何兼容的服务，本地开发时可以使用桩服务
+func NewHCaptcha(siteKey, secretKey, verifyURL string, timeout time.Duration) *HCaptcha {
+	return &HCaptcha{
+		siteKey:   siteKey,
+		secretKey: secretKey,
+		verifyURL: verifyURL,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 返回验证方式的名称
+func (h *HCaptcha) Name() string {
+	return "hcaptcha"
+}
+
+// Challenge 返回站点密钥，挑战由客户端组件向 hCaptcha 获取
+func (h *HCaptcha) Challenge(ctx context.Context) (*Challenge, error) {
+	return &Challenge{Provider: h.Name(), SiteKey: h.siteKey}, nil
+}
+
+// Verify 将组件返回的响应提交给校验接口，token 不使用
+func (h *HCaptcha) Verify(ctx context.Context, token, answer, remoteIP string) error {
+	if answer == "" {
+		return ErrFailed
+	}
+
+	form := url.Values{
+		"secret":   {h.secretKey},
+		"response": {answer},
+		"sitekey":  {h.siteKey},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call captcha verify endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha verify endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode captcha verify response: %w", err)
+	}
+	if !result.Success {
+		return ErrFailed
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHCaptchaVerify(t *testing.T) {
+	// 桩服务只接受响应 good，并检查提交的表单
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.PostForm.Get("response") {
+		case "unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "garbage":
+			fmt.Fprint(w, "not json")
+			return
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+		ok := r.PostForm.Get("secret") == "secret" &&
+			r.PostForm.Get("sitekey") == "site" &&
+			r.PostForm.Get("response") == "good" &&
+			r.PostForm.Get("remoteip") == "203.0.113.1"
+		if ok {
+			fmt.Fprint(w, `{"success": true}`)
+			return
+		}
+		fmt.Fprint(w, `{"success": false, "error-codes": ["invalid-input-response"]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		answer   string
+		remoteIP string
+		wantErr  error
+		failed   bool // 校验接口出错，要求返回 ErrFailed 以外的错误
+	}{
+		{name: "success", answer: "good", remoteIP: "203.0.113.1"},
+		{name: "rejected", answer: "bad", remoteIP: "203.0.113.1", wantErr: ErrFailed},
+		{name: "wrong remote ip", answer: "good", remoteIP: "198.51.100.1", wantErr: ErrFailed},
+		{name: "empty answer", answer: "", wantErr: ErrFailed},
+		{name: "endpoint unavailable", answer: "unavailable", failed: true},
+		{name: "invalid response", answer: "garbage", failed: true},
+		{name: "timeout", answer: "slow", failed: true},
+	}
+
+	h := NewHCaptcha("site", "secret", server.URL, 100*time.Millisecond)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := h.Verify(context.Background(), "", tt.answer, tt.remoteIP)
+			switch {
+			case tt.failed:
+				if err == nil || errors.Is(err, ErrFailed) {
+					t.Fatalf("Verify = %v, want an endpoint error", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHCaptchaChallenge(t *testing.T) {
+	h := NewHCaptcha("site", "secret", "http://127.0.0.1", time.Second)
+	challenge, err := h.Challenge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.Provider != "hcaptcha" || challenge.SiteKey != "site" || challenge.Token != "" {
+		t.Fatalf("Challenge = %+v", challenge)
+	}
+}
@@ -0,0 +1,240 @@
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 算式图片的绘制参数
+const (
+	glyphWidth  = 5 // 字形宽度（点）
+	glyphHeight = 7 // 字形高度（点）
+	glyphScale  = 4 // 每个点绘制的像素数
+	glyphGap    = 2 // 字符间距（点）
+	imagePad    = 3 // 图片边距（点）
+	noiseDots   = 160
+	noiseLines  = 4
+)
+
+// glyphs 算式中用到的字符的点阵字形
+var glyphs = map[rune][glyphHeight]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"####.", "....#", "....#", ".###.", "....#", "....#", "####."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'=': {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'?': {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+}
+
+// MathCaptcha 内置验证码：以图片形式给出一道加减法算式。
+// 答案不在服务端保存，而是与过期时间一起签名在挑战令牌中，令牌只能使用一次
+type MathCaptcha struct {
+	key        []byte
+	expiration time.Duration
+
+	mu   sync.Mutex
+	used map[string]time.Time // 已使用的令牌及其过期时间
+}
+
+// NewMathCaptcha 创建内置验证码，key 用于签名挑战令牌
+func NewMathCaptcha(key []byte, expiration time.Duration) *MathCaptcha {
+	return &MathCaptcha{
+		key:        key,
+		expiration: expiration,
+		used:       make(map[string]time.Time),
+	}
+}
+
+// Name 返回验证方式的名称
+func (m *MathCaptcha) Name() string {
+	return "math"
+}
+
+// Challenge 生成一道 20 以内的加减法，结果不为负数
+func (m *MathCaptcha) Challenge(ctx context.Context) (*Challenge, error) {
+	a, err := randomInt(1, 20)
+	if err != nil {
+		return nil, err
+	}
+	b, err := randomInt(1, 20)
+	if err != nil {
+		return nil, err
+	}
+	op, err := randomInt(0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	question, answer := fmt.Sprintf("%d+%d=?", a, b), a+b
+	if op == 1 {
+		if a < b {
+			a, b = b, a
+		}
+		question, answer = fmt.Sprintf("%d-%d=?", a, b), a-b
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	payload := fmt.Sprintf("%d.%s", time.Now().Add(m.expiration).Unix(), hex.EncodeToString(nonce))
+	token := payload + "." + m.sign(payload, strconv.Itoa(answer))
+
+	img, err := renderQuestion(question)
+	if err != nil {
+		return nil, err
+	}
+	return &Challenge{Provider: m.Name(), Token: token, Image: img}, nil
+}
+
+// Verify 检查令牌有效期、签名和答案。令牌无论答案是否正确都只能提交一次，
+// 避免对同一道题反复猜测答案
+func (m *MathCaptcha) Verify(ctx context.Context, token, answer, remoteIP string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrFailed
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrFailed
+	}
+	expiresAt := time.Unix(expires, 0)
+	now := time.Now()
+	// 有效期超过签发时长的令牌不可能由本服务签发，不记录以免占用内存
+	if now.After(expiresAt) || expiresAt.After(now.Add(m.expiration)) {
+		return ErrFailed
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !m.burn(payload, expiresAt, now) {
+		return ErrFailed
+	}
+
+	expected := m.sign(payload, strings.TrimSpace(answer))
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return ErrFailed
+	}
+	return nil
+}
+
+// burn 将令牌标记为已使用，令牌之前已使用过时返回 false
+func (m *MathCaptcha) burn(payload string, expiresAt, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for used, usedExpiresAt := range m.used {
+		if now.After(usedExpiresAt) {
+			delete(m.used, used)
+		}
+	}
+	if _, ok := m.used[payload]; ok {
+		return false
+	}
+	m.used[payload] = expiresAt
+	return true
+}
+
+// sign 计算令牌内容和答案的签名
+func (m *MathCaptcha) sign(payload, answer string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(payload + "." + answer))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// renderQuestion 将算式绘制为带干扰点和干扰线的 PNG 图片，返回 data URL
+func renderQuestion(question string) (string, error) {
+	width := (imagePad*2 + len(question)*(glyphWidth+glyphGap) - glyphGap) * glyphScale
+	height := (imagePad*2 + glyphHeight) * glyphScale
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	background := color.NRGBA{R: 245, G: 245, B: 240, A: 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, background)
+		}
+	}
+
+	ink := color.NRGBA{R: 40, G: 50, B: 90, A: 255}
+	for i, char := range question {
+		// 每个字符随机上下偏移，增加识别难度
+		offset, err := randomInt(-glyphScale*2, glyphScale*2)
+		if err != nil {
+			return "", err
+		}
+		left := (imagePad + i*(glyphWidth+glyphGap)) * glyphScale
+		top := imagePad*glyphScale + offset
+		for row, line := range glyphs[char] {
+			for col, dot := range line {
+				if dot != '#' {
+					continue
+				}
+				for dy := 0; dy < glyphScale; dy++ {
+					for dx := 0; dx < glyphScale; dx++ {
+						img.SetNRGBA(left+col*glyphScale+dx, top+row*glyphScale+dy, ink)
+					}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < noiseDots; i++ {
+		x, err := randomInt(0, width-1)
+		if err != nil {
+			return "", err
+		}
+		y, err := randomInt(0, height-1)
+		if err != nil {
+			return "", err
+		}
+		img.SetNRGBA(x, y, ink)
+	}
+	for i := 0; i < noiseLines; i++ {
+		y0, err := randomInt(0, height-1)
+		if err != nil {
+			return "", err
+		}
+		y1, err := randomInt(0, height-1)
+		if err != nil {
+			return "", err
+		}
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y0+(y1-y0)*x/width, ink)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// randomInt 返回 [min, max] 之间的随机整数
+func randomInt(min, max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min+1)))
+	if err != nil {
+		return 0, err
+	}
+	return min + int(n.Int64()), nil
+}
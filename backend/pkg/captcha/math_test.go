@@ -0,0 +1,99 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mathToken 按 Challenge 的格式签发答案已知的令牌
+func mathToken(m *MathCaptcha, expiresAt time.Time, nonce, answer string) string {
+	payload := fmt.Sprintf("%d.%s", expiresAt.Unix(), nonce)
+	return payload + "." + m.sign(payload, answer)
+}
+
+func TestMathCaptchaVerify(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		token    func(m *MathCaptcha) string
+		attempts []string // 依次提交的答案
+		want     []error
+	}{
+		{
+			name:     "correct answer",
+			token:    func(m *MathCaptcha) string { return mathToken(m, expiresAt, "a", "7") },
+			attempts: []string{" 7 "},
+			want:     []error{nil},
+		},
+		{
+			name:     "token used twice",
+			token:    func(m *MathCaptcha) string { return mathToken(m, expiresAt, "a", "7") },
+			attempts: []string{"7", "7"},
+			want:     []error{nil, ErrFailed},
+		},
+		{
+			name:     "wrong answer burns token",
+			token:    func(m *MathCaptcha) string { return mathToken(m, expiresAt, "a", "7") },
+			attempts: []string{"6", "7"},
+			want:     []error{ErrFailed, ErrFailed},
+		},
+		{
+			name:     "expired",
+			token:    func(m *MathCaptcha) string { return mathToken(m, time.Now().Add(-time.Second), "a", "7") },
+			attempts: []string{"7"},
+			want:     []error{ErrFailed},
+		},
+		{
+			name:     "expiry beyond issuing window",
+			token:    func(m *MathCaptcha) string { return mathToken(m, time.Now().Add(time.Hour), "a", "7") },
+			attempts: []string{"7"},
+			want:     []error{ErrFailed},
+		},
+		{
+			name: "signed with another key",
+			token: func(m *MathCaptcha) string {
+				return mathToken(NewMathCaptcha([]byte("other"), time.Minute), expiresAt, "a", "7")
+			},
+			attempts: []string{"7"},
+			want:     []error{ErrFailed},
+		},
+		{
+			name:     "malformed",
+			token:    func(m *MathCaptcha) string { return "not-a-token" },
+			attempts: []string{"7"},
+			want:     []error{ErrFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMathCaptcha([]byte("key"), 5*time.Minute)
+			token := tt.token(m)
+			for i, answer := range tt.attempts {
+				if err := m.Verify(ctx, token, answer, ""); !errors.Is(err, tt.want[i]) {
+					t.Fatalf("attempt %d: Verify(%q) = %v, want %v", i+1, answer, err, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMathCaptchaChallenge(t *testing.T) {
+	m := NewMathCaptcha([]byte("key"), time.Minute)
+	challenge, err := m.Challenge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.Provider != "math" || !strings.HasPrefix(challenge.Image, "data:image/png;base64,") {
+		t.Fatalf("Challenge = %+v", challenge)
+	}
+	if parts := strings.Split(challenge.Token, "."); len(parts) != 3 {
+		t.Fatalf("token %q has %d parts, want 3", challenge.Token, len(parts))
+	}
+}
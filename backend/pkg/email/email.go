@@ -104,6 +104,50 @@ func (s *EmailSender) SendEmailChangeNotification(to, newEmail, code string, loc
 	return s.SendTemplateEmail(to, "email_change", data, locale)
 }
 
+// SendWorkspaceInvitationEmail 发送工作区邀请邮件
+func (s *EmailSender) SendWorkspaceInvitationEmail(to, inviterName, workspaceName, role, token string, expiresInDays int, locale string) error {
+	data := struct {
+		InviterName   string
+		WorkspaceName string
+		Role          string
+		Token         string
+		ExpiresIn     int
+		T             func(key string, args ...interface{}) string
+	}{
+		InviterName:   inviterName,
+		WorkspaceName: workspaceName,
+		Role:          role,
+		Token:         token,
+		ExpiresIn:     expiresInDays,
+		T: func(key string, args ...interface{}) string {
+			return i18n.T(locale, key, args...)
+		},
+	}
+
+	return s.SendTemplateEmail(to, "workspace_invitation", data, locale)
+}
+
+// SendGuestCommentEmail 发送访客评论确认邮件，link 为确认链接
+func (s *EmailSender) SendGuestCommentEmail(to, name, postTitle, link string, expiresInHours int, locale string) error {
+	data := struct {
+		Name      string
+		PostTitle string
+		Link      string
+		ExpiresIn int
+		T         func(key string, args ...interface{}) string
+	}{
+		Name:      name,
+		PostTitle: postTitle,
+		Link:      link,
+		ExpiresIn: expiresInHours,
+		T: func(key string, args ...interface{}) string {
+			return i18n.T(locale, key, args...)
+		},
+	}
+
+	return s.SendTemplateEmail(to, "guest_comment", data, locale)
+}
+
 // 以下是包级别的便捷函数，使用默认发送器
 
 // SendEmail 使用默认发送器发送邮件
@@ -138,6 +182,22 @@ func SendEmailChangeNotification(to, newEmail, code string, locale string) error
 	return defaultSender.SendEmailChangeNotification(to, newEmail, code, locale)
 }
 
+// SendWorkspaceInvitationEmail 使用默认发送器发送工作区邀请邮件
+func SendWorkspaceInvitationEmail(to, inviterName, workspaceName, role, token string, expiresInDays int, locale string) error {
+	if defaultSender == nil {
+		return fmt.Errorf("email sender not initialized")
+	}
+	return defaultSender.SendWorkspaceInvitationEmail(to, inviterName, workspaceName, role, token, expiresInDays, locale)
+}
+
+// SendGuestCommentEmail 使用默认发送器发送访客评论确认邮件
+func SendGuestCommentEmail(to, name, postTitle, link string, expiresInHours int, locale string) error {
+	if defaultSender == nil {
+		return fmt.Errorf("email sender not initialized")
+	}
+	return defaultSender.SendGuestCommentEmail(to, name, postTitle, link, expiresInHours, locale)
+}
+
 // PreviewTemplate 预览邮件模板
 func PreviewTemplate(templateName, locale string) (string, error) {
 	if defaultSender == nil {
@@ -0,0 +1,31 @@
+package embedding
+
+import "strings"
+
+// Chunk 将文本按字符数切分为带重叠的分块
+func Chunk(text string, size, overlap int) []string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) == 0 {
+		return nil
+	}
+	if size <= 0 || len(runes) <= size {
+		return []string{string(runes)}
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var chunks []string
+	step := size - overlap
+	for start := 0; start < len(runes); start += step {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+		if end == len(runes) {
+			break
+		}
+	}
+	return chunks
+}
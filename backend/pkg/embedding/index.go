@@ -0,0 +1,162 @@
+package embedding
+
+import (
+	"sort"
+	"sync"
+)
+
+// Match 表示一条检索结果
+type Match struct {
+	ID    uint    `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// ChunkMatch 表示一个分块的检索结果
+type ChunkMatch struct {
+	ID    uint    `json:"id"`
+	Chunk int     `json:"chunk"`
+	Score float64 `json:"score"`
+}
+
+// Index 进程内的向量索引，按文章保存各分块向量及其均值向量
+type Index struct {
+	mu        sync.RWMutex
+	chunks    map[uint][][]float32
+	centroids map[uint][]float32
+}
+
+// NewIndex 创建向量索引
+func NewIndex() *Index {
+	return &Index{
+		chunks:    make(map[uint][][]float32),
+		centroids: make(map[uint][]float32),
+	}
+}
+
+// Set 设置文章的分块向量
+func (idx *Index) Set(id uint, vectors [][]float32) {
+	if len(vectors) == 0 {
+		idx.Remove(id)
+		return
+	}
+
+	centroid := make([]float32, len(vectors[0]))
+	for _, vector := range vectors {
+		for i := range centroid {
+			if i < len(vector) {
+				centroid[i] += vector[i]
+			}
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.chunks[id] = vectors
+	idx.centroids[id] = Normalize(centroid)
+}
+
+// Remove 从索引中移除文章
+func (idx *Index) Remove(id uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.chunks, id)
+	delete(idx.centroids, id)
+}
+
+// Search 按与查询向量最相似的分块对文章排序，limit 为 0 时返回全部正相关结果
+func (idx *Index) Search(query []float32, limit int) []Match {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := make([]Match, 0, len(idx.chunks))
+	for id, vectors := range idx.chunks {
+		best := 0.0
+		for _, vector := range vectors {
+			if score := dot(query, vector); score > best {
+				best = score
+			}
+		}
+		if best > 0 {
+			matches = append(matches, Match{ID: id, Score: best})
+		}
+	}
+	return topMatches(matches, limit)
+}
+
+// SearchChunks 返回与查询向量最相似的分块
+func (idx *Index) SearchChunks(query []float32, limit int) []ChunkMatch {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := make([]ChunkMatch, 0)
+	for id, vectors := range idx.chunks {
+		for i, vector := range vectors {
+			if score := dot(query, vector); score > 0 {
+				matches = append(matches, ChunkMatch{ID: id, Chunk: i, Score: score})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score == matches[j].Score {
+			if matches[i].ID == matches[j].ID {
+				return matches[i].Chunk < matches[j].Chunk
+			}
+			return matches[i].ID > matches[j].ID
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Nearest 按均值向量查找与指定文章最相似的其他文章
+func (idx *Index) Nearest(id uint, limit int) []Match {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	target, ok := idx.centroids[id]
+	if !ok {
+		return []Match{}
+	}
+
+	matches := make([]Match, 0, len(idx.centroids))
+	for otherID, centroid := range idx.centroids {
+		if otherID == id {
+			continue
+		}
+		if score := dot(target, centroid); score > 0 {
+			matches = append(matches, Match{ID: otherID, Score: score})
+		}
+	}
+	return topMatches(matches, limit)
+}
+
+// topMatches 按相似度降序排列并截取前 limit 条
+func topMatches(matches []Match, limit int) []Match {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score == matches[j].Score {
+			return matches[i].ID > matches[j].ID
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// dot 计算两个单位向量的点积，即余弦相似度
+func dot(a, b []float32) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,210 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"notex/config"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Provider 向量嵌入提供者接口
+type Provider interface {
+	// Name 返回提供者和模型的标识，用于区分不同模型生成的向量
+	Name() string
+
+	// Embed 为每段文本计算一个向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewProvider 根据配置创建向量嵌入提供者
+func NewProvider(cfg *config.EmbeddingConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "", "local":
+		return NewLocalProvider(cfg.Dimension), nil
+	case "openai":
+		return NewOpenAIProvider(cfg.Endpoint, cfg.APIKey, cfg.Model, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("embedding provider not found: %s", cfg.Provider)
+	}
+}
+
+// LocalProvider 本地确定性向量提供者，基于特征哈希，无需外部服务
+type LocalProvider struct {
+	dimension int
+}
+
+// NewLocalProvider 创建本地向量提供者
+func NewLocalProvider(dimension int) *LocalProvider {
+	if dimension <= 0 {
+		dimension = 256
+	}
+	return &LocalProvider{dimension: dimension}
+}
+
+// Name 返回提供者标识
+func (p *LocalProvider) Name() string {
+	return fmt.Sprintf("local:hash-%d", p.dimension)
+}
+
+// Embed 计算文本的哈希特征向量
+func (p *LocalProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, p.dimension)
+		for _, token := range tokenize(text) {
+			h := fnv.New32a()
+			h.Write([]byte(token))
+			sum := h.Sum32()
+			// 使用最高位决定符号，减少哈希冲突带来的偏差
+			if sum&(1<<31) != 0 {
+				vector[sum%uint32(p.dimension)] -= 1
+			} else {
+				vector[sum%uint32(p.dimension)] += 1
+			}
+		}
+		vectors[i] = Normalize(vector)
+	}
+	return vectors, nil
+}
+
+// tokenize 将文本切分为词，中日韩文字按单字和相邻双字切分
+func tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var prevHan rune
+
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			tokens = append(tokens, string(r))
+			if prevHan != 0 {
+				tokens = append(tokens, string([]rune{prevHan, r}))
+			}
+			prevHan = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prevHan = 0
+	}
+	flush()
+
+	return tokens
+}
+
+// OpenAIProvider 兼容 OpenAI embeddings 接口的向量提供者
+type OpenAIProvider struct {
+	endpoint string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewOpenAIProvider 创建 OpenAI 向量提供者，timeout 为每次请求的超时
+func NewOpenAIProvider(endpoint, apiKey, model string, timeout time.Duration) *OpenAIProvider {
+	if endpoint == "" {
+		endpoint = "https://api.openai.com/v1/embeddings"
+	}
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	return &OpenAIProvider{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		model:    model,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 返回提供者标识
+func (p *OpenAIProvider) Name() string {
+	return "openai:" + p.model
+}
+
+// Embed 调用 embeddings 接口计算向量
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request embeddings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings request failed: %s", string(respBody))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("invalid embedding index: %d", item.Index)
+		}
+		vectors[item.Index] = Normalize(item.Embedding)
+	}
+	return vectors, nil
+}
+
+// Normalize 将向量归一化为单位长度
+func Normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
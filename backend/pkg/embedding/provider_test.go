@@ -0,0 +1,76 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenAIProviderEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if body.Model != "test-model" || len(body.Input) != 2 {
+			t.Errorf("unexpected request: %+v", body)
+		}
+		// 结果顺序与输入不同，提供者需要按 index 还原
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,2]},{"index":0,"embedding":[3,0]}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(server.URL, "secret", "test-model", time.Second)
+	vectors, err := provider.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	want := [][]float32{{1, 0}, {0, 1}}
+	for i := range want {
+		for j := range want[i] {
+			if vectors[i][j] != want[i][j] {
+				t.Fatalf("vectors = %v, want %v", vectors, want)
+			}
+		}
+	}
+}
+
+func TestOpenAIProviderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := NewOpenAIProvider(server.URL, "secret", "test-model", 50*time.Millisecond)
+	if _, err := provider.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Fatal("Embed should fail when the endpoint does not respond in time")
+	}
+}
+
+func TestLocalProviderDeterministic(t *testing.T) {
+	provider := NewLocalProvider(64)
+	first, err := provider.Embed(context.Background(), []string{"向量检索 semantic search"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	second, _ := provider.Embed(context.Background(), []string{"向量检索 semantic search"})
+	if len(first[0]) != 64 {
+		t.Fatalf("dimension = %d, want 64", len(first[0]))
+	}
+	for i := range first[0] {
+		if first[0][i] != second[0][i] {
+			t.Fatal("local provider should return the same vector for the same text")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// EncodeFunc 将图片编码为某种格式，quality 取值 1-100，无损格式可忽略
+type EncodeFunc func(w io.Writer, img image.Image, quality int) error
+
+// Encoder 图片编码器
+type Encoder struct {
+	ContentType string     // 内容类型，如 image/webp
+	Ext         string     // 文件扩展名，如 .webp
+	Encode      EncodeFunc // 编码函数
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		"jpeg": {
+			ContentType: "image/jpeg",
+			Ext:         ".jpg",
+			Encode: func(w io.Writer, img image.Image, quality int) error {
+				return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+			},
+		},
+		"png": {
+			ContentType: "image/png",
+			Ext:         ".png",
+			Encode: func(w io.Writer, img image.Image, quality int) error {
+				return png.Encode(w, img)
+			},
+		},
+		"webp": {
+			ContentType: "image/webp",
+			Ext:         ".webp",
+			Encode: func(w io.Writer, img image.Image, quality int) error {
+				// 纯 Go 实现的无损 (VP8L) 编码，不需要 cgo 和 libwebp
+				return nativewebp.Encode(w, img, nil)
+			},
+		},
+	}
+)
+
+// RegisterEncoder 注册图片编码器，内置 JPEG、PNG 和 WebP，
+// AVIF 等其它格式需要在加载配置前注册对应的编码器，否则配置校验不通过
+func RegisterEncoder(format string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[NormalizeFormat(format)] = encoder
+}
+
+// UnregisterEncoder 移除格式对应的编码器
+func UnregisterEncoder(format string) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	delete(encoders, NormalizeFormat(format))
+}
+
+// LookupEncoder 查找格式对应的编码器
+func LookupEncoder(format string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	encoder, ok := encoders[NormalizeFormat(format)]
+	return encoder, ok
+}
+
+// Encode 将图片编码为指定格式
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	encoder, ok := LookupEncoder(format)
+	if !ok {
+		return ErrUnsupportedFormat
+	}
+	return encoder.Encode(w, img, quality)
+}
+
+// NormalizeFormat 统一格式名称，如 jpg -> jpeg
+func NormalizeFormat(format string) string {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "jpg" {
+		return "jpeg"
+	}
+	return format
+}
+
+// OutputFormat 返回原图格式缩放后使用的输出格式，没有对应编码器的格式（如 GIF）输出为 PNG
+func OutputFormat(format string) string {
+	format = NormalizeFormat(format)
+	if _, ok := LookupEncoder(format); ok {
+		return format
+	}
+	return "png"
+}
@@ -0,0 +1,44 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestEncodeWebP(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(1, 1, color.NRGBA{R: 255, A: 255})
+
+	encoder, ok := LookupEncoder(".WEBP")
+	if !ok {
+		t.Fatal("webp encoder is not registered")
+	}
+	if encoder.ContentType != "image/webp" || encoder.Ext != ".webp" {
+		t.Fatalf("encoder = %q %q", encoder.ContentType, encoder.Ext)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, src, "webp", 85); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	data := buf.Bytes()
+	if len(data) < 30 || string(data[:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8L" {
+		t.Fatalf("output is not a lossless WebP file: % x", data[:min(len(data), 16)])
+	}
+	// VP8L 头中保存的宽高减 1，各占 14 位
+	bits := uint32(data[21]) | uint32(data[22])<<8 | uint32(data[23])<<16 | uint32(data[24])<<24
+	if width, height := int(bits&0x3FFF)+1, int(bits>>14&0x3FFF)+1; width != 3 || height != 2 {
+		t.Fatalf("size = %dx%d, want 3x2", width, height)
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	tests := map[string]string{"jpg": "jpeg", "PNG": "png", "webp": "webp", "gif": "png"}
+	for format, want := range tests {
+		if got := OutputFormat(format); got != want {
+			t.Errorf("OutputFormat(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
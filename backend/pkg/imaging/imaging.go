@@ -0,0 +1,84 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"github.com/nfnt/resize"
+)
+
+var ErrTooLarge = errors.New("image dimensions exceed the limit")
+
+// Image 经过规范化处理的图片
+type Image struct {
+	Data   []byte // 去除元数据并应用方向后的编码数据
+	Format string // 原图格式：jpeg, png, gif
+	Width  int    // 应用方向后的宽度
+	Height int    // 应用方向后的高度
+
+	decoded image.Image
+}
+
+// Normalize 去除图片的 EXIF/GPS 等元数据并按方向标记转正。
+// 无需旋转时以无损方式去除元数据，需要旋转的 JPEG 以 quality 重新编码；
+// GIF 保持原样以保留动画。maxPixels 大于 0 时拒绝像素数超过限制的图片。
+func Normalize(data []byte, maxPixels, quality int) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img := &Image{Data: data, Format: format, Width: cfg.Width, Height: cfg.Height}
+	switch format {
+	case "jpeg":
+		if orientation := jpegOrientation(data); orientation > 1 {
+			decoded, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			decoded = ApplyOrientation(decoded, orientation)
+
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, err
+			}
+			img.Data, img.decoded = buf.Bytes(), decoded
+			img.Width, img.Height = decoded.Bounds().Dx(), decoded.Bounds().Dy()
+			return img, nil
+		}
+		img.Data, err = stripJPEG(data)
+	case "png":
+		img.Data, err = stripPNG(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Decode 返回解码后的图片，GIF 只取第一帧
+func (i *Image) Decode() (image.Image, error) {
+	if i.decoded == nil {
+		decoded, _, err := image.Decode(bytes.NewReader(i.Data))
+		if err != nil {
+			return nil, err
+		}
+		i.decoded = decoded
+	}
+	return i.decoded, nil
+}
+
+// Resize 按宽度等比缩放，目标宽度不小于原图时返回原图
+func Resize(img image.Image, width int) image.Image {
+	if width <= 0 || width >= img.Bounds().Dx() {
+		return img
+	}
+	return resize.Resize(uint(width), 0, img, resize.Lanczos3)
+}
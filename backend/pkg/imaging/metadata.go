@@ -0,0 +1,176 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("malformed image data")
+
+// jpegSegment 表示 JPEG 中的一个标记段，start 指向 0xFF，end 指向段之后的位置
+type jpegSegment struct {
+	marker byte
+	start  int
+	end    int
+}
+
+// jpegSegments 解析 JPEG 扫描数据之前的全部标记段，返回标记段和扫描数据的起始位置
+func jpegSegments(data []byte) ([]jpegSegment, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, errMalformed
+	}
+
+	var segments []jpegSegment
+	pos := 2
+	for pos+1 < len(data) {
+		if data[pos] != 0xFF {
+			return nil, 0, errMalformed
+		}
+		// 跳过填充字节
+		if data[pos+1] == 0xFF {
+			pos++
+			continue
+		}
+
+		marker := data[pos+1]
+		switch {
+		case marker == 0xDA: // SOS，之后是压缩数据
+			return segments, pos, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // 无长度的独立标记
+			segments = append(segments, jpegSegment{marker: marker, start: pos, end: pos + 2})
+			pos += 2
+			continue
+		}
+
+		if pos+4 > len(data) {
+			return nil, 0, errMalformed
+		}
+		// 段长度包括长度字段本身的 2 字节
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 {
+			return nil, 0, errMalformed
+		}
+		end := pos + 2 + length
+		if end > len(data) {
+			return nil, 0, errMalformed
+		}
+		segments = append(segments, jpegSegment{marker: marker, start: pos, end: end})
+		pos = end
+	}
+	return nil, 0, errMalformed
+}
+
+// stripJPEG 无损去除 JPEG 中的 EXIF、XMP、IPTC 和注释段，保留 ICC 色彩配置等其它段
+func stripJPEG(data []byte) ([]byte, error) {
+	segments, scan, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	for _, seg := range segments {
+		switch seg.marker {
+		case 0xE1, 0xED, 0xFE: // APP1 (EXIF/XMP)、APP13 (IPTC)、COM
+			continue
+		}
+		out = append(out, data[seg.start:seg.end]...)
+	}
+	return append(out, data[scan:]...), nil
+}
+
+// jpegOrientation 读取 JPEG 中 EXIF 的方向标记，未找到时返回 1
+func jpegOrientation(data []byte) int {
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		return 1
+	}
+
+	for _, seg := range segments {
+		// 独立标记没有长度字段和数据
+		if seg.marker != 0xE1 || seg.end-seg.start < 4 {
+			continue
+		}
+		payload := data[seg.start+4 : seg.end]
+		if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			continue
+		}
+		if orientation := tiffOrientation(payload[6:]); orientation != 0 {
+			return orientation
+		}
+	}
+	return 1
+}
+
+// tiffOrientation 在 TIFF 结构的第一个 IFD 中查找方向标记（0x0112）
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) != 0x0112 {
+			continue
+		}
+		orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+		if orientation < 1 || orientation > 8 {
+			return 0
+		}
+		return orientation
+	}
+	return 0
+}
+
+// pngSignature PNG 文件头
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// stripPNG 无损去除 PNG 中的 EXIF、文本和时间块
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errMalformed
+		}
+		end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:pos+4]))
+		if end > len(data) || end < pos {
+			return nil, errMalformed
+		}
+
+		switch string(data[pos+4 : pos+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return out, nil
+}
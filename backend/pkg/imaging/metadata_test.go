@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// testJPEG 返回 width x height 的 JPEG，在 SOI 之后插入 extra
+func testJPEG(t *testing.T, width, height int, extra []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	return append(append([]byte{0xFF, 0xD8}, extra...), data[2:]...)
+}
+
+// exifSegment 返回只包含方向标记的 APP1 段
+func exifSegment(orientation byte) []byte {
+	payload := []byte("Exif\x00\x00")
+	payload = append(payload, "II*\x00\x08\x00\x00\x00"...)
+	payload = append(payload, 0x01, 0x00) // 1 个 IFD 项
+	payload = append(payload, 0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, orientation, 0x00, 0x00, 0x00)
+	payload = append(payload, 0x00, 0x00, 0x00, 0x00)
+	length := len(payload) + 2
+	return append([]byte{0xFF, 0xE1, byte(length >> 8), byte(length)}, payload...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra []byte
+		want  int
+	}{
+		{name: "no exif", want: 1},
+		{name: "exif", extra: exifSegment(6), want: 6},
+		{name: "rst marker", extra: []byte{0xFF, 0xD0}, want: 1},
+		{name: "tem marker before exif", extra: append([]byte{0xFF, 0x01}, exifSegment(3)...), want: 3},
+		{name: "app1 without payload", extra: []byte{0xFF, 0xE1, 0x00, 0x02}, want: 1},
+		{name: "length below 2", extra: []byte{0xFF, 0xE1, 0x00, 0x01}, want: 1},
+		{name: "zero length", extra: []byte{0xFF, 0xE0, 0x00, 0x00}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(testJPEG(t, 4, 2, tt.extra)); got != tt.want {
+				t.Fatalf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGSegmentsRejectsShortLength(t *testing.T) {
+	for _, length := range []byte{0, 1} {
+		data := testJPEG(t, 4, 2, []byte{0xFF, 0xE1, 0x00, length})
+		if _, _, err := jpegSegments(data); err != errMalformed {
+			t.Fatalf("length %d: jpegSegments() error = %v, want %v", length, err, errMalformed)
+		}
+	}
+}
+
+func TestNormalizeJPEG(t *testing.T) {
+	tests := []struct {
+		name          string
+		extra         []byte
+		width, height int
+		wantErr       bool
+	}{
+		{name: "rst marker", extra: []byte{0xFF, 0xD0}, width: 4, height: 2},
+		{name: "exif stripped", extra: exifSegment(1), width: 4, height: 2},
+		{name: "rotated", extra: exifSegment(6), width: 2, height: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Normalize(testJPEG(t, 4, 2, tt.extra), 0, 90)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if img.Width != tt.width || img.Height != tt.height {
+				t.Fatalf("size = %dx%d, want %dx%d", img.Width, img.Height, tt.width, tt.height)
+			}
+			if bytes.Contains(img.Data, []byte("Exif\x00\x00")) {
+				t.Fatal("EXIF data was not removed")
+			}
+			if jpegOrientation(img.Data) != 1 {
+				t.Fatal("orientation was not applied")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package policy
+
+import "errors"
+
+// ErrDenied 没有任何规则允许该操作
+var ErrDenied = errors.New("operation not permitted")
+
+// Action 受策略保护的操作
+type Action string
+
+// Any 匹配全部操作的通配符，只用于规则声明
+const Any Action = "*"
+
+// Facts 评估规则时按需查询的关系数据，实现方应缓存查询结果以免重复访问数据库
+type Facts interface {
+	// WorkspaceRole 返回用户在工作区中的角色，非成员返回空字符串
+	WorkspaceRole(workspaceID uint) string
+	// WritesInAnyWorkspace 检查用户是否在任一工作区中具有撰稿人以上角色
+	WritesInAnyWorkspace() bool
+	// EditsCategory 检查用户是否是分类的责任编辑
+	EditsCategory(categoryID uint) bool
+}
+
+// Subject 执行操作的用户，未登录时 UserID 为 0
+type Subject struct {
+	UserID uint
+	Role   string // 全站角色
+	Facts  Facts  // 为空时所有关系查询都返回否定结果
+}
+
+// WorkspaceRole 返回用户在工作区中的角色
+func (s *Subject) WorkspaceRole(workspaceID uint) string {
+	if s.UserID == 0 || s.Facts == nil {
+		return ""
+	}
+	return s.Facts.WorkspaceRole(workspaceID)
+}
+
+// writesInAnyWorkspace 检查用户是否在任一工作区中具有撰稿人以上角色
+func (s *Subject) writesInAnyWorkspace() bool {
+	return s.UserID != 0 && s.Facts != nil && s.Facts.WritesInAnyWorkspace()
+}
+
+// editsCategory 检查用户是否是分类的责任编辑
+func (s *Subject) editsCategory(categoryID uint) bool {
+	return s.UserID != 0 && s.Facts != nil && categoryID != 0 && s.Facts.EditsCategory(categoryID)
+}
+
+// owns 检查用户是否是资源的创建者
+func (s *Subject) owns(r *Resource) bool {
+	return s.UserID != 0 && s.UserID == r.OwnerID
+}
+
+// Resource 被操作资源中与授权相关的属性，各字段按操作需要填写
+type Resource struct {
+	OwnerID     uint   // 创建者或所属用户
+	WorkspaceID *uint  // 所属工作区，为空时属于个人或全站
+	CategoryID  uint   // 文章或草稿所属的分类
+	PostOwnerID uint   // 评论所属文章的作者
+	TargetRole  string // 管理工作区成员时被管理成员的角色
+}
+
+// Rule 一条声明式授权规则，Allow 返回真时允许 Actions 中的操作
+type Rule struct {
+	Name    string
+	Actions []Action
+	Allow   func(s *Subject, r *Resource) bool
+}
+
+// Engine 按规则评估操作，任一规则允许即放行，没有规则允许时拒绝
+type Engine struct {
+	rules    map[Action][]Rule
+	wildcard []Rule
+}
+
+// NewEngine 创建策略引擎
+func NewEngine(rules ...Rule) *Engine {
+	e := &Engine{rules: make(map[Action][]Rule)}
+	for _, rule := range rules {
+		for _, action := range rule.Actions {
+			if action == Any {
+				e.wildcard = append(e.wildcard, rule)
+				continue
+			}
+			e.rules[action] = append(e.rules[action], rule)
+		}
+	}
+	return e
+}
+
+// Allowed 检查用户能否对资源执行操作
+func (e *Engine) Allowed(s *Subject, action Action, r *Resource) bool {
+	if s == nil {
+		s = &Subject{}
+	}
+	if r == nil {
+		r = &Resource{}
+	}
+	for _, rule := range e.wildcard {
+		if rule.Allow(s, r) {
+			return true
+		}
+	}
+	for _, rule := range e.rules[action] {
+		if rule.Allow(s, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize 检查用户能否对资源执行操作，不能时返回 ErrDenied
+func (e *Engine) Authorize(s *Subject, action Action, r *Resource) error {
+	if !e.Allowed(s, action, r) {
+		return ErrDenied
+	}
+	return nil
+}
+
+// Default 使用内置规则的策略引擎
+var Default = NewEngine(Rules...)
+
+// Allowed 使用内置规则检查用户能否对资源执行操作
+func Allowed(s *Subject, action Action, r *Resource) bool {
+	return Default.Allowed(s, action, r)
+}
+
+// Authorize 使用内置规则检查用户能否对资源执行操作
+func Authorize(s *Subject, action Action, r *Resource) error {
+	return Default.Authorize(s, action, r)
+}
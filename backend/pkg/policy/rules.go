@@ -0,0 +1,327 @@
+package policy
+
+import "notex/model"
+
+// 受保护的操作
+const (
+	PostCreate Action = "post:create"
+	PostUpdate Action = "post:update"
+	PostDelete Action = "post:delete"
+	PostStats  Action = "post:stats" // 查看文章的浏览统计
+	PostReact  Action = "post:react" // 点赞和收藏文章，调用方需另外检查文章是否可见
+
+	DraftView    Action = "draft:view"
+	DraftCreate  Action = "draft:create"
+	DraftUpdate  Action = "draft:update"
+	DraftDelete  Action = "draft:delete"
+	DraftPublish Action = "draft:publish"
+
+	CategoryView          Action = "category:view"
+	CategoryManage        Action = "category:manage"         // 创建、修改和删除分类
+	CategoryAssignEditors Action = "category:assign-editors" // 指定分类的责任编辑
+
+	TagCreate Action = "tag:create"
+	TagManage Action = "tag:manage" // 修改和删除标签
+
+	CommentUpdate Action = "comment:update" // 修改评论内容，调用方需另外检查修改期限
+	CommentDelete Action = "comment:delete"
+	CommentHide   Action = "comment:hide"  // 隐藏和恢复评论
+	CommentReact  Action = "comment:react" // 对评论使用表情回应，调用方需另外检查评论是否可见
+
+	NotificationUpdate Action = "notification:update"
+
+	MediaManage        Action = "media:manage" // 查看、删除媒体文件和获取其缩放地址
+	AttachmentDelete   Action = "attachment:delete"
+	AttachmentDownload Action = "attachment:download" // 不经文章可见性检查直接下载附件
+
+	AIAssistApply   Action = "ai:assist"      // 对文章或草稿使用写作助手并写回结果
+	AIImageSetCover Action = "ai:image-cover" // 将生成的图片设为文章或草稿的封面
+
+	ReadingListManage Action = "reading-list:manage" // 修改和删除阅读列表，向其中添加收藏
+
+	AnalyticsView Action = "analytics:view" // 查看作者统计
+
+	WorkspaceView          Action = "workspace:view"
+	WorkspaceUpdate        Action = "workspace:update"
+	WorkspaceDelete        Action = "workspace:delete"
+	WorkspaceManageMembers Action = "workspace:manage-members" // 修改角色、移除成员和管理邀请
+	WorkspaceLeave         Action = "workspace:leave"
+)
+
+// Rules 内置授权规则
+var Rules = []Rule{
+	{
+		Name:    "admins can do anything",
+		Actions: []Action{Any},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.Role == model.RoleAdmin
+		},
+	},
+
+	// 文章和草稿
+	{
+		Name:    "editors create personal posts and drafts",
+		Actions: []Action{PostCreate, DraftCreate},
+		Allow: func(s *Subject, r *Resource) bool {
+			return r.WorkspaceID == nil && s.Role == model.RoleEditor
+		},
+	},
+	{
+		Name:    "workspace writers create workspace posts and drafts",
+		Actions: []Action{PostCreate, DraftCreate},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleWriter)
+		},
+	},
+	{
+		Name:    "authors edit own posts",
+		Actions: []Action{PostUpdate, PostDelete},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r) && (r.WorkspaceID == nil || inWorkspace(s, r, model.WorkspaceRoleWriter))
+		},
+	},
+	{
+		Name:    "editors edit any post in their categories",
+		Actions: []Action{PostUpdate, PostDelete},
+		Allow: func(s *Subject, r *Resource) bool {
+			return r.WorkspaceID == nil && s.Role == model.RoleEditor && s.editsCategory(r.CategoryID)
+		},
+	},
+	{
+		Name:    "workspace maintainers edit any workspace post or draft",
+		Actions: []Action{PostUpdate, PostDelete, DraftUpdate, DraftDelete, DraftPublish},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleMaintainer)
+		},
+	},
+	{
+		Name:    "authors and workspace maintainers view post stats",
+		Actions: []Action{PostStats},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r) || inWorkspace(s, r, model.WorkspaceRoleMaintainer)
+		},
+	},
+	{
+		Name:    "authors view own drafts",
+		Actions: []Action{DraftView},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+	{
+		Name:    "workspace members view workspace drafts",
+		Actions: []Action{DraftView},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleReader)
+		},
+	},
+	{
+		Name:    "authors edit own drafts",
+		Actions: []Action{DraftUpdate, DraftDelete},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r) && (r.WorkspaceID == nil || inWorkspace(s, r, model.WorkspaceRoleWriter))
+		},
+	},
+	{
+		Name:    "authors publish own drafts they could create",
+		Actions: []Action{DraftPublish},
+		Allow: func(s *Subject, r *Resource) bool {
+			if !s.owns(r) {
+				return false
+			}
+			if r.WorkspaceID == nil {
+				return s.Role == model.RoleEditor
+			}
+			return inWorkspace(s, r, model.WorkspaceRoleWriter)
+		},
+	},
+
+	{
+		Name:    "signed-in users like and bookmark posts",
+		Actions: []Action{PostReact},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.UserID != 0
+		},
+	},
+	{
+		Name:    "users manage own reading lists",
+		Actions: []Action{ReadingListManage},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+
+	// 分类和标签
+	{
+		Name:    "anyone views site-wide categories",
+		Actions: []Action{CategoryView},
+		Allow: func(s *Subject, r *Resource) bool {
+			return r.WorkspaceID == nil
+		},
+	},
+	{
+		Name:    "workspace members view workspace categories",
+		Actions: []Action{CategoryView},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleReader)
+		},
+	},
+	{
+		Name:    "editors manage site-wide categories",
+		Actions: []Action{CategoryManage},
+		Allow: func(s *Subject, r *Resource) bool {
+			return r.WorkspaceID == nil && s.Role == model.RoleEditor
+		},
+	},
+	{
+		Name:    "workspace maintainers manage workspace categories",
+		Actions: []Action{CategoryManage},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleMaintainer)
+		},
+	},
+	{
+		Name:    "editors and workspace writers create tags",
+		Actions: []Action{TagCreate},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.Role == model.RoleEditor || s.writesInAnyWorkspace()
+		},
+	},
+	{
+		Name:    "editors manage shared tags",
+		Actions: []Action{TagManage},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.Role == model.RoleEditor
+		},
+	},
+
+	// 评论
+	{
+		Name:    "authors edit own comments",
+		Actions: []Action{CommentUpdate},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+	{
+		Name:    "signed-in users react to comments",
+		Actions: []Action{CommentReact},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.UserID != 0
+		},
+	},
+	{
+		Name:    "users delete own comments",
+		Actions: []Action{CommentDelete},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+	{
+		Name:    "post authors hide comments on their posts",
+		Actions: []Action{CommentHide},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.UserID != 0 && s.UserID == r.PostOwnerID
+		},
+	},
+	{
+		Name:    "workspace maintainers moderate comments on workspace posts",
+		Actions: []Action{CommentDelete, CommentHide},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleMaintainer)
+		},
+	},
+
+	// 通知
+	{
+		Name:    "users update own notifications",
+		Actions: []Action{NotificationUpdate},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+
+	// 媒体文件和附件
+	{
+		Name:    "users manage own media assets",
+		Actions: []Action{MediaManage},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+	{
+		Name:    "uploaders delete and download own attachments",
+		Actions: []Action{AttachmentDelete, AttachmentDownload},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+
+	// AI 辅助
+	{
+		Name:    "authors use AI tools on own posts and drafts they can edit",
+		Actions: []Action{AIAssistApply, AIImageSetCover},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r) && (r.WorkspaceID == nil || inWorkspace(s, r, model.WorkspaceRoleWriter))
+		},
+	},
+
+	// 统计
+	{
+		Name:    "authors view own analytics",
+		Actions: []Action{AnalyticsView},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r)
+		},
+	},
+
+	// 工作区
+	{
+		Name:    "members view their workspaces",
+		Actions: []Action{WorkspaceView},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleReader)
+		},
+	},
+	{
+		Name:    "maintainers update workspaces",
+		Actions: []Action{WorkspaceUpdate},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleMaintainer)
+		},
+	},
+	{
+		Name:    "owners delete workspaces",
+		Actions: []Action{WorkspaceDelete},
+		Allow: func(s *Subject, r *Resource) bool {
+			return inWorkspace(s, r, model.WorkspaceRoleOwner)
+		},
+	},
+	{
+		Name:    "owners manage all members, maintainers manage writers and readers",
+		Actions: []Action{WorkspaceManageMembers},
+		Allow: func(s *Subject, r *Resource) bool {
+			if r.WorkspaceID == nil {
+				return false
+			}
+			role := s.WorkspaceRole(*r.WorkspaceID)
+			if role == model.WorkspaceRoleOwner {
+				return true
+			}
+			return model.WorkspaceRoleAtLeast(role, model.WorkspaceRoleMaintainer) &&
+				!model.WorkspaceRoleAtLeast(r.TargetRole, model.WorkspaceRoleMaintainer)
+		},
+	},
+	{
+		Name:    "members leave workspaces",
+		Actions: []Action{WorkspaceLeave},
+		Allow: func(s *Subject, r *Resource) bool {
+			return s.owns(r) && inWorkspace(s, r, model.WorkspaceRoleReader)
+		},
+	},
+}
+
+// inWorkspace 检查资源属于工作区且用户在其中至少具有 minRole 角色
+func inWorkspace(s *Subject, r *Resource, minRole string) bool {
+	return r.WorkspaceID != nil && model.WorkspaceRoleAtLeast(s.WorkspaceRole(*r.WorkspaceID), minRole)
+}
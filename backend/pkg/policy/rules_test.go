@@ -0,0 +1,249 @@
+package policy
+
+import (
+	"notex/model"
+	"testing"
+)
+
+// fakeFacts 固定的关系数据
+type fakeFacts struct {
+	workspaceRoles map[uint]string
+	writer         bool
+	categories     map[uint]bool
+}
+
+func (f *fakeFacts) WorkspaceRole(workspaceID uint) string { return f.workspaceRoles[workspaceID] }
+func (f *fakeFacts) WritesInAnyWorkspace() bool            { return f.writer }
+func (f *fakeFacts) EditsCategory(categoryID uint) bool    { return f.categories[categoryID] }
+
+type ruleCase struct {
+	name    string
+	subject *Subject
+	action  Action
+	r       *Resource
+	want    bool
+}
+
+func runRuleCases(t *testing.T, cases []ruleCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Allowed(tc.subject, tc.action, tc.r); got != tc.want {
+				t.Fatalf("Allowed(%s) = %v, want %v", tc.action, got, tc.want)
+			}
+		})
+	}
+}
+
+func user(id uint, role string, workspaceRoles map[uint]string) *Subject {
+	return &Subject{UserID: id, Role: role, Facts: &fakeFacts{workspaceRoles: workspaceRoles}}
+}
+
+func workspace(id uint) *uint {
+	return &id
+}
+
+func TestAdminsCanDoAnything(t *testing.T) {
+	admin := user(9, model.RoleAdmin, nil)
+	runRuleCases(t, []ruleCase{
+		{name: "media of another user", subject: admin, action: MediaManage, r: &Resource{OwnerID: 1}, want: true},
+		{name: "attachment of another user", subject: admin, action: AttachmentDelete, r: &Resource{OwnerID: 1}, want: true},
+		{name: "workspace post", subject: admin, action: AIAssistApply, r: &Resource{OwnerID: 1, WorkspaceID: workspace(3)}, want: true},
+		{name: "editor is not admin", subject: user(9, model.RoleEditor, nil), action: MediaManage, r: &Resource{OwnerID: 1}, want: false},
+	})
+}
+
+func TestUsersManageOwnMediaAssets(t *testing.T) {
+	owner := user(1, model.RoleUser, nil)
+	runRuleCases(t, []ruleCase{
+		{name: "owner", subject: owner, action: MediaManage, r: &Resource{OwnerID: 1}, want: true},
+		{name: "other user", subject: user(2, model.RoleUser, nil), action: MediaManage, r: &Resource{OwnerID: 1}, want: false},
+		{name: "editor", subject: user(2, model.RoleEditor, nil), action: MediaManage, r: &Resource{OwnerID: 1}, want: false},
+		{name: "anonymous", subject: &Subject{}, action: MediaManage, r: &Resource{}, want: false},
+	})
+}
+
+func TestUploadersDeleteAndDownloadOwnAttachments(t *testing.T) {
+	owner := user(1, model.RoleUser, nil)
+	other := user(2, model.RoleEditor, nil)
+	runRuleCases(t, []ruleCase{
+		{name: "owner deletes", subject: owner, action: AttachmentDelete, r: &Resource{OwnerID: 1}, want: true},
+		{name: "owner downloads", subject: owner, action: AttachmentDownload, r: &Resource{OwnerID: 1}, want: true},
+		{name: "other user deletes", subject: other, action: AttachmentDelete, r: &Resource{OwnerID: 1}, want: false},
+		{name: "other user downloads", subject: other, action: AttachmentDownload, r: &Resource{OwnerID: 1}, want: false},
+		{name: "anonymous downloads unowned", subject: nil, action: AttachmentDownload, r: &Resource{}, want: false},
+	})
+}
+
+func TestAuthorsUseAIToolsOnOwnPostsAndDrafts(t *testing.T) {
+	ws := workspace(3)
+	writer := user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter})
+	reader := user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleReader})
+	maintainer := user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleMaintainer})
+	runRuleCases(t, []ruleCase{
+		{name: "author of personal post", subject: writer, action: AIAssistApply, r: &Resource{OwnerID: 1}, want: true},
+		{name: "author sets cover", subject: writer, action: AIImageSetCover, r: &Resource{OwnerID: 1}, want: true},
+		{name: "other user", subject: user(2, model.RoleEditor, nil), action: AIAssistApply, r: &Resource{OwnerID: 1}, want: false},
+		{name: "workspace writer author", subject: writer, action: AIAssistApply, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "author demoted to reader", subject: reader, action: AIImageSetCover, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "author removed from workspace", subject: user(1, model.RoleUser, nil), action: AIAssistApply, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "maintainer of another author's post", subject: maintainer, action: AIAssistApply, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+	})
+}
+
+func categoryEditor(id uint, role string, categories ...uint) *Subject {
+	facts := &fakeFacts{categories: make(map[uint]bool)}
+	for _, categoryID := range categories {
+		facts.categories[categoryID] = true
+	}
+	return &Subject{UserID: id, Role: role, Facts: facts}
+}
+
+func TestPostAndDraftCreation(t *testing.T) {
+	ws := workspace(3)
+	runRuleCases(t, []ruleCase{
+		{name: "editor creates personal post", subject: user(1, model.RoleEditor, nil), action: PostCreate, r: &Resource{}, want: true},
+		{name: "user creates personal post", subject: user(1, model.RoleUser, nil), action: PostCreate, r: &Resource{}, want: false},
+		{name: "anonymous creates draft", subject: nil, action: DraftCreate, r: &Resource{}, want: false},
+		{name: "writer creates workspace draft", subject: user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter}), action: DraftCreate, r: &Resource{WorkspaceID: ws}, want: true},
+		{name: "reader creates workspace post", subject: user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleReader}), action: PostCreate, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "editor outside workspace", subject: user(1, model.RoleEditor, nil), action: PostCreate, r: &Resource{WorkspaceID: ws}, want: false},
+	})
+}
+
+func TestAuthorsEditOwnPosts(t *testing.T) {
+	ws := workspace(3)
+	author := user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter})
+	runRuleCases(t, []ruleCase{
+		{name: "author updates personal post", subject: author, action: PostUpdate, r: &Resource{OwnerID: 1}, want: true},
+		{name: "author deletes personal post", subject: author, action: PostDelete, r: &Resource{OwnerID: 1}, want: true},
+		{name: "other user updates", subject: user(2, model.RoleUser, nil), action: PostUpdate, r: &Resource{OwnerID: 1}, want: false},
+		{name: "other user deletes", subject: user(2, model.RoleUser, nil), action: PostDelete, r: &Resource{OwnerID: 1}, want: false},
+		{name: "anonymous with unowned post", subject: nil, action: PostUpdate, r: &Resource{}, want: false},
+		{name: "workspace writer updates own post", subject: author, action: PostUpdate, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "author demoted to reader", subject: user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleReader}), action: PostUpdate, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "author removed from workspace", subject: user(1, model.RoleUser, nil), action: PostDelete, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "writer updates another writer's post", subject: user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter}), action: PostUpdate, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+	})
+}
+
+func TestEditorsEditPostsInTheirCategories(t *testing.T) {
+	editor := categoryEditor(2, model.RoleEditor, 5)
+	runRuleCases(t, []ruleCase{
+		{name: "post in edited category", subject: editor, action: PostUpdate, r: &Resource{OwnerID: 1, CategoryID: 5}, want: true},
+		{name: "delete post in edited category", subject: editor, action: PostDelete, r: &Resource{OwnerID: 1, CategoryID: 5}, want: true},
+		{name: "post in other category", subject: editor, action: PostUpdate, r: &Resource{OwnerID: 1, CategoryID: 6}, want: false},
+		{name: "uncategorized post", subject: editor, action: PostUpdate, r: &Resource{OwnerID: 1}, want: false},
+		{name: "category editor demoted to user", subject: categoryEditor(2, model.RoleUser, 5), action: PostUpdate, r: &Resource{OwnerID: 1, CategoryID: 5}, want: false},
+		{name: "workspace post in edited category", subject: editor, action: PostUpdate, r: &Resource{OwnerID: 1, CategoryID: 5, WorkspaceID: workspace(3)}, want: false},
+		{name: "editor is not category editor for drafts", subject: editor, action: DraftUpdate, r: &Resource{OwnerID: 1, CategoryID: 5}, want: false},
+	})
+}
+
+func TestWorkspaceMaintainersEditWorkspacePostsAndDrafts(t *testing.T) {
+	ws := workspace(3)
+	maintainer := user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleMaintainer})
+	writer := user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter})
+	runRuleCases(t, []ruleCase{
+		{name: "maintainer updates post", subject: maintainer, action: PostUpdate, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "maintainer publishes draft", subject: maintainer, action: DraftPublish, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "owner deletes draft", subject: user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleOwner}), action: DraftDelete, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "writer deletes another's post", subject: writer, action: PostDelete, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "maintainer of another workspace", subject: maintainer, action: PostUpdate, r: &Resource{OwnerID: 1, WorkspaceID: workspace(4)}, want: false},
+		{name: "maintainer on personal post", subject: maintainer, action: PostUpdate, r: &Resource{OwnerID: 1}, want: false},
+	})
+}
+
+func TestPostStats(t *testing.T) {
+	ws := workspace(3)
+	runRuleCases(t, []ruleCase{
+		{name: "author", subject: user(1, model.RoleUser, nil), action: PostStats, r: &Resource{OwnerID: 1}, want: true},
+		{name: "maintainer", subject: user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleMaintainer}), action: PostStats, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "writer", subject: user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter}), action: PostStats, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "editor", subject: categoryEditor(2, model.RoleEditor, 5), action: PostStats, r: &Resource{OwnerID: 1, CategoryID: 5}, want: false},
+	})
+}
+
+func TestDrafts(t *testing.T) {
+	ws := workspace(3)
+	reader := user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleReader})
+	runRuleCases(t, []ruleCase{
+		{name: "author views", subject: user(1, model.RoleUser, nil), action: DraftView, r: &Resource{OwnerID: 1}, want: true},
+		{name: "other user views", subject: user(2, model.RoleEditor, nil), action: DraftView, r: &Resource{OwnerID: 1}, want: false},
+		{name: "workspace reader views", subject: reader, action: DraftView, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "workspace reader updates", subject: reader, action: DraftUpdate, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+		{name: "author updates", subject: user(1, model.RoleUser, nil), action: DraftUpdate, r: &Resource{OwnerID: 1}, want: true},
+		{name: "editor publishes own draft", subject: user(1, model.RoleEditor, nil), action: DraftPublish, r: &Resource{OwnerID: 1}, want: true},
+		{name: "user publishes own draft", subject: user(1, model.RoleUser, nil), action: DraftPublish, r: &Resource{OwnerID: 1}, want: false},
+		{name: "editor publishes another's draft", subject: user(2, model.RoleEditor, nil), action: DraftPublish, r: &Resource{OwnerID: 1}, want: false},
+		{name: "writer publishes own workspace draft", subject: user(1, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter}), action: DraftPublish, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "reader publishes own workspace draft", subject: user(1, model.RoleEditor, map[uint]string{3: model.WorkspaceRoleReader}), action: DraftPublish, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: false},
+	})
+}
+
+func TestCategoriesAndTags(t *testing.T) {
+	ws := workspace(3)
+	editor := user(1, model.RoleEditor, nil)
+	member := user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleReader})
+	runRuleCases(t, []ruleCase{
+		{name: "anonymous views site category", subject: nil, action: CategoryView, r: &Resource{}, want: true},
+		{name: "anonymous views workspace category", subject: nil, action: CategoryView, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "member views workspace category", subject: member, action: CategoryView, r: &Resource{WorkspaceID: ws}, want: true},
+		{name: "editor manages site category", subject: editor, action: CategoryManage, r: &Resource{}, want: true},
+		{name: "user manages site category", subject: user(2, model.RoleUser, nil), action: CategoryManage, r: &Resource{}, want: false},
+		{name: "editor manages workspace category", subject: editor, action: CategoryManage, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "maintainer manages workspace category", subject: user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleMaintainer}), action: CategoryManage, r: &Resource{WorkspaceID: ws}, want: true},
+		{name: "writer manages workspace category", subject: user(2, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter}), action: CategoryManage, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "editor assigns category editors", subject: editor, action: CategoryAssignEditors, r: &Resource{}, want: false},
+		{name: "editor creates tag", subject: editor, action: TagCreate, r: &Resource{}, want: true},
+		{name: "workspace writer creates tag", subject: &Subject{UserID: 2, Role: model.RoleUser, Facts: &fakeFacts{writer: true}}, action: TagCreate, r: &Resource{}, want: true},
+		{name: "user creates tag", subject: user(2, model.RoleUser, nil), action: TagCreate, r: &Resource{}, want: false},
+		{name: "anonymous with writer facts", subject: &Subject{Facts: &fakeFacts{writer: true}}, action: TagCreate, r: &Resource{}, want: false},
+		{name: "editor manages tag", subject: editor, action: TagManage, r: &Resource{}, want: true},
+		{name: "workspace writer manages tag", subject: &Subject{UserID: 2, Role: model.RoleUser, Facts: &fakeFacts{writer: true}}, action: TagManage, r: &Resource{}, want: false},
+	})
+}
+
+func TestComments(t *testing.T) {
+	ws := workspace(3)
+	author := user(1, model.RoleUser, nil)
+	postAuthor := user(2, model.RoleUser, nil)
+	runRuleCases(t, []ruleCase{
+		{name: "author updates", subject: author, action: CommentUpdate, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: true},
+		{name: "post author updates", subject: postAuthor, action: CommentUpdate, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: false},
+		{name: "author deletes", subject: author, action: CommentDelete, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: true},
+		{name: "other user deletes", subject: user(3, model.RoleEditor, nil), action: CommentDelete, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: false},
+		{name: "post author deletes", subject: postAuthor, action: CommentDelete, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: false},
+		{name: "anonymous deletes guest comment", subject: nil, action: CommentDelete, r: &Resource{PostOwnerID: 2}, want: false},
+		{name: "post author hides", subject: postAuthor, action: CommentHide, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: true},
+		{name: "comment author hides", subject: author, action: CommentHide, r: &Resource{OwnerID: 1, PostOwnerID: 2}, want: false},
+		{name: "anonymous hides on unowned post", subject: nil, action: CommentHide, r: &Resource{}, want: false},
+		{name: "maintainer deletes on workspace post", subject: user(3, model.RoleUser, map[uint]string{3: model.WorkspaceRoleMaintainer}), action: CommentDelete, r: &Resource{OwnerID: 1, PostOwnerID: 2, WorkspaceID: ws}, want: true},
+		{name: "writer hides on workspace post", subject: user(3, model.RoleUser, map[uint]string{3: model.WorkspaceRoleWriter}), action: CommentHide, r: &Resource{OwnerID: 1, PostOwnerID: 2, WorkspaceID: ws}, want: false},
+		{name: "signed-in user reacts", subject: author, action: CommentReact, r: &Resource{}, want: true},
+		{name: "anonymous reacts", subject: nil, action: CommentReact, r: &Resource{}, want: false},
+	})
+}
+
+func TestWorkspaceRoleLimits(t *testing.T) {
+	ws := workspace(3)
+	member := func(role string) *Subject {
+		return user(1, model.RoleUser, map[uint]string{3: role})
+	}
+	runRuleCases(t, []ruleCase{
+		{name: "reader views", subject: member(model.WorkspaceRoleReader), action: WorkspaceView, r: &Resource{WorkspaceID: ws}, want: true},
+		{name: "non-member views", subject: user(1, model.RoleEditor, nil), action: WorkspaceView, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "maintainer updates", subject: member(model.WorkspaceRoleMaintainer), action: WorkspaceUpdate, r: &Resource{WorkspaceID: ws}, want: true},
+		{name: "writer updates", subject: member(model.WorkspaceRoleWriter), action: WorkspaceUpdate, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "owner deletes", subject: member(model.WorkspaceRoleOwner), action: WorkspaceDelete, r: &Resource{WorkspaceID: ws}, want: true},
+		{name: "maintainer deletes", subject: member(model.WorkspaceRoleMaintainer), action: WorkspaceDelete, r: &Resource{WorkspaceID: ws}, want: false},
+		{name: "owner manages maintainer", subject: member(model.WorkspaceRoleOwner), action: WorkspaceManageMembers, r: &Resource{WorkspaceID: ws, TargetRole: model.WorkspaceRoleMaintainer}, want: true},
+		{name: "maintainer manages writer", subject: member(model.WorkspaceRoleMaintainer), action: WorkspaceManageMembers, r: &Resource{WorkspaceID: ws, TargetRole: model.WorkspaceRoleWriter}, want: true},
+		{name: "maintainer manages maintainer", subject: member(model.WorkspaceRoleMaintainer), action: WorkspaceManageMembers, r: &Resource{WorkspaceID: ws, TargetRole: model.WorkspaceRoleMaintainer}, want: false},
+		{name: "maintainer manages owner", subject: member(model.WorkspaceRoleMaintainer), action: WorkspaceManageMembers, r: &Resource{WorkspaceID: ws, TargetRole: model.WorkspaceRoleOwner}, want: false},
+		{name: "writer manages reader", subject: member(model.WorkspaceRoleWriter), action: WorkspaceManageMembers, r: &Resource{WorkspaceID: ws, TargetRole: model.WorkspaceRoleReader}, want: false},
+		{name: "manage members without workspace", subject: member(model.WorkspaceRoleOwner), action: WorkspaceManageMembers, r: &Resource{}, want: false},
+		{name: "member leaves", subject: member(model.WorkspaceRoleReader), action: WorkspaceLeave, r: &Resource{OwnerID: 1, WorkspaceID: ws}, want: true},
+		{name: "member removes another", subject: member(model.WorkspaceRoleMaintainer), action: WorkspaceLeave, r: &Resource{OwnerID: 2, WorkspaceID: ws}, want: false},
+	})
+}
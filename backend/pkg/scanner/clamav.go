@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clamAVChunkSize INSTREAM 每个数据块的大小
+const clamAVChunkSize = 64 << 10
+
+// ClamAVScanner 通过 clamd 的 INSTREAM 协议扫描数据
+type ClamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner 创建 ClamAV 扫描器，address 形如 tcp://127.0.0.1:3310 或 unix:///var/run/clamav/clamd.ctl，
+// 不带协议时按 TCP 地址处理
+func NewClamAVScanner(address string, timeout time.Duration) (*ClamAVScanner, error) {
+	network := "tcp"
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clamav address: %w", err)
+		}
+		switch u.Scheme {
+		case "tcp":
+			address = u.Host
+		case "unix":
+			network, address = "unix", u.Path
+		default:
+			return nil, fmt.Errorf("unsupported clamav address scheme: %s", u.Scheme)
+		}
+	}
+	if address == "" {
+		return nil, fmt.Errorf("clamav address cannot be empty")
+	}
+
+	return &ClamAVScanner{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}, nil
+}
+
+// Name 返回扫描器名称
+func (s *ClamAVScanner) Name() string {
+	return "clamav"
+}
+
+// Scan 将数据分块发送给 clamd 并解析扫描结果
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamav: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to send clamav command: %w", err)
+	}
+
+	// 每块以4字节大端长度开头，长度为0的块表示结束
+	buf := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return nil, fmt.Errorf("failed to send data to clamav: %w", err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to send data to clamav: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return nil, fmt.Errorf("failed to read clamav reply: %w", err)
+	}
+	return parseClamAVReply(reply)
+}
+
+// parseClamAVReply 解析 clamd 的回复：stream: OK、stream: <特征> FOUND 或 <原因> ERROR
+func parseClamAVReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(reply, " FOUND")
+		if i := strings.Index(signature, ": "); i >= 0 {
+			signature = signature[i+2:]
+		}
+		return &Result{Infected: true, Signature: signature}, nil
+	default:
+		return nil, fmt.Errorf("clamav: %s", reply)
+	}
+}
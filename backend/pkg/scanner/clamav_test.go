@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd 模拟 clamd 的 INSTREAM 协议，内容包含 EICAR 时报告感染，超过 limit 时返回错误
+type fakeClamd struct {
+	listener net.Listener
+	limit    int
+	chunks   chan int // 每次扫描收到的数据块数量
+	silent   bool     // 收到数据后不回复，用于测试超时
+}
+
+func startFakeClamd(t *testing.T, limit int) *fakeClamd {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeClamd{listener: listener, limit: limit, chunks: make(chan int, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go d.serve()
+	return d
+}
+
+func (d *fakeClamd) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	command, err := r.ReadString(0)
+	if err != nil || command != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	var data bytes.Buffer
+	chunks := 0
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		chunks++
+		if _, err := io.CopyN(&data, r, int64(size)); err != nil {
+			return
+		}
+		if data.Len() > d.limit {
+			conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+			io.Copy(io.Discard, r)
+			return
+		}
+	}
+	d.chunks <- chunks
+
+	if d.silent {
+		io.Copy(io.Discard, r)
+		return
+	}
+	if bytes.Contains(data.Bytes(), []byte("EICAR")) {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		return
+	}
+	conn.Write([]byte("stream: OK\x00"))
+}
+
+func TestClamAVScan(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		wantInfected  bool
+		wantSignature string
+		wantChunks    int
+		wantErr       string
+	}{
+		{name: "clean", data: []byte("hello world"), wantChunks: 1},
+		{name: "empty", data: nil, wantChunks: 0},
+		{name: "infected", data: []byte("X5O!P%@AP...EICAR-STANDARD-ANTIVIRUS-TEST-FILE"), wantInfected: true, wantSignature: "Eicar-Test-Signature", wantChunks: 1},
+		{name: "multiple chunks", data: bytes.Repeat([]byte("a"), clamAVChunkSize*2+1), wantChunks: 3},
+		{name: "infected in last chunk", data: append(bytes.Repeat([]byte("a"), clamAVChunkSize), "EICAR"...), wantInfected: true, wantSignature: "Eicar-Test-Signature", wantChunks: 2},
+		{name: "size limit", data: bytes.Repeat([]byte("a"), 1<<20), wantErr: "size limit exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := startFakeClamd(t, 512<<10)
+			s, err := NewClamAVScanner("tcp://"+d.listener.Addr().String(), 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := s.Scan(context.Background(), bytes.NewReader(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Scan error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if result.Infected != tt.wantInfected || result.Signature != tt.wantSignature {
+				t.Fatalf("Scan = %+v, want infected %v signature %q", result, tt.wantInfected, tt.wantSignature)
+			}
+			if chunks := <-d.chunks; chunks != tt.wantChunks {
+				t.Fatalf("clamd received %d chunks, want %d", chunks, tt.wantChunks)
+			}
+		})
+	}
+}
+
+func TestClamAVScanTimeout(t *testing.T) {
+	d := startFakeClamd(t, 1<<20)
+	d.silent = true
+	s, err := NewClamAVScanner(d.listener.Addr().String(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Scan(context.Background(), strings.NewReader("data")); err == nil {
+		t.Fatal("Scan succeeded without a reply from clamd")
+	}
+}
+
+func TestClamAVScanUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	s, err := NewClamAVScanner(address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Scan(context.Background(), strings.NewReader("data")); err == nil {
+		t.Fatal("Scan succeeded without clamd")
+	}
+}
+
+func TestNewClamAVScannerAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{address: "tcp://127.0.0.1:3310", wantNetwork: "tcp", wantAddress: "127.0.0.1:3310"},
+		{address: "127.0.0.1:3310", wantNetwork: "tcp", wantAddress: "127.0.0.1:3310"},
+		{address: "unix:///var/run/clamav/clamd.ctl", wantNetwork: "unix", wantAddress: "/var/run/clamav/clamd.ctl"},
+		{address: "http://127.0.0.1:3310", wantErr: true},
+		{address: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			s, err := NewClamAVScanner(tt.address, time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClamAVScanner(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+			if err == nil && (s.network != tt.wantNetwork || s.address != tt.wantAddress) {
+				t.Fatalf("NewClamAVScanner(%q) = %s %s", tt.address, s.network, s.address)
+			}
+		})
+	}
+}
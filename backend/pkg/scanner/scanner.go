@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"notex/config"
+)
+
+// Result 扫描结果
+type Result struct {
+	Infected  bool   // 是否发现恶意内容
+	Signature string // 命中的特征名称
+}
+
+// Scanner 上传文件安全扫描接口
+type Scanner interface {
+	// Name 返回扫描器名称
+	Name() string
+
+	// Scan 扫描数据流，扫描服务不可用时返回错误
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// NewScanner 根据配置创建扫描器，未启用扫描时返回 nil
+func NewScanner(cfg *config.ScannerConfig) (Scanner, error) {
+	switch cfg.Provider {
+	case "", "none":
+		return nil, nil
+	case "clamav":
+		scanner, err := NewClamAVScanner(cfg.Address, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		return scanner, nil
+	default:
+		return nil, fmt.Errorf("scanner provider not found: %s", cfg.Provider)
+	}
+}
@@ -46,6 +46,56 @@ func (p *OSSStorageProvider) NewStorage(config *types.StorageConfig) (Storage, e
 	return NewOSSStorage(config)
 }
 
+// COSStorageProvider 腾讯云COS存储提供者，使用COS的S3兼容接口
+type COSStorageProvider struct{}
+
+func (p *COSStorageProvider) NewStorage(config *types.StorageConfig) (Storage, error) {
+	return NewS3Storage(config, S3Options{
+		Type:      StorageTypeCOS,
+		Endpoint:  config.COS.Endpoint,
+		AccessKey: config.COS.AccessKey,
+		SecretKey: config.COS.AccessSecret,
+		Bucket:    config.COS.BucketName,
+		Region:    config.COS.Region,
+		URLPrefix: config.COS.URLPrefix,
+		UseSSL:    true,
+	})
+}
+
+// MinioStorageProvider MinIO存储提供者
+type MinioStorageProvider struct{}
+
+func (p *MinioStorageProvider) NewStorage(config *types.StorageConfig) (Storage, error) {
+	return NewS3Storage(config, S3Options{
+		Type:      StorageTypeMinio,
+		Endpoint:  config.MinIO.Endpoint,
+		AccessKey: config.MinIO.AccessKey,
+		SecretKey: config.MinIO.AccessSecret,
+		Bucket:    config.MinIO.BucketName,
+		Region:    config.MinIO.Region,
+		URLPrefix: config.MinIO.URLPrefix,
+		UseSSL:    config.MinIO.UseSSL,
+		PathStyle: true,
+	})
+}
+
+// S3StorageProvider S3存储提供者
+type S3StorageProvider struct{}
+
+func (p *S3StorageProvider) NewStorage(config *types.StorageConfig) (Storage, error) {
+	return NewS3Storage(config, S3Options{
+		Type:      StorageTypeS3,
+		Endpoint:  config.S3.Endpoint,
+		AccessKey: config.S3.AccessKey,
+		SecretKey: config.S3.AccessSecret,
+		Bucket:    config.S3.BucketName,
+		Region:    config.S3.Region,
+		URLPrefix: config.S3.URLPrefix,
+		UseSSL:    config.S3.UseSSL,
+		PathStyle: config.S3.PathStyle,
+	})
+}
+
 // DefaultFactory 默认存储工厂实例
 var DefaultFactory = NewStorageFactory()
 
@@ -53,4 +103,7 @@ func init() {
 	// 注册默认的存储提供者
 	DefaultFactory.RegisterProvider(StorageTypeLocal, &LocalStorageProvider{})
 	DefaultFactory.RegisterProvider(StorageTypeOSS, &OSSStorageProvider{})
+	DefaultFactory.RegisterProvider(StorageTypeCOS, &COSStorageProvider{})
+	DefaultFactory.RegisterProvider(StorageTypeMinio, &MinioStorageProvider{})
+	DefaultFactory.RegisterProvider(StorageTypeS3, &S3StorageProvider{})
 }
@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 	"notex/pkg/types"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -27,17 +28,32 @@ func NewLocalStorage(config *types.StorageConfig) Storage {
 
 // Upload 上传文件到本地
 func (s *LocalStorage) Upload(file multipart.File, header *multipart.FileHeader) (*UploadResult, error) {
+	return s.Put(file, header.Filename, header.Header.Get("Content-Type"), header.Size)
+}
+
+// Put 保存数据到本地
+func (s *LocalStorage) Put(reader io.Reader, filename string, contentType string, size int64) (*UploadResult, error) {
+	// 按日期分目录并生成文件名
+	key := fmt.Sprintf("%s/%s%s", time.Now().Format("2006/01/02"), uuid.New().String(), filepath.Ext(filename))
+
+	result, err := s.PutObject(key, reader, contentType, size)
+	if err != nil {
+		return nil, err
+	}
+	result.Filename = filename
+	return result, nil
+}
+
+// PutObject 以指定的键保存数据到本地
+func (s *LocalStorage) PutObject(key string, reader io.Reader, contentType string, size int64) (*UploadResult, error) {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	filePath := filepath.Join(s.config.Local.UploadDir, filepath.FromSlash(key))
+
 	// 创建上传目录
-	uploadPath := filepath.Join(s.config.Local.UploadDir, time.Now().Format("2006/01/02"))
-	if err := os.MkdirAll(uploadPath, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
-	// 生成文件名
-	ext := filepath.Ext(header.Filename)
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
-	filePath := filepath.Join(uploadPath, filename)
-
 	// 保存文件
 	dst, err := os.Create(filePath)
 	if err != nil {
@@ -45,24 +61,39 @@ func (s *LocalStorage) Upload(file multipart.File, header *multipart.FileHeader)
 	}
 	defer dst.Close()
 
-	if _, err = io.Copy(dst, file); err != nil {
+	if _, err = io.Copy(dst, reader); err != nil {
 		return nil, fmt.Errorf("failed to save file: %w", err)
 	}
 
-	// 生成访问URL（修复路径问题）
-	relativePath := strings.TrimPrefix(filePath, s.config.Local.UploadDir)
-	relativePath = strings.TrimPrefix(relativePath, "/")
-	fileURL := filepath.Join(s.config.Local.URLPrefix, relativePath)
-	fileURL = strings.ReplaceAll(fileURL, "\\", "/") // 确保 URL 使用正斜杠
-
 	return &UploadResult{
-		URL:      fileURL,
-		Filename: header.Filename,
-		Size:     header.Size,
-		Type:     header.Header.Get("Content-Type"),
+		URL:      s.urlPrefix() + key,
+		Key:      key,
+		Filename: path.Base(key),
+		Size:     size,
+		Type:     contentType,
 	}, nil
 }
 
+// Get 读取本地文件
+func (s *LocalStorage) Get(key string) (io.ReadCloser, error) {
+	filePath := filepath.Join(s.config.Local.UploadDir, filepath.FromSlash(path.Clean("/"+key)))
+	return os.Open(filePath)
+}
+
+// KeyFromURL 从本地文件URL中提取相对路径
+func (s *LocalStorage) KeyFromURL(fileURL string) (string, bool) {
+	prefix := s.urlPrefix()
+	if !strings.HasPrefix(fileURL, prefix) || len(fileURL) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(fileURL, prefix), true
+}
+
+// urlPrefix 返回以 / 结尾的访问URL前缀
+func (s *LocalStorage) urlPrefix() string {
+	return strings.TrimSuffix(s.config.Local.URLPrefix, "/") + "/"
+}
+
 // Delete 删除本地文件
 func (s *LocalStorage) Delete(fileURL string) error {
 	// 将URL转换为本地文件路径
@@ -1,10 +1,21 @@
 package storage
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"notex/pkg/types"
+	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/sts"
@@ -12,6 +23,13 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ossMultipartThreshold = 5 << 20 // 超过该大小使用分片上传
+	ossPartSize           = 1 << 20 // 分片大小
+	ossUploadRoutines     = 3       // 分片上传并发数
+	ossUploadAttempts     = 3       // 每个分片的最大尝试次数
+)
+
 // OSSStorage 阿里云OSS存储实现
 type OSSStorage struct {
 	config *types.StorageConfig
@@ -44,26 +62,253 @@ func NewOSSStorage(config *types.StorageConfig) (Storage, error) {
 	}, nil
 }
 
-// Upload 上传文件到OSS（仅作为后备方案，不推荐使用）
+// Upload 上传文件到OSS
 func (s *OSSStorage) Upload(file multipart.File, header *multipart.FileHeader) (*UploadResult, error) {
-	return nil, fmt.Errorf("server-side upload is not supported for OSS, please use direct upload with STS credentials")
+	return s.Put(file, header.Filename, header.Header.Get("Content-Type"), header.Size)
+}
+
+// Put 保存数据到OSS，大文件使用可续传的分片上传，图片同时生成缩略图
+func (s *OSSStorage) Put(reader io.Reader, filename string, contentType string, size int64) (*UploadResult, error) {
+	objectKey := fmt.Sprintf("%s/%s%s",
+		time.Now().Format("2006/01/02"),
+		uuid.New().String(),
+		filepath.Ext(filename),
+	)
+
+	result, err := s.putObject(objectKey, reader, contentType, true)
+	if err != nil {
+		return nil, err
+	}
+	result.Filename = filename
+	return result, nil
+}
+
+// PutObject 以指定的键保存数据到OSS，不生成缩略图
+func (s *OSSStorage) PutObject(key string, reader io.Reader, contentType string, size int64) (*UploadResult, error) {
+	return s.putObject(key, reader, contentType, false)
+}
+
+// putObject 上传对象，thumbnail 为 true 时为图片生成缩略图
+func (s *OSSStorage) putObject(objectKey string, reader io.Reader, contentType string, thumbnail bool) (*UploadResult, error) {
+	// 先写入临时文件，分片上传和续传都需要可重复读取的数据源
+	tmp, err := os.CreateTemp("", "oss-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	written, err := io.Copy(tmp, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file: %w", err)
+	}
+
+	// 检测内容类型
+	head := make([]byte, 512)
+	n, _ := tmp.ReadAt(head, 0)
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(head[:n])
+	}
+
+	if err := s.putFile(objectKey, tmp.Name(), written, contentType); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	result := &UploadResult{
+		URL:      s.publicURL(objectKey),
+		Key:      objectKey,
+		Filename: path.Base(objectKey),
+		Size:     written,
+		Type:     contentType,
+	}
+
+	// 生成缩略图，失败时不影响原图上传
+	if thumbnail && strings.HasPrefix(contentType, "image/") {
+		if data, err := os.ReadFile(tmp.Name()); err == nil {
+			if thumbData, thumbType, err := CreateThumbnail(data, s.config.ThumbnailSize); err == nil {
+				thumbKey := thumbnailKey(objectKey)
+				if err := s.bucket.PutObject(thumbKey, bytes.NewReader(thumbData), oss.ContentType(thumbType)); err == nil {
+					result.ThumbnailURL = s.publicURL(thumbKey)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// putFile 上传本地文件，超过阈值时使用分片上传。分片上传按对象键续传：继续使用同一对象键未完成的分片上传，
+// 已上传且 ETag 与本地分片 MD5 一致的分片不再上传；失败时保留已上传的分片，再次上传同一对象键时从中断处继续。
+// 放弃的分片上传需要通过存储空间的生命周期规则清理
+func (s *OSSStorage) putFile(objectKey, filePath string, size int64, contentType string) error {
+	if size <= ossMultipartThreshold {
+		return s.bucket.PutObjectFromFile(objectKey, filePath, oss.ContentType(contentType))
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	imur, uploaded, err := s.resumeMultipart(objectKey, contentType)
+	if err != nil {
+		return err
+	}
+
+	count := int((size + ossPartSize - 1) / ossPartSize)
+	parts := make([]oss.UploadPart, count)
+	errs := make([]error, count)
+	numbers := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < ossUploadRoutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				offset := int64(number-1) * ossPartSize
+				section := io.NewSectionReader(file, offset, min(ossPartSize, size-offset))
+				parts[number-1], errs[number-1] = s.uploadPart(imur, section, number, uploaded[number])
+			}
+		}()
+	}
+	for number := 1; number <= count; number++ {
+		numbers <- number
+	}
+	close(numbers)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	_, err = s.bucket.CompleteMultipartUpload(imur, parts)
+	return err
+}
+
+// resumeMultipart 返回对象键最近一次未完成的分片上传及其已上传分片的 ETag，没有时创建新的分片上传
+func (s *OSSStorage) resumeMultipart(objectKey, contentType string) (oss.InitiateMultipartUploadResult, map[int]string, error) {
+	uploaded := make(map[int]string)
+	uploads, err := s.bucket.ListMultipartUploads(oss.Prefix(objectKey))
+	if err != nil {
+		return oss.InitiateMultipartUploadResult{}, nil, err
+	}
+
+	var latest *oss.UncompletedUpload
+	for i, upload := range uploads.Uploads {
+		if upload.Key == objectKey && (latest == nil || upload.Initiated.After(latest.Initiated)) {
+			latest = &uploads.Uploads[i]
+		}
+	}
+	if latest == nil {
+		imur, err := s.bucket.InitiateMultipartUpload(objectKey, oss.ContentType(contentType))
+		return imur, uploaded, err
+	}
+
+	imur := oss.InitiateMultipartUploadResult{Bucket: s.bucket.BucketName, Key: objectKey, UploadID: latest.UploadID}
+	marker := 0
+	for {
+		result, err := s.bucket.ListUploadedParts(imur, oss.PartNumberMarker(marker))
+		if err != nil {
+			return imur, nil, err
+		}
+		for _, part := range result.UploadedParts {
+			uploaded[part.PartNumber] = part.ETag
+		}
+		next, _ := strconv.Atoi(result.NextPartNumberMarker)
+		if !result.IsTruncated || next <= marker {
+			return imur, uploaded, nil
+		}
+		marker = next
+	}
+}
+
+// uploadPart 上传一个分片，etag 为已上传分片的 ETag，与分片内容一致时跳过上传
+func (s *OSSStorage) uploadPart(imur oss.InitiateMultipartUploadResult, section *io.SectionReader, number int, etag string) (oss.UploadPart, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, section); err != nil {
+		return oss.UploadPart{}, err
+	}
+	if etag != "" && strings.EqualFold(strings.Trim(etag, `"`), hex.EncodeToString(hash.Sum(nil))) {
+		return oss.UploadPart{PartNumber: number, ETag: etag}, nil
+	}
+
+	var err error
+	for attempt := 0; attempt < ossUploadAttempts; attempt++ {
+		var part oss.UploadPart
+		part, err = s.bucket.UploadPart(imur, io.NewSectionReader(section, 0, section.Size()), section.Size(), number)
+		if err == nil {
+			return part, nil
+		}
+	}
+	return oss.UploadPart{}, err
+}
+
+// Get 读取OSS文件
+func (s *OSSStorage) Get(key string) (io.ReadCloser, error) {
+	body, err := s.bucket.GetObject(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return body, nil
 }
 
-// Delete 删除OSS文件
+// Delete 删除OSS文件及其缩略图
 func (s *OSSStorage) Delete(fileURL string) error {
-	// 从URL提取对象键
-	objectKey := filepath.Base(fileURL)
-	return s.bucket.DeleteObject(objectKey)
+	objectKey := s.objectKeyFromURL(fileURL)
+	if objectKey == "" {
+		return fmt.Errorf("invalid file URL: %s", fileURL)
+	}
+
+	_, err := s.bucket.DeleteObjects([]string{objectKey, thumbnailKey(objectKey)}, oss.DeleteObjectsQuiet(true))
+	return err
+}
+
+// KeyFromURL 从文件URL中提取对象键，只接受本存储访问前缀下的URL
+func (s *OSSStorage) KeyFromURL(fileURL string) (string, bool) {
+	prefix := s.urlPrefix()
+	if !strings.HasPrefix(fileURL, prefix) || len(fileURL) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(fileURL, prefix), true
+}
+
+// publicURL 返回对象的访问URL
+func (s *OSSStorage) publicURL(objectKey string) string {
+	return s.urlPrefix() + objectKey
+}
+
+// urlPrefix 返回访问URL前缀，未配置 CDN 时使用默认的 OSS 域名
+func (s *OSSStorage) urlPrefix() string {
+	if s.config.OSS.URLPrefix != "" {
+		return strings.TrimSuffix(s.config.OSS.URLPrefix, "/") + "/"
+	}
+	return fmt.Sprintf("https://%s.%s/", s.config.OSS.BucketName, s.config.OSS.Endpoint)
+}
+
+// objectKeyFromURL 从文件URL中提取对象键
+func (s *OSSStorage) objectKeyFromURL(fileURL string) string {
+	if strings.HasPrefix(fileURL, s.urlPrefix()) {
+		return strings.TrimPrefix(fileURL, s.urlPrefix())
+	}
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+// thumbnailKey 返回原图对应的缩略图对象键
+func thumbnailKey(objectKey string) string {
+	ext := filepath.Ext(objectKey)
+	return strings.TrimSuffix(objectKey, ext) + "_thumb" + ext
 }
 
 // GetUploadConfig 获取上传配置
 func (s *OSSStorage) GetUploadConfig() interface{} {
 	// 使用配置的 CDN 域名作为 URL 前缀
-	urlPrefix := s.config.OSS.URLPrefix
-	if urlPrefix == "" {
-		// 如果未配置 CDN，则使用默认的 OSS 域名（不推荐）
-		urlPrefix = fmt.Sprintf("https://%s.%s/", s.config.OSS.BucketName, s.config.OSS.Endpoint)
-	}
+	urlPrefix := s.urlPrefix()
 
 	return &OSSUploadConfig{
 		UploadConfig: UploadConfig{
@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"notex/pkg/types"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOSS 模拟路径风格访问的 OSS 服务，支持简单上传和分片上传，不校验签名
+type fakeOSS struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	types    map[string]string
+	uploads  map[string]*fakeOSSUpload
+	nextID   int
+	partPuts int              // 收到的分片上传请求数
+	failPart func(n int) bool // 返回 true 时拒绝该分片
+}
+
+type fakeOSSUpload struct {
+	key         string
+	contentType string
+	initiated   time.Time
+	parts       map[int][]byte
+}
+
+func newFakeOSS(t *testing.T) (*fakeOSS, *OSSStorage) {
+	f := &fakeOSS{
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+		uploads: make(map[string]*fakeOSSUpload),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	cfg := &types.StorageConfig{}
+	cfg.OSS.Endpoint = server.URL
+	cfg.OSS.AccessKey = "AK"
+	cfg.OSS.AccessSecret = "SK"
+	cfg.OSS.BucketName = "bucket"
+	cfg.OSS.URLPrefix = "https://cdn.example.com"
+	s, err := NewOSSStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, s.(*OSSStorage)
+}
+
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + strings.ToUpper(hex.EncodeToString(sum[:])) + `"`
+}
+
+func (f *fakeOSS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/bucket"), "/")
+	query := r.URL.Query()
+	_, uploadsParam := query["uploads"]
+	uploadID := query.Get("uploadId")
+
+	switch {
+	case r.Method == http.MethodGet && uploadsParam:
+		var buf bytes.Buffer
+		buf.WriteString("<ListMultipartUploadsResult><Bucket>bucket</Bucket><IsTruncated>false</IsTruncated>")
+		for id, upload := range f.uploads {
+			if strings.HasPrefix(upload.key, query.Get("prefix")) {
+				fmt.Fprintf(&buf, "<Upload><Key>%s</Key><UploadId>%s</UploadId><Initiated>%s</Initiated></Upload>",
+					upload.key, id, upload.initiated.UTC().Format("2006-01-02T15:04:05.000Z"))
+			}
+		}
+		buf.WriteString("</ListMultipartUploadsResult>")
+		w.Write(buf.Bytes())
+
+	case r.Method == http.MethodPost && uploadsParam:
+		f.nextID++
+		id := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[id] = &fakeOSSUpload{
+			key:         key,
+			contentType: r.Header.Get("Content-Type"),
+			initiated:   time.Now().Add(time.Duration(f.nextID) * time.Second),
+			parts:       make(map[int][]byte),
+		}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key, id)
+
+	case r.Method == http.MethodPut && uploadID != "":
+		upload := f.uploads[uploadID]
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		data, err := io.ReadAll(r.Body)
+		if upload == nil || err != nil {
+			http.Error(w, "<Error><Code>NoSuchUpload</Code></Error>", http.StatusNotFound)
+			return
+		}
+		f.partPuts++
+		if f.failPart != nil && f.failPart(number) {
+			http.Error(w, "<Error><Code>InternalError</Code></Error>", http.StatusInternalServerError)
+			return
+		}
+		upload.parts[number] = data
+		w.Header().Set("ETag", etagOf(data))
+
+	case r.Method == http.MethodGet && uploadID != "":
+		upload := f.uploads[uploadID]
+		if upload == nil {
+			http.Error(w, "<Error><Code>NoSuchUpload</Code></Error>", http.StatusNotFound)
+			return
+		}
+		marker, _ := strconv.Atoi(query.Get("part-number-marker"))
+		numbers := make([]int, 0, len(upload.parts))
+		for number := range upload.parts {
+			if number > marker {
+				numbers = append(numbers, number)
+			}
+		}
+		sort.Ints(numbers)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "<ListPartsResult><Bucket>bucket</Bucket><Key>%s</Key><UploadId>%s</UploadId><IsTruncated>false</IsTruncated>", upload.key, uploadID)
+		for _, number := range numbers {
+			fmt.Fprintf(&buf, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag><Size>%d</Size><LastModified>2024-01-01T00:00:00.000Z</LastModified></Part>",
+				number, etagOf(upload.parts[number]), len(upload.parts[number]))
+		}
+		buf.WriteString("</ListPartsResult>")
+		w.Write(buf.Bytes())
+
+	case r.Method == http.MethodPost && uploadID != "":
+		upload := f.uploads[uploadID]
+		var body struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		if upload == nil || xml.NewDecoder(r.Body).Decode(&body) != nil {
+			http.Error(w, "<Error><Code>InvalidPart</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		var object []byte
+		for i, part := range body.Parts {
+			data, ok := upload.parts[part.PartNumber]
+			if !ok || part.PartNumber != i+1 || part.ETag != etagOf(data) {
+				http.Error(w, "<Error><Code>InvalidPart</Code></Error>", http.StatusBadRequest)
+				return
+			}
+			object = append(object, data...)
+		}
+		f.objects[upload.key] = object
+		f.types[upload.key] = upload.contentType
+		delete(f.uploads, uploadID)
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>%s</Key><ETag>\"done\"</ETag></CompleteMultipartUploadResult>", upload.key)
+
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = data
+		f.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", etagOf(data))
+
+	default:
+		http.Error(w, "<Error><Code>NotImplemented</Code></Error>", http.StatusNotImplemented)
+	}
+}
+
+// largeData 返回需要分片上传的随机数据，共 6 个分片
+func largeData() []byte {
+	data := make([]byte, ossMultipartThreshold+ossPartSize/2)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+func TestOSSPutObjectSmall(t *testing.T) {
+	fake, s := newFakeOSS(t)
+	result, err := s.PutObject("2024/01/01/a.txt", strings.NewReader("hello"), "text/plain", 5)
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if result.URL != "https://cdn.example.com/2024/01/01/a.txt" || result.Size != 5 {
+		t.Fatalf("result = %+v", result)
+	}
+	if string(fake.objects["2024/01/01/a.txt"]) != "hello" || fake.types["2024/01/01/a.txt"] != "text/plain" {
+		t.Fatalf("stored %q as %q", fake.objects["2024/01/01/a.txt"], fake.types["2024/01/01/a.txt"])
+	}
+	if fake.partPuts != 0 {
+		t.Fatalf("small object used %d part uploads", fake.partPuts)
+	}
+}
+
+func TestOSSPutObjectMultipart(t *testing.T) {
+	fake, s := newFakeOSS(t)
+	data := largeData()
+
+	// 第 2 个分片第一次失败，在同一次调用中重试
+	failed := false
+	fake.failPart = func(n int) bool {
+		if n == 2 && !failed {
+			failed = true
+			return true
+		}
+		return false
+	}
+
+	result, err := s.PutObject("big.bin", bytes.NewReader(data), "application/zip", int64(len(data)))
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if result.Size != int64(len(data)) {
+		t.Fatalf("size = %d, want %d", result.Size, len(data))
+	}
+	if !bytes.Equal(fake.objects["big.bin"], data) || fake.types["big.bin"] != "application/zip" {
+		t.Fatalf("stored %d bytes as %q", len(fake.objects["big.bin"]), fake.types["big.bin"])
+	}
+	if len(fake.uploads) != 0 {
+		t.Fatalf("%d multipart uploads left open", len(fake.uploads))
+	}
+}
+
+func TestOSSPutObjectResume(t *testing.T) {
+	fake, s := newFakeOSS(t)
+	data := largeData()
+
+	// 第 3 个分片一直失败，上传中断
+	fake.failPart = func(n int) bool { return n == 3 }
+	if _, err := s.PutObject("big.bin", bytes.NewReader(data), "application/zip", int64(len(data))); err == nil {
+		t.Fatal("PutObject succeeded with a failing part")
+	}
+	if _, ok := fake.objects["big.bin"]; ok {
+		t.Fatal("object was created by an interrupted upload")
+	}
+	if len(fake.uploads) != 1 {
+		t.Fatalf("%d multipart uploads kept, want 1", len(fake.uploads))
+	}
+
+	// 再次上传同一对象键时只上传缺失的分片
+	fake.failPart = nil
+	fake.partPuts = 0
+	if _, err := s.PutObject("big.bin", bytes.NewReader(data), "application/zip", int64(len(data))); err != nil {
+		t.Fatalf("resumed PutObject: %v", err)
+	}
+	if fake.partPuts != 1 {
+		t.Fatalf("resumed upload sent %d parts, want 1", fake.partPuts)
+	}
+	if !bytes.Equal(fake.objects["big.bin"], data) {
+		t.Fatal("resumed object does not match the data")
+	}
+
+	// 内容不同的分片不会被复用
+	fake.failPart = func(n int) bool { return n == 6 }
+	if _, err := s.PutObject("other.bin", bytes.NewReader(data), "application/zip", int64(len(data))); err == nil {
+		t.Fatal("PutObject succeeded with a failing part")
+	}
+	changed := append([]byte(nil), data...)
+	changed[0]++
+	fake.failPart = nil
+	fake.partPuts = 0
+	if _, err := s.PutObject("other.bin", bytes.NewReader(changed), "application/zip", int64(len(changed))); err != nil {
+		t.Fatalf("resumed PutObject: %v", err)
+	}
+	if fake.partPuts != 2 || !bytes.Equal(fake.objects["other.bin"], changed) {
+		t.Fatalf("resumed upload sent %d parts, want 2 (changed first part and missing last part)", fake.partPuts)
+	}
+}
@@ -0,0 +1,342 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"notex/pkg/types"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// S3Options S3协议存储的连接参数
+type S3Options struct {
+	Type      StorageType // 存储类型：cos, minio, s3
+	Endpoint  string      // 服务地址，可带协议前缀
+	AccessKey string      // 访问密钥ID
+	SecretKey string      // 访问密钥密码
+	Bucket    string      // 存储桶名称
+	Region    string      // 地域
+	URLPrefix string      // 访问URL前缀
+	UseSSL    bool        // 是否使用 HTTPS
+	PathStyle bool        // 使用路径风格访问（endpoint/bucket/key）
+}
+
+// S3Storage 基于S3协议的存储实现，适用于 AWS S3、MinIO 和腾讯云 COS
+type S3Storage struct {
+	config  *types.StorageConfig
+	options S3Options
+	signer  *sigV4Signer
+	client  *http.Client
+	scheme  string
+	host    string
+}
+
+// NewS3Storage 创建S3协议存储实例
+func NewS3Storage(config *types.StorageConfig, options S3Options) (Storage, error) {
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+
+	scheme := "http"
+	if options.UseSSL {
+		scheme = "https"
+	}
+	host := options.Endpoint
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint: %w", err)
+		}
+		scheme, host = u.Scheme, u.Host
+	}
+	host = strings.TrimSuffix(host, "/")
+	if host == "" {
+		return nil, fmt.Errorf("endpoint cannot be empty")
+	}
+
+	return &S3Storage{
+		config:  config,
+		options: options,
+		signer: &sigV4Signer{
+			accessKey: options.AccessKey,
+			secretKey: options.SecretKey,
+			region:    options.Region,
+			service:   "s3",
+		},
+		client: &http.Client{Timeout: 5 * time.Minute},
+		scheme: scheme,
+		host:   host,
+	}, nil
+}
+
+// Upload 上传文件到S3
+func (s *S3Storage) Upload(file multipart.File, header *multipart.FileHeader) (*UploadResult, error) {
+	return s.Put(file, header.Filename, header.Header.Get("Content-Type"), header.Size)
+}
+
+// Put 保存数据到S3
+func (s *S3Storage) Put(reader io.Reader, filename string, contentType string, size int64) (*UploadResult, error) {
+	result, err := s.putObject(s.newObjectKey(filename), reader, contentType, size)
+	if err != nil {
+		return nil, err
+	}
+	result.Filename = filename
+	return result, nil
+}
+
+// PutObject 以指定的键保存数据到S3
+func (s *S3Storage) PutObject(key string, reader io.Reader, contentType string, size int64) (*UploadResult, error) {
+	return s.putObject(key, reader, contentType, size)
+}
+
+// putObject 签名并流式上传对象，请求体不参与签名（UNSIGNED-PAYLOAD），无需整体读入内存；
+// S3 的 PUT 请求必须带 Content-Length，大小未知（size < 0）时才读入内存计算
+func (s *S3Storage) putObject(objectKey string, reader io.Reader, contentType string, size int64) (*UploadResult, error) {
+	if size < 0 {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		reader, size = bytes.NewReader(data), int64(len(data))
+	}
+	if contentType == "" {
+		buffered := bufio.NewReader(reader)
+		head, err := buffered.Peek(512)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		contentType = http.DetectContentType(head)
+		reader = buffered
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(objectKey).String(), io.NopCloser(reader))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.signer.SignRequest(req, sigV4UnsignedBody, time.Now())
+
+	if err := s.do(req); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return &UploadResult{
+		URL:      s.publicURL(objectKey),
+		Key:      objectKey,
+		Filename: path.Base(objectKey),
+		Size:     size,
+		Type:     contentType,
+	}, nil
+}
+
+// Get 读取S3文件
+func (s *S3Storage) Get(key string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	s.signer.SignRequest(req, sigV4EmptyBodyHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("failed to get file: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp.Body, nil
+}
+
+// KeyFromURL 从文件URL中提取对象键
+func (s *S3Storage) KeyFromURL(fileURL string) (string, bool) {
+	key, err := s.objectKeyFromURL(fileURL)
+	if err != nil || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// Delete 删除S3文件
+func (s *S3Storage) Delete(fileURL string) error {
+	objectKey, err := s.objectKeyFromURL(fileURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(objectKey).String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	s.signer.SignRequest(req, sigV4EmptyBodyHash, time.Now())
+
+	if err := s.do(req); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// GetUploadConfig 获取上传配置
+func (s *S3Storage) GetUploadConfig() interface{} {
+	base := UploadConfig{
+		Type:         s.options.Type,
+		MaxSize:      s.config.MaxSize,
+		AllowedTypes: s.config.AllowedTypes,
+		DirectUpload: true,
+		UploadURL:    s.bucketURL().String(),
+	}
+	urlPrefix := s.publicURL("")
+
+	switch s.options.Type {
+	case StorageTypeCOS:
+		return &COSUploadConfig{
+			UploadConfig: base,
+			Region:       s.options.Region,
+			Bucket:       s.options.Bucket,
+			Endpoint:     s.options.Endpoint,
+			Headers:      map[string]string{},
+			URLPrefix:    urlPrefix,
+		}
+	case StorageTypeMinio:
+		return &MinioUploadConfig{
+			UploadConfig: base,
+			Bucket:       s.options.Bucket,
+			Endpoint:     s.options.Endpoint,
+			Region:       s.options.Region,
+			URLPrefix:    urlPrefix,
+		}
+	default:
+		return &S3UploadConfig{
+			UploadConfig: base,
+			Region:       s.options.Region,
+			Bucket:       s.options.Bucket,
+			Endpoint:     s.options.Endpoint,
+			PathStyle:    s.options.PathStyle,
+			URLPrefix:    urlPrefix,
+		}
+	}
+}
+
+// GetType 获取存储类型
+func (s *S3Storage) GetType() StorageType {
+	return s.options.Type
+}
+
+// GetCredentials 获取上传凭证，同时返回预签名 PUT URL 和表单 POST 策略，前端可任选其一直传
+func (s *S3Storage) GetCredentials(filename string, contentType string) (*UploadCredentials, error) {
+	now := time.Now().UTC()
+	expires := time.Hour
+	objectKey := s.newObjectKey(filename)
+
+	putURL := s.signer.PresignURL(http.MethodPut, s.objectURL(objectKey), expires, now)
+
+	// 构造 POST 表单上传策略
+	policy := map[string]interface{}{
+		"expiration": now.Add(expires).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": []interface{}{
+			map[string]string{"bucket": s.options.Bucket},
+			[]string{"eq", "$key", objectKey},
+			[]string{"eq", "$Content-Type", contentType},
+			[]interface{}{"content-length-range", 0, s.config.MaxSize},
+			map[string]string{"x-amz-algorithm": sigV4Algorithm},
+			map[string]string{"x-amz-credential": s.signer.credential(now)},
+			map[string]string{"x-amz-date": now.Format(sigV4DateFormat)},
+		},
+	}
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy: %w", err)
+	}
+	encodedPolicy := base64.StdEncoding.EncodeToString(policyJSON)
+
+	return &UploadCredentials{
+		FileKey: objectKey,
+		Expires: now.Add(expires).Unix(),
+		Extra: map[string]string{
+			"putUrl":           putURL,
+			"postUrl":          s.bucketURL().String(),
+			"policy":           encodedPolicy,
+			"x-amz-algorithm":  sigV4Algorithm,
+			"x-amz-credential": s.signer.credential(now),
+			"x-amz-date":       now.Format(sigV4DateFormat),
+			"x-amz-signature":  s.signer.sign(now, encodedPolicy),
+			"url":              s.publicURL(objectKey),
+			"bucket":           s.options.Bucket,
+			"region":           s.options.Region,
+		},
+	}, nil
+}
+
+// do 发送已签名的请求并检查响应状态
+func (s *S3Storage) do(req *http.Request) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// newObjectKey 生成按日期分目录的对象键
+func (s *S3Storage) newObjectKey(filename string) string {
+	return fmt.Sprintf("%s/%s%s", time.Now().Format("2006/01/02"), uuid.New().String(), filepath.Ext(filename))
+}
+
+// bucketURL 返回存储桶的访问地址
+func (s *S3Storage) bucketURL() *url.URL {
+	if s.options.PathStyle {
+		return &url.URL{Scheme: s.scheme, Host: s.host, Path: "/" + s.options.Bucket + "/"}
+	}
+	return &url.URL{Scheme: s.scheme, Host: s.options.Bucket + "." + s.host, Path: "/"}
+}
+
+// objectURL 返回对象的访问地址
+func (s *S3Storage) objectURL(objectKey string) *url.URL {
+	u := s.bucketURL()
+	u.Path += objectKey
+	return u
+}
+
+// publicURL 返回对象的公开访问URL，优先使用配置的URL前缀
+func (s *S3Storage) publicURL(objectKey string) string {
+	if s.options.URLPrefix != "" {
+		return strings.TrimSuffix(s.options.URLPrefix, "/") + "/" + objectKey
+	}
+	return s.objectURL(objectKey).String()
+}
+
+// objectKeyFromURL 从文件URL中提取对象键
+func (s *S3Storage) objectKeyFromURL(fileURL string) (string, error) {
+	if s.options.URLPrefix != "" {
+		if prefix := s.publicURL(""); strings.HasPrefix(fileURL, prefix) {
+			return strings.TrimPrefix(fileURL, prefix), nil
+		}
+	}
+	// 存储桶地址中的对象键经过转义
+	if prefix := s.objectURL("").String(); strings.HasPrefix(fileURL, prefix) {
+		return url.PathUnescape(strings.TrimPrefix(fileURL, prefix))
+	}
+	return "", fmt.Errorf("file URL does not belong to bucket %s: %s", s.options.Bucket, fileURL)
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"notex/pkg/types"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 模拟路径风格访问的 S3 服务，校验请求签名并在内存中保存对象
+type fakeS3 struct {
+	t       *testing.T
+	signer  *sigV4Signer
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	// onBody 在读取请求体之前调用，用于检查上传是否流式发送
+	onBody func(r *http.Request)
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{
+		t:       t,
+		signer:  &sigV4Signer{accessKey: "AK", secretKey: "SK", region: "us-east-1", service: "s3"},
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f.verify(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch r.Method {
+	case http.MethodPut:
+		if r.ContentLength < 0 {
+			http.Error(w, "MissingContentLength", http.StatusLengthRequired)
+			return
+		}
+		if f.onBody != nil {
+			f.onBody(r)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.objects[key] = data
+		f.types[key] = r.Header.Get("Content-Type")
+		f.mu.Unlock()
+	case http.MethodGet:
+		f.mu.Lock()
+		data, ok := f.objects[key]
+		f.mu.Unlock()
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		f.mu.Lock()
+		delete(f.objects, key)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verify 按服务端收到的请求重新计算签名并与 Authorization 头比较
+func (f *fakeS3) verify(r *http.Request) error {
+	date, err := time.Parse(sigV4DateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return fmt.Errorf("bad X-Amz-Date: %v", err)
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if r.Method == http.MethodPut && payloadHash != sigV4UnsignedBody {
+		return fmt.Errorf("upload payload hash = %q, want %s", payloadHash, sigV4UnsignedBody)
+	}
+
+	expected := &http.Request{Method: r.Method, URL: r.URL, Header: http.Header{}}
+	expected.URL.Host = r.Host
+	for name, values := range r.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-md5" || (strings.HasPrefix(lower, "x-amz-") && lower != "x-amz-date" && lower != "x-amz-content-sha256") {
+			expected.Header[name] = values
+		}
+	}
+	f.signer.SignRequest(expected, payloadHash, date)
+	if got, want := r.Header.Get("Authorization"), expected.Header.Get("Authorization"); got != want {
+		return fmt.Errorf("signature mismatch:\n got  %s\n want %s", got, want)
+	}
+	return nil
+}
+
+func newTestS3Storage(t *testing.T, server *httptest.Server) *S3Storage {
+	t.Helper()
+	s, err := NewS3Storage(&types.StorageConfig{MaxSize: 1 << 20}, S3Options{
+		Type:      StorageTypeMinio,
+		Endpoint:  server.URL,
+		AccessKey: "AK",
+		SecretKey: "SK",
+		Bucket:    "bucket",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.(*S3Storage)
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		data        string
+		contentType string
+		size        int64
+		wantType    string
+	}{
+		{name: "explicit type", key: "2024/01/02/a.txt", data: "hello", contentType: "text/plain", size: 5, wantType: "text/plain"},
+		{name: "detected type", key: "2024/01/02/b.png", data: "\x89PNG\r\n\x1a\nrest", size: 12, wantType: "image/png"},
+		{name: "unknown size", key: "2024/01/02/c.bin", data: "abc", contentType: "application/octet-stream", size: -1, wantType: "application/octet-stream"},
+		{name: "empty object", key: "2024/01/02/d.txt", data: "", contentType: "text/plain", size: 0, wantType: "text/plain"},
+		{name: "escaped key", key: "dir/名 称 (1).txt", data: "x", contentType: "text/plain", size: 1, wantType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server := newFakeS3(t)
+			s := newTestS3Storage(t, server)
+
+			result, err := s.PutObject(tt.key, strings.NewReader(tt.data), tt.contentType, tt.size)
+			if err != nil {
+				t.Fatalf("PutObject: %v", err)
+			}
+			if result.Key != tt.key || result.Type != tt.wantType || result.Size != int64(len(tt.data)) {
+				t.Fatalf("result = %+v", result)
+			}
+			if got := fake.types[tt.key]; got != tt.wantType {
+				t.Fatalf("stored content type = %q, want %q", got, tt.wantType)
+			}
+
+			key, ok := s.KeyFromURL(result.URL)
+			if !ok || key != tt.key {
+				t.Fatalf("KeyFromURL(%q) = %q, %v", result.URL, key, ok)
+			}
+
+			body, err := s.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			data, _ := io.ReadAll(body)
+			body.Close()
+			if string(data) != tt.data {
+				t.Fatalf("Get = %q, want %q", data, tt.data)
+			}
+
+			if err := s.Delete(result.URL); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := s.Get(tt.key); err == nil {
+				t.Fatal("Get after Delete succeeded")
+			}
+		})
+	}
+}
+
+func TestS3PutStreamsBody(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s := newTestS3Storage(t, server)
+
+	first := []byte("first chunk;")
+	rest := bytes.Repeat([]byte("x"), 64<<10)
+	received := make(chan struct{})
+	fake.onBody = func(r *http.Request) {
+		buf := make([]byte, len(first))
+		if _, err := io.ReadFull(r.Body, buf); err != nil || !bytes.Equal(buf, first) {
+			t.Errorf("first chunk = %q, %v", buf, err)
+		}
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(buf), r.Body))
+		close(received)
+	}
+
+	// 在服务端收到第一块数据之前不写入剩余数据，若上传前整体读入内存则会超时
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write(first)
+		select {
+		case <-received:
+			pw.Write(rest)
+			pw.Close()
+		case <-time.After(5 * time.Second):
+			pw.CloseWithError(fmt.Errorf("upload was not streamed"))
+		}
+	}()
+
+	size := int64(len(first) + len(rest))
+	result, err := s.Put(pr, "big.bin", "application/octet-stream", size)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if result.Filename != "big.bin" || result.Size != size {
+		t.Fatalf("result = %+v", result)
+	}
+	if got := len(fake.objects[result.Key]); int64(got) != size {
+		t.Fatalf("stored %d bytes, want %d", got, size)
+	}
+}
+
+func TestS3UploadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+	s := newTestS3Storage(t, server)
+
+	_, err := s.PutObject("a.txt", strings.NewReader("a"), "text/plain", 1)
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("PutObject error = %v, want AccessDenied", err)
+	}
+}
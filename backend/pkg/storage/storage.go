@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"io"
 	"mime/multipart"
 	"notex/pkg/types"
 )
@@ -13,6 +14,7 @@ const (
 	StorageTypeOSS   StorageType = "oss"   // 阿里云 OSS
 	StorageTypeCOS   StorageType = "cos"   // 腾讯云 COS
 	StorageTypeMinio StorageType = "minio" // MinIO 对象存储
+	StorageTypeS3    StorageType = "s3"    // AWS S3 或其他兼容 S3 协议的服务
 )
 
 // UploadConfig 上传配置基础结构
@@ -59,9 +61,20 @@ type MinioUploadConfig struct {
 	URLPrefix string `json:"urlPrefix"` // 访问URL前缀
 }
 
+// S3UploadConfig S3上传配置
+type S3UploadConfig struct {
+	UploadConfig
+	Region    string `json:"region"`    // 地域
+	Bucket    string `json:"bucket"`    // Bucket名称
+	Endpoint  string `json:"endpoint"`  // 访问域名
+	PathStyle bool   `json:"pathStyle"` // 是否使用路径风格访问
+	URLPrefix string `json:"urlPrefix"` // 访问URL前缀
+}
+
 // UploadResult 上传结果
 type UploadResult struct {
 	URL          string `json:"url"`           // 文件访问URL
+	Key          string `json:"key"`           // 文件在存储中的路径或对象键
 	ThumbnailURL string `json:"thumbnail_url"` // 缩略图URL（仅图片）
 	Filename     string `json:"filename"`      // 文件名
 	Size         int64  `json:"size"`          // 文件大小
@@ -84,6 +97,18 @@ type Storage interface {
 	// Upload 上传文件
 	Upload(file multipart.File, header *multipart.FileHeader) (*UploadResult, error)
 
+	// Put 保存服务端生成的数据，filename 用于确定扩展名
+	Put(reader io.Reader, filename string, contentType string, size int64) (*UploadResult, error)
+
+	// PutObject 以指定的键保存数据，用于在存储之间迁移文件
+	PutObject(key string, reader io.Reader, contentType string, size int64) (*UploadResult, error)
+
+	// Get 读取文件内容，key 为上传结果中的 Key
+	Get(key string) (io.ReadCloser, error)
+
+	// KeyFromURL 从本存储生成的文件URL中提取键，URL不属于本存储时返回 false
+	KeyFromURL(fileURL string) (string, bool)
+
 	// Delete 删除文件
 	Delete(fileURL string) error
 
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/nfnt/resize"
+)
+
+// CreateThumbnail 根据图片数据生成缩略图，返回缩略图数据和对应的内容类型
+func CreateThumbnail(data []byte, size int) ([]byte, string, error) {
+	// 解码图片
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 生成缩略图
+	thumbnail := resize.Thumbnail(uint(size), uint(size), img, resize.Lanczos3)
+
+	// 根据原图格式选择编码器
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if format == "jpeg" {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, thumbnail, nil)
+	} else {
+		err = png.Encode(&buf, thumbnail)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), contentType, nil
+}
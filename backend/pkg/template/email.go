@@ -37,6 +37,8 @@ func Initialize(templatesDir string) error {
 		"verification.html",
 		"password_reset.html",
 		"email_change.html",
+		"workspace_invitation.html",
+		"guest_comment.html",
 	}
 
 	for _, tmpl := range templates {
@@ -0,0 +1,361 @@
+// Package tus 实现 tus 1.0 可续传上传协议的本地磁盘暂存
+package tus
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Version 支持的协议版本
+const Version = "1.0.0"
+
+var (
+	ErrNotFound       = errors.New("upload not found")
+	ErrExpired        = errors.New("upload has expired")
+	ErrOffsetMismatch = errors.New("upload offset does not match")
+	ErrLocked         = errors.New("upload is being written by another request")
+	ErrExceedsLength  = errors.New("data exceeds the declared upload length")
+	ErrCompleted      = errors.New("upload is already completed")
+	ErrInvalidMeta    = errors.New("invalid upload metadata")
+)
+
+// idPattern 上传ID的格式，防止通过ID访问暂存目录之外的文件
+var idPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Upload 暂存中的上传
+type Upload struct {
+	ID        string            `json:"id"`
+	UserID    uint              `json:"user_id"`
+	Length    int64             `json:"length"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+
+	// 上传完成并移交后的结果，数据文件此时已删除
+	Completed bool            `json:"completed"`
+	Result    json.RawMessage `json:"result,omitempty"`
+}
+
+// Done 判断数据是否已全部接收
+func (u *Upload) Done() bool {
+	return u.Offset == u.Length
+}
+
+// Store 将上传数据暂存在本地目录中，每个上传由数据文件和 JSON 描述文件组成
+type Store struct {
+	dir        string
+	expiration time.Duration
+
+	mu     sync.Mutex
+	locked map[string]bool
+}
+
+// NewStore 创建暂存目录，上传在最后一次写入 expiration 之后过期
+func NewStore(dir string, expiration time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create tus directory: %w", err)
+	}
+	return &Store{dir: dir, expiration: expiration, locked: make(map[string]bool)}, nil
+}
+
+// Create 创建上传并分配ID
+func (s *Store) Create(userID uint, length int64, metadata map[string]string) (*Upload, error) {
+	now := time.Now()
+	upload := &Upload{
+		ID:        strings.ReplaceAll(uuid.New().String(), "-", ""),
+		UserID:    userID,
+		Length:    length,
+		Metadata:  metadata,
+		ExpiresAt: now.Add(s.expiration),
+		CreatedAt: now,
+	}
+
+	file, err := os.OpenFile(s.dataPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	if err := s.save(upload); err != nil {
+		os.Remove(s.dataPath(upload.ID))
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get 读取上传信息，偏移量以数据文件的实际大小为准，已过期的上传返回 ErrExpired
+func (s *Store) Get(id string) (*Upload, error) {
+	upload, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.ExpiresAt.After(time.Now()) {
+		return nil, ErrExpired
+	}
+	return upload, nil
+}
+
+// load 读取上传信息，不检查是否过期
+func (s *Store) load(id string) (*Upload, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.infoPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, err
+	}
+
+	if !upload.Completed {
+		info, err := os.Stat(s.dataPath(id))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		upload.Offset = info.Size()
+	}
+	return &upload, nil
+}
+
+// FinishFunc 在数据全部接收后调用，返回的结果会被记录在上传信息中
+type FinishFunc func(upload *Upload, data *os.File) (interface{}, error)
+
+// Append 从 offset 处追加数据，返回追加后的上传信息。数据全部接收后在同一锁内调用 finish 移交上传，
+// 成功后删除数据文件；finish 失败时保留数据，客户端可以在该偏移量发送空的 PATCH 请求重试。
+// 写入中断时已写入的数据会保留，客户端可以通过 HEAD 请求获取偏移量后继续上传。
+func (s *Store) Append(id string, offset int64, r io.Reader, finish FinishFunc) (*Upload, error) {
+	if err := s.lock(id); err != nil {
+		return nil, err
+	}
+	defer s.unlock(id)
+
+	upload, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Completed {
+		return nil, ErrCompleted
+	}
+	if offset != upload.Offset {
+		return nil, ErrOffsetMismatch
+	}
+
+	written, err := s.write(upload, r)
+	upload.Offset += written
+	if written > 0 {
+		upload.ExpiresAt = time.Now().Add(s.expiration)
+		if err := s.save(upload); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		// 已写入的数据保留，客户端可以续传
+		if written > 0 {
+			return upload, nil
+		}
+		return nil, err
+	}
+	if !upload.Done() {
+		return upload, nil
+	}
+
+	data, err := os.Open(s.dataPath(id))
+	if err != nil {
+		return nil, err
+	}
+	result, err := finish(upload, data)
+	data.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.complete(upload, result); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// write 将数据追加到数据文件，数据超过声明的长度时丢弃本次写入并返回 ErrExceedsLength
+func (s *Store) write(upload *Upload, r io.Reader) (int64, error) {
+	file, err := os.OpenFile(s.dataPath(upload.ID), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// 多读一个字节用于判断是否超过声明的长度
+	remaining := upload.Length - upload.Offset
+	written, err := io.Copy(file, io.LimitReader(r, remaining+1))
+	if written > remaining {
+		if err := file.Truncate(upload.Offset); err != nil {
+			return 0, err
+		}
+		return 0, ErrExceedsLength
+	}
+	return written, err
+}
+
+// Delete 删除上传的数据和描述文件
+func (s *Store) Delete(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrNotFound
+	}
+	if err := s.lock(id); err != nil {
+		return err
+	}
+	defer s.unlock(id)
+
+	if err := removeIfExists(s.dataPath(id)); err != nil {
+		return err
+	}
+	return removeIfExists(s.infoPath(id))
+}
+
+// DeleteExpired 删除在 now 之前过期的上传，返回删除的数量
+func (s *Store) DeleteExpired(now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".info")
+		if !ok || !idPattern.MatchString(id) {
+			continue
+		}
+		upload, err := s.load(id)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if upload != nil && upload.ExpiresAt.After(now) {
+			continue
+		}
+		// 数据文件缺失的上传同样清理
+		if err := s.Delete(id); err == nil {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ParseMetadata 解析 Upload-Metadata 头，格式为逗号分隔的 "键 base64值"，值可以省略
+func ParseMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, ErrInvalidMeta
+		}
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, ErrInvalidMeta
+			}
+			value = string(decoded)
+		}
+		if _, exists := metadata[parts[0]]; exists {
+			return nil, ErrInvalidMeta
+		}
+		metadata[parts[0]] = value
+	}
+	return metadata, nil
+}
+
+// FormatMetadata 将元数据编码为 Upload-Metadata 头
+func FormatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if value == "" {
+			pairs = append(pairs, key)
+		} else {
+			pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// complete 记录上传已移交的结果并删除数据文件
+func (s *Store) complete(upload *Upload, result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	upload.Completed = true
+	upload.Result = data
+	if err := s.save(upload); err != nil {
+		return err
+	}
+	return removeIfExists(s.dataPath(upload.ID))
+}
+
+// save 写入上传的描述文件，先写临时文件再重命名
+func (s *Store) save(upload *Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	tmp := s.infoPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(upload.ID))
+}
+
+// lock 标记上传正在写入，同一上传不允许并发写入
+func (s *Store) lock(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locked[id] {
+		return ErrLocked
+	}
+	s.locked[id] = true
+	return nil
+}
+
+func (s *Store) unlock(id string) {
+	s.mu.Lock()
+	delete(s.locked, id)
+	s.mu.Unlock()
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+func (s *Store) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+// removeIfExists 删除文件，文件不存在时不报错
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
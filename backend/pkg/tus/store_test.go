@@ -0,0 +1,325 @@
+package tus
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, expiration time.Duration) *Store {
+	t.Helper()
+	store, err := NewStore(t.TempDir(), expiration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// failingReader 返回 data 后以 err 结束，模拟中断的请求
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// recordFinish 记录移交的数据，返回固定的结果
+func recordFinish(received *string) FinishFunc {
+	return func(upload *Upload, data *os.File) (interface{}, error) {
+		content, err := io.ReadAll(data)
+		if err != nil {
+			return nil, err
+		}
+		*received = string(content)
+		return map[string]string{"url": "/uploads/" + upload.Metadata["filename"]}, nil
+	}
+}
+
+func TestStoreCreateAndGet(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 11, map[string]string{"filename": "a.txt"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !idPattern.MatchString(upload.ID) {
+		t.Fatalf("ID = %q", upload.ID)
+	}
+
+	got, err := store.Get(upload.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.UserID != 7 || got.Length != 11 || got.Offset != 0 || got.Metadata["filename"] != "a.txt" || got.Completed {
+		t.Fatalf("Get = %+v", got)
+	}
+
+	for _, id := range []string{"missing", "../" + upload.ID, strings.Repeat("0", 32)} {
+		if _, err := store.Get(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) error = %v, want %v", id, err, ErrNotFound)
+		}
+	}
+}
+
+func TestStoreAppend(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 11, map[string]string{"filename": "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received string
+	finish := recordFinish(&received)
+
+	got, err := store.Append(upload.ID, 0, strings.NewReader("hello "), finish)
+	if err != nil || got.Offset != 6 || got.Completed {
+		t.Fatalf("Append = %+v, %v", got, err)
+	}
+	if got.ExpiresAt.Before(upload.ExpiresAt) {
+		t.Fatalf("expiration was not extended: %v -> %v", upload.ExpiresAt, got.ExpiresAt)
+	}
+
+	if _, err := store.Append(upload.ID, 3, strings.NewReader("world"), finish); !errors.Is(err, ErrOffsetMismatch) {
+		t.Fatalf("Append at wrong offset error = %v, want %v", err, ErrOffsetMismatch)
+	}
+	if _, err := store.Append(upload.ID, 6, strings.NewReader("world!!"), finish); !errors.Is(err, ErrExceedsLength) {
+		t.Fatalf("Append beyond length error = %v, want %v", err, ErrExceedsLength)
+	}
+	if got, _ := store.Get(upload.ID); got.Offset != 6 {
+		t.Fatalf("offset after rejected write = %d, want 6", got.Offset)
+	}
+
+	got, err = store.Append(upload.ID, 6, strings.NewReader("world"), finish)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if !got.Completed || got.Offset != 11 || string(got.Result) != `{"url":"/uploads/a.txt"}` {
+		t.Fatalf("Append = %+v", got)
+	}
+	if received != "hello world" {
+		t.Fatalf("finish received %q", received)
+	}
+	if _, err := os.Stat(store.dataPath(upload.ID)); !os.IsNotExist(err) {
+		t.Fatalf("data file was not removed: %v", err)
+	}
+
+	got, err = store.Get(upload.ID)
+	if err != nil || !got.Completed || got.Offset != 11 {
+		t.Fatalf("Get after completion = %+v, %v", got, err)
+	}
+	if _, err := store.Append(upload.ID, 11, strings.NewReader(""), finish); !errors.Is(err, ErrCompleted) {
+		t.Fatalf("Append after completion error = %v, want %v", err, ErrCompleted)
+	}
+}
+
+func TestStoreAppendInterrupted(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received string
+
+	// 中断前写入的数据保留，客户端从新的偏移量续传
+	got, err := store.Append(upload.ID, 0, &failingReader{data: "abcd", err: io.ErrUnexpectedEOF}, recordFinish(&received))
+	if err != nil || got.Offset != 4 {
+		t.Fatalf("interrupted Append = %+v, %v", got, err)
+	}
+	if _, err := store.Append(upload.ID, 4, &failingReader{err: io.ErrUnexpectedEOF}, recordFinish(&received)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Append without data error = %v", err)
+	}
+
+	got, err = store.Append(upload.ID, 4, strings.NewReader("efghij"), recordFinish(&received))
+	if err != nil || !got.Completed || received != "abcdefghij" {
+		t.Fatalf("resumed Append = %+v, %v, received %q", got, err, received)
+	}
+}
+
+func TestStoreAppendFinishRetry(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("storage unavailable")
+	_, err = store.Append(upload.ID, 0, strings.NewReader("abc"), func(*Upload, *os.File) (interface{}, error) {
+		return nil, failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Append error = %v, want %v", err, failed)
+	}
+
+	// 数据保留，在完整的偏移量发送空请求重试移交
+	var received string
+	got, err := store.Append(upload.ID, 3, strings.NewReader(""), recordFinish(&received))
+	if err != nil || !got.Completed || received != "abc" {
+		t.Fatalf("retried Append = %+v, %v, received %q", got, err, received)
+	}
+}
+
+func TestStoreEmptyUpload(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := "unset"
+	got, err := store.Append(upload.ID, 0, strings.NewReader(""), recordFinish(&received))
+	if err != nil || !got.Completed || received != "" {
+		t.Fatalf("Append = %+v, %v, received %q", got, err, received)
+	}
+}
+
+func TestStoreLocked(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.lock(upload.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Append(upload.ID, 0, strings.NewReader("abc"), nil); !errors.Is(err, ErrLocked) {
+		t.Fatalf("concurrent Append error = %v, want %v", err, ErrLocked)
+	}
+	if err := store.Delete(upload.ID); !errors.Is(err, ErrLocked) {
+		t.Fatalf("concurrent Delete error = %v, want %v", err, ErrLocked)
+	}
+	store.unlock(upload.ID)
+}
+
+func TestStoreDelete(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	upload, err := store.Create(7, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Delete(upload.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(upload.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete error = %v, want %v", err, ErrNotFound)
+	}
+	for _, path := range []string{store.dataPath(upload.ID), store.infoPath(upload.ID)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s was not removed: %v", path, err)
+		}
+	}
+	if err := store.Delete("../etc"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Delete with invalid ID error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestStoreExpiration(t *testing.T) {
+	store := newTestStore(t, time.Hour)
+	active, err := store.Create(7, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := store.Create(7, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken, err := store.Create(7, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload, err := store.load(expired.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := store.save(upload); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(expired.ID); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Get expired error = %v, want %v", err, ErrExpired)
+	}
+	if _, err := store.Append(expired.ID, 0, strings.NewReader("abc"), nil); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Append expired error = %v, want %v", err, ErrExpired)
+	}
+	// 数据文件缺失的上传同样被清理
+	if err := os.Remove(store.dataPath(broken.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := store.DeleteExpired(time.Now())
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteExpired = %d, %v, want 2", deleted, err)
+	}
+	if _, err := store.Get(active.ID); err != nil {
+		t.Fatalf("active upload was deleted: %v", err)
+	}
+	for _, id := range []string{expired.ID, broken.ID} {
+		if _, err := os.Stat(store.infoPath(id)); !os.IsNotExist(err) {
+			t.Fatalf("upload %s was not cleaned up: %v", id, err)
+		}
+	}
+
+	deleted, err = store.DeleteExpired(time.Now().Add(2 * time.Hour))
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteExpired later = %d, %v, want 1", deleted, err)
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", header: "", want: map[string]string{}},
+		{name: "pairs", header: "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential", want: map[string]string{"filename": "world_domination_plan.pdf", "is_confidential": ""}},
+		{name: "spaces", header: " target bWVkaWE= , filename YS50eHQ=", want: map[string]string{"target": "media", "filename": "a.txt"}},
+		{name: "invalid base64", header: "filename !!!", wantErr: true},
+		{name: "too many parts", header: "filename YQ== YQ==", wantErr: true},
+		{name: "empty pair", header: "filename YQ==,", wantErr: true},
+		{name: "duplicate key", header: "filename YQ==,filename Yg==", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMetadata(tt.header)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMeta) {
+					t.Fatalf("ParseMetadata error = %v, want %v", err, ErrInvalidMeta)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMetadata: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseMetadata = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Fatalf("ParseMetadata = %v, want %v", got, tt.want)
+				}
+			}
+
+			roundTrip, err := ParseMetadata(FormatMetadata(got))
+			if err != nil || len(roundTrip) != len(got) {
+				t.Fatalf("round trip = %v, %v", roundTrip, err)
+			}
+			for key, value := range got {
+				if roundTrip[key] != value {
+					t.Fatalf("round trip = %v, want %v", roundTrip, got)
+				}
+			}
+		})
+	}
+}
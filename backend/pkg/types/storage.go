@@ -4,7 +4,7 @@ import "fmt"
 
 // StorageConfig 存储配置
 type StorageConfig struct {
-	Type          string   `yaml:"type" json:"type"`                     // 存储类型：local, oss, cos, minio
+	Type          string   `yaml:"type" json:"type"`                     // 存储类型：local, oss, cos, minio, s3
 	MaxSize       int64    `yaml:"max_size" json:"max_size"`             // 最大文件大小（字节）
 	AllowedTypes  []string `yaml:"allowed_types" json:"allowed_types"`   // 允许的文件类型
 	ThumbnailSize int      `yaml:"thumbnail_size" json:"thumbnail_size"` // 缩略图大小
@@ -46,6 +46,18 @@ type StorageConfig struct {
 		URLPrefix    string `yaml:"url_prefix" json:"url_prefix"`
 		UseSSL       bool   `yaml:"use_ssl" json:"use_ssl"`
 	} `yaml:"minio" json:"minio"`
+
+	// S3配置（AWS S3 或其他兼容 S3 协议的服务）
+	S3 struct {
+		Endpoint     string `yaml:"endpoint" json:"endpoint"`
+		AccessKey    string `yaml:"access_key" json:"access_key"`
+		AccessSecret string `yaml:"access_secret" json:"access_secret"`
+		BucketName   string `yaml:"bucket_name" json:"bucket_name"`
+		Region       string `yaml:"region" json:"region"`
+		URLPrefix    string `yaml:"url_prefix" json:"url_prefix"`
+		UseSSL       bool   `yaml:"use_ssl" json:"use_ssl"`
+		PathStyle    bool   `yaml:"path_style" json:"path_style"` // 使用路径风格访问（endpoint/bucket/key）
+	} `yaml:"s3" json:"s3"`
 }
 
 // Validate 验证存储配置
@@ -118,6 +130,22 @@ func (c *StorageConfig) Validate() error {
 		if c.MinIO.BucketName == "" {
 			return fmt.Errorf("bucket_name cannot be empty for MinIO")
 		}
+	case "s3":
+		if c.S3.Endpoint == "" {
+			return fmt.Errorf("endpoint cannot be empty for S3")
+		}
+		if c.S3.AccessKey == "" {
+			return fmt.Errorf("access_key cannot be empty for S3")
+		}
+		if c.S3.AccessSecret == "" {
+			return fmt.Errorf("access_secret cannot be empty for S3")
+		}
+		if c.S3.BucketName == "" {
+			return fmt.Errorf("bucket_name cannot be empty for S3")
+		}
+		if c.S3.Region == "" {
+			return fmt.Errorf("region cannot be empty for S3")
+		}
 	default:
 		return fmt.Errorf("unsupported storage type: %s", c.Type)
 	}
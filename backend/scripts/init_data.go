@@ -114,7 +114,7 @@ func getOrCreateCategories(repo *repository.CategoryRepository) []*model.Categor
 	categoryNames := []string{"技术", "生活", "学习", "工具", "其他"}
 
 	// 获取所有分类
-	existingCategories, _, err := repo.List(1, 100, "")
+	existingCategories, _, err := repo.List(1, 100, "", 0)
 	if err != nil {
 		log.Printf("Failed to get categories: %v", err)
 	}